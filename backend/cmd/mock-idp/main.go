@@ -0,0 +1,51 @@
+// mock-idp 啟動本機模擬的 OpenID Connect 身分提供者，供開發時測試單一登入
+// 授權端點不顯示登入畫面，直接以旗標指定的使用者登入，例如：
+//
+//	go run ./cmd/mock-idp -addr :9000 -email alice@example.com -claims '{"groups":["soc-admins"]}'
+//
+// 後端設定 OIDC_ISSUER_URL=http://localhost:9000、OIDC_CLIENT_ID 與 OIDC_CLIENT_SECRET 與旗標一致即可
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "監聽位址")
+	issuer := flag.String("issuer", "http://localhost:9000", "發行者網址，須與 OIDC_ISSUER_URL 一致")
+	clientID := flag.String("client-id", "security-intel-platform", "用戶端 ID")
+	clientSecret := flag.String("client-secret", "", "用戶端密鑰，留空表示不驗證")
+	subject := flag.String("sub", "mock-user-1", "使用者 subject")
+	email := flag.String("email", "user@example.com", "使用者信箱")
+	emailVerified := flag.Bool("email-verified", true, "信箱是否已驗證")
+	username := flag.String("username", "mockuser", "preferred_username")
+	claims := flag.String("claims", "", "額外宣告（JSON 物件），例如角色或 amr")
+	flag.Parse()
+
+	provider, err := oidctest.NewProvider(*issuer, *clientID, *clientSecret)
+	if err != nil {
+		log.Fatal("無法建立模擬身分提供者:", err)
+	}
+
+	user := oidctest.User{
+		Subject:           *subject,
+		Email:             *email,
+		EmailVerified:     *emailVerified,
+		Name:              *username,
+		PreferredUsername: *username,
+	}
+	if *claims != "" {
+		if err := json.Unmarshal([]byte(*claims), &user.Claims); err != nil {
+			log.Fatal("無法解析額外宣告:", err)
+		}
+	}
+	provider.SetUser(user)
+
+	log.Printf("模擬身分提供者啟動於 %s（issuer %s，使用者 %s）", *addr, *issuer, *email)
+	log.Fatal(http.ListenAndServe(*addr, provider))
+}
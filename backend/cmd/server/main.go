@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,6 +14,8 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/collector"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/config"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/event"
+	grpchandler "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/grpc"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/handler"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/middleware"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
@@ -21,7 +24,10 @@ import (
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/database"
 	pkgjwt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/jwt"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/mail"
 	pkgmqtt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/mqtt"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/oidc"
+	pkgredis "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/redis"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -47,6 +53,11 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued to a user; accepted wherever a JWT is.
+
 func main() {
 	// 載入環境變數
 	if err := godotenv.Load(); err != nil {
@@ -93,6 +104,51 @@ func main() {
 		24, // 24小時過期
 	)
 
+	// 初始化令牌撤銷儲存；多個執行個體時須使用 Redis 才能共用登出狀態
+	var redisClient *pkgredis.Client
+	switch cfg.Auth.RevocationStore {
+	case "redis":
+		redisClient = pkgredis.NewClient(pkgredis.Options{
+			Addr:     net.JoinHostPort(cfg.Redis.Host, cfg.Redis.Port),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		pingCtx, cancelPing := context.WithTimeout(context.Background(), 5*time.Second)
+		err := redisClient.Ping(pingCtx)
+		cancelPing()
+		if err != nil {
+			logger.Error("無法連接Redis", logger.Fields{
+				"error": err.Error(),
+				"host":  cfg.Redis.Host,
+			})
+			log.Fatal("Redis連接失敗")
+		}
+		jwtManager.SetRevocationStore(pkgjwt.NewRedisRevocationStore(redisClient))
+	case "memory":
+		jwtManager.SetRevocationStore(pkgjwt.NewMemoryRevocationStore())
+	default:
+		log.Fatalf("不支援的令牌撤銷儲存: %s", cfg.Auth.RevocationStore)
+	}
+
+	// 初始化郵件發送器
+	var mailer mail.Sender
+	switch cfg.Mail.Driver {
+	case "smtp":
+		mailer = mail.NewSMTPSender(mail.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+		})
+	case "file":
+		mailer = mail.NewFileSender(cfg.Mail.FileDir, cfg.Mail.From)
+	case "log":
+		mailer = mail.NewLogSender()
+	default:
+		log.Fatalf("不支援的郵件發送方式: %s", cfg.Mail.Driver)
+	}
+
 	// 初始化MQTT客戶端
 	var mqttClient pkgmqtt.MQTTClientInterface
 	
@@ -104,7 +160,7 @@ func main() {
 		)
 		
 		if err := mqttClient.Connect(); err != nil {
-			logger.Warn("MQTT連接失敗，將在沒有實時通知的情況下運行", logger.Fields{
+			logger.Warn("MQTT連接失敗，將於背景重試，期間的通知會暫存至重新連線", logger.Fields{
 				"error": err.Error(),
 			})
 		} else {
@@ -116,34 +172,201 @@ func main() {
 
 	// 初始化Repository層
 	threatIntelRepo := repository.NewThreatIntelligenceRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+
+	// 初始化威脅事件匯流排，供即時訂閱使用
+	eventBus := event.NewBus(event.BusConfig{
+		SubscriberBuffer: cfg.Events.SubscriberBuffer,
+		HistorySize:      cfg.Events.HistorySize,
+	})
+
+	// 威脅異動寫入 outbox，由 relay 投遞到匯流排、MQTT 與 Webhook
+	eventSinks := []event.Sink{eventBus}
+	if mqttClient != nil {
+		eventSinks = append(eventSinks, event.NewMQTTSink(pkgmqtt.NewThreatNotificationPublisher(mqttClient)))
+	}
+	for _, url := range cfg.Events.WebhookURLs {
+		eventSinks = append(eventSinks, event.NewWebhookSink(url, time.Duration(cfg.Events.WebhookTimeout)*time.Second))
+	}
+
+	// 使用者註冊的 Webhook 訂閱：relay 寫入投遞佇列，由派送器各自簽章、重試與自動停用
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookDeliveryRepo := repository.NewWebhookDeliveryRepository(db)
+	webhookDispatcher := event.NewWebhookDispatcher(webhookRepo, webhookDeliveryRepo, event.WebhookDispatcherConfig{
+		Timeout:      time.Duration(cfg.Events.WebhookTimeout) * time.Second,
+		MaxAttempts:  cfg.Events.WebhookMaxAttempts,
+		DisableAfter: cfg.Events.WebhookDisableAfter,
+		Retention:    time.Duration(cfg.Events.WebhookLogRetention) * time.Hour,
+
+		AllowPrivateNetworks: cfg.Events.WebhookAllowPrivate,
+	})
+	webhookDispatcher.Start()
+	eventSinks = append(eventSinks, event.NewWebhookSubscriptionSink(webhookRepo, webhookDeliveryRepo, webhookDispatcher))
+	outboxRelay := event.NewRelay(repository.NewEventOutboxRepository(db), eventSinks, event.RelayConfig{
+		PollInterval: time.Duration(cfg.Events.RelayPollInterval) * time.Second,
+		BatchSize:    cfg.Events.RelayBatchSize,
+		MaxAttempts:  cfg.Events.RelayMaxAttempts,
+		Retention:    time.Duration(cfg.Events.OutboxRetention) * time.Hour,
+	})
+	outboxRelay.Start()
 
 	// 初始化Service層
-	threatIntelService := service.NewThreatIntelligenceService(threatIntelRepo)
-	authService := service.NewAuthService(db, jwtManager)
+	threatIntelService := service.NewThreatIntelligenceService(threatIntelRepo, outboxRelay)
+
+	// 多因素驗證，共享密鑰加密保存；未設定專用金鑰時沿用 JWT 簽章金鑰
+	mfaEncryptionKey := cfg.Auth.MFAEncryptionKey
+	if mfaEncryptionKey == "" {
+		logger.Warn("未設定 AUTH_MFA_ENCRYPTION_KEY，改用 JWT_SECRET 加密 TOTP 共享密鑰")
+		mfaEncryptionKey = getEnvOrDefault("JWT_SECRET", "your-secret-key")
+	}
+	mfaService, err := service.NewMFAService(repository.NewMFARepository(db), service.MFAServiceConfig{
+		Issuer:        cfg.Auth.MFAIssuer,
+		EncryptionKey: mfaEncryptionKey,
+		RequiredRoles: cfg.Auth.MFARequiredRoles,
+		ChallengeTTL:  time.Duration(cfg.Auth.MFAChallengeTTL) * time.Second,
+		MaxAttempts:   cfg.Auth.MFAMaxAttempts,
+	})
+	if err != nil {
+		log.Fatalf("多因素驗證初始化失敗: %v", err)
+	}
+	authService := service.NewAuthService(
+		db,
+		jwtManager,
+		refreshTokenRepo,
+		repository.NewPasswordResetTokenRepository(db),
+		repository.NewEmailVerificationTokenRepository(db),
+		mailer,
+		mfaService,
+		service.AuthServiceConfig{
+			PasswordResetTTL:           time.Duration(cfg.Auth.PasswordResetTTL) * time.Minute,
+			PasswordResetURL:           cfg.Auth.PasswordResetURL,
+			EmailVerificationTTL:       time.Duration(cfg.Auth.EmailVerificationTTL) * time.Minute,
+			EmailVerificationURL:       cfg.Auth.EmailVerificationURL,
+			VerificationResendCooldown: time.Duration(cfg.Auth.VerificationResendCooldown) * time.Second,
+			VerificationResendPerHour:  cfg.Auth.VerificationResendPerHour,
+		},
+	)
+
+	// 信箱驗證政策，未設定的角色不受限制
+	emailVerificationPolicy, err := middleware.ParseEmailVerificationPolicy(cfg.Auth.EmailVerificationPolicy)
+	if err != nil {
+		log.Fatalf("信箱驗證政策設定錯誤: %v", err)
+	}
+	requireVerifiedEmail := middleware.RequireVerifiedEmailMiddleware(authService, emailVerificationPolicy)
+	requireMFA := middleware.RequireMFAMiddleware(cfg.Auth.MFARequiredRoles)
+
+	// 角色與權限，啟動時補齊內建角色；端點以權限而非角色名稱授權
+	roleService := service.NewRoleService(
+		repository.NewRoleRepository(db),
+		authService,
+		time.Duration(cfg.Auth.RoleCacheTTL)*time.Second,
+	)
+	if err := roleService.EnsureBuiltinRoles(context.Background()); err != nil {
+		log.Fatal("無法建立內建角色:", err)
+	}
+	requirePermission := middleware.NewPermissionGuard(roleService)
+	apiKeyService := service.NewAPIKeyService(
+		apiKeyRepo,
+		cfg.Auth.APIKeyMaxPerUser,
+		time.Duration(cfg.Auth.APIKeyRotationOverlap)*time.Minute,
+	)
 
-	// 初始化威脅情報收集器
-	_ = collector.NewAbuseIPDBCollector(
-		getEnvOrDefault("ABUSEIPDB_API_KEY", ""),
+	// 初始化威脅情報收集器註冊表，並綁定到情報來源資料列
+	sourceRepo := repository.NewIntelligenceSourceRepository(db)
+	collectorRegistry := collector.NewRegistry(collector.Dependencies{
+		Service:  threatIntelService,
+		External: cfg.External,
+	})
+	if err := collectorRegistry.Sync(context.Background(), sourceRepo); err != nil {
+		log.Fatal("無法同步情報來源:", err)
+	}
+
+	// 啟動背景收集排程器
+	collectionJobRepo := repository.NewCollectionJobRepository(db)
+	collectorScheduler := collector.NewScheduler(
+		collectorRegistry,
+		sourceRepo,
+		collectionJobRepo,
 		threatIntelService,
+		collector.SchedulerConfig{
+			PollInterval:   time.Duration(cfg.Collector.PollInterval) * time.Second,
+			MaxConcurrency: cfg.Collector.MaxConcurrency,
+			Timeout:        time.Duration(cfg.Collector.Timeout) * time.Second,
+		},
 	)
-	
-	// 初始化 HIBP 收集器
-	hibpCollector := collector.NewHIBPCollector(
-		getEnvOrDefault("HIBP_API_KEY", ""),
+	if cfg.Collector.SchedulerEnabled {
+		collectorScheduler.Start()
+	}
+
+	sourceService := service.NewIntelligenceSourceService(sourceRepo, collectionJobRepo, collectorScheduler)
+	dashboardService := service.NewDashboardService(
 		threatIntelService,
+		sourceService,
+		threatIntelRepo,
+		time.Duration(cfg.Dashboard.CacheTTL)*time.Second,
 	)
 
+	// 取得 HIBP 收集器
+	registered, ok := collectorRegistry.Get(collector.HIBPSourceName)
+	if !ok {
+		log.Fatal("HIBP 收集器未綁定")
+	}
+	hibpCollector := registered.(*collector.HIBPCollector)
+
 	// 初始化Handler層
 	threatIntelHandler := handler.NewThreatIntelligenceHandler(threatIntelService)
-	collectorHandler := handler.NewCollectorHandler(threatIntelService)
+	collectorHandler := handler.NewCollectorHandler(collectorRegistry)
 	authHandler := handler.NewAuthHandler(authService)
 	hibpHandler := handler.NewHIBPHandler(hibpCollector, threatIntelService)
+	sourceHandler := handler.NewIntelligenceSourceHandler(sourceService)
+	dashboardHandler := handler.NewDashboardHandler(dashboardService)
+	webhookHandler := handler.NewWebhookHandler(service.NewWebhookService(webhookRepo, webhookDeliveryRepo, cfg.Events.WebhookAllowPrivate))
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	roleHandler := handler.NewRoleHandler(roleService)
+
+	// OpenID Connect 單一登入，未設定 OIDC_ISSUER_URL 時不註冊路由
+	var oidcHandler *handler.OIDCHandler
+	if cfg.OIDC.Enabled() {
+		roleMapping, err := service.ParseOIDCRoleMapping(cfg.OIDC.RoleMapping)
+		if err != nil {
+			log.Fatalf("單一登入角色對應設定錯誤: %v", err)
+		}
+		if err := service.ValidateOIDCRoles(context.Background(), roleService, roleMapping, model.UserRole(cfg.OIDC.DefaultRole)); err != nil {
+			log.Fatalf("單一登入角色對應引用了不存在的角色: %v", err)
+		}
+		oidcService := service.NewOIDCService(
+			db,
+			oidc.NewProvider(oidc.Config{
+				IssuerURL:    cfg.OIDC.IssuerURL,
+				ClientID:     cfg.OIDC.ClientID,
+				ClientSecret: cfg.OIDC.ClientSecret,
+				RedirectURL:  cfg.OIDC.RedirectURL,
+				Scopes:       cfg.OIDC.Scopes,
+			}),
+			repository.NewOIDCRepository(db),
+			authService,
+			roleService,
+			service.OIDCServiceConfig{
+				RoleClaim:   cfg.OIDC.RoleClaim,
+				RoleMapping: roleMapping,
+				DefaultRole: model.UserRole(cfg.OIDC.DefaultRole),
+				StateTTL:    time.Duration(cfg.OIDC.StateTTL) * time.Second,
+			},
+		)
+		oidcHandler = handler.NewOIDCHandler(oidcService)
+		logger.Info("已啟用 OpenID Connect 單一登入", logger.Fields{
+			"issuer": cfg.OIDC.IssuerURL,
+		})
+	}
 
-	// 創建gRPC服務器
-	// TODO: 修復 gRPC 服務器
-	// grpcServer := grpc.NewServer()
-	// threatGRPCHandler := grpchandler.NewThreatIntelligenceGRPCServer(threatIntelService)
-	// proto.RegisterThreatIntelligenceServiceServer(grpcServer, threatGRPCHandler)
+	// 創建gRPC服務器，以與 REST 相同的 JWT／API 金鑰規則認證每個 RPC
+	grpcAuth := grpchandler.NewAuthInterceptor(jwtManager, apiKeyService, roleService, authService, emailVerificationPolicy).RequireMFA(cfg.Auth.MFARequiredRoles...)
+	grpcServer := grpchandler.NewServer(grpchandler.ServerConfig{
+		Port:       cfg.Server.GRPCPort,
+		Reflection: cfg.Server.GRPCReflection,
+	}, threatIntelService, eventBus, grpcAuth.ServerOptions()...)
 
 	// 設定Gin模式
 	if cfg.Environment == "production" {
@@ -159,7 +382,9 @@ func main() {
 	setupMiddlewares(r, cfg, jwtManager)
 
 	// 設定路由
-	setupRoutes(r, threatIntelHandler, collectorHandler, authHandler, hibpHandler, jwtManager)
+	setupRoutes(r, threatIntelHandler, collectorHandler, authHandler, hibpHandler, sourceHandler, dashboardHandler, webhookHandler, apiKeyHandler, mfaHandler, oidcHandler, roleHandler, jwtManager, apiKeyService, requireVerifiedEmail, requireMFA, requirePermission, func() gin.H {
+		return serviceStatus(grpcServer, mqttClient)
+	})
 
 	// 創建HTTP伺服器
 	srv := &http.Server{
@@ -168,28 +393,17 @@ func main() {
 	}
 
 	// 啟動gRPC伺服器
-	// TODO: 修復 gRPC 服務器
-	// grpcPort := getEnvOrDefault("GRPC_PORT", "9090")
-	// go func() {
-	// 	lis, err := net.Listen("tcp", ":"+grpcPort)
-	// 	if err != nil {
-	// 		logger.Error("gRPC伺服器監聽失敗", logger.Fields{
-	// 			"error": err.Error(),
-	// 			"port":  grpcPort,
-	// 		})
-	// 		return
-	// 	}
-
-	// 	logger.Info("gRPC伺服器啟動", logger.Fields{
-	// 		"port": grpcPort,
-	// 	})
-
-	// 	if err := grpcServer.Serve(lis); err != nil {
-	// 		logger.Error("gRPC伺服器啟動失敗", logger.Fields{
-	// 			"error": err.Error(),
-	// 		})
-	// 	}
-	// }()
+	if err := grpcServer.Start(); err != nil {
+		logger.Error("gRPC伺服器啟動失敗", logger.Fields{
+			"error": err.Error(),
+			"port":  cfg.Server.GRPCPort,
+		})
+	} else {
+		logger.Info("gRPC伺服器啟動", logger.Fields{
+			"port":       cfg.Server.GRPCPort,
+			"reflection": cfg.Server.GRPCReflection,
+		})
+	}
 
 	// 啟動HTTP伺服器
 	go func() {
@@ -223,15 +437,41 @@ func main() {
 		})
 	}
 
-	// 關閉gRPC伺服器
-	// TODO: 修復 gRPC 服務器
-	// grpcServer.GracefulStop()
+	// 停止收集排程器，等待執行中的收集任務寫回結果
+	if err := collectorScheduler.Stop(ctx); err != nil {
+		logger.Error("收集排程器關閉失敗", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+
+	// 停止outbox relay，未投遞的事件保留在outbox中，重啟後繼續投遞
+	if err := outboxRelay.Stop(ctx); err != nil {
+		logger.Error("outbox relay關閉失敗", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+
+	// 停止Webhook派送器，未送出的紀錄保留在投遞佇列中
+	if err := webhookDispatcher.Stop(ctx); err != nil {
+		logger.Error("Webhook派送器關閉失敗", logger.Fields{
+			"error": err.Error(),
+		})
+	}
+
+	// 關閉事件匯流排以結束訂閱串流，再關閉gRPC伺服器並等待進行中的 RPC 完成
+	eventBus.Close()
+	grpcServer.Stop(ctx)
 
 	// 關閉MQTT連接
 	if mqttClient != nil {
 		mqttClient.Disconnect()
 	}
 
+	// 關閉Redis連接
+	if redisClient != nil {
+		redisClient.Close()
+	}
+
 	logger.Info("伺服器已關閉")
 }
 
@@ -253,19 +493,21 @@ func setupMiddlewares(r *gin.Engine, cfg *config.Config, jwtManager *pkgjwt.JWTM
 }
 
 // setupRoutes 設定API路由
-func setupRoutes(r *gin.Engine, threatIntelHandler *handler.ThreatIntelligenceHandler, collectorHandler *handler.CollectorHandler, authHandler *handler.AuthHandler, hibpHandler *handler.HIBPHandler, jwtManager *pkgjwt.JWTManager) {
-	// 健康檢查端點
+func setupRoutes(r *gin.Engine, threatIntelHandler *handler.ThreatIntelligenceHandler, collectorHandler *handler.CollectorHandler, authHandler *handler.AuthHandler, hibpHandler *handler.HIBPHandler, sourceHandler *handler.IntelligenceSourceHandler, dashboardHandler *handler.DashboardHandler, webhookHandler *handler.WebhookHandler, apiKeyHandler *handler.APIKeyHandler, mfaHandler *handler.MFAHandler, oidcHandler *handler.OIDCHandler, roleHandler *handler.RoleHandler, jwtManager *pkgjwt.JWTManager, apiKeyService service.APIKeyService, requireVerifiedEmail gin.HandlerFunc, requireMFA gin.HandlerFunc, requirePermission middleware.PermissionGuard, services func() gin.H) {
+	// 健康檢查端點，回報各服務的實際狀態；gRPC 未運行時為 degraded
 	r.GET("/health", func(c *gin.Context) {
+		status := services()
+		overall, message := "ok", "資安情報平台 API 正常運行"
+		if status["grpc"] != string(grpchandler.StateRunning) {
+			overall, message = "degraded", "資安情報平台 API 部分服務未運行"
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"status":    "ok",
-			"message":   "資安情報平台 API 正常運行",
+			"status":    overall,
+			"message":   message,
 			"version":   "1.0.0",
 			"timestamp": time.Now().UTC(),
-			"services": gin.H{
-				"http": "running",
-				"grpc": "running",
-				"mqtt": "available",
-			},
+			"services":  status,
 		})
 	})
 
@@ -276,47 +518,104 @@ func setupRoutes(r *gin.Engine, threatIntelHandler *handler.ThreatIntelligenceHa
 	api := r.Group("/api/v1")
 	{
 		// 認證路由（公開）
-		authHandler.RegisterRoutes(api)
+		authHandler.RegisterRoutes(api, middleware.JWTAuthMiddleware(jwtManager))
+
+		// 多因素驗證設定路由，不套用多因素驗證要求，角色要求啟用的使用者才能在此完成設定
+		mfaHandler.RegisterRoutes(api, middleware.JWTAuthMiddleware(jwtManager))
+
+		// 單一登入路由（公開），僅在設定身分提供者時註冊
+		if oidcHandler != nil {
+			oidcHandler.RegisterRoutes(api)
+		}
+
+		// API 金鑰自助管理路由，僅接受 JWT，避免以金鑰再簽發金鑰
+		apiKeyManagement := api.Group("")
+		apiKeyManagement.Use(middleware.JWTAuthMiddleware(jwtManager), requireVerifiedEmail, requireMFA)
+		apiKeyHandler.RegisterRoutes(apiKeyManagement)
 
 		// 需要認證的路由
 		authenticated := api.Group("")
-		authenticated.Use(middleware.JWTAuthMiddleware(jwtManager))
+		authenticated.Use(middleware.CombinedAuthMiddleware(jwtManager, apiKeyService), requireVerifiedEmail, requireMFA)
 		{
 			// 威脅情報路由
 			threatIntel := authenticated.Group("/threat-intelligence")
 			{
+				canRead := requirePermission(model.PermissionThreatRead)
+				canWrite := requirePermission(model.PermissionThreatWrite)
+				canDelete := requirePermission(model.PermissionThreatDelete)
+
 				// 基本CRUD操作
-				threatIntel.GET("", threatIntelHandler.ListThreats)
-				threatIntel.POST("", threatIntelHandler.CreateThreat)
-				threatIntel.GET("/:id", threatIntelHandler.GetThreat)
-				threatIntel.PUT("/:id", threatIntelHandler.UpdateThreat)
-				threatIntel.DELETE("/:id", threatIntelHandler.DeleteThreat)
+				threatIntel.GET("", canRead, threatIntelHandler.ListThreats)
+				threatIntel.POST("", canWrite, threatIntelHandler.CreateThreat)
+				threatIntel.GET("/:id", canRead, threatIntelHandler.GetThreat)
+				threatIntel.PUT("/:id", canWrite, threatIntelHandler.UpdateThreat)
+				threatIntel.DELETE("/:id", canDelete, threatIntelHandler.DeleteThreat)
 
 				// 搜尋和查詢
-				threatIntel.GET("/search", threatIntelHandler.SearchThreats)
-				threatIntel.GET("/lookup/ip", threatIntelHandler.LookupIP)
-				threatIntel.GET("/lookup/domain", threatIntelHandler.LookupDomain)
+				threatIntel.GET("/search", canRead, threatIntelHandler.SearchThreats)
+				threatIntel.GET("/lookup/ip", canRead, threatIntelHandler.LookupIP)
+				threatIntel.GET("/lookup/domain", canRead, threatIntelHandler.LookupDomain)
+				threatIntel.GET("/lookup/:type", canRead, threatIntelHandler.LookupIndicator)
 				
 				// 統計和分析
-				threatIntel.GET("/statistics", threatIntelHandler.GetStatistics)
+				threatIntel.GET("/statistics", canRead, threatIntelHandler.GetStatistics)
+				threatIntel.GET("/stats", canRead, threatIntelHandler.GetStats)
 				
 				// 批量操作
-				threatIntel.POST("/batch", threatIntelHandler.BulkCreateThreats)
-				threatIntel.PUT("/batch", threatIntelHandler.BulkUpdateThreats)
-				threatIntel.DELETE("/batch", threatIntelHandler.BulkDeleteThreats)
+				threatIntel.POST("/batch", canWrite, threatIntelHandler.BulkCreateThreats)
+				threatIntel.PUT("/batch", canWrite, threatIntelHandler.BulkUpdateThreats)
+				threatIntel.DELETE("/batch", canDelete, threatIntelHandler.BulkDeleteThreats)
 			}
 
 			// 收集器路由
 			collector := authenticated.Group("/collector")
+			collector.Use(requirePermission(model.PermissionCollectorRun))
 			{
 				collector.POST("/collect-ip", collectorHandler.CollectIPThreatIntel)
 				collector.POST("/collect-ips", collectorHandler.CollectBulkIPThreatIntel)
 			}
 
 			// HIBP 路由
-			hibpHandler.RegisterRoutes(authenticated)
+			hibpHandler.RegisterRoutes(authenticated, requirePermission)
+
+			// 儀表板路由
+			dashboard := authenticated.Group("")
+			dashboard.Use(requirePermission(model.PermissionDashboardRead))
+			dashboardHandler.RegisterRoutes(dashboard)
+
+			// Webhook 訂閱路由
+			webhooks := authenticated.Group("")
+			webhooks.Use(requirePermission(model.PermissionWebhookManage))
+			webhookHandler.RegisterRoutes(webhooks)
+
+			// 情報來源與收集任務管理路由
+			sources := authenticated.Group("")
+			sources.Use(requirePermission(model.PermissionSourceManage))
+			sourceHandler.RegisterRoutes(sources)
+
+			// 角色定義與使用者角色管理路由
+			roleHandler.RegisterRoutes(authenticated, requirePermission)
+		}
+	}
+}
+
+// serviceStatus 取得各服務的實際狀態
+func serviceStatus(grpcServer *grpchandler.Server, mqttClient pkgmqtt.MQTTClientInterface) gin.H {
+	grpcState, _ := grpcServer.State()
+
+	mqttState := "disabled"
+	if mqttClient != nil {
+		mqttState = "disconnected"
+		if mqttClient.IsConnected() {
+			mqttState = "connected"
 		}
 	}
+
+	return gin.H{
+		"http": "running",
+		"grpc": string(grpcState),
+		"mqtt": mqttState,
+	}
 }
 
 // getEnvOrDefault 取得環境變數或預設值
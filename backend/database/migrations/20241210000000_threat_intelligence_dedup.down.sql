@@ -0,0 +1,7 @@
+-- 移除收集任務的新建立/更新計數
+ALTER TABLE collection_jobs DROP COLUMN IF EXISTS records_updated;
+ALTER TABLE collection_jobs DROP COLUMN IF EXISTS records_created;
+
+-- 移除威脅情報去重鍵（已合併的重複記錄無法還原）
+DROP INDEX IF EXISTS idx_threat_intelligence_fingerprint;
+ALTER TABLE threat_intelligence DROP COLUMN IF EXISTS fingerprint;
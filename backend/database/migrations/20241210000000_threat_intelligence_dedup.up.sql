@@ -0,0 +1,50 @@
+-- 威脅情報去重：依自然鍵（來源 + IP + 域名 + 外部 ID）計算 fingerprint
+-- 計算方式需與 model.ThreatIntelligence.ComputeFingerprint 保持一致
+ALTER TABLE threat_intelligence ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(64);
+
+UPDATE threat_intelligence
+SET fingerprint = encode(sha256(convert_to(
+    source || '|' || host(ip_address) || '|' || COALESCE(domain, '') || '|' || COALESCE(external_id, ''),
+    'UTF8')), 'hex')
+WHERE fingerprint IS NULL;
+
+-- 合併既有的重複記錄：保留最早出現的記錄，並帶入最後出現時間與所有標籤
+WITH ranked AS (
+    SELECT id,
+           ROW_NUMBER() OVER (PARTITION BY fingerprint ORDER BY first_seen ASC, created_at ASC) AS rn
+    FROM threat_intelligence
+),
+merged AS (
+    SELECT t.fingerprint,
+           MAX(t.last_seen) AS last_seen,
+           ARRAY(
+               SELECT DISTINCT tag
+               FROM threat_intelligence t2, unnest(COALESCE(t2.tags, '{}')) AS tag
+               WHERE t2.fingerprint = t.fingerprint
+           ) AS tags
+    FROM threat_intelligence t
+    GROUP BY t.fingerprint
+    HAVING COUNT(*) > 1
+)
+UPDATE threat_intelligence t
+SET last_seen = merged.last_seen,
+    tags = merged.tags
+FROM ranked, merged
+WHERE t.id = ranked.id
+  AND ranked.rn = 1
+  AND merged.fingerprint = t.fingerprint;
+
+DELETE FROM threat_intelligence t
+USING (
+    SELECT id,
+           ROW_NUMBER() OVER (PARTITION BY fingerprint ORDER BY first_seen ASC, created_at ASC) AS rn
+    FROM threat_intelligence
+) ranked
+WHERE t.id = ranked.id
+  AND ranked.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_threat_intelligence_fingerprint ON threat_intelligence(fingerprint);
+
+-- 收集任務區分新建立與合併更新的記錄數
+ALTER TABLE collection_jobs ADD COLUMN IF NOT EXISTS records_created INTEGER DEFAULT 0;
+ALTER TABLE collection_jobs ADD COLUMN IF NOT EXISTS records_updated INTEGER DEFAULT 0;
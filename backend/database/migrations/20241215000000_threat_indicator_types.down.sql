@@ -0,0 +1,32 @@
+DROP INDEX IF EXISTS idx_threat_intelligence_cidr;
+DROP INDEX IF EXISTS idx_threat_intelligence_file_hash;
+DROP INDEX IF EXISTS idx_threat_intelligence_indicator_value;
+DROP INDEX IF EXISTS idx_threat_intelligence_indicator_type;
+DROP INDEX IF EXISTS idx_threat_intelligence_fingerprint;
+
+-- 無 IP 的指標無法以舊結構表示
+DELETE FROM threat_intelligence WHERE ip_address IS NULL AND indicator_type <> 'domain';
+UPDATE threat_intelligence SET ip_address = '0.0.0.0'::inet WHERE ip_address IS NULL;
+
+UPDATE threat_intelligence
+SET fingerprint = encode(sha256(convert_to(
+    source || '|' || host(ip_address) || '|' || COALESCE(domain, '') || '|' || COALESCE(external_id, ''),
+    'UTF8')), 'hex');
+
+DELETE FROM threat_intelligence t
+USING (
+    SELECT id,
+           ROW_NUMBER() OVER (PARTITION BY fingerprint ORDER BY first_seen ASC, created_at ASC) AS rn
+    FROM threat_intelligence
+) ranked
+WHERE t.id = ranked.id
+  AND ranked.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_threat_intelligence_fingerprint ON threat_intelligence(fingerprint);
+
+ALTER TABLE threat_intelligence DROP CONSTRAINT IF EXISTS chk_threat_intelligence_indicator_type;
+ALTER TABLE threat_intelligence DROP COLUMN IF EXISTS file_hash;
+ALTER TABLE threat_intelligence DROP COLUMN IF EXISTS url;
+ALTER TABLE threat_intelligence DROP COLUMN IF EXISTS indicator_value;
+ALTER TABLE threat_intelligence DROP COLUMN IF EXISTS indicator_type;
+ALTER TABLE threat_intelligence ALTER COLUMN ip_address SET NOT NULL;
@@ -0,0 +1,98 @@
+-- 威脅情報指標類型：除 IP 外支援域名、URL、檔案雜湊、Email、CIDR、ASN
+ALTER TABLE threat_intelligence ALTER COLUMN ip_address DROP NOT NULL;
+
+ALTER TABLE threat_intelligence ADD COLUMN IF NOT EXISTS indicator_type VARCHAR(20);
+ALTER TABLE threat_intelligence ADD COLUMN IF NOT EXISTS indicator_value VARCHAR(2048);
+ALTER TABLE threat_intelligence ADD COLUMN IF NOT EXISTS url TEXT;
+ALTER TABLE threat_intelligence ADD COLUMN IF NOT EXISTS file_hash VARCHAR(64);
+
+-- 既有記錄皆為 IP 指標
+UPDATE threat_intelligence
+SET indicator_type = CASE WHEN family(ip_address) = 6 THEN 'ipv6' ELSE 'ipv4' END,
+    indicator_value = host(ip_address)
+WHERE indicator_type IS NULL;
+
+-- HIBP 外洩事件先前以 0.0.0.0 佔位，改為域名指標；舊版收集器對沒有域名的事件寫入空字串
+UPDATE threat_intelligence
+SET indicator_type = 'domain',
+    indicator_value = lower(domain),
+    ip_address = NULL
+WHERE source = 'HaveIBeenPwned'
+  AND ip_address = '0.0.0.0'::inet
+  AND NULLIF(domain, '') IS NOT NULL;
+
+-- 沒有域名的外洩事件沒有可用的指標（目前的收集器也會略過），保留下來會成為 0.0.0.0 的 IPv4 指標，
+-- 使 IP 查詢 0.0.0.0 時命中所有外洩事件，因此刪除
+DELETE FROM threat_intelligence
+WHERE source = 'HaveIBeenPwned'
+  AND ip_address = '0.0.0.0'::inet
+  AND NULLIF(domain, '') IS NULL;
+
+ALTER TABLE threat_intelligence ALTER COLUMN indicator_type SET DEFAULT 'ipv4';
+ALTER TABLE threat_intelligence ALTER COLUMN indicator_type SET NOT NULL;
+ALTER TABLE threat_intelligence ALTER COLUMN indicator_value SET NOT NULL;
+
+ALTER TABLE threat_intelligence ADD CONSTRAINT chk_threat_intelligence_indicator_type
+    CHECK (indicator_type IN ('ipv4', 'ipv6', 'cidr', 'domain', 'url', 'md5', 'sha1', 'sha256', 'email', 'asn'));
+
+-- 依新的自然鍵（來源 + 指標類型 + 指標值 + 外部 ID）重新計算 fingerprint
+-- 計算方式需與 model.ThreatIntelligence.ComputeFingerprint 保持一致
+DROP INDEX IF EXISTS idx_threat_intelligence_fingerprint;
+
+UPDATE threat_intelligence
+SET fingerprint = encode(sha256(convert_to(
+    source || '|' || indicator_type || '|' || indicator_value || '|' || COALESCE(external_id, ''),
+    'UTF8')), 'hex');
+
+-- 合併重新計算後重複的記錄：保留最早出現的記錄，並帶入最早與最後出現時間、所有標籤與 metadata
+-- metadata 依最後出現時間由舊到新合併，同名欄位以較新的值為準，與收集時的 upsert 行為一致
+WITH ranked AS (
+    SELECT id,
+           ROW_NUMBER() OVER (PARTITION BY fingerprint ORDER BY first_seen ASC, created_at ASC) AS rn
+    FROM threat_intelligence
+),
+merged AS (
+    SELECT t.fingerprint,
+           MIN(t.first_seen) AS first_seen,
+           MAX(t.last_seen) AS last_seen,
+           ARRAY(
+               SELECT DISTINCT tag
+               FROM threat_intelligence t2, unnest(COALESCE(t2.tags, '{}')) AS tag
+               WHERE t2.fingerprint = t.fingerprint
+           ) AS tags,
+           (
+               SELECT jsonb_object_agg(kv.key, kv.value ORDER BY t2.last_seen ASC, t2.created_at ASC)
+               FROM threat_intelligence t2,
+                    jsonb_each(CASE WHEN jsonb_typeof(t2.metadata) = 'object' THEN t2.metadata ELSE '{}'::jsonb END) AS kv
+               WHERE t2.fingerprint = t.fingerprint
+           ) AS metadata
+    FROM threat_intelligence t
+    GROUP BY t.fingerprint
+    HAVING COUNT(*) > 1
+)
+UPDATE threat_intelligence t
+SET first_seen = LEAST(t.first_seen, merged.first_seen),
+    last_seen = GREATEST(t.last_seen, merged.last_seen),
+    tags = merged.tags,
+    metadata = COALESCE(merged.metadata, t.metadata)
+FROM ranked, merged
+WHERE t.id = ranked.id
+  AND ranked.rn = 1
+  AND merged.fingerprint = t.fingerprint;
+
+DELETE FROM threat_intelligence t
+USING (
+    SELECT id,
+           ROW_NUMBER() OVER (PARTITION BY fingerprint ORDER BY first_seen ASC, created_at ASC) AS rn
+    FROM threat_intelligence
+) ranked
+WHERE t.id = ranked.id
+  AND ranked.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_threat_intelligence_fingerprint ON threat_intelligence(fingerprint);
+CREATE INDEX IF NOT EXISTS idx_threat_intelligence_indicator_type ON threat_intelligence(indicator_type);
+CREATE INDEX IF NOT EXISTS idx_threat_intelligence_indicator_value ON threat_intelligence(indicator_value);
+CREATE INDEX IF NOT EXISTS idx_threat_intelligence_file_hash ON threat_intelligence(file_hash);
+-- 供 IP 查詢比對所屬網段
+CREATE INDEX IF NOT EXISTS idx_threat_intelligence_cidr ON threat_intelligence
+    USING gist ((indicator_value::cidr) inet_ops) WHERE indicator_type = 'cidr';
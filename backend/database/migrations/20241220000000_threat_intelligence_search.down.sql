@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_threat_intelligence_search_vector;
+DROP TRIGGER IF EXISTS trg_threat_intelligence_search_vector ON threat_intelligence;
+DROP FUNCTION IF EXISTS threat_intelligence_search_vector_update();
+ALTER TABLE threat_intelligence DROP COLUMN IF EXISTS search_vector;
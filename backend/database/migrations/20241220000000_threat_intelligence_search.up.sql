@@ -0,0 +1,33 @@
+-- 威脅情報全文檢索：描述、域名、標籤、ISP 與元資料
+-- 使用 simple 設定以保留指標原貌（不做詞幹與停用詞處理），需與 repository 的 tsquery 設定一致
+ALTER TABLE threat_intelligence ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION threat_intelligence_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', COALESCE(NEW.indicator_value, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.domain, '')), 'A') ||
+        -- 拆分域名標籤，讓搜尋 "example" 也能命中 evil.example.com
+        setweight(to_tsvector('simple', COALESCE(replace(NEW.domain, '.', ' '), '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(array_to_string(NEW.tags, ' '), '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.description, '')), 'C') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.isp, '')), 'C') ||
+        setweight(jsonb_to_tsvector('simple', COALESCE(NEW.metadata, '{}'::jsonb), '["string", "numeric"]'), 'D');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_threat_intelligence_search_vector ON threat_intelligence;
+CREATE TRIGGER trg_threat_intelligence_search_vector
+    BEFORE INSERT OR UPDATE OF indicator_value, domain, tags, description, isp, metadata
+    ON threat_intelligence
+    FOR EACH ROW
+    EXECUTE FUNCTION threat_intelligence_search_vector_update();
+
+-- 回填既有記錄（由觸發器計算 search_vector），回填期間不更新 updated_at
+ALTER TABLE threat_intelligence DISABLE TRIGGER update_threat_intelligence_updated_at;
+UPDATE threat_intelligence SET description = description;
+ALTER TABLE threat_intelligence ENABLE TRIGGER update_threat_intelligence_updated_at;
+
+CREATE INDEX IF NOT EXISTS idx_threat_intelligence_search_vector ON threat_intelligence USING gin(search_vector);
@@ -0,0 +1,2 @@
+-- 移除交易式 outbox（尚未投遞的事件會一併遺失）
+DROP TABLE IF EXISTS event_outbox;
@@ -0,0 +1,20 @@
+-- 交易式 outbox：威脅情報異動與事件在同一交易寫入，由 relay 以至少一次語意投遞到 MQTT、gRPC 串流與 webhook
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(20) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    delivered_sinks TEXT[] DEFAULT '{}',
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP,
+    CONSTRAINT chk_event_outbox_status CHECK (status IN ('pending', 'delivered', 'dead'))
+);
+
+-- relay 依序領取到期的待投遞事件
+CREATE INDEX IF NOT EXISTS idx_event_outbox_pending ON event_outbox(next_attempt_at, id) WHERE status = 'pending';
+-- 清理已投遞事件與查詢死信
+CREATE INDEX IF NOT EXISTS idx_event_outbox_status_created_at ON event_outbox(status, created_at);
@@ -0,0 +1,4 @@
+-- 移除 Webhook 訂閱與投遞紀錄
+DROP TRIGGER IF EXISTS update_webhooks_updated_at ON webhooks;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,2 @@
+-- 移除 API 金鑰雜湊索引
+DROP INDEX IF EXISTS idx_api_keys_key_hash;
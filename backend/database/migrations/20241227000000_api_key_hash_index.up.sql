@@ -0,0 +1,2 @@
+-- API 金鑰以雜湊查詢認證，建立唯一索引避免全表掃描並防止重複雜湊
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys(key_hash);
@@ -0,0 +1,7 @@
+-- 移除 API 金鑰自助管理欄位
+DROP INDEX IF EXISTS idx_api_keys_user_created;
+
+ALTER TABLE api_keys
+    DROP COLUMN IF EXISTS rotated_from_id,
+    DROP COLUMN IF EXISTS revoked_at,
+    DROP COLUMN IF EXISTS key_preview;
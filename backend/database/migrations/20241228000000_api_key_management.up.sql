@@ -0,0 +1,7 @@
+-- 自助管理 API 金鑰：保存遮蔽後的金鑰供辨識，並記錄撤銷與輪替關係
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS key_preview VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS rotated_from_id UUID REFERENCES api_keys(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_created ON api_keys(user_id, created_at DESC);
@@ -0,0 +1,2 @@
+-- 移除刷新令牌家族
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,2 @@
+-- 移除密碼重設令牌
+DROP TABLE IF EXISTS password_reset_tokens;
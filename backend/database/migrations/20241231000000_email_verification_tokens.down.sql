@@ -0,0 +1,2 @@
+-- 移除信箱驗證令牌
+DROP TABLE IF EXISTS email_verification_tokens;
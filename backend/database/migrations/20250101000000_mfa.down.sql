@@ -0,0 +1,6 @@
+-- 移除 TOTP 多因素驗證
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS mfa_verified;
+DROP TRIGGER IF EXISTS update_user_mfa_updated_at ON user_mfa;
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
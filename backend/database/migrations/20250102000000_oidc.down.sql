@@ -0,0 +1,3 @@
+-- 移除 OpenID Connect 單一登入
+DROP TABLE IF EXISTS oidc_login_states;
+DROP TABLE IF EXISTS user_identities;
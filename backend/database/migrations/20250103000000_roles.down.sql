@@ -0,0 +1,9 @@
+-- 移除權限式角色存取控制，指派自訂角色的使用者改回 basic
+CREATE TYPE user_role AS ENUM ('admin', 'premium', 'basic');
+UPDATE users SET role = 'basic' WHERE role NOT IN ('admin', 'premium', 'basic');
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE user_role USING role::user_role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'basic';
+
+DROP TRIGGER IF EXISTS update_roles_updated_at ON roles;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,2 @@
+-- 恢復投遞紀錄的回應內容欄位，移除前的內容無法復原
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS response_body TEXT;
@@ -0,0 +1,2 @@
+-- 投遞紀錄不再保存目標端點的回應內容，避免透過投遞日誌讀取內部服務回應；既有內容一併移除
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS response_body;
@@ -0,0 +1,2 @@
+-- 移除角色指派來源，單一登入恢復每次登入同步所有使用者的角色
+ALTER TABLE users DROP COLUMN IF EXISTS role_assigned_by_admin;
@@ -0,0 +1,2 @@
+-- 記錄角色是否由管理員指派，管理員指派的角色優先於單一登入的角色宣告，登入時不再同步覆寫
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role_assigned_by_admin BOOLEAN NOT NULL DEFAULT false;
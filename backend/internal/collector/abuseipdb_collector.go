@@ -11,10 +11,45 @@ import (
 	"time"
 
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
 	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
 )
 
+const (
+	// AbuseIPDBSourceName AbuseIPDB 情報來源名稱
+	AbuseIPDBSourceName = "AbuseIPDB"
+
+	abuseIPDBBaseURL = "https://api.abuseipdb.com/api/v2"
+
+	// abuseIPDBBlacklistConfidence 排程收集黑名單的最低信心分數
+	abuseIPDBBlacklistConfidence = 90
+	// abuseIPDBBlacklistLimit 排程收集黑名單的最大筆數
+	abuseIPDBBlacklistLimit = 1000
+)
+
+func init() {
+	baseURL := abuseIPDBBaseURL
+	Register(Definition{
+		Name:               AbuseIPDBSourceName,
+		URL:                &baseURL,
+		APIKeyRequired:     true,
+		CollectionInterval: 3600,
+		Factory: func(source *model.IntelligenceSource, deps Dependencies) (Collector, error) {
+			if deps.External.AbuseIPDBKey == "" {
+				return nil, dto.ErrCollectorNotConfigured
+			}
+			baseURL, err := sourceBaseURL(source, abuseIPDBBaseURL)
+			if err != nil {
+				return nil, err
+			}
+			c := NewAbuseIPDBCollector(deps.External.AbuseIPDBKey, deps.Service)
+			c.baseURL = baseURL
+			return c, nil
+		},
+	})
+}
+
 // AbuseIPDBCollector AbuseIPDB 威脅情報收集器
 type AbuseIPDBCollector struct {
 	apiKey  string
@@ -45,11 +80,24 @@ type AbuseIPDBResponse struct {
 	Data AbuseIPDBData `json:"data"`
 }
 
+// AbuseIPDBBlacklistEntry AbuseIPDB 黑名單項目
+type AbuseIPDBBlacklistEntry struct {
+	IPAddress            string `json:"ipAddress"`
+	CountryCode          string `json:"countryCode"`
+	AbuseConfidenceScore int    `json:"abuseConfidenceScore"`
+	LastReportedAt       string `json:"lastReportedAt"`
+}
+
+// AbuseIPDBBlacklistResponse AbuseIPDB 黑名單回應結構
+type AbuseIPDBBlacklistResponse struct {
+	Data []AbuseIPDBBlacklistEntry `json:"data"`
+}
+
 // NewAbuseIPDBCollector 建立新的 AbuseIPDB 收集器
 func NewAbuseIPDBCollector(apiKey string, service service.ThreatIntelligenceService) *AbuseIPDBCollector {
 	return &AbuseIPDBCollector{
 		apiKey:  apiKey,
-		baseURL: "https://api.abuseipdb.com/api/v2",
+		baseURL: abuseIPDBBaseURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -65,7 +113,7 @@ func (c *AbuseIPDBCollector) CollectIPThreatIntel(ctx context.Context, ipAddress
 	}
 
 	// 呼叫 AbuseIPDB API
-	response, err := c.queryAbuseIPDB(ipAddress)
+	response, err := c.queryAbuseIPDB(ctx, ipAddress)
 	if err != nil {
 		pkglogger.Error("AbuseIPDB API 查詢失敗", pkglogger.Fields{
 			"ip_address": ipAddress,
@@ -142,11 +190,86 @@ func (c *AbuseIPDBCollector) CollectBulkIPThreatIntel(ctx context.Context, ipAdd
 	return successful, failed
 }
 
+// Name 收集器名稱
+func (c *AbuseIPDBCollector) Name() string {
+	return AbuseIPDBSourceName
+}
+
+// Capabilities 收集器能力
+func (c *AbuseIPDBCollector) Capabilities() Capabilities {
+	return Capabilities{
+		IndicatorTypes: []string{"ipv4", "ipv6"},
+		Scheduled:      true,
+		OnDemand:       true,
+		RequiresAPIKey: true,
+	}
+}
+
+// HealthCheck 健康檢查
+func (c *AbuseIPDBCollector) HealthCheck(ctx context.Context) error {
+	if c.apiKey == "" {
+		return dto.ErrCollectorNotConfigured
+	}
+	_, err := c.queryAbuseIPDB(ctx, "127.0.0.1")
+	return err
+}
+
+// Collect 收集 AbuseIPDB 黑名單中的高信心惡意 IP
+func (c *AbuseIPDBCollector) Collect(ctx context.Context) ([]*dto.ThreatIntelligenceCreateRequest, error) {
+	url := fmt.Sprintf("%s/blacklist", c.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("建立 HTTP 請求失敗: %w", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("confidenceMinimum", strconv.Itoa(abuseIPDBBlacklistConfidence))
+	q.Add("limit", strconv.Itoa(abuseIPDBBlacklistLimit))
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Set("Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP 請求失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API 返回錯誤狀態碼 %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response AbuseIPDBBlacklistResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("解析 JSON 回應失敗: %w", err)
+	}
+
+	records := make([]*dto.ThreatIntelligenceCreateRequest, 0, len(response.Data))
+	for _, entry := range response.Data {
+		record := c.convertToThreatIntel(&AbuseIPDBResponse{
+			Data: AbuseIPDBData{
+				IPAddress:            entry.IPAddress,
+				AbuseConfidenceScore: entry.AbuseConfidenceScore,
+				CountryCode:          entry.CountryCode,
+				LastReportedAt:       entry.LastReportedAt,
+			},
+		})
+		if record != nil {
+			record.Tags = append(record.Tags, "blacklist")
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
 // queryAbuseIPDB 查詢 AbuseIPDB API
-func (c *AbuseIPDBCollector) queryAbuseIPDB(ipAddress string) (*AbuseIPDBResponse, error) {
+func (c *AbuseIPDBCollector) queryAbuseIPDB(ctx context.Context, ipAddress string) (*AbuseIPDBResponse, error) {
 	// 建立請求
 	url := fmt.Sprintf("%s/check", c.baseURL)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("建立 HTTP 請求失敗: %w", err)
 	}
@@ -0,0 +1,245 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/config"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// Collector 威脅情報收集器介面
+// 每個情報來源實作此介面並透過 Register 註冊，即可依 IntelligenceSource 資料列被綁定與排程
+type Collector interface {
+	// Name 收集器名稱，需與 IntelligenceSource.Name 一致
+	Name() string
+	// HealthCheck 檢查外部情報來源是否可用
+	HealthCheck(ctx context.Context) error
+	// Collect 執行一次收集，回傳取得的威脅情報記錄
+	Collect(ctx context.Context) ([]*dto.ThreatIntelligenceCreateRequest, error)
+	// Capabilities 收集器支援的能力
+	Capabilities() Capabilities
+}
+
+// IPCollector 支援即時查詢單一 IP 的收集器
+type IPCollector interface {
+	Collector
+	CollectIPThreatIntel(ctx context.Context, ipAddress string) error
+	CollectBulkIPThreatIntel(ctx context.Context, ipAddresses []string) ([]string, []string)
+}
+
+// Capabilities 收集器能力描述
+type Capabilities struct {
+	IndicatorTypes []string `json:"indicator_types"`  // 產出的指標類型
+	Scheduled      bool     `json:"scheduled"`        // 支援排程收集
+	OnDemand       bool     `json:"on_demand"`        // 支援即時查詢
+	RequiresAPIKey bool     `json:"requires_api_key"` // 需要 API 金鑰
+}
+
+// Dependencies 建立收集器時可用的共用依賴
+type Dependencies struct {
+	Service  service.ThreatIntelligenceService
+	External config.ExternalConfig
+}
+
+// Factory 依據情報來源資料列建立收集器
+// 若收集器缺少必要設定（例如 API 金鑰）應回傳 dto.ErrCollectorNotConfigured
+type Factory func(source *model.IntelligenceSource, deps Dependencies) (Collector, error)
+
+// Definition 收集器定義
+type Definition struct {
+	Name               string  // 對應 IntelligenceSource.Name
+	URL                *string // 官方 API 位址，來源資料列只能覆寫路徑，主機固定
+	APIKeyRequired     bool
+	CollectionInterval int // 預設收集間隔（秒）
+	Factory            Factory
+}
+
+var (
+	definitionsMu sync.RWMutex
+	definitions   = make(map[string]Definition)
+)
+
+// Register 註冊收集器定義，通常在各收集器檔案的 init 中呼叫
+// 名稱重複或缺少 Factory 時會 panic
+func Register(def Definition) {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+
+	if def.Name == "" {
+		panic("collector: Register called with empty name")
+	}
+	if def.Factory == nil {
+		panic("collector: Register factory is nil for " + def.Name)
+	}
+	if _, exists := definitions[def.Name]; exists {
+		panic("collector: Register called twice for " + def.Name)
+	}
+	definitions[def.Name] = def
+}
+
+// Definitions 取得所有已註冊的收集器定義（依名稱排序）
+func Definitions() []Definition {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+
+	defs := make([]Definition, 0, len(definitions))
+	for _, def := range definitions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+	return defs
+}
+
+// ValidateSource 檢查情報來源資料列的設定能否用於其收集器
+// 未註冊收集器或沒有官方 API 位址的來源不受限制
+func ValidateSource(source *model.IntelligenceSource) error {
+	def, ok := lookupDefinition(source.Name)
+	if !ok || def.URL == nil {
+		return nil
+	}
+	_, err := sourceBaseURL(source, *def.URL)
+	return err
+}
+
+// sourceBaseURL 取得收集器實際使用的 API 位址
+// 收集器會在請求中附上伺服器設定的 API 金鑰，因此覆寫的位址必須使用 HTTPS 且主機與官方位址相同，避免金鑰被送往其他主機
+func sourceBaseURL(source *model.IntelligenceSource, official string) (string, error) {
+	if source.URL == nil || *source.URL == "" {
+		return official, nil
+	}
+
+	raw := strings.TrimRight(*source.URL, "/")
+	officialURL, err := url.Parse(official)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil || !strings.EqualFold(u.Host, officialURL.Host) {
+		return "", fmt.Errorf("%w: %s only accepts https://%s", dto.ErrSourceURLNotAllowed, source.Name, officialURL.Host)
+	}
+	return raw, nil
+}
+
+// lookupDefinition 依名稱查找收集器定義
+func lookupDefinition(name string) (Definition, bool) {
+	definitionsMu.RLock()
+	defer definitionsMu.RUnlock()
+
+	def, ok := definitions[name]
+	return def, ok
+}
+
+// Registry 收集器註冊表，將收集器實例綁定到情報來源資料列
+type Registry struct {
+	deps       Dependencies
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry 建立收集器註冊表
+func NewRegistry(deps Dependencies) *Registry {
+	return &Registry{
+		deps:       deps,
+		collectors: make(map[string]Collector),
+	}
+}
+
+// Sync 確保每個已註冊的收集器都有對應的情報來源資料列並完成綁定
+// 缺少必要設定的收集器只會記錄警告，不會中斷同步
+func (r *Registry) Sync(ctx context.Context, repo repository.IntelligenceSourceRepository) error {
+	for _, def := range Definitions() {
+		source, err := repo.GetByName(ctx, def.Name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			source = &model.IntelligenceSource{
+				Name:               def.Name,
+				URL:                def.URL,
+				APIKeyRequired:     def.APIKeyRequired,
+				IsActive:           true,
+				CollectionInterval: def.CollectionInterval,
+			}
+			if err := repo.Create(ctx, source); err != nil {
+				return fmt.Errorf("建立情報來源 %s 失敗: %w", def.Name, err)
+			}
+			pkglogger.Info("已建立情報來源", pkglogger.Fields{
+				"source": def.Name,
+			})
+		} else if err != nil {
+			return fmt.Errorf("查詢情報來源 %s 失敗: %w", def.Name, err)
+		}
+
+		if _, err := r.Bind(source); err != nil {
+			if errors.Is(err, dto.ErrCollectorNotConfigured) || errors.Is(err, dto.ErrSourceURLNotAllowed) {
+				pkglogger.Warn("收集器未設定，略過綁定", pkglogger.Fields{
+					"source": def.Name,
+					"error":  err.Error(),
+				})
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Bind 依據情報來源資料列建立並綁定收集器
+func (r *Registry) Bind(source *model.IntelligenceSource) (Collector, error) {
+	def, ok := lookupDefinition(source.Name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", dto.ErrCollectorNotFound, source.Name)
+	}
+
+	c, err := def.Factory(source, r.deps)
+	if err != nil {
+		return nil, fmt.Errorf("建立收集器 %s 失敗: %w", source.Name, err)
+	}
+
+	r.mu.Lock()
+	r.collectors[source.Name] = c
+	r.mu.Unlock()
+
+	pkglogger.Info("收集器已綁定", pkglogger.Fields{
+		"source":    source.Name,
+		"source_id": source.ID,
+	})
+
+	return c, nil
+}
+
+// Get 依情報來源名稱取得已綁定的收集器
+func (r *Registry) Get(name string) (Collector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.collectors[name]
+	return c, ok
+}
+
+// Collectors 取得所有已綁定的收集器（依名稱排序）
+func (r *Registry) Collectors() []Collector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+	return result
+}
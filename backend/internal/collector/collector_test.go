@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/config"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+func TestDefinitions_BuiltinCollectorsRegistered(t *testing.T) {
+	names := make([]string, 0)
+	for _, def := range Definitions() {
+		names = append(names, def.Name)
+	}
+
+	assert.Contains(t, names, AbuseIPDBSourceName)
+	assert.Contains(t, names, HIBPSourceName)
+}
+
+func TestRegistry_Bind(t *testing.T) {
+	registry := NewRegistry(Dependencies{
+		Service: &MockThreatIntelligenceService{},
+	})
+
+	// AbuseIPDB 未設定金鑰時不應綁定
+	_, err := registry.Bind(&model.IntelligenceSource{Name: AbuseIPDBSourceName})
+	assert.ErrorIs(t, err, dto.ErrCollectorNotConfigured)
+
+	// 未註冊的來源
+	_, err = registry.Bind(&model.IntelligenceSource{Name: "Unknown"})
+	assert.ErrorIs(t, err, dto.ErrCollectorNotFound)
+
+	// 來源資料列的 URL 只能覆寫官方主機上的路徑，其他主機不會收到 API 金鑰
+	registry = NewRegistry(Dependencies{
+		Service:  &MockThreatIntelligenceService{},
+		External: config.ExternalConfig{AbuseIPDBKey: "test-key"},
+	})
+	url := "http://localhost:8081/api/v2"
+	_, err = registry.Bind(&model.IntelligenceSource{Name: AbuseIPDBSourceName, URL: &url})
+	assert.ErrorIs(t, err, dto.ErrSourceURLNotAllowed)
+	_, ok := registry.Get(AbuseIPDBSourceName)
+	assert.False(t, ok)
+
+	url = "https://api.abuseipdb.com/api/v3/"
+	c, err := registry.Bind(&model.IntelligenceSource{Name: AbuseIPDBSourceName, URL: &url})
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.abuseipdb.com/api/v3", c.(*AbuseIPDBCollector).baseURL)
+
+	bound, ok := registry.Get(AbuseIPDBSourceName)
+	assert.True(t, ok)
+	assert.Equal(t, c, bound)
+	_, ok = bound.(IPCollector)
+	assert.True(t, ok)
+	assert.Len(t, registry.Collectors(), 1)
+}
+
+func TestValidateSource(t *testing.T) {
+	source := func(name, url string) *model.IntelligenceSource {
+		return &model.IntelligenceSource{Name: name, URL: &url}
+	}
+
+	assert.NoError(t, ValidateSource(&model.IntelligenceSource{Name: HIBPSourceName}))
+	assert.NoError(t, ValidateSource(source(HIBPSourceName, "https://HaveIBeenPwned.com/api/v3")))
+	assert.NoError(t, ValidateSource(source("Manual Entry", "http://10.0.0.1/feed")))
+
+	for _, url := range []string{
+		"http://haveibeenpwned.com/api/v3",
+		"https://attacker.example/api/v3",
+		"https://haveibeenpwned.com.attacker.example/api/v3",
+		"https://haveibeenpwned.com:8443/api/v3",
+		"https://user@haveibeenpwned.com/api/v3",
+	} {
+		assert.ErrorIs(t, ValidateSource(source(HIBPSourceName, url)), dto.ErrSourceURLNotAllowed, url)
+	}
+}
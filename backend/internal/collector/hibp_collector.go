@@ -13,9 +13,37 @@ import (
 	"crypto/sha1"
 
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
 )
 
+const (
+	// HIBPSourceName Have I Been Pwned 情報來源名稱
+	HIBPSourceName = "HaveIBeenPwned"
+
+	hibpBaseURL = "https://haveibeenpwned.com/api/v3"
+)
+
+func init() {
+	baseURL := hibpBaseURL
+	Register(Definition{
+		Name:               HIBPSourceName,
+		URL:                &baseURL,
+		APIKeyRequired:     true,
+		CollectionInterval: 86400,
+		Factory: func(source *model.IntelligenceSource, deps Dependencies) (Collector, error) {
+			baseURL, err := sourceBaseURL(source, hibpBaseURL)
+			if err != nil {
+				return nil, err
+			}
+			// 未設定金鑰時仍建立收集器，Pwned Passwords 等端點不需認證
+			c := NewHIBPCollector(deps.External.HIBPAPIKey, deps.Service)
+			c.baseURL = baseURL
+			return c, nil
+		},
+	})
+}
+
 // HIBPCollector 整合 Have I Been Pwned API v3
 type HIBPCollector struct {
 	apiKey     string
@@ -83,7 +111,7 @@ type HIBPDomainBreach map[string][]string
 func NewHIBPCollector(apiKey string, service service.ThreatIntelligenceService) *HIBPCollector {
 	return &HIBPCollector{
 		apiKey:    apiKey,
-		baseURL:   "https://haveibeenpwned.com/api/v3",
+		baseURL:   hibpBaseURL,
 		userAgent: "Security-Intelligence-Platform/1.0",
 		client: &http.Client{
 			Timeout: 30 * time.Second,
@@ -265,29 +293,10 @@ func (c *HIBPCollector) ProcessAccountBreaches(ctx context.Context, account stri
 	
 	// 為每個泄露事件創建威脅情報
 	for _, breach := range breaches {
-		threatReq := &dto.ThreatIntelligenceCreateRequest{
-			IPAddress:       "0.0.0.0", // 域名相關威脅，使用佔位符 IP
-			Domain:          &breach.Domain,
-			ThreatType:      "other",
-			Severity:        c.determineBreachSeverity(breach),
-			ConfidenceScore: 100, // HIBP 數據可信度很高
-			Description:     &breach.Description,
-			Source:          "HaveIBeenPwned",
-			ExternalID:      &breach.Name,
-			Tags:            []string{"data-breach", "hibp"},
-			Metadata: map[string]interface{}{
-				"breach_name":     breach.Name,
-				"breach_date":     breach.BreachDate,
-				"pwn_count":       breach.PwnCount,
-				"data_classes":    breach.DataClasses,
-				"is_verified":     breach.IsVerified,
-				"is_sensitive":    breach.IsSensitive,
-				"is_retired":      breach.IsRetired,
-				"is_spam_list":    breach.IsSpamList,
-				"is_malware":      breach.IsMalware,
-				"is_stealer_log":  breach.IsStealerLog,
-			},
-		}
+		threatReq := c.breachToThreatRequest(breach)
+		// 帳號查詢的指標為外洩的 Email 本身，而非外洩網站的域名
+		threatReq.IndicatorType = string(model.IndicatorEmail)
+		threatReq.IndicatorValue = account
 		
 		_, err := c.service.CreateThreat(ctx, threatReq)
 		if err != nil {
@@ -299,6 +308,38 @@ func (c *HIBPCollector) ProcessAccountBreaches(ctx context.Context, account stri
 	return nil
 }
 
+// breachToThreatRequest 將泄露事件轉換為威脅情報建立請求
+func (c *HIBPCollector) breachToThreatRequest(breach HIBPBreach) *dto.ThreatIntelligenceCreateRequest {
+	domain := breach.Domain
+	description := breach.Description
+	name := breach.Name
+
+	return &dto.ThreatIntelligenceCreateRequest{
+		IndicatorType:   string(model.IndicatorDomain),
+		IndicatorValue:  domain,
+		Domain:          &domain,
+		ThreatType:      "other",
+		Severity:        c.determineBreachSeverity(breach),
+		ConfidenceScore: 100, // HIBP 數據可信度很高
+		Description:     &description,
+		Source:          HIBPSourceName,
+		ExternalID:      &name,
+		Tags:            []string{"data-breach", "hibp"},
+		Metadata: map[string]interface{}{
+			"breach_name":     breach.Name,
+			"breach_date":     breach.BreachDate,
+			"pwn_count":       breach.PwnCount,
+			"data_classes":    breach.DataClasses,
+			"is_verified":     breach.IsVerified,
+			"is_sensitive":    breach.IsSensitive,
+			"is_retired":      breach.IsRetired,
+			"is_spam_list":    breach.IsSpamList,
+			"is_malware":      breach.IsMalware,
+			"is_stealer_log":  breach.IsStealerLog,
+		},
+	}
+}
+
 // 輔助方法
 func (c *HIBPCollector) makeRequest(ctx context.Context, endpoint string, result interface{}) error {
 	url := c.baseURL + endpoint
@@ -355,6 +396,39 @@ func (c *HIBPCollector) determineBreachSeverity(breach HIBPBreach) string {
 	return "low"
 }
 
+// Name 收集器名稱
+func (c *HIBPCollector) Name() string {
+	return HIBPSourceName
+}
+
+// Capabilities 收集器能力
+func (c *HIBPCollector) Capabilities() Capabilities {
+	return Capabilities{
+		IndicatorTypes: []string{"domain"},
+		Scheduled:      true,
+		OnDemand:       true,
+		RequiresAPIKey: true,
+	}
+}
+
+// Collect 收集所有已驗證且非偽造的泄露事件
+func (c *HIBPCollector) Collect(ctx context.Context) ([]*dto.ThreatIntelligenceCreateRequest, error) {
+	breaches, err := c.GetAllBreaches(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*dto.ThreatIntelligenceCreateRequest, 0, len(breaches))
+	for _, breach := range breaches {
+		if breach.IsFabricated || breach.Domain == "" {
+			continue
+		}
+		records = append(records, c.breachToThreatRequest(breach))
+	}
+
+	return records, nil
+}
+
 // 健康檢查
 func (c *HIBPCollector) HealthCheck(ctx context.Context) error {
 	_, err := c.GetSubscriptionStatus(ctx)
@@ -0,0 +1,385 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// persistBatchSize 每次寫入威脅情報的批次大小
+const persistBatchSize = 100
+
+// interruptedJobMessage 上次執行異常結束而未完成的收集任務錯誤訊息
+const interruptedJobMessage = "interrupted before completion, scheduler restarted"
+
+// SchedulerConfig 收集排程器設定
+type SchedulerConfig struct {
+	PollInterval   time.Duration // 輪詢情報來源的間隔
+	MaxConcurrency int           // 同時執行的收集器上限
+	Timeout        time.Duration // 單次收集逾時
+}
+
+// Scheduler 背景收集排程器
+// 定期輪詢啟用中的情報來源，依 CollectionInterval 執行到期的收集器並記錄 CollectionJob
+type Scheduler struct {
+	registry   *Registry
+	sourceRepo repository.IntelligenceSourceRepository
+	jobRepo    repository.CollectionJobRepository
+	service    service.ThreatIntelligenceService
+	config     SchedulerConfig
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	sem     chan struct{}
+	mu      sync.Mutex
+	running map[uuid.UUID]bool
+	stopped bool           // 由 mu 保護；停止後不再派發，確保 wg.Add 不會與 Stop 的 Wait 交錯
+	wg      sync.WaitGroup // 由 mu 保護 Add
+	done    chan struct{}
+}
+
+// NewScheduler 建立收集排程器
+func NewScheduler(
+	registry *Registry,
+	sourceRepo repository.IntelligenceSourceRepository,
+	jobRepo repository.CollectionJobRepository,
+	threatService service.ThreatIntelligenceService,
+	config SchedulerConfig,
+) *Scheduler {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Minute
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = 1
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		ctx:        ctx,
+		cancel:     cancel,
+		registry:   registry,
+		sourceRepo: sourceRepo,
+		jobRepo:    jobRepo,
+		service:    threatService,
+		config:     config,
+		sem:        make(chan struct{}, config.MaxConcurrency),
+		running:    make(map[uuid.UUID]bool),
+	}
+}
+
+// Start 啟動排程器背景 goroutine
+// 啟動前先將上次異常結束遺留的待執行與執行中任務標記為失敗；須在開始接受手動觸發前呼叫
+func (s *Scheduler) Start() {
+	ctx := s.ctx
+	s.failInterruptedJobs(ctx)
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(s.config.PollInterval)
+		defer ticker.Stop()
+
+		pkglogger.Info("收集排程器已啟動", pkglogger.Fields{
+			"poll_interval":   s.config.PollInterval.String(),
+			"max_concurrency": s.config.MaxConcurrency,
+			"timeout":         s.config.Timeout.String(),
+		})
+
+		s.tick(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.tick(ctx)
+			}
+		}
+	}()
+}
+
+// Stop 停止排程器，取消執行中的收集並等待其記錄結果
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.cancel()
+
+	finished := make(chan struct{})
+	go func() {
+		if s.done != nil {
+			<-s.done
+		}
+		s.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		pkglogger.Info("收集排程器已停止")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tick 檢查所有情報來源並派發到期的收集任務
+func (s *Scheduler) tick(ctx context.Context) {
+	sources, err := s.sourceRepo.List(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			pkglogger.Error("取得情報來源失敗", pkglogger.Fields{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	for _, source := range sources {
+		if !source.ShouldCollect() {
+			continue
+		}
+
+		c, ok := s.registry.Get(source.Name)
+		if !ok || !c.Capabilities().Scheduled {
+			continue
+		}
+
+		_, err := s.dispatch(ctx, source, c)
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		if err != nil && !errors.Is(err, dto.ErrCollectionInProgress) {
+			pkglogger.Error("派發收集任務失敗", pkglogger.Fields{
+				"source": source.Name,
+				"error":  err.Error(),
+			})
+		}
+	}
+}
+
+// Bind 依據情報來源資料列重新綁定收集器，使 URL 等設定變更立即生效
+func (s *Scheduler) Bind(source *model.IntelligenceSource) error {
+	_, err := s.registry.Bind(source)
+	return err
+}
+
+// Validate 檢查情報來源設定能否用於其收集器
+func (s *Scheduler) Validate(source *model.IntelligenceSource) error {
+	return ValidateSource(source)
+}
+
+// Trigger 立即為指定來源派發一次收集，不受 CollectionInterval 限制
+// 回傳的任務為派發當下的快照，實際結果需透過收集任務查詢
+func (s *Scheduler) Trigger(ctx context.Context, source *model.IntelligenceSource) (*model.CollectionJob, error) {
+	c, ok := s.registry.Get(source.Name)
+	if !ok {
+		bound, err := s.registry.Bind(source)
+		if err != nil {
+			return nil, err
+		}
+		c = bound
+	}
+
+	return s.dispatch(ctx, source, c)
+}
+
+// dispatch 建立待執行的收集任務並在背景執行，同一來源同時只會有一個任務
+// 排程器已停止時回傳 context.Canceled
+func (s *Scheduler) dispatch(ctx context.Context, source *model.IntelligenceSource, c Collector) (*model.CollectionJob, error) {
+	if err := s.markRunning(source.ID); err != nil {
+		return nil, err
+	}
+
+	job := &model.CollectionJob{
+		SourceID: source.ID,
+		Status:   model.StatusPending,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		s.markDone(source.ID)
+		return nil, err
+	}
+	snapshot := *job
+
+	go func() {
+		defer s.markDone(source.ID)
+
+		select {
+		case s.sem <- struct{}{}:
+			defer func() { <-s.sem }()
+		case <-s.ctx.Done():
+			job.Fail(s.ctx.Err().Error())
+			s.saveJob(source, job)
+			return
+		}
+
+		s.run(s.ctx, source, c, job)
+	}()
+
+	return &snapshot, nil
+}
+
+// run 執行單一來源的收集並更新 CollectionJob
+func (s *Scheduler) run(ctx context.Context, source *model.IntelligenceSource, c Collector, job *model.CollectionJob) {
+	// 收集結果在關閉期間仍需寫回資料庫
+	persistCtx := context.WithoutCancel(ctx)
+
+	job.Start()
+	if err := s.jobRepo.Update(persistCtx, job); err != nil {
+		pkglogger.Error("更新收集任務失敗", pkglogger.Fields{
+			"source": source.Name,
+			"job_id": job.ID,
+			"error":  err.Error(),
+		})
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	records, err := c.Collect(runCtx)
+	created, updated := 0, 0
+	if err == nil {
+		created, updated, err = s.persist(runCtx, records)
+	}
+	count := created + updated
+
+	if err != nil {
+		job.Fail(err.Error())
+		job.RecordsCollected = count
+	} else {
+		job.Complete(count)
+	}
+	job.RecordsCreated = created
+	job.RecordsUpdated = updated
+	s.saveJob(source, job)
+
+	// 無論成功或失敗都推進最後收集時間，避免失敗的來源在每次輪詢被重試
+	source.UpdateLastCollection()
+	source.IncrementCollected(count)
+	if err := s.sourceRepo.RecordCollection(persistCtx, source.ID, *source.LastCollection, count); err != nil {
+		pkglogger.Error("更新情報來源收集紀錄失敗", pkglogger.Fields{
+			"source": source.Name,
+			"error":  err.Error(),
+		})
+	}
+
+	fields := pkglogger.Fields{
+		"source":  source.Name,
+		"job_id":  job.ID,
+		"status":  job.Status,
+		"records": count,
+		"created": created,
+		"updated": updated,
+	}
+	if duration := job.GetDuration(); duration != nil {
+		fields["duration"] = duration.String()
+	}
+	if job.IsFailed() {
+		fields["error"] = *job.ErrorMessage
+		pkglogger.Warn("收集任務失敗", fields)
+	} else {
+		pkglogger.Info("收集任務完成", fields)
+	}
+}
+
+// saveJob 寫回收集任務結果，不受排程器關閉影響
+func (s *Scheduler) saveJob(source *model.IntelligenceSource, job *model.CollectionJob) {
+	if err := s.jobRepo.Update(context.WithoutCancel(s.ctx), job); err != nil {
+		pkglogger.Error("更新收集任務失敗", pkglogger.Fields{
+			"source": source.Name,
+			"job_id": job.ID,
+			"error":  err.Error(),
+		})
+	}
+}
+
+// persist 將收集到的記錄分批寫入威脅情報，回傳新建立與合併更新的數量
+// 已存在的指標會依自然鍵合併，不會產生重複記錄
+func (s *Scheduler) persist(ctx context.Context, records []*dto.ThreatIntelligenceCreateRequest) (int, int, error) {
+	created, updated := 0, 0
+	for start := 0; start < len(records); start += persistBatchSize {
+		end := start + persistBatchSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		batch := &dto.ThreatIntelligenceBulkCreateRequest{
+			Items: make([]dto.ThreatIntelligenceCreateRequest, 0, end-start),
+		}
+		for _, record := range records[start:end] {
+			batch.Items = append(batch.Items, *record)
+		}
+
+		result, err := s.service.BulkCreateThreats(ctx, batch)
+		if err != nil {
+			return created, updated, err
+		}
+		created += result.CreatedCount
+		updated += result.UpdatedCount
+
+		for _, failed := range result.Failed {
+			pkglogger.Debug("略過無效的威脅情報記錄", pkglogger.Fields{
+				"index":   start + failed.Index,
+				"error":   failed.Error,
+				"message": failed.Message,
+			})
+		}
+	}
+	return created, updated, nil
+}
+
+// markRunning 標記來源為執行中並登記一個背景任務，之後須呼叫 markDone
+// 排程器已停止時回傳 context.Canceled，來源已在執行時回傳 ErrCollectionInProgress
+func (s *Scheduler) markRunning(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return context.Canceled
+	}
+	if s.running[id] {
+		return dto.ErrCollectionInProgress
+	}
+	s.running[id] = true
+	s.wg.Add(1)
+	return nil
+}
+
+// markDone 清除來源的執行中標記並結束背景任務
+func (s *Scheduler) markDone(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.running, id)
+	s.wg.Done()
+}
+
+// failInterruptedJobs 將上次異常結束時仍為待執行或執行中的任務標記為失敗
+// 這些任務已沒有執行中的 goroutine 會更新，否則會一直顯示為進行中
+func (s *Scheduler) failInterruptedJobs(ctx context.Context) {
+	count, err := s.jobRepo.FailUnfinished(ctx, interruptedJobMessage)
+	if err != nil {
+		pkglogger.Error("清理未完成的收集任務失敗", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+	if count > 0 {
+		pkglogger.Warn("已將上次未完成的收集任務標記為失敗", pkglogger.Fields{
+			"count": count,
+		})
+	}
+}
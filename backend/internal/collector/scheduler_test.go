@@ -0,0 +1,319 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// fakeCollector 測試用收集器
+type fakeCollector struct {
+	name    string
+	records []*dto.ThreatIntelligenceCreateRequest
+	err     error
+	block   bool
+}
+
+func (f *fakeCollector) Name() string                          { return f.name }
+func (f *fakeCollector) HealthCheck(ctx context.Context) error { return nil }
+func (f *fakeCollector) Capabilities() Capabilities            { return Capabilities{Scheduled: true} }
+
+func (f *fakeCollector) Collect(ctx context.Context) ([]*dto.ThreatIntelligenceCreateRequest, error) {
+	if f.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return f.records, f.err
+}
+
+// fakeSourceRepository 測試用情報來源儲存庫
+type fakeSourceRepository struct {
+	mu      sync.Mutex
+	sources []*model.IntelligenceSource
+}
+
+func (r *fakeSourceRepository) Create(ctx context.Context, source *model.IntelligenceSource) error {
+	return nil
+}
+
+func (r *fakeSourceRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.IntelligenceSource, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeSourceRepository) GetByName(ctx context.Context, name string) (*model.IntelligenceSource, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeSourceRepository) List(ctx context.Context) ([]*model.IntelligenceSource, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*model.IntelligenceSource, len(r.sources))
+	for i, source := range r.sources {
+		copied := *source
+		result[i] = &copied
+	}
+	return result, nil
+}
+
+func (r *fakeSourceRepository) Update(ctx context.Context, source *model.IntelligenceSource) error {
+	return nil
+}
+
+func (r *fakeSourceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (r *fakeSourceRepository) RecordCollection(ctx context.Context, id uuid.UUID, collectedAt time.Time, count int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, source := range r.sources {
+		if source.ID == id {
+			source.LastCollection = &collectedAt
+			source.TotalCollected += count
+		}
+	}
+	return nil
+}
+
+// fakeJobRepository 測試用收集任務儲存庫
+type fakeJobRepository struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]model.CollectionJob
+}
+
+func (r *fakeJobRepository) Create(ctx context.Context, job *model.CollectionJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job.ID = uuid.New()
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *fakeJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.CollectionJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := r.jobs[id]
+	return &job, nil
+}
+
+func (r *fakeJobRepository) Update(ctx context.Context, job *model.CollectionJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *fakeJobRepository) List(ctx context.Context, filter *repository.CollectionJobFilter) ([]*model.CollectionJob, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (r *fakeJobRepository) FailUnfinished(ctx context.Context, message string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var count int64
+	for id, job := range r.jobs {
+		if job.Status == model.StatusPending || job.Status == model.StatusInProgress {
+			job.Fail(message)
+			r.jobs[id] = job
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeJobRepository) all() []model.CollectionJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]model.CollectionJob, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		result = append(result, job)
+	}
+	return result
+}
+
+func newTestScheduler(collectors ...*fakeCollector) (*Scheduler, *fakeSourceRepository, *fakeJobRepository, *MockThreatIntelligenceService) {
+	mockService := &MockThreatIntelligenceService{}
+	registry := NewRegistry(Dependencies{Service: mockService})
+	sourceRepo := &fakeSourceRepository{}
+	jobRepo := &fakeJobRepository{jobs: make(map[uuid.UUID]model.CollectionJob)}
+
+	for _, c := range collectors {
+		registry.collectors[c.name] = c
+		sourceRepo.sources = append(sourceRepo.sources, &model.IntelligenceSource{
+			ID:                 uuid.New(),
+			Name:               c.name,
+			IsActive:           true,
+			CollectionInterval: 3600,
+		})
+	}
+
+	scheduler := NewScheduler(registry, sourceRepo, jobRepo, mockService, SchedulerConfig{
+		PollInterval:   time.Hour,
+		MaxConcurrency: 2,
+		Timeout:        time.Second,
+	})
+	return scheduler, sourceRepo, jobRepo, mockService
+}
+
+func TestScheduler_TickRecordsJobs(t *testing.T) {
+	ok := &fakeCollector{
+		name: "ok",
+		records: []*dto.ThreatIntelligenceCreateRequest{
+			{IPAddress: "192.0.2.1", Source: "ok"},
+			{IPAddress: "192.0.2.2", Source: "ok"},
+		},
+	}
+	broken := &fakeCollector{name: "broken", err: errors.New("upstream unavailable")}
+
+	scheduler, sourceRepo, jobRepo, mockService := newTestScheduler(ok, broken)
+	mockService.On("BulkCreateThreats", mock.Anything, mock.Anything).Return(&vo.ThreatIntelligenceBulkCreateVO{
+		SuccessCount: 2,
+		CreatedCount: 1,
+		UpdatedCount: 1,
+	}, nil)
+
+	ctx := context.Background()
+	scheduler.tick(ctx)
+	scheduler.wg.Wait()
+
+	jobs := jobRepo.all()
+	require.Len(t, jobs, 2)
+	for _, job := range jobs {
+		switch job.SourceID {
+		case sourceRepo.sources[0].ID:
+			assert.True(t, job.IsCompleted())
+			assert.Equal(t, 2, job.RecordsCollected)
+			assert.Equal(t, 1, job.RecordsCreated)
+			assert.Equal(t, 1, job.RecordsUpdated)
+		case sourceRepo.sources[1].ID:
+			assert.True(t, job.IsFailed())
+			assert.Equal(t, "upstream unavailable", *job.ErrorMessage)
+		}
+		assert.NotNil(t, job.CompletedAt)
+	}
+
+	assert.Equal(t, 2, sourceRepo.sources[0].TotalCollected)
+	assert.NotNil(t, sourceRepo.sources[1].LastCollection)
+
+	// 尚未到期的來源不會再次執行
+	scheduler.tick(ctx)
+	scheduler.wg.Wait()
+	assert.Len(t, jobRepo.all(), 2)
+}
+
+func TestScheduler_Timeout(t *testing.T) {
+	slow := &fakeCollector{name: "slow", block: true}
+
+	scheduler, _, jobRepo, _ := newTestScheduler(slow)
+	scheduler.config.Timeout = 10 * time.Millisecond
+
+	scheduler.tick(context.Background())
+	scheduler.wg.Wait()
+
+	jobs := jobRepo.all()
+	require.Len(t, jobs, 1)
+	assert.True(t, jobs[0].IsFailed())
+	assert.Equal(t, context.DeadlineExceeded.Error(), *jobs[0].ErrorMessage)
+}
+
+func TestScheduler_StopCancelsRunningJobs(t *testing.T) {
+	slow := &fakeCollector{name: "slow", block: true}
+
+	scheduler, _, jobRepo, _ := newTestScheduler(slow)
+	scheduler.config.Timeout = time.Hour
+
+	scheduler.Start()
+	require.Eventually(t, func() bool { return len(jobRepo.all()) == 1 }, time.Second, 5*time.Millisecond)
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, scheduler.Stop(stopCtx))
+
+	jobs := jobRepo.all()
+	require.Len(t, jobs, 1)
+	assert.True(t, jobs[0].IsFailed())
+}
+
+func TestScheduler_TriggerRejectsConcurrentRun(t *testing.T) {
+	slow := &fakeCollector{name: "slow", block: true}
+
+	scheduler, sourceRepo, jobRepo, _ := newTestScheduler(slow)
+	scheduler.config.Timeout = time.Hour
+	source := sourceRepo.sources[0]
+
+	job, err := scheduler.Trigger(context.Background(), source)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusPending, job.Status)
+
+	_, err = scheduler.Trigger(context.Background(), source)
+	assert.ErrorIs(t, err, dto.ErrCollectionInProgress)
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, scheduler.Stop(stopCtx))
+
+	stored, err := jobRepo.GetByID(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsFailed())
+}
+
+func TestScheduler_StartFailsInterruptedJobs(t *testing.T) {
+	scheduler, sourceRepo, jobRepo, _ := newTestScheduler()
+	sourceID := uuid.New()
+	sourceRepo.sources = append(sourceRepo.sources, &model.IntelligenceSource{ID: sourceID, Name: "gone", IsActive: true})
+
+	ctx := context.Background()
+	pending := &model.CollectionJob{SourceID: sourceID, Status: model.StatusPending}
+	running := &model.CollectionJob{SourceID: sourceID, Status: model.StatusInProgress}
+	completed := &model.CollectionJob{SourceID: sourceID, Status: model.StatusCompleted}
+	for _, job := range []*model.CollectionJob{pending, running, completed} {
+		require.NoError(t, jobRepo.Create(ctx, job))
+	}
+
+	scheduler.Start()
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	require.NoError(t, scheduler.Stop(stopCtx))
+
+	for _, id := range []uuid.UUID{pending.ID, running.ID} {
+		stored, err := jobRepo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, stored.IsFailed())
+		assert.Equal(t, interruptedJobMessage, *stored.ErrorMessage)
+	}
+	stored, err := jobRepo.GetByID(ctx, completed.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.IsCompleted())
+}
+
+func TestScheduler_TriggerAfterStop(t *testing.T) {
+	slow := &fakeCollector{name: "slow", block: true}
+
+	scheduler, sourceRepo, jobRepo, _ := newTestScheduler(slow)
+	stopCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, scheduler.Stop(stopCtx))
+
+	_, err := scheduler.Trigger(context.Background(), sourceRepo.sources[0])
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, jobRepo.all())
+}
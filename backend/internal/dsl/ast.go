@@ -0,0 +1,93 @@
+// Package dsl 實作威脅情報查詢語言
+//
+// 查詢語法範例：
+//
+//	severity:>=high AND tag:botnet AND country:CN
+//	(domain:*.example.com OR ip:10.0.0.0/8) NOT source:Manual
+//	confidence_score:>80 last_seen:>=now-7d "command and control"
+//
+// 查詢字串會先解析為 AST，欄位與值在解析階段即完成型別驗證，
+// 再由儲存庫編譯為 SQL 條件，使用者輸入只會以參數綁定的方式出現在 SQL 中
+package dsl
+
+import "time"
+
+// Node 查詢語法樹節點
+type Node interface {
+	// Position 節點在查詢字串中的起始位置（以字元計，從 0 開始）
+	Position() int
+}
+
+// BoolOp 布林運算子
+type BoolOp string
+
+const (
+	OpAnd BoolOp = "AND"
+	OpOr  BoolOp = "OR"
+)
+
+// Operator 比較運算子
+type Operator string
+
+const (
+	OpEq    Operator = "="
+	OpNotEq Operator = "!="
+	OpGt    Operator = ">"
+	OpGte   Operator = ">="
+	OpLt    Operator = "<"
+	OpLte   Operator = "<="
+	OpIn    Operator = "in"
+	OpNotIn Operator = "not in"
+	OpLike  Operator = "like"
+)
+
+// BinaryNode 布林運算節點
+type BinaryNode struct {
+	Op    BoolOp
+	Left  Node
+	Right Node
+	Pos   int
+}
+
+// Position 實作 Node 介面
+func (n *BinaryNode) Position() int { return n.Pos }
+
+// NotNode 否定節點
+type NotNode struct {
+	Expr Node
+	Pos  int
+}
+
+// Position 實作 Node 介面
+func (n *NotNode) Position() int { return n.Pos }
+
+// TermNode 欄位條件節點
+//
+// Value 的型別依欄位而定：
+//   - 列舉欄位（OpIn / OpNotIn）：[]string
+//   - 字串、標籤、國家、域名欄位：string（OpLike 時為已轉換的 LIKE 樣式）
+//   - ip 欄位：net.IP 或 *net.IPNet
+//   - 數值欄位：int64
+//   - 時間欄位：time.Time
+type TermNode struct {
+	Field string
+	Op    Operator
+	Value interface{}
+	Pos   int
+}
+
+// Position 實作 Node 介面
+func (n *TermNode) Position() int { return n.Pos }
+
+// TextNode 未指定欄位的全文檢索字詞
+type TextNode struct {
+	Text   string
+	Phrase bool // 以引號包住的片語
+	Pos    int
+}
+
+// Position 實作 Node 介面
+func (n *TextNode) Position() int { return n.Pos }
+
+// nowFunc 取得目前時間，供相對時間計算使用（測試可替換）
+var nowFunc = time.Now
@@ -0,0 +1,267 @@
+package dsl
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldKind 欄位值的種類
+type fieldKind int
+
+const (
+	kindEnum fieldKind = iota
+	kindString
+	kindUpper
+	kindDomain
+	kindIP
+	kindInt
+	kindTime
+)
+
+// fieldDef 查詢欄位定義
+type fieldDef struct {
+	name    string
+	kind    fieldKind
+	values  []string // 列舉值，依嚴重程度排序時可進行大小比較
+	ordered bool
+	min     int64
+	max     int64
+}
+
+// Field 名稱常數，供編譯器對應資料庫欄位
+const (
+	FieldSeverity        = "severity"
+	FieldThreatType      = "threat_type"
+	FieldIndicatorType   = "indicator_type"
+	FieldIndicator       = "indicator"
+	FieldTag             = "tag"
+	FieldCountry         = "country"
+	FieldSource          = "source"
+	FieldDomain          = "domain"
+	FieldIP              = "ip"
+	FieldASN             = "asn"
+	FieldConfidenceScore = "confidence_score"
+	FieldRiskScore       = "risk_score"
+	FieldFirstSeen       = "first_seen"
+	FieldLastSeen        = "last_seen"
+	FieldCreatedAt       = "created_at"
+)
+
+var fields = map[string]*fieldDef{
+	FieldSeverity:        {name: FieldSeverity, kind: kindEnum, values: []string{"low", "medium", "high", "critical"}, ordered: true},
+	FieldThreatType:      {name: FieldThreatType, kind: kindEnum, values: []string{"malware", "phishing", "spam", "botnet", "scanner", "ddos", "bruteforce", "other"}},
+	FieldIndicatorType:   {name: FieldIndicatorType, kind: kindEnum, values: []string{"ipv4", "ipv6", "cidr", "domain", "url", "md5", "sha1", "sha256", "email", "asn"}},
+	FieldIndicator:       {name: FieldIndicator, kind: kindString},
+	FieldTag:             {name: FieldTag, kind: kindString},
+	FieldCountry:         {name: FieldCountry, kind: kindUpper},
+	FieldSource:          {name: FieldSource, kind: kindString},
+	FieldDomain:          {name: FieldDomain, kind: kindDomain},
+	FieldIP:              {name: FieldIP, kind: kindIP},
+	FieldASN:             {name: FieldASN, kind: kindInt, min: 1, max: 4294967295},
+	FieldConfidenceScore: {name: FieldConfidenceScore, kind: kindInt, min: 0, max: 100},
+	FieldRiskScore:       {name: FieldRiskScore, kind: kindInt, min: 0, max: 100},
+	FieldFirstSeen:       {name: FieldFirstSeen, kind: kindTime},
+	FieldLastSeen:        {name: FieldLastSeen, kind: kindTime},
+	FieldCreatedAt:       {name: FieldCreatedAt, kind: kindTime},
+}
+
+// fieldAliases 欄位別名
+var fieldAliases = map[string]string{
+	"type":         FieldThreatType,
+	"itype":        FieldIndicatorType,
+	"value":        FieldIndicator,
+	"tags":         FieldTag,
+	"country_code": FieldCountry,
+	"confidence":   FieldConfidenceScore,
+	"risk":         FieldRiskScore,
+	"created":      FieldCreatedAt,
+}
+
+var (
+	domainPatternChars = regexp.MustCompile(`^[a-z0-9_.*?-]+$`)
+	countryPattern     = regexp.MustCompile(`^[A-Z]{2}$`)
+	relativeTime       = regexp.MustCompile(`^now(?:-(\d+)([smhdw]))?$`)
+)
+
+// lookupField 依名稱或別名取得欄位定義
+func lookupField(name string) (*fieldDef, bool) {
+	name = strings.ToLower(name)
+	if canonical, ok := fieldAliases[name]; ok {
+		name = canonical
+	}
+	def, ok := fields[name]
+	return def, ok
+}
+
+// buildTerm 驗證欄位值並建立條件節點
+// 未限定時間單位的日期等於比較會展開為當日區間
+func (f *fieldDef) buildTerm(op Operator, raw string, pos int) (Node, error) {
+	switch f.kind {
+	case kindEnum:
+		return f.buildEnumTerm(op, raw, pos)
+
+	case kindString, kindUpper:
+		if err := f.requireEquality(op, pos); err != nil {
+			return nil, err
+		}
+		value := raw
+		if f.kind == kindUpper {
+			value = strings.ToUpper(raw)
+			if !countryPattern.MatchString(value) {
+				return nil, newSyntaxError(pos, "%s must be a two-letter country code, got %q", f.name, raw)
+			}
+		}
+		return &TermNode{Field: f.name, Op: op, Value: value, Pos: pos}, nil
+
+	case kindDomain:
+		if err := f.requireEquality(op, pos); err != nil {
+			return nil, err
+		}
+		value := strings.TrimSuffix(strings.ToLower(raw), ".")
+		if !domainPatternChars.MatchString(value) {
+			return nil, newSyntaxError(pos, "invalid domain pattern %q", raw)
+		}
+		if strings.ContainsAny(value, "*?") {
+			pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%", "?", "_").Replace(value)
+			term := &TermNode{Field: f.name, Op: OpLike, Value: pattern, Pos: pos}
+			if op == OpNotEq {
+				return &NotNode{Expr: term, Pos: pos}, nil
+			}
+			return term, nil
+		}
+		return &TermNode{Field: f.name, Op: op, Value: value, Pos: pos}, nil
+
+	case kindIP:
+		if err := f.requireEquality(op, pos); err != nil {
+			return nil, err
+		}
+		if strings.Contains(raw, "/") {
+			_, network, err := net.ParseCIDR(raw)
+			if err != nil {
+				return nil, newSyntaxError(pos, "invalid CIDR %q", raw)
+			}
+			return &TermNode{Field: f.name, Op: op, Value: network, Pos: pos}, nil
+		}
+		ip := net.ParseIP(raw)
+		if ip == nil {
+			return nil, newSyntaxError(pos, "invalid IP address %q", raw)
+		}
+		return &TermNode{Field: f.name, Op: op, Value: ip, Pos: pos}, nil
+
+	case kindInt:
+		value := raw
+		if f.name == FieldASN && len(value) > 2 && strings.EqualFold(value[:2], "AS") {
+			value = value[2:]
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, newSyntaxError(pos, "%s expects a number, got %q", f.name, raw)
+		}
+		if n < f.min || n > f.max {
+			return nil, newSyntaxError(pos, "%s must be between %d and %d", f.name, f.min, f.max)
+		}
+		return &TermNode{Field: f.name, Op: op, Value: n, Pos: pos}, nil
+
+	case kindTime:
+		t, dateOnly, err := parseTime(raw)
+		if err != nil {
+			return nil, newSyntaxError(pos, "%s expects a date (2024-01-31), timestamp (RFC 3339) or relative time (now-7d), got %q", f.name, raw)
+		}
+		if dateOnly && (op == OpEq || op == OpNotEq) {
+			day := &BinaryNode{
+				Op:    OpAnd,
+				Left:  &TermNode{Field: f.name, Op: OpGte, Value: t, Pos: pos},
+				Right: &TermNode{Field: f.name, Op: OpLt, Value: t.AddDate(0, 0, 1), Pos: pos},
+				Pos:   pos,
+			}
+			if op == OpNotEq {
+				return &NotNode{Expr: day, Pos: pos}, nil
+			}
+			return day, nil
+		}
+		return &TermNode{Field: f.name, Op: op, Value: t, Pos: pos}, nil
+	}
+
+	return nil, newSyntaxError(pos, "unsupported field %q", f.name)
+}
+
+// buildEnumTerm 建立列舉欄位條件，排序列舉的比較運算會展開為值集合
+func (f *fieldDef) buildEnumTerm(op Operator, raw string, pos int) (Node, error) {
+	value := strings.ToLower(raw)
+	index := -1
+	for i, v := range f.values {
+		if v == value {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, newSyntaxError(pos, "invalid %s %q, expected one of %s", f.name, raw, strings.Join(f.values, ", "))
+	}
+
+	var values []string
+	switch op {
+	case OpEq, OpNotEq:
+		values = []string{value}
+	case OpGt, OpGte, OpLt, OpLte:
+		if !f.ordered {
+			return nil, newSyntaxError(pos, "operator %s is not supported for %s", op, f.name)
+		}
+		for i, v := range f.values {
+			if (op == OpGt && i > index) || (op == OpGte && i >= index) ||
+				(op == OpLt && i < index) || (op == OpLte && i <= index) {
+				values = append(values, v)
+			}
+		}
+	}
+
+	if op == OpNotEq {
+		return &TermNode{Field: f.name, Op: OpNotIn, Value: values, Pos: pos}, nil
+	}
+	return &TermNode{Field: f.name, Op: OpIn, Value: values, Pos: pos}, nil
+}
+
+// requireEquality 檢查欄位只使用等於或不等於運算
+func (f *fieldDef) requireEquality(op Operator, pos int) error {
+	if op != OpEq && op != OpNotEq {
+		return newSyntaxError(pos, "operator %s is not supported for %s", op, f.name)
+	}
+	return nil
+}
+
+// parseTime 解析時間值，回傳是否為僅有日期的格式
+func parseTime(raw string) (time.Time, bool, error) {
+	if m := relativeTime.FindStringSubmatch(strings.ToLower(raw)); m != nil {
+		now := nowFunc()
+		if m[1] == "" {
+			return now, false, nil
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		unit := map[string]time.Duration{
+			"s": time.Second,
+			"m": time.Minute,
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+		}[m[2]]
+		return now.Add(-time.Duration(n) * unit), false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse("2006-01-02T15:04:05", raw); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid time %q", raw)
+	}
+	return t, true, nil
+}
@@ -0,0 +1,343 @@
+package dsl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxQueryLength 查詢字串長度上限
+const MaxQueryLength = 2000
+
+// maxDepth 括號與 NOT 巢狀深度上限，避免惡意查詢造成過深遞迴
+const maxDepth = 32
+
+// ErrSyntax 查詢語法錯誤
+var ErrSyntax = errors.New("query syntax error")
+
+// SyntaxError 帶有位置資訊的查詢語法錯誤
+type SyntaxError struct {
+	Pos    int    `json:"position"` // 錯誤位置（以字元計，從 0 開始）
+	Reason string `json:"reason"`
+}
+
+// Error 實作 error 介面
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Reason)
+}
+
+// Unwrap 讓 errors.Is 可比對 ErrSyntax
+func (e *SyntaxError) Unwrap() error {
+	return ErrSyntax
+}
+
+// newSyntaxError 建立語法錯誤
+func newSyntaxError(pos int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Pos: pos, Reason: fmt.Sprintf(format, args...)}
+}
+
+// parser 遞迴下降解析器
+//
+//	query   = or
+//	or      = and { "OR" and }
+//	and     = not { [ "AND" ] not }
+//	not     = "NOT" not | primary
+//	primary = "(" or ")" | field ":" [ op ] value | text
+type parser struct {
+	input []rune
+	pos   int
+	depth int
+}
+
+// Parse 解析查詢字串為語法樹
+func Parse(input string) (Node, error) {
+	if len(input) > MaxQueryLength {
+		return nil, newSyntaxError(MaxQueryLength, "query exceeds %d characters", MaxQueryLength)
+	}
+
+	p := &parser{input: []rune(input)}
+	p.skipSpace()
+	if p.eof() {
+		return nil, newSyntaxError(0, "empty query")
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.eof() {
+		if p.peek() == ')' {
+			return nil, newSyntaxError(p.pos, "unexpected ')' without matching '('")
+		}
+		return nil, newSyntaxError(p.pos, "unexpected %q", p.peek())
+	}
+	return node, nil
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		pos, ok := p.acceptKeyword("OR")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryNode{Op: OpOr, Left: left, Right: right, Pos: pos}
+	}
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if p.eof() || p.peek() == ')' || p.peekKeyword("OR") {
+			return left, nil
+		}
+		pos := p.pos
+		if kwPos, ok := p.acceptKeyword("AND"); ok {
+			pos = kwPos
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryNode{Op: OpAnd, Left: left, Right: right, Pos: pos}
+	}
+}
+
+func (p *parser) parseNot() (Node, error) {
+	pos, ok := p.acceptKeyword("NOT")
+	if !ok {
+		return p.parsePrimary()
+	}
+	if err := p.enter(pos); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	expr, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	return &NotNode{Expr: expr, Pos: pos}, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	p.skipSpace()
+	if p.eof() {
+		return nil, newSyntaxError(p.pos, "unexpected end of query, expected a condition")
+	}
+
+	start := p.pos
+	switch p.peek() {
+	case '(':
+		if err := p.enter(start); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.eof() || p.peek() != ')' {
+			return nil, newSyntaxError(start, "missing closing ')'")
+		}
+		p.pos++
+		return node, nil
+
+	case ')':
+		return nil, newSyntaxError(start, "unexpected ')'")
+
+	case '"':
+		text, err := p.readQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return &TextNode{Text: text, Phrase: true, Pos: start}, nil
+	}
+
+	if p.peekKeyword("AND") || p.peekKeyword("OR") {
+		return nil, newSyntaxError(start, "unexpected operator, expected a condition")
+	}
+
+	name := p.readIdent()
+	if name != "" && !p.eof() && p.peek() == ':' {
+		return p.parseTerm(name, start)
+	}
+
+	// 未指定欄位的字詞視為全文檢索
+	p.pos = start
+	word := p.readBare()
+	if word == "" {
+		return nil, newSyntaxError(start, "unexpected %q", p.peek())
+	}
+	return &TextNode{Text: word, Pos: start}, nil
+}
+
+// parseTerm 解析 field:[op]value 條件
+func (p *parser) parseTerm(name string, start int) (Node, error) {
+	def, ok := lookupField(name)
+	if !ok {
+		return nil, newSyntaxError(start, "unknown field %q", name)
+	}
+	p.pos++ // ':'
+
+	op := p.readOperator()
+	valuePos := p.pos
+	if p.eof() || unicode.IsSpace(p.peek()) || p.peek() == ')' {
+		return nil, newSyntaxError(valuePos, "expected a value after %q", string(p.input[start:p.pos]))
+	}
+
+	var value string
+	if p.peek() == '"' {
+		quoted, err := p.readQuoted()
+		if err != nil {
+			return nil, err
+		}
+		value = quoted
+	} else {
+		value = p.readBare()
+	}
+	if value == "" {
+		return nil, newSyntaxError(valuePos, "expected a value after %q", string(p.input[start:valuePos]))
+	}
+
+	return def.buildTerm(op, value, valuePos)
+}
+
+// readOperator 讀取比較運算子，未指定時為等於
+func (p *parser) readOperator() Operator {
+	for _, op := range []Operator{OpGte, OpLte, OpNotEq, OpGt, OpLt, OpEq} {
+		if p.hasPrefix(string(op)) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return OpEq
+}
+
+// readIdent 讀取欄位名稱
+func (p *parser) readIdent() string {
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			break
+		}
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// readBare 讀取未加引號的值，直到空白或右括號
+func (p *parser) readBare() string {
+	start := p.pos
+	for !p.eof() {
+		r := p.peek()
+		if unicode.IsSpace(r) || r == '(' || r == ')' || r == '"' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+// readQuoted 讀取雙引號字串，支援 \" 與 \\ 跳脫
+func (p *parser) readQuoted() (string, error) {
+	start := p.pos
+	p.pos++ // 開頭引號
+
+	var b strings.Builder
+	for !p.eof() {
+		r := p.peek()
+		p.pos++
+		switch r {
+		case '\\':
+			if p.eof() {
+				return "", newSyntaxError(start, "unterminated string")
+			}
+			b.WriteRune(p.peek())
+			p.pos++
+		case '"':
+			if b.Len() == 0 {
+				return "", newSyntaxError(start, "empty string")
+			}
+			return b.String(), nil
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", newSyntaxError(start, "unterminated string")
+}
+
+// acceptKeyword 若下一個字詞為指定關鍵字則消耗並回傳其位置
+func (p *parser) acceptKeyword(keyword string) (int, bool) {
+	if !p.peekKeyword(keyword) {
+		return 0, false
+	}
+	pos := p.pos
+	p.pos += len(keyword)
+	return pos, true
+}
+
+// peekKeyword 檢查下一個字詞是否為指定關鍵字（需大寫，避免與一般字詞混淆）
+func (p *parser) peekKeyword(keyword string) bool {
+	p.skipSpace()
+	if !p.hasPrefix(keyword) {
+		return false
+	}
+	end := p.pos + len(keyword)
+	return end == len(p.input) || unicode.IsSpace(p.input[end]) || p.input[end] == '('
+}
+
+func (p *parser) enter(pos int) error {
+	p.depth++
+	if p.depth > maxDepth {
+		return newSyntaxError(pos, "query nesting exceeds %d levels", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) hasPrefix(s string) bool {
+	i := p.pos
+	for _, r := range s {
+		if i >= len(p.input) || p.input[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() rune {
+	return p.input[p.pos]
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
@@ -0,0 +1,118 @@
+package dsl
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Precedence(t *testing.T) {
+	node, err := Parse(`severity:>=high tag:botnet OR NOT country:cn`)
+	require.NoError(t, err)
+
+	or, ok := node.(*BinaryNode)
+	require.True(t, ok)
+	assert.Equal(t, OpOr, or.Op)
+
+	and, ok := or.Left.(*BinaryNode)
+	require.True(t, ok)
+	assert.Equal(t, OpAnd, and.Op)
+
+	severity := and.Left.(*TermNode)
+	assert.Equal(t, FieldSeverity, severity.Field)
+	assert.Equal(t, OpIn, severity.Op)
+	assert.Equal(t, []string{"high", "critical"}, severity.Value)
+
+	not, ok := or.Right.(*NotNode)
+	require.True(t, ok)
+	assert.Equal(t, "CN", not.Expr.(*TermNode).Value)
+}
+
+func TestParse_Values(t *testing.T) {
+	nowFunc = func() time.Time { return time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC) }
+	defer func() { nowFunc = time.Now }()
+
+	tests := []struct {
+		query string
+		check func(t *testing.T, node Node)
+	}{
+		{`ip:10.0.0.0/8`, func(t *testing.T, node Node) {
+			_, ok := node.(*TermNode).Value.(*net.IPNet)
+			assert.True(t, ok)
+		}},
+		{`ip:2001:db8::1`, func(t *testing.T, node Node) {
+			assert.Equal(t, "2001:db8::1", node.(*TermNode).Value.(net.IP).String())
+		}},
+		{`domain:*.Example.com`, func(t *testing.T, node Node) {
+			term := node.(*TermNode)
+			assert.Equal(t, OpLike, term.Op)
+			assert.Equal(t, "%.example.com", term.Value)
+		}},
+		{`domain:a_b*.com`, func(t *testing.T, node Node) {
+			assert.Equal(t, `a\_b%.com`, node.(*TermNode).Value)
+		}},
+		{`asn:AS13335`, func(t *testing.T, node Node) {
+			assert.Equal(t, int64(13335), node.(*TermNode).Value)
+		}},
+		{`last_seen:>=now-7d`, func(t *testing.T, node Node) {
+			assert.Equal(t, time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC), node.(*TermNode).Value)
+		}},
+		{`first_seen:2024-01-02`, func(t *testing.T, node Node) {
+			day := node.(*BinaryNode)
+			assert.Equal(t, OpGte, day.Left.(*TermNode).Op)
+			assert.Equal(t, OpLt, day.Right.(*TermNode).Op)
+		}},
+		{`"command and control"`, func(t *testing.T, node Node) {
+			text := node.(*TextNode)
+			assert.True(t, text.Phrase)
+			assert.Equal(t, "command and control", text.Text)
+		}},
+		{`source:"Abuse \"IP\" DB"`, func(t *testing.T, node Node) {
+			assert.Equal(t, `Abuse "IP" DB`, node.(*TermNode).Value)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			require.NoError(t, err)
+			tt.check(t, node)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{`   `, 0},
+		{`severity:>=extreme`, 11},
+		{`type:>malware`, 6},
+		{`colour:red`, 0},
+		{`tag:botnet AND`, 14},
+		{`(tag:botnet OR tag:spam`, 0},
+		{`tag:botnet)`, 10},
+		{`confidence:101`, 11},
+		{`ip:10.0.0.0/33`, 3},
+		{`country:`, 8},
+		{`source:"unterminated`, 7},
+		{`OR tag:botnet`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrSyntax))
+
+			var syntaxErr *SyntaxError
+			require.True(t, errors.As(err, &syntaxErr))
+			assert.Equal(t, tt.pos, syntaxErr.Pos, syntaxErr.Error())
+		})
+	}
+}
@@ -8,12 +8,11 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required,min=6,max=128" validate:"required"`
 }
 
-// RegisterRequest 註冊請求
+// RegisterRequest 註冊請求，自行註冊的帳號一律為 basic 角色，其他角色由管理員指派
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50" validate:"required"`
 	Email    string `json:"email" binding:"required,email" validate:"required,email"`
 	Password string `json:"password" binding:"required,min=6,max=128" validate:"required"`
-	Role     string `json:"role" binding:"omitempty,oneof=basic premium" validate:"omitempty,oneof=basic premium"`
 }
 
 // RefreshTokenRequest 刷新令牌請求
@@ -38,6 +37,28 @@ type ConfirmResetPasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=6,max=128" validate:"required"`
 }
 
+// VerifyEmailRequest 驗證信箱請求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" validate:"required"`
+}
+
+// MFACodeRequest 多因素驗證代碼請求，可填入驗證器產生的代碼或復原碼
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,max=32" validate:"required,max=32"`
+}
+
+// MFAVerifyRequest 登入第二步驗證請求
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" validate:"required"`
+	Code     string `json:"code" binding:"required,max=32" validate:"required,max=32"`
+}
+
+// OIDCCallbackRequest 單一登入回呼請求，前端將身分提供者導回時附帶的 code 與 state 原樣送出
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required" validate:"required"`
+	State string `json:"state" binding:"required" validate:"required"`
+}
+
 // UpdateProfileRequest 更新個人檔案請求
 type UpdateProfileRequest struct {
 	Username *string `json:"username" validate:"omitempty,min=3,max=50"`
@@ -53,9 +74,9 @@ type APIKeyCreateRequest struct {
 
 // APIKeyUpdateRequest 更新API金鑰請求
 type APIKeyUpdateRequest struct {
-	Name      *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Name      *string `json:"name" binding:"omitempty,min=1,max=100" validate:"omitempty,min=1,max=100"`
 	IsActive  *bool   `json:"is_active" validate:"omitempty"`
-	ExpiresAt *int64  `json:"expires_at" validate:"omitempty"`
+	ExpiresAt *int64  `json:"expires_at" validate:"omitempty"` // Unix 秒；0 表示永不過期，已輪替的舊金鑰只能提前到期
 	Quota     *int    `json:"quota" validate:"omitempty,min=1"`
 }
 
@@ -66,11 +87,16 @@ type APIKeyListRequest struct {
 	IsActive *bool `json:"is_active" form:"is_active" validate:"omitempty"`
 }
 
+// APIKeyRotateRequest 輪替API金鑰請求
+type APIKeyRotateRequest struct {
+	OverlapMinutes *int `json:"overlap_minutes" binding:"omitempty,min=0,max=10080" validate:"omitempty,min=0,max=10080"`
+}
+
 // UserListRequest 使用者列表請求
 type UserListRequest struct {
 	Page     int     `json:"page" form:"page" validate:"omitempty,min=1"`
 	PageSize int     `json:"page_size" form:"page_size" validate:"omitempty,min=1,max=100"`
-	Role     *string `json:"role" form:"role" validate:"omitempty,oneof=admin premium basic"`
+	Role     *string `json:"role" form:"role" validate:"omitempty,max=50"`
 	IsActive *bool   `json:"is_active" form:"is_active" validate:"omitempty"`
 	Search   *string `json:"search" form:"search" validate:"omitempty,max=100"`
 }
@@ -79,7 +105,7 @@ type UserListRequest struct {
 type UserUpdateRequest struct {
 	Username              *string `json:"username" validate:"omitempty,min=3,max=50"`
 	Email                 *string `json:"email" validate:"omitempty,email"`
-	Role                  *string `json:"role" validate:"omitempty,oneof=admin premium basic"`
+	Role                  *string `json:"role" validate:"omitempty,max=50"`
 	IsActive              *bool   `json:"is_active" validate:"omitempty"`
 	SubscriptionExpiresAt *int64  `json:"subscription_expires_at" validate:"omitempty"`
 	APIQuota              *int    `json:"api_quota" validate:"omitempty,min=0"`
@@ -6,6 +6,9 @@ import "errors"
 var (
 	ErrInvalidIPAddress     = errors.New("invalid IP address")
 	ErrInvalidDomain        = errors.New("invalid domain")
+	ErrInvalidIndicator     = errors.New("invalid indicator")
+	ErrInvalidIndicatorType = errors.New("invalid indicator type")
+	ErrInvalidSearchQuery   = errors.New("invalid search query")
 	ErrInvalidThreatType    = errors.New("invalid threat type")
 	ErrInvalidSeverity      = errors.New("invalid severity level")
 	ErrInvalidConfidence    = errors.New("invalid confidence score")
@@ -41,8 +44,63 @@ var (
 	ErrUserInactive         = errors.New("user is inactive")
 	ErrUserNotFound         = errors.New("user not found")
 	ErrInvalidRefreshToken  = errors.New("invalid refresh token")
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected")
+	ErrInvalidToken         = errors.New("invalid token")
 	ErrInvalidCurrentPassword = errors.New("invalid current password")
+	ErrInvalidResetToken    = errors.New("invalid or expired password reset token")
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	ErrVerificationRateLimited = errors.New("too many verification emails requested")
+
+	// 多因素驗證相關錯誤
+	ErrInvalidMFACode      = errors.New("invalid MFA code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired MFA challenge")
+	ErrMFANotSetup         = errors.New("MFA setup has not been started")
+	ErrMFANotEnabled       = errors.New("MFA is not enabled")
+	ErrMFAAlreadyEnabled   = errors.New("MFA is already enabled")
+	ErrMFARequired         = errors.New("MFA is required for this role")
+
+	// 單一登入相關錯誤
+	ErrInvalidOIDCState         = errors.New("invalid or expired SSO login state")
+	ErrOIDCAuthenticationFailed = errors.New("SSO authentication failed")
+	ErrOIDCProviderUnavailable  = errors.New("SSO identity provider unavailable")
+	ErrOIDCEmailRequired        = errors.New("identity provider did not return an email address")
+	ErrOIDCAccountConflict      = errors.New("an account with this email exists but cannot be linked automatically")
 	
+	// API 金鑰相關錯誤
+	ErrAPIKeyInactive      = errors.New("API key is inactive")
+	ErrAPIKeyExpired       = errors.New("API key has expired")
+	ErrAPIKeyQuotaExceeded = errors.New("API key quota exceeded")
+	ErrAPIKeyNotFound      = errors.New("API key not found")
+	ErrAPIKeyRevoked       = errors.New("API key has been revoked")
+	ErrAPIKeyLimitReached  = errors.New("API key limit reached")
+	ErrAPIKeyRotated       = errors.New("API key has already been rotated")
+
 	// 威脅情報相關錯誤
 	ErrThreatNotFound       = errors.New("threat not found")
+
+	// 收集器相關錯誤
+	ErrCollectorNotFound      = errors.New("collector not registered")
+	ErrCollectorNotConfigured = errors.New("collector not configured")
+	ErrCollectionInProgress   = errors.New("collection already in progress")
+
+	// 情報來源相關錯誤
+	ErrSourceNotFound        = errors.New("intelligence source not found")
+	ErrSourceExists          = errors.New("intelligence source already exists")
+	ErrCollectionJobNotFound = errors.New("collection job not found")
+	ErrSourceURLNotAllowed   = errors.New("source URL must use the collector's official API host")
+
+	// Webhook 相關錯誤
+	ErrWebhookNotFound      = errors.New("webhook not found")
+	ErrInvalidWebhookURL    = errors.New("webhook URL must use http or https")
+	ErrWebhookURLNotAllowed = errors.New("webhook URL must not point to a loopback, private or internal address")
+
+	// 角色與權限相關錯誤
+	ErrRoleNotFound        = errors.New("role not found")
+	ErrRoleExists          = errors.New("role already exists")
+	ErrRoleInUse           = errors.New("role is assigned to users")
+	ErrInvalidRoleName     = errors.New("invalid role name")
+	ErrInvalidPermission   = errors.New("invalid permission")
+	ErrSystemRoleProtected = errors.New("built-in role is protected")
+	ErrCannotChangeOwnRole = errors.New("cannot change your own role")
 ) 
\ No newline at end of file
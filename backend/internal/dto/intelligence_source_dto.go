@@ -0,0 +1,38 @@
+package dto
+
+// IntelligenceSourceCreateRequest 建立情報來源請求
+type IntelligenceSourceCreateRequest struct {
+	Name               string  `json:"name" binding:"required,min=1,max=100" validate:"required"`
+	URL                *string `json:"url" binding:"omitempty,url,max=500" validate:"omitempty,url"`
+	APIKeyRequired     bool    `json:"api_key_required"`
+	IsActive           *bool   `json:"is_active"`
+	CollectionInterval int     `json:"collection_interval" binding:"omitempty,min=60,max=2592000" validate:"omitempty,min=60"`
+}
+
+// IntelligenceSourceUpdateRequest 更新情報來源請求
+type IntelligenceSourceUpdateRequest struct {
+	URL                *string `json:"url" binding:"omitempty,url,max=500" validate:"omitempty,url"`
+	APIKeyRequired     *bool   `json:"api_key_required"`
+	IsActive           *bool   `json:"is_active"`
+	CollectionInterval *int    `json:"collection_interval" binding:"omitempty,min=60,max=2592000" validate:"omitempty,min=60"`
+}
+
+// CollectionJobQueryRequest 查詢收集任務請求
+type CollectionJobQueryRequest struct {
+	SourceID *string `json:"source_id" form:"source_id" binding:"omitempty,uuid"`
+	Status   *string `json:"status" form:"status" binding:"omitempty,oneof=pending in_progress completed failed"`
+
+	// 分頁參數
+	Page     int `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize int `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// SetDefaults 設定預設值
+func (r *CollectionJobQueryRequest) SetDefaults() {
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.PageSize == 0 {
+		r.PageSize = 20
+	}
+}
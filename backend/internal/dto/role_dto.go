@@ -0,0 +1,19 @@
+package dto
+
+// RoleCreateRequest 建立角色請求，角色名稱為小寫英數字、底線或連字號
+type RoleCreateRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=50"`
+	Description string   `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions" binding:"omitempty,dive,min=1,max=100"`
+}
+
+// RoleUpdateRequest 更新角色請求，未提供的欄位維持不變；permissions 會整組取代
+type RoleUpdateRequest struct {
+	Description *string   `json:"description" binding:"omitempty,max=255"`
+	Permissions *[]string `json:"permissions" binding:"omitempty,dive,min=1,max=100"`
+}
+
+// AssignRoleRequest 指派使用者角色請求
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required,min=2,max=50"`
+}
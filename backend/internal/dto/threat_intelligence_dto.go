@@ -6,8 +6,12 @@ import (
 )
 
 // ThreatIntelligenceCreateRequest 建立威脅情報請求
+// 指標可由 indicator_type + indicator_value 指定；未指定類型時依 indicator_value 自動判斷，
+// 兩者皆未提供時沿用 ip_address 或 domain
 type ThreatIntelligenceCreateRequest struct {
-	IPAddress       string                 `json:"ip_address" binding:"required,ip" validate:"required,ip"`
+	IndicatorType   string                 `json:"indicator_type" binding:"omitempty,oneof=ipv4 ipv6 cidr domain url md5 sha1 sha256 email asn"`
+	IndicatorValue  string                 `json:"indicator_value" binding:"omitempty,max=2048"`
+	IPAddress       string                 `json:"ip_address" binding:"omitempty,ip" validate:"omitempty,ip"`
 	Domain          *string                `json:"domain" validate:"omitempty,fqdn"`
 	ThreatType      string                 `json:"threat_type" binding:"required,oneof=malware phishing spam botnet scanner ddos bruteforce other"`
 	Severity        string                 `json:"severity" binding:"required,oneof=low medium high critical"`
@@ -45,6 +49,9 @@ type ThreatIntelligenceQueryRequest struct {
 	Source      *string  `json:"source" form:"source" validate:"omitempty,max=100"`
 	CountryCode *string  `json:"country_code" form:"country_code" validate:"omitempty,len=2"`
 	Tags        []string `json:"tags" form:"tags" validate:"omitempty"`
+
+	// 查詢語言，例如 severity:>=high AND tag:botnet AND country:CN
+	Query string `json:"query" form:"query" binding:"omitempty,max=2000"`
 	
 	// 分頁參數
 	Page     int `json:"page" form:"page" validate:"omitempty,min=1"`
@@ -59,22 +66,55 @@ type ThreatIntelligenceQueryRequest struct {
 	EndTime   *time.Time `json:"end_time" form:"end_time" validate:"omitempty"`
 }
 
+// ThreatIntelligenceSearchRequest 全文搜尋威脅情報請求
+// 搜尋語法支援引號片語、OR 與排除字詞（-word），可與結構化篩選條件併用
+type ThreatIntelligenceSearchRequest struct {
+	Query         string   `json:"q" form:"q" binding:"required,min=1,max=500"`
+	IPAddress     *string  `json:"ip_address" form:"ip_address" binding:"omitempty,ip"`
+	Domain        *string  `json:"domain" form:"domain" binding:"omitempty,fqdn"`
+	IndicatorType *string  `json:"indicator_type" form:"indicator_type" binding:"omitempty,oneof=ipv4 ipv6 cidr domain url md5 sha1 sha256 email asn"`
+	ThreatType    *string  `json:"threat_type" form:"threat_type" binding:"omitempty,oneof=malware phishing spam botnet scanner ddos bruteforce other"`
+	Severity      *string  `json:"severity" form:"severity" binding:"omitempty,oneof=low medium high critical"`
+	Source        *string  `json:"source" form:"source" binding:"omitempty,max=100"`
+	CountryCode   *string  `json:"country_code" form:"country_code" binding:"omitempty,len=2"`
+	Tags          []string `json:"tags" form:"tags" binding:"omitempty"`
+
+	// 分頁參數
+	Page     int `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize int `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+
+	// 排序參數，預設依相關度排序
+	SortBy    string `json:"sort_by" form:"sort_by" binding:"omitempty,oneof=relevance created_at updated_at last_seen confidence_score"`
+	SortOrder string `json:"sort_order" form:"sort_order" binding:"omitempty,oneof=asc desc"`
+
+	// 時間範圍
+	StartTime *time.Time `json:"start_time" form:"start_time" binding:"omitempty"`
+	EndTime   *time.Time `json:"end_time" form:"end_time" binding:"omitempty"`
+}
+
 // ThreatIntelligenceBulkCreateRequest 批量建立威脅情報請求
 type ThreatIntelligenceBulkCreateRequest struct {
 	Items []ThreatIntelligenceCreateRequest `json:"items" binding:"required,min=1,max=100"`
 }
 
 // ThreatIntelligenceBulkUpdateRequest 批量更新威脅情報請求
+// Atomic 為 true 時任一項目失敗即全部回滾，否則允許部分成功
 type ThreatIntelligenceBulkUpdateRequest struct {
-	Items []struct {
-		ID   string                          `json:"id" binding:"required,uuid"`
-		Data ThreatIntelligenceUpdateRequest `json:"data" binding:"required"`
-	} `json:"items" binding:"required,min=1,max=100"`
+	Items  []ThreatIntelligenceBulkUpdateItem `json:"items" binding:"required,min=1,max=100"`
+	Atomic bool                               `json:"atomic"`
+}
+
+// ThreatIntelligenceBulkUpdateItem 批量更新項目
+type ThreatIntelligenceBulkUpdateItem struct {
+	ID   string                          `json:"id"`
+	Data ThreatIntelligenceUpdateRequest `json:"data"`
 }
 
 // ThreatIntelligenceBulkDeleteRequest 批量刪除威脅情報請求
+// Atomic 為 true 時任一項目失敗即全部回滾，否則允許部分成功
 type ThreatIntelligenceBulkDeleteRequest struct {
-	IDs []string `json:"ids" binding:"required,min=1,max=100"`
+	IDs    []string `json:"ids" binding:"required,min=1,max=100"`
+	Atomic bool     `json:"atomic"`
 }
 
 // ThreatIntelligenceTagRequest 標籤操作請求
@@ -92,16 +132,26 @@ type ThreatIntelligenceDomainLookupRequest struct {
 	Domain string `json:"domain" form:"domain" binding:"required,fqdn"`
 }
 
+// ThreatIntelligenceIndicatorLookupRequest 指標查詢請求
+// 類型取自路徑參數，可為具體指標類型，或 ip（自動判斷 IPv4/IPv6）、hash（依長度判斷雜湊演算法）
+type ThreatIntelligenceIndicatorLookupRequest struct {
+	Type  string `json:"type" binding:"required,oneof=ip ipv4 ipv6 cidr domain url hash md5 sha1 sha256 email asn"`
+	Value string `json:"value" form:"value" binding:"required,max=2048"`
+}
+
 // ThreatIntelligenceStatsRequest 統計請求
+// 時間範圍同時套用於彙總統計與時間線；未指定時依間隔使用預設範圍（hour 24 小時、day 30 天、week 12 週）
 type ThreatIntelligenceStatsRequest struct {
-	GroupBy   string     `json:"group_by" form:"group_by" validate:"omitempty,oneof=threat_type severity source country_code"`
-	StartTime *time.Time `json:"start_time" form:"start_time" validate:"omitempty"`
-	EndTime   *time.Time `json:"end_time" form:"end_time" validate:"omitempty"`
+	GroupBy   string     `json:"group_by" form:"group_by" binding:"omitempty,oneof=threat_type severity source country_code indicator_type"`
+	Interval  string     `json:"interval" form:"interval" binding:"omitempty,oneof=hour day week"`
+	TimeField string     `json:"time_field" form:"time_field" binding:"omitempty,oneof=created_at last_seen"`
+	StartTime *time.Time `json:"start_time" form:"start_time"`
+	EndTime   *time.Time `json:"end_time" form:"end_time"`
 }
 
 // ValidateIPAddress 驗證 IP 地址
 func (r *ThreatIntelligenceCreateRequest) ValidateIPAddress() error {
-	if net.ParseIP(r.IPAddress) == nil {
+	if r.IPAddress != "" && net.ParseIP(r.IPAddress) == nil {
 		return ErrInvalidIPAddress
 	}
 	return nil
@@ -123,6 +173,32 @@ func (r *ThreatIntelligenceQueryRequest) SetDefaults() {
 	}
 }
 
+// SetDefaults 設定預設值
+func (r *ThreatIntelligenceSearchRequest) SetDefaults() {
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.PageSize == 0 {
+		r.PageSize = 20
+	}
+	if r.SortBy == "" {
+		r.SortBy = "relevance"
+	}
+	if r.SortOrder == "" {
+		r.SortOrder = "desc"
+	}
+}
+
+// SetDefaults 設定預設值
+func (r *ThreatIntelligenceStatsRequest) SetDefaults() {
+	if r.Interval == "" {
+		r.Interval = "day"
+	}
+	if r.TimeField == "" {
+		r.TimeField = "created_at"
+	}
+}
+
 // GetOffset 取得分頁偏移量
 func (r *ThreatIntelligenceQueryRequest) GetOffset() int {
 	return (r.Page - 1) * r.PageSize
@@ -0,0 +1,42 @@
+package dto
+
+// WebhookCreateRequest 建立 Webhook 訂閱請求，過濾條件留空代表不限制
+type WebhookCreateRequest struct {
+	Name               string   `json:"name" binding:"required,min=1,max=100"`
+	URL                string   `json:"url" binding:"required,url,max=500"`
+	ThreatTypes        []string `json:"threat_types" binding:"omitempty,dive,oneof=malware phishing spam botnet scanner ddos bruteforce other"`
+	Severities         []string `json:"severities" binding:"omitempty,dive,oneof=low medium high critical"`
+	MinConfidenceScore int      `json:"min_confidence_score" binding:"omitempty,min=0,max=100"`
+	Sources            []string `json:"sources" binding:"omitempty,dive,min=1,max=100"`
+	IsActive           *bool    `json:"is_active"`
+}
+
+// WebhookUpdateRequest 更新 Webhook 訂閱請求，未提供的欄位維持不變；重新啟用時會重設連續失敗次數
+type WebhookUpdateRequest struct {
+	Name               *string   `json:"name" binding:"omitempty,min=1,max=100"`
+	URL                *string   `json:"url" binding:"omitempty,url,max=500"`
+	ThreatTypes        *[]string `json:"threat_types" binding:"omitempty,dive,oneof=malware phishing spam botnet scanner ddos bruteforce other"`
+	Severities         *[]string `json:"severities" binding:"omitempty,dive,oneof=low medium high critical"`
+	MinConfidenceScore *int      `json:"min_confidence_score" binding:"omitempty,min=0,max=100"`
+	Sources            *[]string `json:"sources" binding:"omitempty,dive,min=1,max=100"`
+	IsActive           *bool     `json:"is_active"`
+}
+
+// WebhookDeliveryQueryRequest 查詢 Webhook 投遞紀錄請求
+type WebhookDeliveryQueryRequest struct {
+	Status *string `json:"status" form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+
+	// 分頁參數
+	Page     int `json:"page" form:"page" binding:"omitempty,min=1"`
+	PageSize int `json:"page_size" form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// SetDefaults 設定預設值
+func (r *WebhookDeliveryQueryRequest) SetDefaults() {
+	if r.Page == 0 {
+		r.Page = 1
+	}
+	if r.PageSize == 0 {
+		r.PageSize = 20
+	}
+}
@@ -0,0 +1,333 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// Type 威脅事件類型
+type Type string
+
+const (
+	ThreatCreated Type = "created"
+	ThreatUpdated Type = "updated"
+	ThreatDeleted Type = "deleted"
+)
+
+// StreamSinkName gRPC 串流接收端名稱
+const StreamSinkName = "stream"
+
+// 預設容量
+const (
+	DefaultSubscriberBuffer = 256
+	DefaultHistorySize      = 1024
+)
+
+var (
+	// ErrSlowConsumer 訂閱者處理速度跟不上事件產生速度，已被中斷；可使用最後收到的游標續傳
+	ErrSlowConsumer = errors.New("subscriber too slow, events dropped")
+	// ErrCursorExpired 游標對應的事件已不在保留範圍內（或來自重啟前的匯流排），需重新同步
+	ErrCursorExpired = errors.New("resume cursor expired")
+	// ErrInvalidCursor 游標格式錯誤
+	ErrInvalidCursor = errors.New("invalid resume cursor")
+	// ErrBusClosed 匯流排已關閉
+	ErrBusClosed = errors.New("event bus closed")
+)
+
+// ThreatEvent 威脅情報異動事件
+type ThreatEvent struct {
+	ID             string // outbox 事件 ID，重試投遞時保持不變，供接收端去重
+	Cursor         string
+	Type           Type
+	Threat         vo.ThreatIntelligenceVO
+	BecameHighRisk bool // 本次異動使威脅由非高風險變為高風險（含新建即為高風險）
+	OccurredAt     time.Time
+
+	seq uint64
+}
+
+// Filter 訂閱過濾條件，空白條件代表不限制
+type Filter struct {
+	ThreatTypes        []string
+	Severities         []string
+	MinConfidenceScore int
+	Sources            []string
+}
+
+// Match 檢查事件是否符合過濾條件
+func (f Filter) Match(event ThreatEvent) bool {
+	if len(f.ThreatTypes) > 0 && !containsFold(f.ThreatTypes, event.Threat.ThreatType) {
+		return false
+	}
+	if len(f.Severities) > 0 && !containsFold(f.Severities, event.Threat.Severity) {
+		return false
+	}
+	if len(f.Sources) > 0 && !containsFold(f.Sources, event.Threat.Source) {
+		return false
+	}
+	return event.Threat.ConfidenceScore >= f.MinConfidenceScore
+}
+
+// BusConfig 事件匯流排配置
+type BusConfig struct {
+	SubscriberBuffer int // 每個訂閱者的緩衝事件數，滿了即視為慢速消費者
+	HistorySize      int // 保留供續傳的最近事件數
+}
+
+// Bus 行程內事件匯流排
+// 發布永不阻塞：訂閱者緩衝區已滿時會被中斷並收到 ErrSlowConsumer，
+// 之後可帶著最後收到的游標重新訂閱，從保留的歷史中補回遺漏的事件
+type Bus struct {
+	config BusConfig
+	epoch  string
+
+	mu      sync.Mutex
+	seq     uint64
+	history []ThreatEvent // 環狀緩衝區
+	start   int
+	subs    map[*Subscription]struct{}
+	closed  bool
+}
+
+// NewBus 建立事件匯流排
+func NewBus(cfg BusConfig) *Bus {
+	if cfg.SubscriberBuffer <= 0 {
+		cfg.SubscriberBuffer = DefaultSubscriberBuffer
+	}
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = DefaultHistorySize
+	}
+	return &Bus{
+		config:  cfg,
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		history: make([]ThreatEvent, 0, cfg.HistorySize),
+		subs:    make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish 發布事件並指派游標
+func (b *Bus) Publish(events ...ThreatEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	for _, event := range events {
+		b.seq++
+		event.seq = b.seq
+		event.Cursor = b.cursor(b.seq)
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = time.Now().UTC()
+		}
+		b.remember(event)
+
+		for sub := range b.subs {
+			if !sub.filter.Match(event) {
+				continue
+			}
+			select {
+			case sub.ch <- event:
+			default:
+				delete(b.subs, sub)
+				sub.terminate(ErrSlowConsumer)
+			}
+		}
+	}
+}
+
+// Name 接收端名稱
+func (b *Bus) Name() string {
+	return StreamSinkName
+}
+
+// Deliver 作為 relay 接收端發布事件；匯流排已關閉時回傳 ErrBusClosed 讓 relay 稍後重試
+func (b *Bus) Deliver(ctx context.Context, event ThreatEvent) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return ErrBusClosed
+	}
+	b.Publish(event)
+	return nil
+}
+
+// Subscribe 建立訂閱；cursor 為空時只接收之後的事件，否則先補送該游標之後且符合條件的歷史事件
+func (b *Bus) Subscribe(filter Filter, cursor string) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+
+	sub := &Subscription{
+		bus:    b,
+		filter: filter,
+		ch:     make(chan ThreatEvent, b.config.SubscriberBuffer),
+		done:   make(chan struct{}),
+	}
+
+	if cursor != "" {
+		after, err := b.parseCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		replay, err := b.since(after)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range replay {
+			if filter.Match(event) {
+				sub.pending = append(sub.pending, event)
+			}
+		}
+	}
+
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// Close 關閉匯流排並中斷所有訂閱
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		sub.terminate(ErrBusClosed)
+	}
+}
+
+// remember 將事件寫入環狀歷史緩衝區
+func (b *Bus) remember(event ThreatEvent) {
+	if len(b.history) < b.config.HistorySize {
+		b.history = append(b.history, event)
+		return
+	}
+	b.history[b.start] = event
+	b.start = (b.start + 1) % len(b.history)
+}
+
+// since 取得序號之後的歷史事件
+func (b *Bus) since(after uint64) ([]ThreatEvent, error) {
+	if after > b.seq {
+		return nil, ErrCursorExpired
+	}
+	oldest := b.seq - uint64(len(b.history)) + 1
+	if after+1 < oldest {
+		return nil, ErrCursorExpired
+	}
+
+	events := make([]ThreatEvent, 0, b.seq-after)
+	for i := 0; i < len(b.history); i++ {
+		event := b.history[(b.start+i)%len(b.history)]
+		if event.seq > after {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// cursor 組合游標：匯流排啟動代號與序號，重啟後舊游標會被識別為過期
+func (b *Bus) cursor(seq uint64) string {
+	return fmt.Sprintf("%s-%d", b.epoch, seq)
+}
+
+// parseCursor 解析游標序號
+func (b *Bus) parseCursor(cursor string) (uint64, error) {
+	epoch, rawSeq, ok := strings.Cut(cursor, "-")
+	if !ok {
+		return 0, ErrInvalidCursor
+	}
+	seq, err := strconv.ParseUint(rawSeq, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	if epoch != b.epoch {
+		return 0, ErrCursorExpired
+	}
+	return seq, nil
+}
+
+// unsubscribe 移除訂閱
+func (b *Bus) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, sub)
+}
+
+// Subscription 事件訂閱
+type Subscription struct {
+	bus     *Bus
+	filter  Filter
+	ch      chan ThreatEvent
+	pending []ThreatEvent // 待補送的歷史事件，只由 Next 的呼叫端存取
+
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+// Next 取得下一個事件；訂閱被中斷時會先送完已緩衝的事件再回傳原因
+func (s *Subscription) Next(ctx context.Context) (ThreatEvent, error) {
+	if len(s.pending) > 0 {
+		event := s.pending[0]
+		s.pending = s.pending[1:]
+		return event, nil
+	}
+
+	select {
+	case event := <-s.ch:
+		return event, nil
+	default:
+	}
+
+	select {
+	case event := <-s.ch:
+		return event, nil
+	case <-ctx.Done():
+		return ThreatEvent{}, ctx.Err()
+	case <-s.done:
+		select {
+		case event := <-s.ch:
+			return event, nil
+		default:
+			return ThreatEvent{}, s.err
+		}
+	}
+}
+
+// Close 取消訂閱
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s)
+	s.terminate(ErrBusClosed)
+}
+
+// terminate 中斷訂閱並記錄原因
+func (s *Subscription) terminate(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+// containsFold 不分大小寫比對
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
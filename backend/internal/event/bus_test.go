@@ -0,0 +1,125 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+func threatEvent(threatType, severity string, confidence int) ThreatEvent {
+	return ThreatEvent{
+		Type:   ThreatCreated,
+		Threat: vo.ThreatIntelligenceVO{ThreatType: threatType, Severity: severity, ConfidenceScore: confidence},
+	}
+}
+
+func nextEvent(t *testing.T, sub *Subscription) (ThreatEvent, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return sub.Next(ctx)
+}
+
+func TestBus_Filter(t *testing.T) {
+	bus := NewBus(BusConfig{})
+	sub, err := bus.Subscribe(Filter{ThreatTypes: []string{"malware"}, Severities: []string{"high", "critical"}, MinConfidenceScore: 50}, "")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	bus.Publish(
+		threatEvent("phishing", "high", 90),
+		threatEvent("malware", "low", 90),
+		threatEvent("malware", "high", 10),
+		threatEvent("malware", "critical", 80),
+	)
+
+	event, err := nextEvent(t, sub)
+	require.NoError(t, err)
+	assert.Equal(t, "critical", event.Threat.Severity)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = sub.Next(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBus_ResumeFromCursor(t *testing.T) {
+	bus := NewBus(BusConfig{HistorySize: 3})
+	sub, err := bus.Subscribe(Filter{}, "")
+	require.NoError(t, err)
+
+	bus.Publish(threatEvent("malware", "high", 90))
+	first, err := nextEvent(t, sub)
+	require.NoError(t, err)
+	sub.Close()
+
+	// 斷線期間的事件在重新訂閱後補送
+	bus.Publish(threatEvent("botnet", "high", 90), threatEvent("spam", "low", 90))
+	resumed, err := bus.Subscribe(Filter{}, first.Cursor)
+	require.NoError(t, err)
+	defer resumed.Close()
+
+	for _, want := range []string{"botnet", "spam"} {
+		event, err := nextEvent(t, resumed)
+		require.NoError(t, err)
+		assert.Equal(t, want, event.Threat.ThreatType)
+	}
+
+	// 超出保留範圍的游標需重新同步
+	bus.Publish(threatEvent("ddos", "high", 90), threatEvent("ddos", "high", 90))
+	_, err = bus.Subscribe(Filter{}, first.Cursor)
+	assert.ErrorIs(t, err, ErrCursorExpired)
+
+	// 其他匯流排（重啟前）的游標視為過期
+	_, err = NewBus(BusConfig{}).Subscribe(Filter{}, first.Cursor)
+	assert.ErrorIs(t, err, ErrCursorExpired)
+
+	_, err = bus.Subscribe(Filter{}, "garbage")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestBus_SlowConsumer(t *testing.T) {
+	bus := NewBus(BusConfig{SubscriberBuffer: 2})
+	sub, err := bus.Subscribe(Filter{}, "")
+	require.NoError(t, err)
+
+	bus.Publish(
+		threatEvent("malware", "high", 90),
+		threatEvent("botnet", "high", 90),
+		threatEvent("spam", "high", 90),
+	)
+
+	// 已緩衝的事件仍會送達，之後才回報中斷
+	var last ThreatEvent
+	for i := 0; i < 2; i++ {
+		last, err = nextEvent(t, sub)
+		require.NoError(t, err)
+	}
+	_, err = nextEvent(t, sub)
+	assert.ErrorIs(t, err, ErrSlowConsumer)
+
+	resumed, err := bus.Subscribe(Filter{}, last.Cursor)
+	require.NoError(t, err)
+	defer resumed.Close()
+	event, err := nextEvent(t, resumed)
+	require.NoError(t, err)
+	assert.Equal(t, "spam", event.Threat.ThreatType)
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := NewBus(BusConfig{})
+	sub, err := bus.Subscribe(Filter{}, "")
+	require.NoError(t, err)
+
+	bus.Close()
+	_, err = nextEvent(t, sub)
+	assert.ErrorIs(t, err, ErrBusClosed)
+
+	_, err = bus.Subscribe(Filter{}, "")
+	assert.ErrorIs(t, err, ErrBusClosed)
+}
@@ -0,0 +1,81 @@
+package event
+
+import (
+	"context"
+	"strconv"
+
+	pkgmqtt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/mqtt"
+)
+
+// MQTTSinkName MQTT 接收端名稱
+const MQTTSinkName = "mqtt"
+
+// ThreatNotifier MQTT 威脅通知發送介面，由 pkgmqtt.ThreatNotificationPublisher 實作
+type ThreatNotifier interface {
+	PublishThreatCreated(threat *pkgmqtt.ThreatNotification) error
+	PublishThreatUpdated(threat *pkgmqtt.ThreatNotification) error
+	PublishThreatDeleted(threatID, threatType, severity string) error
+	PublishHighRiskAlert(threat *pkgmqtt.ThreatNotification) error
+}
+
+// MQTTSink 將威脅事件轉為 MQTT 通知；broker 無法連線時回傳錯誤，由 relay 負責重試
+type MQTTSink struct {
+	notifier ThreatNotifier
+}
+
+// NewMQTTSink 建立 MQTT 接收端
+func NewMQTTSink(notifier ThreatNotifier) *MQTTSink {
+	return &MQTTSink{notifier: notifier}
+}
+
+// Name 接收端名稱
+func (s *MQTTSink) Name() string {
+	return MQTTSinkName
+}
+
+// Deliver 發送事件通知；成為高風險時另外發送警報
+func (s *MQTTSink) Deliver(ctx context.Context, event ThreatEvent) error {
+	notification := toThreatNotification(event)
+	var err error
+	switch event.Type {
+	case ThreatCreated:
+		err = s.notifier.PublishThreatCreated(notification)
+	case ThreatDeleted:
+		err = s.notifier.PublishThreatDeleted(notification.ThreatID, notification.ThreatType, notification.Severity)
+	default:
+		err = s.notifier.PublishThreatUpdated(notification)
+	}
+	if err != nil || !event.BecameHighRisk || event.Type == ThreatDeleted {
+		return err
+	}
+
+	alert := toThreatNotification(event)
+	alert.ID = event.ID + ":alert"
+	return s.notifier.PublishHighRiskAlert(alert)
+}
+
+// toThreatNotification 轉換威脅事件為 MQTT 通知；通知 ID 沿用事件 ID，重試時保持不變供消費端去重
+func toThreatNotification(event ThreatEvent) *pkgmqtt.ThreatNotification {
+	threat := event.Threat
+	notification := &pkgmqtt.ThreatNotification{
+		ID:         event.ID,
+		ThreatID:   threat.ID.String(),
+		IPAddress:  threat.IPAddress,
+		ThreatType: threat.ThreatType,
+		Severity:   threat.Severity,
+		RiskScore:  strconv.Itoa(threat.RiskScore),
+		Source:     threat.Source,
+		Metadata: map[string]interface{}{
+			"indicator_type":   threat.IndicatorType,
+			"indicator_value":  threat.IndicatorValue,
+			"confidence_score": threat.ConfidenceScore,
+		},
+	}
+	if threat.Domain != nil {
+		notification.Domain = *threat.Domain
+	}
+	if threat.Description != nil {
+		notification.Description = *threat.Description
+	}
+	return notification
+}
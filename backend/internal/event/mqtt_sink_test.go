@@ -0,0 +1,80 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgmqtt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/mqtt"
+)
+
+// fakeNotifier 記錄送出的通知，down 為 true 時模擬 broker 無法連線
+type fakeNotifier struct {
+	mu   sync.Mutex
+	down bool
+	sent []string
+}
+
+func (f *fakeNotifier) record(kind, threatType string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return errors.New("MQTT client not connected")
+	}
+	f.sent = append(f.sent, kind+":"+threatType)
+	return nil
+}
+
+func (f *fakeNotifier) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *fakeNotifier) sentMessages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sent...)
+}
+
+func (f *fakeNotifier) PublishThreatCreated(threat *pkgmqtt.ThreatNotification) error {
+	return f.record("created", threat.ThreatType)
+}
+
+func (f *fakeNotifier) PublishThreatUpdated(threat *pkgmqtt.ThreatNotification) error {
+	return f.record("updated", threat.ThreatType)
+}
+
+func (f *fakeNotifier) PublishThreatDeleted(threatID, threatType, severity string) error {
+	return f.record("deleted", threatType)
+}
+
+func (f *fakeNotifier) PublishHighRiskAlert(threat *pkgmqtt.ThreatNotification) error {
+	return f.record("alert", threat.ThreatType)
+}
+
+func TestMQTTSink_Deliver(t *testing.T) {
+	notifier := &fakeNotifier{}
+	sink := NewMQTTSink(notifier)
+
+	created := threatEvent("malware", "critical", 90)
+	created.BecameHighRisk = true
+	updated := threatEvent("botnet", "low", 90)
+	updated.Type = ThreatUpdated
+	deleted := threatEvent("spam", "low", 90)
+	deleted.Type = ThreatDeleted
+	deleted.BecameHighRisk = true
+
+	for _, event := range []ThreatEvent{created, updated, deleted} {
+		require.NoError(t, sink.Deliver(context.Background(), event))
+	}
+	assert.Equal(t, []string{"created:malware", "alert:malware", "updated:botnet", "deleted:spam"}, notifier.sentMessages())
+
+	// broker 無法連線時回報錯誤，由 relay 重試
+	notifier.setDown(true)
+	assert.Error(t, sink.Deliver(context.Background(), updated))
+}
@@ -0,0 +1,69 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// Sink 事件接收端，由 relay 投遞 outbox 中的事件
+// Deliver 回傳錯誤時 relay 會在稍後重試，因此同一事件可能被投遞多次，接收端應以 ThreatEvent.ID 去重
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, event ThreatEvent) error
+}
+
+// Notifier 通知 relay 有新的 outbox 事件，可立即投遞而不必等待下次輪詢
+type Notifier interface {
+	Notify()
+}
+
+// outboxPayload outbox 事件內容
+type outboxPayload struct {
+	Type           Type                    `json:"type"`
+	Threat         vo.ThreatIntelligenceVO `json:"threat"`
+	BecameHighRisk bool                    `json:"became_high_risk"`
+	OccurredAt     time.Time               `json:"occurred_at"`
+}
+
+// NewOutboxRecord 將威脅事件轉為 outbox 紀錄，需與資料異動寫在同一交易中
+func NewOutboxRecord(event ThreatEvent) (*model.EventOutbox, error) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	payload, err := json.Marshal(outboxPayload{
+		Type:           event.Type,
+		Threat:         event.Threat,
+		BecameHighRisk: event.BecameHighRisk,
+		OccurredAt:     event.OccurredAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode outbox event: %w", err)
+	}
+	return &model.EventOutbox{
+		EventType:   string(event.Type),
+		AggregateID: event.Threat.ID,
+		Payload:     payload,
+		Status:      model.OutboxPending,
+	}, nil
+}
+
+// eventFromOutbox 還原 outbox 紀錄中的威脅事件
+func eventFromOutbox(record *model.EventOutbox) (ThreatEvent, error) {
+	var payload outboxPayload
+	if err := json.Unmarshal(record.Payload, &payload); err != nil {
+		return ThreatEvent{}, fmt.Errorf("failed to decode outbox event %d: %w", record.ID, err)
+	}
+	return ThreatEvent{
+		ID:             strconv.FormatInt(record.ID, 10),
+		Type:           payload.Type,
+		Threat:         payload.Threat,
+		BecameHighRisk: payload.BecameHighRisk,
+		OccurredAt:     payload.OccurredAt,
+	}, nil
+}
@@ -0,0 +1,271 @@
+package event
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// Relay 預設值
+const (
+	DefaultRelayPollInterval     = time.Second
+	DefaultRelayBatchSize        = 100
+	DefaultRelayMaxAttempts      = 10
+	DefaultRelayRetryInterval    = time.Second
+	DefaultRelayMaxRetryInterval = 10 * time.Minute
+	DefaultRelayLease            = time.Minute
+	DefaultRelayRetention        = 7 * 24 * time.Hour
+
+	relayPurgeInterval = time.Hour
+)
+
+// RelayConfig outbox relay 配置
+type RelayConfig struct {
+	PollInterval     time.Duration // 沒有通知時輪詢 outbox 的間隔
+	BatchSize        int           // 每次領取的事件數
+	MaxAttempts      int           // 失敗次數達上限即移入死信
+	RetryInterval    time.Duration // 第一次失敗後的重試間隔，之後以指數退避
+	MaxRetryInterval time.Duration // 指數退避的最大重試間隔
+	Lease            time.Duration // 領取後的租約，逾期未回報會被重新領取
+	Retention        time.Duration // 投遞完成的事件保留期限
+}
+
+// Relay 將 outbox 中的事件投遞到所有接收端
+// 保證至少投遞一次：每個接收端成功後才記錄，失敗的接收端以指數退避重試，
+// 已成功的接收端不會重送；超過重試上限的事件標記為死信
+type Relay struct {
+	repo   repository.EventOutboxRepository
+	sinks  []Sink
+	config RelayConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wake   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewRelay 建立 outbox relay，需呼叫 Start 才會開始投遞
+func NewRelay(repo repository.EventOutboxRepository, sinks []Sink, cfg RelayConfig) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultRelayPollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultRelayBatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultRelayMaxAttempts
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = DefaultRelayRetryInterval
+	}
+	if cfg.MaxRetryInterval < cfg.RetryInterval {
+		cfg.MaxRetryInterval = DefaultRelayMaxRetryInterval
+		if cfg.MaxRetryInterval < cfg.RetryInterval {
+			cfg.MaxRetryInterval = cfg.RetryInterval
+		}
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = DefaultRelayLease
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultRelayRetention
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Relay{
+		repo:   repo,
+		sinks:  sinks,
+		config: cfg,
+		ctx:    ctx,
+		cancel: cancel,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Notify 通知有新事件寫入，不會阻塞呼叫端
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start 啟動背景投遞
+func (r *Relay) Start() {
+	go r.run()
+}
+
+// Stop 停止背景投遞並等待進行中的批次完成；ctx 逾時則中斷投遞，未完成的事件於重啟後重新領取
+func (r *Relay) Stop(ctx context.Context) error {
+	close(r.stop)
+	defer r.cancel()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 處理到期事件直到 outbox 清空，之後等待通知或下次輪詢
+func (r *Relay) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+
+	for {
+		for r.processBatch() {
+			select {
+			case <-r.stop:
+				return
+			default:
+			}
+		}
+
+		if time.Since(lastPurge) >= relayPurgeInterval {
+			r.purge()
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-r.wake:
+		case <-ticker.C:
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// processBatch 領取並投遞一批事件，回傳是否可能還有更多到期事件
+func (r *Relay) processBatch() bool {
+	records, err := r.repo.Claim(r.ctx, r.config.BatchSize, r.config.Lease)
+	if err != nil {
+		pkglogger.Warn("領取outbox事件失敗", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return false
+	}
+
+	for _, record := range records {
+		r.deliver(record)
+	}
+	return len(records) == r.config.BatchSize
+}
+
+// deliver 將事件投遞到尚未成功的接收端並回報結果
+func (r *Relay) deliver(record *model.EventOutbox) {
+	event, err := eventFromOutbox(record)
+	if err != nil {
+		r.markDead(record, record.DeliveredSinks, record.Attempts+1, err.Error())
+		return
+	}
+
+	delivered := append([]string(nil), record.DeliveredSinks...)
+	var failures []string
+	for _, sink := range r.sinks {
+		name := sink.Name()
+		if containsString(delivered, name) {
+			continue
+		}
+		if err := sink.Deliver(r.ctx, event); err != nil {
+			failures = append(failures, name+": "+err.Error())
+			continue
+		}
+		delivered = append(delivered, name)
+	}
+
+	if len(failures) == 0 {
+		if err := r.repo.MarkDelivered(r.ctx, record.ID, delivered); err != nil {
+			pkglogger.Warn("更新outbox事件狀態失敗", pkglogger.Fields{
+				"event_id": record.ID,
+				"error":    err.Error(),
+			})
+		}
+		return
+	}
+
+	attempts := record.Attempts + 1
+	lastErr := strings.Join(failures, "; ")
+	if attempts >= r.config.MaxAttempts {
+		r.markDead(record, delivered, attempts, lastErr)
+		return
+	}
+
+	retryAfter := backoff(r.config.RetryInterval, r.config.MaxRetryInterval, attempts)
+	pkglogger.Warn("outbox事件投遞失敗，稍後重試", pkglogger.Fields{
+		"event_id":    record.ID,
+		"attempts":    attempts,
+		"retry_after": retryAfter.String(),
+		"error":       lastErr,
+	})
+	if err := r.repo.MarkFailed(r.ctx, record.ID, delivered, attempts, retryAfter, lastErr); err != nil {
+		pkglogger.Warn("更新outbox事件狀態失敗", pkglogger.Fields{
+			"event_id": record.ID,
+			"error":    err.Error(),
+		})
+	}
+}
+
+// markDead 將事件移入死信
+func (r *Relay) markDead(record *model.EventOutbox, delivered []string, attempts int, lastErr string) {
+	pkglogger.Error("outbox事件超過重試上限，已移入死信", pkglogger.Fields{
+		"event_id":     record.ID,
+		"event_type":   record.EventType,
+		"aggregate_id": record.AggregateID.String(),
+		"attempts":     attempts,
+		"error":        lastErr,
+	})
+	if err := r.repo.MarkDead(r.ctx, record.ID, delivered, attempts, lastErr); err != nil {
+		pkglogger.Warn("更新outbox事件狀態失敗", pkglogger.Fields{
+			"event_id": record.ID,
+			"error":    err.Error(),
+		})
+	}
+}
+
+// purge 清除超過保留期限的已投遞事件
+func (r *Relay) purge() {
+	purged, err := r.repo.PurgeDelivered(r.ctx, r.config.Retention)
+	if err != nil {
+		pkglogger.Warn("清除已投遞outbox事件失敗", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+	if purged > 0 {
+		pkglogger.Info("已清除過期outbox事件", pkglogger.Fields{
+			"purged": purged,
+		})
+	}
+}
+
+// backoff 計算第 attempts 次失敗後的重試間隔，由 base 起每次加倍直到 max
+func backoff(base, max time.Duration, attempts int) time.Duration {
+	interval := base
+	for i := 1; i < attempts; i++ {
+		interval *= 2
+		if interval >= max {
+			return max
+		}
+	}
+	return interval
+}
+
+// containsString 檢查字串是否存在
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// fakeOutbox 記憶體中的 outbox，領取時忽略租約與重試時間
+type fakeOutbox struct {
+	mu      sync.Mutex
+	records map[int64]*model.EventOutbox
+}
+
+func newFakeOutbox(events ...ThreatEvent) *fakeOutbox {
+	outbox := &fakeOutbox{records: make(map[int64]*model.EventOutbox)}
+	for i, event := range events {
+		record, err := NewOutboxRecord(event)
+		if err != nil {
+			panic(err)
+		}
+		record.ID = int64(i + 1)
+		outbox.records[record.ID] = record
+	}
+	return outbox
+}
+
+func (f *fakeOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.EventOutbox, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var claimed []*model.EventOutbox
+	for id := int64(1); id <= int64(len(f.records)) && len(claimed) < limit; id++ {
+		if record := f.records[id]; record.Status == model.OutboxPending {
+			copied := *record
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (f *fakeOutbox) MarkDelivered(ctx context.Context, id int64, sinks []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[id].Status = model.OutboxDelivered
+	f.records[id].DeliveredSinks = sinks
+	return nil
+}
+
+func (f *fakeOutbox) MarkFailed(ctx context.Context, id int64, sinks []string, attempts int, retryAfter time.Duration, lastErr string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[id].Attempts = attempts
+	f.records[id].DeliveredSinks = sinks
+	f.records[id].LastError = &lastErr
+	return nil
+}
+
+func (f *fakeOutbox) MarkDead(ctx context.Context, id int64, sinks []string, attempts int, lastErr string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[id].Status = model.OutboxDead
+	f.records[id].Attempts = attempts
+	f.records[id].DeliveredSinks = sinks
+	f.records[id].LastError = &lastErr
+	return nil
+}
+
+func (f *fakeOutbox) PurgeDelivered(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeOutbox) record(id int64) model.EventOutbox {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.records[id]
+}
+
+// flakySink 前 failures 次投遞失敗
+type flakySink struct {
+	name      string
+	failures  int32
+	delivered atomic.Int32
+	calls     atomic.Int32
+}
+
+func (s *flakySink) Name() string { return s.name }
+
+func (s *flakySink) Deliver(ctx context.Context, event ThreatEvent) error {
+	if s.calls.Add(1) <= s.failures {
+		return errors.New("sink unavailable")
+	}
+	s.delivered.Add(1)
+	return nil
+}
+
+func TestRelay_RetriesOnlyFailedSinks(t *testing.T) {
+	outbox := newFakeOutbox(threatEvent("malware", "high", 90))
+	healthy := &flakySink{name: "healthy"}
+	flaky := &flakySink{name: "flaky", failures: 2}
+
+	relay := NewRelay(outbox, []Sink{healthy, flaky}, RelayConfig{PollInterval: time.Millisecond, RetryInterval: time.Millisecond})
+	relay.Start()
+	defer relay.Stop(context.Background())
+
+	require.Eventually(t, func() bool { return outbox.record(1).Status == model.OutboxDelivered }, time.Second, time.Millisecond)
+	record := outbox.record(1)
+	assert.Equal(t, 2, record.Attempts)
+	assert.ElementsMatch(t, []string{"healthy", "flaky"}, record.DeliveredSinks)
+	assert.Equal(t, int32(1), healthy.delivered.Load(), "delivered sinks must not be retried")
+	assert.Equal(t, int32(1), flaky.delivered.Load())
+}
+
+func TestRelay_DeadLettersAfterMaxAttempts(t *testing.T) {
+	outbox := newFakeOutbox(threatEvent("malware", "high", 90))
+	broken := &flakySink{name: "broken", failures: 100}
+
+	relay := NewRelay(outbox, []Sink{broken}, RelayConfig{PollInterval: time.Millisecond, RetryInterval: time.Millisecond, MaxAttempts: 3})
+	relay.Start()
+	defer relay.Stop(context.Background())
+
+	require.Eventually(t, func() bool { return outbox.record(1).Status == model.OutboxDead }, time.Second, time.Millisecond)
+	record := outbox.record(1)
+	assert.Equal(t, 3, record.Attempts)
+	require.NotNil(t, record.LastError)
+	assert.Contains(t, *record.LastError, "broken: sink unavailable")
+}
+
+func TestWebhookSink_Deliver(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		if r.Header.Get("X-Event-Type") == string(ThreatDeleted) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, time.Second)
+	event := threatEvent("malware", "high", 90)
+	event.ID = "42"
+	event.Threat.ID = uuid.New()
+
+	require.NoError(t, sink.Deliver(context.Background(), event))
+	assert.Equal(t, "42", received.Get("X-Event-ID"))
+	assert.Equal(t, "application/json", received.Get("Content-Type"))
+
+	event.Type = ThreatDeleted
+	assert.Error(t, sink.Deliver(context.Background(), event))
+}
@@ -0,0 +1,339 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/netguard"
+)
+
+// Webhook 派送預設值
+const (
+	DefaultWebhookPollInterval     = time.Second
+	DefaultWebhookBatchSize        = 50
+	DefaultWebhookConcurrency      = 8
+	DefaultWebhookMaxAttempts      = 8
+	DefaultWebhookRetryInterval    = 10 * time.Second
+	DefaultWebhookMaxRetryInterval = time.Hour
+	DefaultWebhookDisableAfter     = 20
+	DefaultWebhookRetention        = 30 * 24 * time.Hour
+)
+
+// WebhookDispatcherConfig Webhook 派送器配置
+type WebhookDispatcherConfig struct {
+	PollInterval     time.Duration // 沒有通知時輪詢待送紀錄的間隔
+	BatchSize        int           // 每次領取的紀錄數
+	Concurrency      int           // 同時發送的請求數
+	Timeout          time.Duration // 單次請求逾時
+	MaxAttempts      int           // 單筆紀錄的嘗試上限，超過即標記為失敗
+	RetryInterval    time.Duration // 第一次失敗後的重試間隔，之後以指數退避
+	MaxRetryInterval time.Duration // 指數退避的最大重試間隔
+	DisableAfter     int           // Webhook 連續失敗達此次數即自動停用
+	Retention        time.Duration // 已結束的投遞紀錄保留期限
+
+	// AllowPrivateNetworks 允許投遞到本機與內部網路位址，僅供開發與測試；預設拒絕以防止 SSRF
+	AllowPrivateNetworks bool
+}
+
+// WebhookDispatcher 發送 Webhook 投遞紀錄
+// 請求以訂閱密鑰簽章；失敗以指數退避重試，同一端點連續失敗過多時自動停用該 Webhook
+type WebhookDispatcher struct {
+	webhooks   repository.WebhookRepository
+	deliveries repository.WebhookDeliveryRepository
+	client     *http.Client
+	config     WebhookDispatcherConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wake   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewWebhookDispatcher 建立 Webhook 派送器，需呼叫 Start 才會開始發送
+func NewWebhookDispatcher(webhooks repository.WebhookRepository, deliveries repository.WebhookDeliveryRepository, cfg WebhookDispatcherConfig) *WebhookDispatcher {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultWebhookPollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultWebhookBatchSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultWebhookConcurrency
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultWebhookTimeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultWebhookMaxAttempts
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = DefaultWebhookRetryInterval
+	}
+	if cfg.MaxRetryInterval < cfg.RetryInterval {
+		cfg.MaxRetryInterval = DefaultWebhookMaxRetryInterval
+		if cfg.MaxRetryInterval < cfg.RetryInterval {
+			cfg.MaxRetryInterval = cfg.RetryInterval
+		}
+	}
+	if cfg.DisableAfter <= 0 {
+		cfg.DisableAfter = DefaultWebhookDisableAfter
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = DefaultWebhookRetention
+	}
+
+	// Webhook URL 由使用者提供，連線時檢查解析後的位址且不跟隨重新導向
+	client := netguard.NewHTTPClient(cfg.Timeout)
+	if cfg.AllowPrivateNetworks {
+		client = &http.Client{
+			Timeout: cfg.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		client:     client,
+		config:     cfg,
+		ctx:        ctx,
+		cancel:     cancel,
+		wake:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Notify 通知有新的待送紀錄，不會阻塞呼叫端
+func (d *WebhookDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start 啟動背景發送
+func (d *WebhookDispatcher) Start() {
+	go d.run()
+}
+
+// Stop 停止背景發送並等待進行中的請求完成；ctx 逾時則中斷請求，未完成的紀錄於重啟後重新領取
+func (d *WebhookDispatcher) Stop(ctx context.Context) error {
+	close(d.stop)
+	defer d.cancel()
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 處理到期紀錄直到清空，之後等待通知或下次輪詢
+func (d *WebhookDispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+
+	for {
+		for d.processBatch() {
+			select {
+			case <-d.stop:
+				return
+			default:
+			}
+		}
+
+		if time.Since(lastPurge) >= relayPurgeInterval {
+			d.purge()
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-d.wake:
+		case <-ticker.C:
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+// processBatch 領取並平行發送一批紀錄，回傳是否可能還有更多到期紀錄
+func (d *WebhookDispatcher) processBatch() bool {
+	deliveries, err := d.deliveries.Claim(d.ctx, d.config.BatchSize, d.config.Timeout*2)
+	if err != nil {
+		pkglogger.Warn("領取Webhook待送紀錄失敗", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return false
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, d.config.Concurrency)
+	disabled := &disabledWebhooks{ids: make(map[uuid.UUID]bool)}
+	for _, delivery := range deliveries {
+		if delivery.Webhook == nil {
+			continue // Webhook 已刪除，紀錄會隨之刪除
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery *model.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// 同批次中已被停用的 Webhook 不再發送，紀錄保持待送，重新啟用後繼續投遞
+			if !disabled.has(delivery.WebhookID) {
+				d.deliver(delivery, disabled)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries) == d.config.BatchSize
+}
+
+// deliver 發送一筆紀錄並記錄結果
+func (d *WebhookDispatcher) deliver(delivery *model.WebhookDelivery, disabled *disabledWebhooks) {
+	webhook := delivery.Webhook
+	err := d.send(delivery)
+	delivery.Attempts++
+
+	var retryAfter time.Duration
+	if err == nil {
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.LastError = nil
+		if err := d.webhooks.RecordSuccess(d.ctx, webhook.ID); err != nil {
+			pkglogger.Warn("更新Webhook狀態失敗", pkglogger.Fields{
+				"webhook_id": webhook.ID.String(),
+				"error":      err.Error(),
+			})
+		}
+	} else {
+		message := err.Error()
+		delivery.LastError = &message
+		if delivery.Attempts >= d.config.MaxAttempts {
+			delivery.Status = model.WebhookDeliveryFailed
+		} else {
+			retryAfter = backoff(d.config.RetryInterval, d.config.MaxRetryInterval, delivery.Attempts)
+		}
+		if d.recordFailure(webhook, message) {
+			disabled.add(webhook.ID)
+		}
+	}
+
+	if err := d.deliveries.RecordAttempt(d.ctx, delivery, retryAfter); err != nil {
+		pkglogger.Warn("更新Webhook投遞紀錄失敗", pkglogger.Fields{
+			"delivery_id": delivery.ID.String(),
+			"error":       err.Error(),
+		})
+	}
+}
+
+// recordFailure 累計 Webhook 連續失敗次數，達上限時自動停用並回傳 true
+func (d *WebhookDispatcher) recordFailure(webhook *model.Webhook, message string) bool {
+	reason := fmt.Sprintf("連續投遞失敗 %d 次後自動停用，最後錯誤：%s", d.config.DisableAfter, message)
+	disabled, err := d.webhooks.RecordFailure(d.ctx, webhook.ID, d.config.DisableAfter, reason)
+	if err != nil {
+		pkglogger.Warn("更新Webhook狀態失敗", pkglogger.Fields{
+			"webhook_id": webhook.ID.String(),
+			"error":      err.Error(),
+		})
+		return false
+	}
+	if disabled {
+		pkglogger.Warn("Webhook連續投遞失敗，已自動停用", pkglogger.Fields{
+			"webhook_id": webhook.ID.String(),
+			"user_id":    webhook.UserID.String(),
+			"url":        webhook.URL,
+			"error":      message,
+		})
+	}
+	return disabled
+}
+
+// send 簽章並發送請求，將回應狀態碼記錄到投遞紀錄；非 2xx 回應（包含重新導向）視為失敗
+// 回應內容不保存，避免投遞日誌成為讀取目標端點內容的管道
+func (d *WebhookDispatcher) send(delivery *model.WebhookDelivery) error {
+	webhook := delivery.Webhook
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, webhook.ID.String())
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	duration := int(time.Since(start).Milliseconds())
+	delivery.DurationMs = &duration
+	delivery.ResponseStatus = nil
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	delivery.ResponseStatus = &resp.StatusCode
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// purge 清除超過保留期限的投遞紀錄
+func (d *WebhookDispatcher) purge() {
+	purged, err := d.deliveries.Purge(d.ctx, d.config.Retention)
+	if err != nil {
+		pkglogger.Warn("清除Webhook投遞紀錄失敗", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return
+	}
+	if purged > 0 {
+		pkglogger.Info("已清除過期Webhook投遞紀錄", pkglogger.Fields{
+			"purged": purged,
+		})
+	}
+}
+
+// disabledWebhooks 記錄批次中被自動停用的 Webhook
+type disabledWebhooks struct {
+	mu  sync.Mutex
+	ids map[uuid.UUID]bool
+}
+
+// add 標記 Webhook 已停用
+func (w *disabledWebhooks) add(id uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ids[id] = true
+}
+
+// has 檢查 Webhook 是否已停用
+func (w *disabledWebhooks) has(id uuid.UUID) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ids[id]
+}
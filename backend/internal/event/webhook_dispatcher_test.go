@@ -0,0 +1,275 @@
+package event
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+)
+
+// fakeWebhookStore 記憶體中的 Webhook 與投遞紀錄，領取時忽略租約與重試時間
+type fakeWebhookStore struct {
+	mu         sync.Mutex
+	webhooks   map[uuid.UUID]*model.Webhook
+	deliveries []*model.WebhookDelivery
+}
+
+func newFakeWebhookStore(webhooks ...*model.Webhook) *fakeWebhookStore {
+	store := &fakeWebhookStore{webhooks: make(map[uuid.UUID]*model.Webhook)}
+	for _, webhook := range webhooks {
+		store.webhooks[webhook.ID] = webhook
+	}
+	return store
+}
+
+func (f *fakeWebhookStore) Create(ctx context.Context, webhook *model.Webhook) error { return nil }
+func (f *fakeWebhookStore) GetByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	return nil, nil
+}
+func (f *fakeWebhookStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Webhook, error) {
+	return nil, nil
+}
+func (f *fakeWebhookStore) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*model.Webhook, error) {
+	return nil, nil
+}
+func (f *fakeWebhookStore) Delete(ctx context.Context, id uuid.UUID) error { return nil }
+
+func (f *fakeWebhookStore) ListActive(ctx context.Context) ([]*model.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var active []*model.Webhook
+	for _, webhook := range f.webhooks {
+		if webhook.IsActive {
+			copied := *webhook
+			active = append(active, &copied)
+		}
+	}
+	return active, nil
+}
+
+func (f *fakeWebhookStore) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhooks[id].ConsecutiveFailures = 0
+	return nil
+}
+
+func (f *fakeWebhookStore) RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	webhook := f.webhooks[id]
+	webhook.ConsecutiveFailures++
+	if webhook.IsActive && webhook.ConsecutiveFailures >= disableAfter {
+		webhook.IsActive = false
+		webhook.DisabledReason = &reason
+		return true, nil
+	}
+	return false, nil
+}
+
+func (f *fakeWebhookStore) Enqueue(ctx context.Context, deliveries ...*model.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, delivery := range deliveries {
+		delivery.ID = uuid.New()
+		f.deliveries = append(f.deliveries, delivery)
+	}
+	return nil
+}
+
+func (f *fakeWebhookStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var claimed []*model.WebhookDelivery
+	for _, delivery := range f.deliveries {
+		webhook := f.webhooks[delivery.WebhookID]
+		if delivery.Status == model.WebhookDeliveryPending && webhook.IsActive && len(claimed) < limit {
+			copied := *delivery
+			webhookCopy := *webhook
+			copied.Webhook = &webhookCopy
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (f *fakeWebhookStore) RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery, retryAfter time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, stored := range f.deliveries {
+		if stored.ID == delivery.ID {
+			stored.Status = delivery.Status
+			stored.Attempts = delivery.Attempts
+			stored.ResponseStatus = delivery.ResponseStatus
+			stored.LastError = delivery.LastError
+		}
+	}
+	return nil
+}
+
+func (f *fakeWebhookStore) List(ctx context.Context, filter *repository.WebhookDeliveryFilter) ([]*model.WebhookDelivery, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeWebhookStore) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeWebhookStore) snapshot() ([]model.WebhookDelivery, map[uuid.UUID]model.Webhook) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	deliveries := make([]model.WebhookDelivery, len(f.deliveries))
+	for i, delivery := range f.deliveries {
+		deliveries[i] = *delivery
+	}
+	webhooks := make(map[uuid.UUID]model.Webhook, len(f.webhooks))
+	for id, webhook := range f.webhooks {
+		webhooks[id] = *webhook
+	}
+	return deliveries, webhooks
+}
+
+func TestWebhookSubscriptionSink_FiltersAndEnqueues(t *testing.T) {
+	malware := &model.Webhook{ID: uuid.New(), IsActive: true, ThreatTypes: model.StringArray{"malware"}, Sources: model.StringArray{"abuseipdb"}}
+	everything := &model.Webhook{ID: uuid.New(), IsActive: true}
+	disabled := &model.Webhook{ID: uuid.New(), IsActive: false}
+	store := newFakeWebhookStore(malware, everything, disabled)
+	sink := NewWebhookSubscriptionSink(store, store, nil)
+
+	event := threatEvent("botnet", "high", 90)
+	event.ID = "1"
+	require.NoError(t, sink.Deliver(context.Background(), event))
+
+	event = threatEvent("malware", "critical", 90)
+	event.ID = "2"
+	event.Threat.Source = "AbuseIPDB"
+	event.BecameHighRisk = true
+	require.NoError(t, sink.Deliver(context.Background(), event))
+
+	deliveries, _ := store.snapshot()
+	got := map[uuid.UUID][]string{}
+	for _, delivery := range deliveries {
+		got[delivery.WebhookID] = append(got[delivery.WebhookID], delivery.EventID+"/"+delivery.EventType)
+	}
+	assert.ElementsMatch(t, []string{"2/created", "2:alert/alert"}, got[malware.ID])
+	assert.ElementsMatch(t, []string{"1/created", "2/created", "2:alert/alert"}, got[everything.ID])
+	assert.Empty(t, got[disabled.ID])
+}
+
+func TestWebhookDispatcher_SignsAndRetries(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := SignWebhookPayload("secret", r.Header.Get(WebhookTimestampHeader), body)
+		assert.Equal(t, expected, r.Header.Get(WebhookSignatureHeader))
+		assert.Equal(t, "created", r.Header.Get(WebhookEventHeader))
+
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	webhook := &model.Webhook{ID: uuid.New(), URL: server.URL, Secret: "secret", IsActive: true}
+	store := newFakeWebhookStore(webhook)
+	require.NoError(t, store.Enqueue(context.Background(), &model.WebhookDelivery{
+		WebhookID: webhook.ID, EventID: "1", EventType: "created", Payload: []byte(`{"id":"1"}`), Status: model.WebhookDeliveryPending,
+	}))
+
+	dispatcher := NewWebhookDispatcher(store, store, WebhookDispatcherConfig{PollInterval: time.Millisecond, RetryInterval: time.Millisecond, AllowPrivateNetworks: true})
+	dispatcher.Start()
+	defer dispatcher.Stop(context.Background())
+
+	require.Eventually(t, func() bool {
+		deliveries, _ := store.snapshot()
+		return deliveries[0].Status == model.WebhookDeliverySucceeded
+	}, time.Second, time.Millisecond)
+
+	deliveries, webhooks := store.snapshot()
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, *deliveries[0].ResponseStatus)
+	assert.Zero(t, webhooks[webhook.ID].ConsecutiveFailures)
+}
+
+func TestWebhookDispatcher_DisablesAfterRepeatedFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	webhook := &model.Webhook{ID: uuid.New(), URL: server.URL, Secret: "secret", IsActive: true}
+	store := newFakeWebhookStore(webhook)
+	for _, id := range []string{"1", "2"} {
+		require.NoError(t, store.Enqueue(context.Background(), &model.WebhookDelivery{
+			WebhookID: webhook.ID, EventID: id, EventType: "created", Payload: []byte(`{}`), Status: model.WebhookDeliveryPending,
+		}))
+	}
+
+	dispatcher := NewWebhookDispatcher(store, store, WebhookDispatcherConfig{
+		PollInterval:  time.Millisecond,
+		RetryInterval: time.Millisecond,
+		Concurrency:   1,
+		MaxAttempts:   2,
+		DisableAfter:  3,
+
+		AllowPrivateNetworks: true,
+	})
+	dispatcher.Start()
+
+	require.Eventually(t, func() bool {
+		_, webhooks := store.snapshot()
+		return !webhooks[webhook.ID].IsActive
+	}, time.Second, time.Millisecond)
+	require.NoError(t, dispatcher.Stop(context.Background()))
+
+	// 第一筆達嘗試上限後標記失敗，第二筆因 Webhook 停用而保持待送
+	deliveries, webhooks := store.snapshot()
+	assert.Equal(t, model.WebhookDeliveryFailed, deliveries[0].Status)
+	assert.Equal(t, model.WebhookDeliveryPending, deliveries[1].Status)
+	assert.Equal(t, 3, webhooks[webhook.ID].ConsecutiveFailures)
+	require.NotNil(t, webhooks[webhook.ID].DisabledReason)
+	assert.Contains(t, *webhooks[webhook.ID].DisabledReason, "status 503")
+}
+
+func TestWebhookDispatcher_RejectsPrivateAddresses(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	webhook := &model.Webhook{ID: uuid.New(), URL: server.URL, Secret: "secret", IsActive: true}
+	store := newFakeWebhookStore(webhook)
+	require.NoError(t, store.Enqueue(context.Background(), &model.WebhookDelivery{
+		WebhookID: webhook.ID, EventID: "1", EventType: "created", Payload: []byte(`{}`), Status: model.WebhookDeliveryPending,
+	}))
+
+	dispatcher := NewWebhookDispatcher(store, store, WebhookDispatcherConfig{PollInterval: time.Millisecond, MaxAttempts: 1})
+	dispatcher.Start()
+
+	require.Eventually(t, func() bool {
+		deliveries, _ := store.snapshot()
+		return deliveries[0].Status == model.WebhookDeliveryFailed
+	}, time.Second, time.Millisecond)
+	require.NoError(t, dispatcher.Stop(context.Background()))
+
+	deliveries, _ := store.snapshot()
+	require.NotNil(t, deliveries[0].LastError)
+	assert.Contains(t, *deliveries[0].LastError, "not allowed")
+	assert.Nil(t, deliveries[0].ResponseStatus)
+	assert.Zero(t, calls)
+}
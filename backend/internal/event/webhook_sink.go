@@ -0,0 +1,81 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// DefaultWebhookTimeout Webhook 請求預設逾時
+const DefaultWebhookTimeout = 10 * time.Second
+
+// webhookPayload Webhook 請求內容
+type webhookPayload struct {
+	ID             string                  `json:"id"`
+	Type           Type                    `json:"type"`
+	Threat         vo.ThreatIntelligenceVO `json:"threat"`
+	BecameHighRisk bool                    `json:"became_high_risk"`
+	OccurredAt     time.Time               `json:"occurred_at"`
+}
+
+// WebhookSink 以 HTTP POST 將威脅事件送到指定 URL，非 2xx 回應視為失敗
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink 建立 Webhook 接收端
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 接收端名稱，以 URL 區分不同的 Webhook
+func (s *WebhookSink) Name() string {
+	return "webhook:" + s.url
+}
+
+// Deliver 發送事件；X-Event-ID 在重試時保持不變，供接收端去重
+func (s *WebhookSink) Deliver(ctx context.Context, event ThreatEvent) error {
+	body, err := json.Marshal(webhookPayload{
+		ID:             event.ID,
+		Type:           event.Type,
+		Threat:         event.Threat,
+		BecameHighRisk: event.BecameHighRisk,
+		OccurredAt:     event.OccurredAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
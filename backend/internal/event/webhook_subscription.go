@@ -0,0 +1,120 @@
+package event
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	pkgmqtt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/mqtt"
+)
+
+// WebhookSubscriptionSinkName Webhook 訂閱接收端名稱
+const WebhookSubscriptionSinkName = "webhook-subscriptions"
+
+// Webhook 請求標頭
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature" // sha256=<hex>，對 "<timestamp>.<body>" 以訂閱密鑰計算的 HMAC-SHA256
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // 簽章時間（Unix 秒），接收端可據以拒絕重放
+	WebhookEventHeader     = "X-Webhook-Event"     // 通知類型：created、updated、deleted、alert
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"  // 投遞紀錄 ID
+	WebhookIDHeader        = "X-Webhook-ID"        // Webhook 訂閱 ID
+)
+
+// WebhookSubscriptionSink 將威脅事件依使用者的 Webhook 訂閱過濾後寫入投遞佇列，實際發送由 WebhookDispatcher 負責
+// 每個 Webhook 各自重試，單一端點失敗不會阻塞 outbox 或其他 Webhook
+type WebhookSubscriptionSink struct {
+	webhooks   repository.WebhookRepository
+	deliveries repository.WebhookDeliveryRepository
+	notifier   Notifier
+}
+
+// NewWebhookSubscriptionSink 建立 Webhook 訂閱接收端；notifier 用於喚醒派送器，可為 nil
+func NewWebhookSubscriptionSink(webhooks repository.WebhookRepository, deliveries repository.WebhookDeliveryRepository, notifier Notifier) *WebhookSubscriptionSink {
+	return &WebhookSubscriptionSink{webhooks: webhooks, deliveries: deliveries, notifier: notifier}
+}
+
+// Name 接收端名稱
+func (s *WebhookSubscriptionSink) Name() string {
+	return WebhookSubscriptionSinkName
+}
+
+// Deliver 為符合條件的 Webhook 建立待送紀錄；擁有者已停用或失去權限的 Webhook 不會列入
+func (s *WebhookSubscriptionSink) Deliver(ctx context.Context, event ThreatEvent) error {
+	webhooks, err := s.webhooks.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	var deliveries []*model.WebhookDelivery
+	notifications := threatNotifications(event)
+	for _, webhook := range webhooks {
+		if !webhookFilter(webhook).Match(event) {
+			continue
+		}
+		for _, notification := range notifications {
+			payload, err := json.Marshal(notification)
+			if err != nil {
+				return fmt.Errorf("failed to encode webhook payload: %w", err)
+			}
+			deliveries = append(deliveries, &model.WebhookDelivery{
+				WebhookID: webhook.ID,
+				EventID:   notification.ID,
+				EventType: notification.Type,
+				Payload:   payload,
+				Status:    model.WebhookDeliveryPending,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.deliveries.Enqueue(ctx, deliveries...); err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	if s.notifier != nil {
+		s.notifier.Notify()
+	}
+	return nil
+}
+
+// SignWebhookPayload 計算 Webhook 簽章；接收端以相同方式計算並以常數時間比對
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookFilter 轉換 Webhook 訂閱條件
+func webhookFilter(webhook *model.Webhook) Filter {
+	return Filter{
+		ThreatTypes:        webhook.ThreatTypes,
+		Severities:         webhook.Severities,
+		MinConfidenceScore: webhook.MinConfidenceScore,
+		Sources:            webhook.Sources,
+	}
+}
+
+// threatNotifications 轉換事件為通知；成為高風險時另外產生警報通知
+func threatNotifications(event ThreatEvent) []*pkgmqtt.ThreatNotification {
+	notification := toThreatNotification(event)
+	notification.Type = string(event.Type)
+	notification.Timestamp = event.OccurredAt
+	notifications := []*pkgmqtt.ThreatNotification{notification}
+
+	if event.BecameHighRisk && event.Type != ThreatDeleted {
+		alert := toThreatNotification(event)
+		alert.ID = event.ID + ":alert"
+		alert.Type = "alert"
+		alert.Timestamp = event.OccurredAt
+		notifications = append(notifications, alert)
+	}
+	return notifications
+}
@@ -0,0 +1,363 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	proto "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/api/proto/api/proto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/middleware"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	pkgjwt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/jwt"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// 認證用 metadata 鍵（gRPC metadata 鍵一律為小寫）
+const (
+	authorizationMetadataKey = "authorization"
+	apiKeyMetadataKey        = "x-api-key"
+)
+
+// 認證方式
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
+// methodRule RPC 的授權規則
+type methodRule struct {
+	permissions []string // 需要的權限
+	write       bool     // 是否會變更資料，對應 REST 的非唯讀 HTTP 方法，決定信箱驗證政策 write 範圍是否適用
+}
+
+// methodPermissions 各 RPC 需要的權限，與對應 REST 路由的規則一致；未列出的方法一律拒絕
+var methodPermissions = map[string]methodRule{
+	proto.ThreatIntelligenceService_GetThreatIntelligence_FullMethodName:    {permissions: []string{model.PermissionThreatRead}},
+	proto.ThreatIntelligenceService_ListThreatIntelligence_FullMethodName:   {permissions: []string{model.PermissionThreatRead}},
+	proto.ThreatIntelligenceService_CreateThreatIntelligence_FullMethodName: {permissions: []string{model.PermissionThreatWrite}, write: true},
+	proto.ThreatIntelligenceService_UpdateThreatIntelligence_FullMethodName: {permissions: []string{model.PermissionThreatWrite}, write: true},
+	proto.ThreatIntelligenceService_DeleteThreatIntelligence_FullMethodName: {permissions: []string{model.PermissionThreatDelete}, write: true},
+	proto.ThreatIntelligenceService_SearchThreatIntelligence_FullMethodName: {permissions: []string{model.PermissionThreatRead}},
+	proto.ThreatIntelligenceService_GetThreatStatistics_FullMethodName:      {permissions: []string{model.PermissionThreatRead}},
+	proto.ThreatIntelligenceService_SubscribeThreats_FullMethodName:         {permissions: []string{model.PermissionThreatRead}},
+}
+
+// publicServices 不需認證的服務：健康檢查供負載平衡器探測，reflection 僅在設定啟用時註冊
+var publicServices = []string{
+	healthpb.Health_ServiceDesc.ServiceName,
+	"grpc.reflection.v1.ServerReflection",
+	"grpc.reflection.v1alpha.ServerReflection",
+}
+
+// Identity 已認證的呼叫者身分，供審計使用
+type Identity struct {
+	UserID      uuid.UUID
+	Username    string
+	Email       string
+	Role        string
+	AuthMethod  string
+	APIKeyID    *uuid.UUID
+	MFAVerified bool // JWT 登入時是否通過多因素驗證
+}
+
+// identityContextKey 身分在 context 中的鍵
+type identityContextKey struct{}
+
+// ContextWithIdentity 將呼叫者身分放入 context
+func ContextWithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext 取得呼叫者身分
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// APIKeyAuthenticator 驗證原始 API 金鑰
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error)
+}
+
+// PermissionChecker 查詢角色是否具備權限
+type PermissionChecker interface {
+	HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error)
+}
+
+// AuthInterceptor gRPC 認證與授權攔截器，接受與 REST 相同的 JWT 及 API 金鑰
+type AuthInterceptor struct {
+	jwtManager        *pkgjwt.JWTManager
+	apiKeys           APIKeyAuthenticator
+	permissions       PermissionChecker
+	emailVerification middleware.EmailVerificationChecker
+	emailPolicy       map[string]string
+	mfaRequiredRoles  map[string]bool
+}
+
+// NewAuthInterceptor 建立認證攔截器；apiKeys 為 nil 時僅接受 JWT
+// emailPolicy 與 REST 的 RequireVerifiedEmailMiddleware 使用同一份「角色:範圍」政策，未列出的角色不受限制
+func NewAuthInterceptor(jwtManager *pkgjwt.JWTManager, apiKeys APIKeyAuthenticator, permissions PermissionChecker, emailVerification middleware.EmailVerificationChecker, emailPolicy map[string]string) *AuthInterceptor {
+	return &AuthInterceptor{
+		jwtManager:        jwtManager,
+		apiKeys:           apiKeys,
+		permissions:       permissions,
+		emailVerification: emailVerification,
+		emailPolicy:       emailPolicy,
+	}
+}
+
+// RequireMFA 要求指定角色以通過多因素驗證的 JWT 呼叫，與 REST 的 RequireMFAMiddleware 規則一致
+func (a *AuthInterceptor) RequireMFA(roles ...string) *AuthInterceptor {
+	a.mfaRequiredRoles = make(map[string]bool, len(roles))
+	for _, role := range roles {
+		if role = strings.TrimSpace(role); role != "" {
+			a.mfaRequiredRoles[role] = true
+		}
+	}
+	return a
+}
+
+// ServerOptions 取得註冊攔截器的伺服器選項
+func (a *AuthInterceptor) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(a.Unary()),
+		grpc.ChainStreamInterceptor(a.Stream()),
+	}
+}
+
+// Unary 一元 RPC 攔截器
+func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		auditCall(ctx, info.FullMethod, err)
+		return resp, err
+	}
+}
+
+// Stream 串流 RPC 攔截器
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		err = handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+		auditCall(ctx, info.FullMethod, err)
+		return err
+	}
+}
+
+// authorize 認證呼叫者並檢查方法權限，成功時回傳帶有身分的 context
+func (a *AuthInterceptor) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	if isPublicMethod(fullMethod) {
+		return ctx, nil
+	}
+
+	identity, err := a.authenticate(ctx)
+	if err != nil {
+		pkglogger.Debug("gRPC authentication failed", pkglogger.Fields{
+			"method": fullMethod,
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	rule, ok := methodPermissions[fullMethod]
+	allowed := false
+	if ok {
+		allowed, err = a.permissions.HasPermissions(ctx, identity.Role, rule.permissions...)
+		if err != nil {
+			pkglogger.Error("gRPC permission check failed", pkglogger.Fields{
+				"method": fullMethod,
+				"error":  err.Error(),
+			})
+			return nil, status.Error(codes.Unavailable, "unable to verify permissions")
+		}
+	}
+	if !allowed {
+		pkglogger.Warn("gRPC authorization denied", pkglogger.Fields{
+			"method":      fullMethod,
+			"user_id":     identity.UserID,
+			"role":        identity.Role,
+			"auth_method": identity.AuthMethod,
+		})
+		return nil, status.Error(codes.PermissionDenied, "insufficient permissions for this action")
+	}
+
+	if identity.AuthMethod == AuthMethodJWT && a.mfaRequiredRoles[identity.Role] && !identity.MFAVerified {
+		pkglogger.Warn("gRPC call rejected, MFA required", pkglogger.Fields{
+			"method":  fullMethod,
+			"user_id": identity.UserID,
+			"role":    identity.Role,
+		})
+		return nil, status.Error(codes.PermissionDenied, "multi-factor authentication is required for this role")
+	}
+
+	if err := a.checkEmailVerified(ctx, fullMethod, identity, rule.write); err != nil {
+		return nil, err
+	}
+
+	return ContextWithIdentity(ctx, identity), nil
+}
+
+// checkEmailVerified 依信箱驗證政策檢查呼叫者，規則與 RequireVerifiedEmailMiddleware 一致：
+// 範圍 write 只限制會變更資料的方法，all 限制所有方法；驗證狀態每次查詢
+func (a *AuthInterceptor) checkEmailVerified(ctx context.Context, fullMethod string, identity *Identity, write bool) error {
+	scope, restricted := a.emailPolicy[identity.Role]
+	if !restricted || (scope == middleware.EmailVerificationScopeWrite && !write) {
+		return nil
+	}
+
+	verified, err := a.emailVerification.IsEmailVerified(ctx, identity.UserID)
+	if err != nil {
+		pkglogger.Error("gRPC email verification check failed", pkglogger.Fields{
+			"method":  fullMethod,
+			"user_id": identity.UserID,
+			"error":   err.Error(),
+		})
+		return status.Error(codes.Unavailable, "unable to verify email status")
+	}
+	if !verified {
+		pkglogger.Warn("gRPC call rejected, email not verified", pkglogger.Fields{
+			"method":  fullMethod,
+			"user_id": identity.UserID,
+			"role":    identity.Role,
+		})
+		return status.Error(codes.PermissionDenied, "please verify your email address before using this feature")
+	}
+	return nil
+}
+
+// authenticate 依序嘗試 JWT 與 API 金鑰，與 CombinedAuthMiddleware 的順序一致
+func (a *AuthInterceptor) authenticate(ctx context.Context) (*Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	authHeader := firstMetadataValue(md, authorizationMetadataKey)
+	if authHeader != "" {
+		tokenString, err := pkgjwt.ExtractTokenFromHeader(authHeader)
+		if err == nil {
+			claims, err := a.jwtManager.VerifyTokenContext(ctx, tokenString)
+			if errors.Is(err, pkgjwt.ErrRevocationCheckFailed) {
+				pkglogger.Error("gRPC token revocation check failed", pkglogger.Fields{
+					"error": err.Error(),
+				})
+				return nil, status.Error(codes.Unavailable, "unable to verify token")
+			}
+			if err == nil {
+				return &Identity{
+					UserID:      claims.UserID,
+					Username:    claims.Username,
+					Email:       claims.Email,
+					Role:        claims.Role,
+					AuthMethod:  AuthMethodJWT,
+					MFAVerified: claims.MFA,
+				}, nil
+			}
+		}
+	}
+
+	rawKey := firstMetadataValue(md, apiKeyMetadataKey)
+	if rawKey != "" && a.apiKeys != nil {
+		apiKey, err := a.apiKeys.Authenticate(ctx, rawKey)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil, status.FromContextError(err).Err()
+			}
+			if errors.Is(err, dto.ErrAPIKeyQuotaExceeded) {
+				return nil, status.Error(codes.ResourceExhausted, "API key quota exceeded")
+			}
+			if !isAPIKeyRejection(err) {
+				pkglogger.Error("gRPC API key authentication error", pkglogger.Fields{
+					"error": err.Error(),
+				})
+				return nil, status.Error(codes.Internal, "failed to authenticate API key")
+			}
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired API key")
+		}
+		apiKeyID := apiKey.ID
+		return &Identity{
+			UserID:     apiKey.User.ID,
+			Username:   apiKey.User.Username,
+			Email:      apiKey.User.Email,
+			Role:       string(apiKey.User.Role),
+			AuthMethod: AuthMethodAPIKey,
+			APIKeyID:   &apiKeyID,
+		}, nil
+	}
+
+	if authHeader != "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+	return nil, status.Error(codes.Unauthenticated, "JWT token or API key is required")
+}
+
+// auditCall 記錄已認證呼叫的審計日誌；公開服務不記錄
+func auditCall(ctx context.Context, fullMethod string, err error) {
+	identity, ok := IdentityFromContext(ctx)
+	if !ok {
+		return
+	}
+	fields := pkglogger.Fields{
+		"method":      fullMethod,
+		"user_id":     identity.UserID,
+		"username":    identity.Username,
+		"role":        identity.Role,
+		"auth_method": identity.AuthMethod,
+		"code":        status.Code(err).String(),
+	}
+	if identity.APIKeyID != nil {
+		fields["api_key_id"] = *identity.APIKeyID
+	}
+	pkglogger.Info("gRPC audit", fields)
+}
+
+// authenticatedStream 以帶有身分的 context 包裝伺服器串流
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 回傳帶有身分的 context
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// isPublicMethod 檢查方法是否屬於公開服務
+func isPublicMethod(fullMethod string) bool {
+	for _, service := range publicServices {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isAPIKeyRejection 檢查是否為金鑰本身無效（而非系統錯誤）
+func isAPIKeyRejection(err error) bool {
+	return errors.Is(err, dto.ErrInvalidAPIKey) ||
+		errors.Is(err, dto.ErrAPIKeyInactive) ||
+		errors.Is(err, dto.ErrAPIKeyExpired) ||
+		errors.Is(err, dto.ErrUserInactive)
+}
+
+// firstMetadataValue 取得 metadata 中第一個非空白值
+func firstMetadataValue(md metadata.MD, key string) string {
+	for _, value := range md.Get(key) {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
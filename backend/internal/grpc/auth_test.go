@@ -0,0 +1,215 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	proto "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/api/proto/api/proto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	pkgjwt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/jwt"
+)
+
+// stubAPIKeys 僅接受固定金鑰
+type stubAPIKeys struct {
+	key    string
+	apiKey *model.APIKey
+}
+
+func (s stubAPIKeys) Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error) {
+	if rawKey == "exhausted-key" {
+		return nil, dto.ErrAPIKeyQuotaExceeded
+	}
+	if rawKey != s.key {
+		return nil, dto.ErrInvalidAPIKey
+	}
+	return s.apiKey, nil
+}
+
+// builtinPermissions 以內建角色的預設權限授權
+type builtinPermissions struct{}
+
+func (builtinPermissions) HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error) {
+	for _, builtin := range model.BuiltinRoles() {
+		if builtin.Name != role {
+			continue
+		}
+		for _, permission := range permissions {
+			granted := false
+			for _, p := range builtin.Permissions {
+				granted = granted || p == permission
+			}
+			if !granted {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	jwtManager := pkgjwt.NewJWTManager("test-secret", "test", 1)
+	apiKeys := stubAPIKeys{
+		key: "valid-key",
+		apiKey: &model.APIKey{
+			ID:   uuid.New(),
+			User: model.User{ID: uuid.New(), Username: "svc", Role: model.RoleBasic},
+		},
+	}
+
+	auth := NewAuthInterceptor(jwtManager, apiKeys, builtinPermissions{}, nil, nil)
+	server := NewServer(ServerConfig{Port: "0"}, stubThreatService{}, nil, auth.ServerOptions()...)
+	require.NoError(t, server.Start())
+	defer server.Stop(context.Background())
+
+	conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err, "health check must not require credentials")
+
+	adminToken, err := jwtManager.GenerateToken(uuid.New(), "admin", "admin@example.com", string(model.RoleAdmin))
+	require.NoError(t, err)
+	guestToken, err := jwtManager.GenerateToken(uuid.New(), "guest", "guest@example.com", "guest")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		md   metadata.MD
+		want codes.Code
+	}{
+		{name: "missing credentials", md: metadata.MD{}, want: codes.Unauthenticated},
+		{name: "invalid token", md: metadata.Pairs("authorization", "Bearer invalid"), want: codes.Unauthenticated},
+		{name: "invalid api key", md: metadata.Pairs("x-api-key", "wrong"), want: codes.Unauthenticated},
+		{name: "api key quota exceeded", md: metadata.Pairs("x-api-key", "exhausted-key"), want: codes.ResourceExhausted},
+		{name: "unknown role", md: metadata.Pairs("authorization", "Bearer "+guestToken), want: codes.PermissionDenied},
+		// 通過認證後由服務驗證參數，回傳 InvalidArgument
+		{name: "valid token", md: metadata.Pairs("authorization", "Bearer "+adminToken), want: codes.InvalidArgument},
+		{name: "valid api key", md: metadata.Pairs("x-api-key", "valid-key"), want: codes.InvalidArgument},
+		{name: "invalid token falls back to api key", md: metadata.Pairs("authorization", "Bearer invalid", "x-api-key", "valid-key"), want: codes.InvalidArgument},
+	}
+
+	client := proto.NewThreatIntelligenceServiceClient(conn)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetThreatIntelligence(metadata.NewOutgoingContext(ctx, tt.md), &proto.GetThreatIntelligenceRequest{Id: "not-a-uuid"})
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
+
+func TestAuthInterceptor_InjectsIdentity(t *testing.T) {
+	jwtManager := pkgjwt.NewJWTManager("test-secret", "test", 1)
+	auth := NewAuthInterceptor(jwtManager, nil, builtinPermissions{}, nil, nil)
+
+	userID := uuid.New()
+	token, err := jwtManager.GenerateToken(userID, "analyst", "analyst@example.com", string(model.RolePremium))
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	info := &grpc.UnaryServerInfo{FullMethod: proto.ThreatIntelligenceService_GetThreatIntelligence_FullMethodName}
+
+	var identity *Identity
+	_, err = auth.Unary()(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, _ = IdentityFromContext(ctx)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.NotNil(t, identity)
+	assert.Equal(t, userID, identity.UserID)
+	assert.Equal(t, string(model.RolePremium), identity.Role)
+	assert.Equal(t, AuthMethodJWT, identity.AuthMethod)
+}
+
+func TestAuthInterceptor_RequireMFA(t *testing.T) {
+	jwtManager := pkgjwt.NewJWTManager("test-secret", "test", 1)
+	auth := NewAuthInterceptor(jwtManager, nil, builtinPermissions{}, nil, nil).RequireMFA(string(model.RoleAdmin))
+	info := &grpc.UnaryServerInfo{FullMethod: proto.ThreatIntelligenceService_GetThreatIntelligence_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	call := func(role string, mfa bool) error {
+		token, err := jwtManager.GenerateTokenWithMFA(uuid.New(), "user", "user@example.com", role, mfa)
+		require.NoError(t, err)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		_, err = auth.Unary()(ctx, nil, info, handler)
+		return err
+	}
+
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(string(model.RoleAdmin), false)))
+	assert.NoError(t, call(string(model.RoleAdmin), true))
+	assert.NoError(t, call(string(model.RolePremium), false))
+}
+
+func TestAuthInterceptor_MethodPermissions(t *testing.T) {
+	jwtManager := pkgjwt.NewJWTManager("test-secret", "test", 1)
+	auth := NewAuthInterceptor(jwtManager, nil, builtinPermissions{}, nil, nil)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	call := func(role, method string) error {
+		token, err := jwtManager.GenerateToken(uuid.New(), "user", "user@example.com", role)
+		require.NoError(t, err)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		_, err = auth.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	deleteMethod := proto.ThreatIntelligenceService_DeleteThreatIntelligence_FullMethodName
+	assert.NoError(t, call(string(model.RoleBasic), proto.ThreatIntelligenceService_CreateThreatIntelligence_FullMethodName))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(string(model.RoleBasic), deleteMethod)))
+	assert.NoError(t, call(string(model.RolePremium), deleteMethod))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(string(model.RoleAdmin), "/api.proto.ThreatIntelligenceService/Unknown")))
+}
+
+// stubEmailVerification 只有 verified 中的使用者已驗證信箱
+type stubEmailVerification map[uuid.UUID]bool
+
+func (s stubEmailVerification) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return s[userID], nil
+}
+
+func TestAuthInterceptor_RequireVerifiedEmail(t *testing.T) {
+	jwtManager := pkgjwt.NewJWTManager("test-secret", "test", 1)
+	verifiedID, unverifiedID := uuid.New(), uuid.New()
+	policy := map[string]string{string(model.RoleBasic): "write", string(model.RolePremium): "all"}
+	auth := NewAuthInterceptor(jwtManager, nil, builtinPermissions{}, stubEmailVerification{verifiedID: true}, policy)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	call := func(userID uuid.UUID, role, method string) error {
+		token, err := jwtManager.GenerateToken(userID, "user", "user@example.com", role)
+		require.NoError(t, err)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		_, err = auth.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	read := proto.ThreatIntelligenceService_GetThreatIntelligence_FullMethodName
+	write := proto.ThreatIntelligenceService_CreateThreatIntelligence_FullMethodName
+
+	// write 範圍：未驗證者只能讀取
+	assert.NoError(t, call(unverifiedID, string(model.RoleBasic), read))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(unverifiedID, string(model.RoleBasic), write)))
+	assert.NoError(t, call(verifiedID, string(model.RoleBasic), write))
+
+	// all 範圍：未驗證者不能呼叫任何方法
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(unverifiedID, string(model.RolePremium), read)))
+	assert.NoError(t, call(verifiedID, string(model.RolePremium), read))
+
+	// 未列入政策的角色不受限制
+	assert.NoError(t, call(unverifiedID, string(model.RoleAdmin), write))
+}
@@ -0,0 +1,159 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	proto "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/api/proto/api/proto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// ServerState gRPC 伺服器狀態
+type ServerState string
+
+const (
+	StateStopped  ServerState = "stopped"
+	StateRunning  ServerState = "running"
+	StateStopping ServerState = "stopping"
+	StateFailed   ServerState = "failed"
+)
+
+// ServerConfig gRPC 伺服器配置
+type ServerConfig struct {
+	Port       string
+	Reflection bool // 啟用 server reflection，供 grpcurl 等工具探索服務
+}
+
+// Server 包裝 gRPC 伺服器，提供健康檢查與可查詢的執行狀態
+type Server struct {
+	config ServerConfig
+	server *grpc.Server
+	health *health.Server
+
+	mu       sync.RWMutex
+	state    ServerState
+	listener net.Listener
+	lastErr  error
+	done     chan struct{}
+}
+
+// NewServer 建立 gRPC 伺服器並註冊威脅情報服務、健康檢查與 reflection
+func NewServer(cfg ServerConfig, threatService service.ThreatIntelligenceService, events ThreatSubscriber, opts ...grpc.ServerOption) *Server {
+	server := grpc.NewServer(opts...)
+	healthServer := health.NewServer()
+
+	proto.RegisterThreatIntelligenceServiceServer(server, NewThreatIntelligenceGRPCServer(threatService, events))
+	healthpb.RegisterHealthServer(server, healthServer)
+	if cfg.Reflection {
+		reflection.Register(server)
+	}
+
+	// 啟動前回報 NOT_SERVING，待開始接受連線後才切換
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus(proto.ThreatIntelligenceService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return &Server{
+		config: cfg,
+		server: server,
+		health: healthServer,
+		state:  StateStopped,
+	}
+}
+
+// Start 監聽埠號並於背景開始服務；監聽失敗會直接回傳錯誤
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", ":"+s.config.Port)
+	if err != nil {
+		s.setState(StateFailed, err)
+		return fmt.Errorf("failed to listen on gRPC port %s: %w", s.config.Port, err)
+	}
+
+	s.mu.Lock()
+	s.listener = listener
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	s.setState(StateRunning, nil)
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(proto.ThreatIntelligenceService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	go func() {
+		defer close(s.done)
+		if err := s.server.Serve(listener); err != nil {
+			pkglogger.Error("gRPC伺服器執行失敗", pkglogger.Fields{
+				"error": err.Error(),
+				"port":  s.config.Port,
+			})
+			s.health.Shutdown()
+			s.setState(StateFailed, err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop 優雅關閉：先回報 NOT_SERVING，等待進行中的 RPC 完成；逾時則強制中斷連線
+func (s *Server) Stop(ctx context.Context) {
+	s.mu.RLock()
+	done := s.done
+	s.mu.RUnlock()
+	if done == nil {
+		return
+	}
+
+	s.setState(StateStopping, nil)
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		pkglogger.Warn("gRPC伺服器優雅關閉逾時，強制關閉", pkglogger.Fields{
+			"error": ctx.Err().Error(),
+		})
+		s.server.Stop()
+		<-stopped
+	}
+	<-done
+	s.setState(StateStopped, nil)
+}
+
+// State 取得目前狀態與最後一次錯誤
+func (s *Server) State() (ServerState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state, s.lastErr
+}
+
+// Addr 取得實際監聽位址，尚未啟動時為 nil
+func (s *Server) Addr() net.Addr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// setState 更新狀態
+func (s *Server) setState(state ServerState, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	if err != nil {
+		s.lastErr = err
+	}
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	proto "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/api/proto/api/proto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+)
+
+// stubThreatService 未實作的方法被呼叫時會 panic
+type stubThreatService struct {
+	service.ThreatIntelligenceService
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	server := NewServer(ServerConfig{Port: "0", Reflection: true}, stubThreatService{}, nil)
+	require.NoError(t, server.Start())
+
+	state, err := server.State()
+	assert.Equal(t, StateRunning, state)
+	assert.NoError(t, err)
+
+	conn, err := grpc.NewClient(server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: proto.ThreatIntelligenceService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.Status)
+
+	_, err = proto.NewThreatIntelligenceServiceClient(conn).GetThreatIntelligence(ctx, &proto.GetThreatIntelligenceRequest{Id: "not-a-uuid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	server.Stop(ctx)
+	state, _ = server.State()
+	assert.Equal(t, StateStopped, state)
+}
+
+func TestServer_StartFailsWhenPortInUse(t *testing.T) {
+	first := NewServer(ServerConfig{Port: "0"}, stubThreatService{}, nil)
+	require.NoError(t, first.Start())
+	defer first.Stop(context.Background())
+
+	_, port, err := net.SplitHostPort(first.Addr().String())
+	require.NoError(t, err)
+	second := NewServer(ServerConfig{Port: port}, stubThreatService{}, nil)
+	require.Error(t, second.Start())
+
+	state, err := second.State()
+	assert.Equal(t, StateFailed, state)
+	assert.Error(t, err)
+}
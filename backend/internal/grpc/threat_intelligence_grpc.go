@@ -2,296 +2,197 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
 
 	proto "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/api/proto/api/proto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dsl"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/event"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
 	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
 )
 
+// statisticsPeriods 統計期間對應的每日統計天數
+var statisticsPeriods = map[string]int{
+	"daily":   1,
+	"weekly":  7,
+	"monthly": 30,
+}
+
+// resumeCursorMetadataKey 訂閱續傳游標的 metadata 鍵
+const resumeCursorMetadataKey = "last-event-id"
+
+// ThreatSubscriber 訂閱威脅異動事件
+type ThreatSubscriber interface {
+	Subscribe(filter event.Filter, cursor string) (*event.Subscription, error)
+}
+
 // ThreatIntelligenceGRPCServer gRPC服務器實作
 type ThreatIntelligenceGRPCServer struct {
 	proto.UnimplementedThreatIntelligenceServiceServer
 	threatService service.ThreatIntelligenceService
+	events        ThreatSubscriber
 }
 
-// NewThreatIntelligenceGRPCServer 建立gRPC服務器
-func NewThreatIntelligenceGRPCServer(threatService service.ThreatIntelligenceService) *ThreatIntelligenceGRPCServer {
+// NewThreatIntelligenceGRPCServer 建立gRPC服務器；events 為 nil 時不提供即時訂閱
+func NewThreatIntelligenceGRPCServer(threatService service.ThreatIntelligenceService, events ThreatSubscriber) *ThreatIntelligenceGRPCServer {
 	return &ThreatIntelligenceGRPCServer{
 		threatService: threatService,
+		events:        events,
 	}
 }
 
 // GetThreatIntelligence 取得威脅情報
 func (s *ThreatIntelligenceGRPCServer) GetThreatIntelligence(ctx context.Context, req *proto.GetThreatIntelligenceRequest) (*proto.GetThreatIntelligenceResponse, error) {
-	// 解析UUID
 	threatID, err := uuid.Parse(req.Id)
 	if err != nil {
-		return &proto.GetThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Invalid threat ID format",
-			ErrorCode: "INVALID_ID",
-		}, status.Error(codes.InvalidArgument, "Invalid threat ID format")
+		return nil, status.Error(codes.InvalidArgument, "Invalid threat ID format")
 	}
 
-	// 呼叫服務
-	threat, err := s.threatService.GetByID(threatID)
+	threat, err := s.threatService.GetThreatByID(ctx, threatID)
 	if err != nil {
-		pkglogger.Error("Failed to get threat intelligence", pkglogger.Fields{
-			"error":      err.Error(),
-			"threat_id":  req.Id,
-		})
-
-		if err == dto.ErrThreatNotFound {
-			return &proto.GetThreatIntelligenceResponse{
-				Success:   false,
-				Message:   "Threat intelligence not found",
-				ErrorCode: "NOT_FOUND",
-			}, status.Error(codes.NotFound, "Threat intelligence not found")
-		}
-
-		return &proto.GetThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Failed to get threat intelligence",
-			ErrorCode: "INTERNAL_ERROR",
-		}, status.Error(codes.Internal, "Internal server error")
+		return nil, toStatusError(err, "Failed to get threat intelligence", pkglogger.Fields{"threat_id": req.Id})
 	}
 
-	// 轉換為proto格式
-	protoThreat := convertThreatToProto(threat)
-
 	return &proto.GetThreatIntelligenceResponse{
 		Success: true,
 		Message: "Threat intelligence retrieved successfully",
-		Data:    protoThreat,
+		Data:    convertThreatToProto(threat),
 	}, nil
 }
 
 // ListThreatIntelligence 列出威脅情報
+// is_active 為 true 時只回傳最近仍有活動的威脅
 func (s *ThreatIntelligenceGRPCServer) ListThreatIntelligence(ctx context.Context, req *proto.ListThreatIntelligenceRequest) (*proto.ListThreatIntelligenceResponse, error) {
-	// 轉換請求參數
-	filter := dto.ThreatIntelligenceFilter{
+	queryReq := &dto.ThreatIntelligenceQueryRequest{
+		ThreatType: optionalString(req.ThreatType),
+		Severity:   optionalString(req.Severity),
+		Source:     optionalString(req.Source),
 		Page:       int(req.Page),
 		PageSize:   int(req.PageSize),
-		ThreatType: req.ThreatType,
-		Severity:   req.Severity,
-		Source:     req.Source,
 		SortBy:     req.SortBy,
 		SortOrder:  req.SortOrder,
-		IsActive:   &req.IsActive,
 	}
-
-	// 設定預設值
-	filter.SetDefaults()
-
-	// 呼叫服務
-	result, err := s.threatService.List(&filter)
-	if err != nil {
-		pkglogger.Error("Failed to list threat intelligence", pkglogger.Fields{
-			"error": err.Error(),
-		})
-
-		return &proto.ListThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Failed to list threat intelligence",
-			ErrorCode: "INTERNAL_ERROR",
-		}, status.Error(codes.Internal, "Internal server error")
+	if req.IsActive {
+		queryReq.Query = fmt.Sprintf("last_seen:>=now-%dh", int(repository.ActiveThreatWindow.Hours()))
+	}
+	if err := validateRequest(queryReq); err != nil {
+		return nil, err
 	}
 
-	// 轉換為proto格式
-	var protoThreats []*proto.ThreatIntelligence
-	for _, threat := range result.Threats {
-		protoThreats = append(protoThreats, convertThreatToProto(&threat))
+	result, err := s.threatService.ListThreats(ctx, queryReq)
+	if err != nil {
+		return nil, toStatusError(err, "Failed to list threat intelligence", nil)
 	}
 
-	pagination := &proto.Pagination{
-		CurrentPage:  int32(result.Pagination.CurrentPage),
-		PageSize:     int32(result.Pagination.PageSize),
-		TotalPages:   int32(result.Pagination.TotalPages),
-		TotalRecords: result.Pagination.TotalRecords,
-		HasNext:      result.Pagination.HasNext,
-		HasPrevious:  result.Pagination.HasPrevious,
+	threats := make([]*proto.ThreatIntelligence, len(result.Data))
+	for i := range result.Data {
+		threats[i] = convertThreatToProto(&result.Data[i])
 	}
 
 	return &proto.ListThreatIntelligenceResponse{
 		Success:    true,
 		Message:    "Threat intelligence list retrieved successfully",
-		Threats:    protoThreats,
-		Pagination: pagination,
+		Threats:    threats,
+		Pagination: convertPaginationToProto(result.Pagination),
 	}, nil
 }
 
 // CreateThreatIntelligence 建立威脅情報
+// 指標依序取自 ip_address、url、file_hash、domain；first_seen 與 last_seen 由伺服器決定
 func (s *ThreatIntelligenceGRPCServer) CreateThreatIntelligence(ctx context.Context, req *proto.CreateThreatIntelligenceRequest) (*proto.CreateThreatIntelligenceResponse, error) {
-	// 轉換請求為DTO
-	createReq := &dto.CreateThreatIntelligenceRequest{
+	createReq := &dto.ThreatIntelligenceCreateRequest{
 		IPAddress:       req.IpAddress,
-		Domain:          req.Domain,
-		URL:             req.Url,
-		FileHash:        req.FileHash,
+		Domain:          optionalString(req.Domain),
 		ThreatType:      req.ThreatType,
 		Severity:        req.Severity,
-		Description:     req.Description,
-		Tags:            req.Tags,
 		ConfidenceScore: int(req.ConfidenceScore),
+		Description:     optionalString(req.Description),
 		Source:          req.Source,
+		Tags:            req.Tags,
+		Metadata:        convertMetadataFromProto(req.Metadata),
+	}
+	if req.IpAddress == "" {
+		switch {
+		case req.Url != "":
+			createReq.IndicatorType = "url"
+			createReq.IndicatorValue = req.Url
+		case req.FileHash != "":
+			createReq.IndicatorValue = req.FileHash
+		}
 	}
-
-	// 轉換時間戳
-	if req.FirstSeen != nil {
-		firstSeen := req.FirstSeen.AsTime()
-		createReq.FirstSeen = &firstSeen
-	}
-	if req.LastSeen != nil {
-		lastSeen := req.LastSeen.AsTime()
-		createReq.LastSeen = &lastSeen
-	}
-
-	// 轉換metadata
-	if req.Metadata != nil {
-		createReq.Metadata = req.Metadata
+	if err := validateRequest(createReq); err != nil {
+		return nil, err
 	}
 
-	// 呼叫服務
-	threat, err := s.threatService.Create(createReq)
+	threat, err := s.threatService.CreateThreat(ctx, createReq)
 	if err != nil {
-		pkglogger.Error("Failed to create threat intelligence", pkglogger.Fields{
-			"error": err.Error(),
-		})
-
-		return &proto.CreateThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Failed to create threat intelligence",
-			ErrorCode: "INTERNAL_ERROR",
-		}, status.Error(codes.Internal, "Internal server error")
+		return nil, toStatusError(err, "Failed to create threat intelligence", nil)
 	}
 
-	// 轉換為proto格式
-	protoThreat := convertThreatToProto(threat)
-
 	return &proto.CreateThreatIntelligenceResponse{
 		Success: true,
 		Message: "Threat intelligence created successfully",
-		Data:    protoThreat,
+		Data:    convertThreatToProto(threat),
 	}, nil
 }
 
 // UpdateThreatIntelligence 更新威脅情報
+// proto3 無法區分未設定與零值，因此只更新非空欄位；指標與來源構成自然鍵，不可變更
 func (s *ThreatIntelligenceGRPCServer) UpdateThreatIntelligence(ctx context.Context, req *proto.UpdateThreatIntelligenceRequest) (*proto.UpdateThreatIntelligenceResponse, error) {
-	// 解析UUID
 	threatID, err := uuid.Parse(req.Id)
 	if err != nil {
-		return &proto.UpdateThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Invalid threat ID format",
-			ErrorCode: "INVALID_ID",
-		}, status.Error(codes.InvalidArgument, "Invalid threat ID format")
-	}
-
-	// 轉換請求為DTO
-	updateReq := &dto.UpdateThreatIntelligenceRequest{
-		IPAddress:       &req.IpAddress,
-		Domain:          &req.Domain,
-		URL:             &req.Url,
-		FileHash:        &req.FileHash,
-		ThreatType:      &req.ThreatType,
-		Severity:        &req.Severity,
-		Description:     &req.Description,
-		Tags:            req.Tags,
-		ConfidenceScore: func() *int { cs := int(req.ConfidenceScore); return &cs }(),
-		Source:          &req.Source,
-		IsActive:        &req.IsActive,
+		return nil, status.Error(codes.InvalidArgument, "Invalid threat ID format")
 	}
 
-	// 轉換時間戳
-	if req.FirstSeen != nil {
-		firstSeen := req.FirstSeen.AsTime()
-		updateReq.FirstSeen = &firstSeen
+	updateReq := &dto.ThreatIntelligenceUpdateRequest{
+		Domain:      optionalString(req.Domain),
+		ThreatType:  optionalString(req.ThreatType),
+		Severity:    optionalString(req.Severity),
+		Description: optionalString(req.Description),
+		Tags:        req.Tags,
+		Metadata:    convertMetadataFromProto(req.Metadata),
 	}
-	if req.LastSeen != nil {
-		lastSeen := req.LastSeen.AsTime()
-		updateReq.LastSeen = &lastSeen
-	}
-
-	// 轉換metadata
-	if req.Metadata != nil {
-		updateReq.Metadata = req.Metadata
+	if req.ConfidenceScore != 0 {
+		score := int(req.ConfidenceScore)
+		updateReq.ConfidenceScore = &score
 	}
 
-	// 呼叫服務
-	threat, err := s.threatService.Update(threatID, updateReq)
+	threat, err := s.threatService.UpdateThreat(ctx, threatID, updateReq)
 	if err != nil {
-		pkglogger.Error("Failed to update threat intelligence", pkglogger.Fields{
-			"error":     err.Error(),
-			"threat_id": req.Id,
-		})
-
-		if err == dto.ErrThreatNotFound {
-			return &proto.UpdateThreatIntelligenceResponse{
-				Success:   false,
-				Message:   "Threat intelligence not found",
-				ErrorCode: "NOT_FOUND",
-			}, status.Error(codes.NotFound, "Threat intelligence not found")
-		}
-
-		return &proto.UpdateThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Failed to update threat intelligence",
-			ErrorCode: "INTERNAL_ERROR",
-		}, status.Error(codes.Internal, "Internal server error")
+		return nil, toStatusError(err, "Failed to update threat intelligence", pkglogger.Fields{"threat_id": req.Id})
 	}
 
-	// 轉換為proto格式
-	protoThreat := convertThreatToProto(threat)
-
 	return &proto.UpdateThreatIntelligenceResponse{
 		Success: true,
 		Message: "Threat intelligence updated successfully",
-		Data:    protoThreat,
+		Data:    convertThreatToProto(threat),
 	}, nil
 }
 
 // DeleteThreatIntelligence 刪除威脅情報
 func (s *ThreatIntelligenceGRPCServer) DeleteThreatIntelligence(ctx context.Context, req *proto.DeleteThreatIntelligenceRequest) (*proto.DeleteThreatIntelligenceResponse, error) {
-	// 解析UUID
 	threatID, err := uuid.Parse(req.Id)
 	if err != nil {
-		return &proto.DeleteThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Invalid threat ID format",
-			ErrorCode: "INVALID_ID",
-		}, status.Error(codes.InvalidArgument, "Invalid threat ID format")
+		return nil, status.Error(codes.InvalidArgument, "Invalid threat ID format")
 	}
 
-	// 呼叫服務
-	err = s.threatService.Delete(threatID)
-	if err != nil {
-		pkglogger.Error("Failed to delete threat intelligence", pkglogger.Fields{
-			"error":     err.Error(),
-			"threat_id": req.Id,
-		})
-
-		if err == dto.ErrThreatNotFound {
-			return &proto.DeleteThreatIntelligenceResponse{
-				Success:   false,
-				Message:   "Threat intelligence not found",
-				ErrorCode: "NOT_FOUND",
-			}, status.Error(codes.NotFound, "Threat intelligence not found")
-		}
-
-		return &proto.DeleteThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Failed to delete threat intelligence",
-			ErrorCode: "INTERNAL_ERROR",
-		}, status.Error(codes.Internal, "Internal server error")
+	if err := s.threatService.DeleteThreat(ctx, threatID); err != nil {
+		return nil, toStatusError(err, "Failed to delete threat intelligence", pkglogger.Fields{"threat_id": req.Id})
 	}
 
 	return &proto.DeleteThreatIntelligenceResponse{
@@ -302,96 +203,93 @@ func (s *ThreatIntelligenceGRPCServer) DeleteThreatIntelligence(ctx context.Cont
 
 // SearchThreatIntelligence 搜尋威脅情報
 func (s *ThreatIntelligenceGRPCServer) SearchThreatIntelligence(ctx context.Context, req *proto.SearchThreatIntelligenceRequest) (*proto.SearchThreatIntelligenceResponse, error) {
-	// 轉換請求參數
-	searchReq := &dto.SearchThreatIntelligenceRequest{
-		Query:       req.Query,
-		IPAddress:   req.IpAddress,
-		Domain:      req.Domain,
-		ThreatType:  req.ThreatType,
-		Severity:    req.Severity,
-		Page:        int(req.Page),
-		PageSize:    int(req.PageSize),
-		SortBy:      req.SortBy,
-		SortOrder:   req.SortOrder,
-	}
-
-	// 設定預設值
-	searchReq.SetDefaults()
-
-	// 呼叫服務
-	result, err := s.threatService.Search(searchReq)
-	if err != nil {
-		pkglogger.Error("Failed to search threat intelligence", pkglogger.Fields{
-			"error": err.Error(),
-			"query": req.Query,
-		})
-
-		return &proto.SearchThreatIntelligenceResponse{
-			Success:   false,
-			Message:   "Failed to search threat intelligence",
-			ErrorCode: "INTERNAL_ERROR",
-		}, status.Error(codes.Internal, "Internal server error")
+	searchReq := &dto.ThreatIntelligenceSearchRequest{
+		Query:      req.Query,
+		IPAddress:  optionalString(req.IpAddress),
+		Domain:     optionalString(req.Domain),
+		ThreatType: optionalString(req.ThreatType),
+		Severity:   optionalString(req.Severity),
+		Page:       int(req.Page),
+		PageSize:   int(req.PageSize),
+		SortBy:     req.SortBy,
+		SortOrder:  req.SortOrder,
+	}
+	if err := validateRequest(searchReq); err != nil {
+		return nil, err
 	}
 
-	// 轉換為proto格式
-	var protoThreats []*proto.ThreatIntelligence
-	for _, threat := range result.Threats {
-		protoThreats = append(protoThreats, convertThreatToProto(&threat))
+	result, err := s.threatService.SearchThreats(ctx, searchReq)
+	if err != nil {
+		return nil, toStatusError(err, "Failed to search threat intelligence", pkglogger.Fields{"query": req.Query})
 	}
 
-	pagination := &proto.Pagination{
-		CurrentPage:  int32(result.Pagination.CurrentPage),
-		PageSize:     int32(result.Pagination.PageSize),
-		TotalPages:   int32(result.Pagination.TotalPages),
-		TotalRecords: result.Pagination.TotalRecords,
-		HasNext:      result.Pagination.HasNext,
-		HasPrevious:  result.Pagination.HasPrevious,
+	threats := make([]*proto.ThreatIntelligence, len(result.Data))
+	for i := range result.Data {
+		threats[i] = convertThreatToProto(&result.Data[i].ThreatIntelligenceVO)
 	}
 
 	return &proto.SearchThreatIntelligenceResponse{
 		Success:    true,
 		Message:    "Search completed successfully",
-		Threats:    protoThreats,
-		Pagination: pagination,
+		Threats:    threats,
+		Pagination: convertPaginationToProto(result.Pagination),
 	}, nil
 }
 
 // GetThreatStatistics 取得統計資訊
+// period 決定每日統計涵蓋的天數：daily、weekly（預設）或 monthly
 func (s *ThreatIntelligenceGRPCServer) GetThreatStatistics(ctx context.Context, req *proto.GetThreatStatisticsRequest) (*proto.GetThreatStatisticsResponse, error) {
-	// 呼叫服務取得統計資訊
-	stats, err := s.threatService.GetStatistics()
+	period := req.Period
+	if period == "" {
+		period = "weekly"
+	}
+	days, ok := statisticsPeriods[period]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid period %q, expected daily, weekly or monthly", req.Period)
+	}
+
+	stats, err := s.threatService.GetStatistics(ctx)
 	if err != nil {
-		pkglogger.Error("Failed to get threat statistics", pkglogger.Fields{
-			"error": err.Error(),
-		})
+		return nil, toStatusError(err, "Failed to get threat statistics", nil)
+	}
 
-		return &proto.GetThreatStatisticsResponse{
-			Success:   false,
-			Message:   "Failed to get threat statistics",
-			ErrorCode: "INTERNAL_ERROR",
-		}, status.Error(codes.Internal, "Internal server error")
+	end := time.Now().UTC()
+	start := dayStart(end).AddDate(0, 0, 1-days)
+	daily, err := s.threatService.GetStats(ctx, &dto.ThreatIntelligenceStatsRequest{
+		GroupBy:   "severity",
+		Interval:  "day",
+		StartTime: &start,
+		EndTime:   &end,
+	})
+	if err != nil {
+		return nil, toStatusError(err, "Failed to get threat statistics", nil)
 	}
 
-	// 轉換為proto格式
 	protoStats := &proto.ThreatStatistics{
-		TotalThreats:     stats.TotalThreats,
-		ActiveThreats:    stats.ActiveThreats,
-		HighRiskThreats:  stats.HighRiskThreats,
-		MediumRiskThreats: stats.MediumRiskThreats,
-		LowRiskThreats:   stats.LowRiskThreats,
-		ThreatTypes:      make(map[string]int64),
-		Sources:          make(map[string]int64),
+		TotalThreats:      int64(stats.TotalThreats),
+		ActiveThreats:     int64(stats.ActiveThreats),
+		HighRiskThreats:   int64(stats.HighRiskThreats),
+		MediumRiskThreats: int64(stats.MediumRiskThreats),
+		LowRiskThreats:    int64(stats.LowRiskThreats),
+		ThreatTypes:       make(map[string]int64, len(stats.ThreatTypeStats)),
+		Sources:           make(map[string]int64, len(stats.SourceStats)),
+		DailyStats:        make([]*proto.DailyStats, len(daily.Timeline)),
 	}
-
-	// 轉換威脅類型統計
 	for _, stat := range stats.ThreatTypeStats {
 		protoStats.ThreatTypes[stat.Label] = int64(stat.Value)
 	}
-
-	// 轉換來源統計
 	for _, stat := range stats.SourceStats {
 		protoStats.Sources[stat.Label] = int64(stat.Value)
 	}
+	for i, point := range daily.Timeline {
+		protoStats.DailyStats[i] = &proto.DailyStats{
+			Date:       point.Date,
+			Count:      int64(point.Count),
+			HighRisk:   int64(point.Breakdown["high"] + point.Breakdown["critical"]),
+			MediumRisk: int64(point.Breakdown["medium"]),
+			LowRisk:    int64(point.Breakdown["low"]),
+		}
+	}
 
 	return &proto.GetThreatStatisticsResponse{
 		Success: true,
@@ -401,59 +299,151 @@ func (s *ThreatIntelligenceGRPCServer) GetThreatStatistics(ctx context.Context,
 }
 
 // SubscribeThreats 即時威脅訂閱
+// 每則通知的 notification_id 即為續傳游標；重新連線時將最後收到的 id 放在 last-event-id metadata，
+// 即可補收斷線期間的事件。處理過慢的訂閱會以 ResourceExhausted 中斷，客戶端可帶游標重連
 func (s *ThreatIntelligenceGRPCServer) SubscribeThreats(req *proto.SubscribeThreatsRequest, stream proto.ThreatIntelligenceService_SubscribeThreatsServer) error {
-	// TODO: 實作即時威脅訂閱功能
-	// 這需要與MQTT或其他訊息佇列系統整合
+	if s.events == nil {
+		return status.Error(codes.Unavailable, "Threat subscription is not available")
+	}
+	if req.MinConfidenceScore < 0 || req.MinConfidenceScore > 100 {
+		return status.Error(codes.InvalidArgument, dto.ErrInvalidConfidence.Error())
+	}
+
+	ctx := stream.Context()
+	cursor := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(resumeCursorMetadataKey); len(values) > 0 {
+			cursor = values[0]
+		}
+	}
+
+	sub, err := s.events.Subscribe(event.Filter{
+		ThreatTypes:        req.ThreatTypes,
+		Severities:         req.Severities,
+		MinConfidenceScore: int(req.MinConfidenceScore),
+	}, cursor)
+	if err != nil {
+		return subscriptionStatusError(err)
+	}
+	defer sub.Close()
 
-	pkglogger.Info("Threat subscription started", pkglogger.Fields{
+	fields := pkglogger.Fields{
 		"threat_types":         req.ThreatTypes,
-		"severities":          req.Severities,
+		"severities":           req.Severities,
 		"min_confidence_score": req.MinConfidenceScore,
-	})
+		"cursor":               cursor,
+	}
+	if identity, ok := IdentityFromContext(ctx); ok {
+		fields["user_id"] = identity.UserID
+	}
+	pkglogger.Info("Threat subscription started", fields)
 
-	// 模擬訂閱，實際實作中應該連接到訊息佇列
 	for {
-		select {
-		case <-stream.Context().Done():
-			pkglogger.Info("Threat subscription ended")
-			return nil
-		case <-time.After(30 * time.Second):
-			// 發送心跳通知
-			notification := &proto.ThreatNotification{
-				NotificationId: uuid.New().String(),
-				Type:          "heartbeat",
-				Timestamp:     timestamppb.Now(),
-				Metadata: map[string]string{
-					"message": "Connection alive",
-				},
+		evt, err := sub.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				pkglogger.Info("Threat subscription ended", fields)
+				return nil
 			}
+			pkglogger.Warn("Threat subscription terminated", pkglogger.Fields{
+				"error":  err.Error(),
+				"cursor": cursor,
+			})
+			return subscriptionStatusError(err)
+		}
 
-			if err := stream.Send(notification); err != nil {
-				pkglogger.Error("Failed to send threat notification", pkglogger.Fields{
-					"error": err.Error(),
-				})
-				return err
-			}
+		notification := &proto.ThreatNotification{
+			NotificationId: evt.Cursor,
+			Type:           string(evt.Type),
+			Threat:         convertThreatToProto(&evt.Threat),
+			Timestamp:      timestamppb.New(evt.OccurredAt),
+		}
+		if err := stream.Send(notification); err != nil {
+			pkglogger.Error("Failed to send threat notification", pkglogger.Fields{
+				"error":  err.Error(),
+				"cursor": evt.Cursor,
+			})
+			return err
 		}
+		cursor = evt.Cursor
 	}
 }
 
+// subscriptionStatusError 將訂閱錯誤轉換為 gRPC 狀態
+func subscriptionStatusError(err error) error {
+	switch {
+	case errors.Is(err, event.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, event.ErrCursorExpired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, event.ErrSlowConsumer):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, event.ErrBusClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, "Internal server error")
+}
+
+// validateRequest 以與 HTTP 相同的 binding 規則驗證請求
+func validateRequest(req interface{}) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// toStatusError 將服務錯誤轉換為 gRPC 狀態，非預期錯誤會記錄並隱藏細節
+func toStatusError(err error, message string, fields pkglogger.Fields) error {
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.Is(err, dto.ErrThreatNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Error(codes.NotFound, "Threat intelligence not found")
+	case errors.As(err, &validationErrs), errors.Is(err, dsl.ErrSyntax), isInvalidArgument(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	logFields := pkglogger.Fields{"error": err.Error()}
+	for k, v := range fields {
+		logFields[k] = v
+	}
+	pkglogger.Error(message, logFields)
+	return status.Error(codes.Internal, "Internal server error")
+}
+
+// isInvalidArgument 檢查是否為請求內容錯誤
+func isInvalidArgument(err error) bool {
+	for _, target := range []error{
+		dto.ErrInvalidIPAddress, dto.ErrInvalidDomain, dto.ErrInvalidIndicator, dto.ErrInvalidIndicatorType,
+		dto.ErrInvalidSearchQuery, dto.ErrInvalidThreatType, dto.ErrInvalidSeverity, dto.ErrInvalidConfidence,
+		dto.ErrInvalidCountryCode, dto.ErrInvalidASN, dto.ErrInvalidDateRange,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // convertThreatToProto 轉換威脅情報到proto格式
 func convertThreatToProto(threat *vo.ThreatIntelligenceVO) *proto.ThreatIntelligence {
 	protoThreat := &proto.ThreatIntelligence{
 		Id:              threat.ID.String(),
 		IpAddress:       threat.IPAddress,
-		Domain:          threat.Domain,
-		Url:             threat.URL,
-		FileHash:        threat.FileHash,
+		Domain:          stringValue(threat.Domain),
+		Url:             stringValue(threat.URL),
+		FileHash:        stringValue(threat.FileHash),
 		ThreatType:      threat.ThreatType,
 		Severity:        threat.Severity,
-		Description:     threat.Description,
+		Description:     stringValue(threat.Description),
 		Tags:            threat.Tags,
 		ConfidenceScore: int32(threat.ConfidenceScore),
 		Source:          threat.Source,
-		IsActive:        threat.IsActive,
-		RiskScore:       threat.RiskScore,
+		IsActive:        threat.LastSeen.After(time.Now().Add(-repository.ActiveThreatWindow)),
+		RiskScore:       strconv.Itoa(threat.RiskScore),
 	}
 
 	// 轉換時間戳
@@ -483,4 +473,50 @@ func convertThreatToProto(threat *vo.ThreatIntelligenceVO) *proto.ThreatIntellig
 	}
 
 	return protoThreat
-} 
\ No newline at end of file
+}
+
+// convertPaginationToProto 轉換分頁資訊到proto格式
+func convertPaginationToProto(pagination vo.PaginationVO) *proto.Pagination {
+	return &proto.Pagination{
+		CurrentPage:  int32(pagination.CurrentPage),
+		PageSize:     int32(pagination.PageSize),
+		TotalPages:   int32(pagination.TotalPages),
+		TotalRecords: pagination.TotalRecords,
+		HasNext:      pagination.HasNext,
+		HasPrevious:  pagination.HasPrevious,
+	}
+}
+
+// convertMetadataFromProto 轉換proto metadata
+func convertMetadataFromProto(metadata map[string]string) map[string]interface{} {
+	if len(metadata) == 0 {
+		return nil
+	}
+	result := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		result[k] = v
+	}
+	return result
+}
+
+// optionalString 將空字串視為未設定
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// stringValue 取得字串指標的值
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// dayStart 取得 UTC 當日起點，與時間線的日區間對齊
+func dayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// APIKeyHandler API 金鑰自助管理處理器
+type APIKeyHandler struct {
+	service service.APIKeyService
+}
+
+// NewAPIKeyHandler 建立 API 金鑰處理器
+func NewAPIKeyHandler(service service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// ListAPIKeys 取得 API 金鑰列表
+// @Summary 取得 API 金鑰列表
+// @Description 取得目前使用者的 API 金鑰，金鑰內容一律遮蔽
+// @Tags API Keys
+// @Produce json
+// @Param page query int false "頁碼" default(1)
+// @Param page_size query int false "每頁數量" default(20)
+// @Param is_active query bool false "是否啟用"
+// @Success 200 {object} vo.GetAPIKeyListResponse "取得成功"
+// @Failure 401 {object} vo.BaseResponse{error=vo.ErrorVO} "未授權"
+// @Security BearerAuth
+// @Router /api/v1/auth/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.APIKeyListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	apiKeys, err := h.service.ListAPIKeys(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, err, "取得 API 金鑰列表失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得 API 金鑰列表成功", apiKeys)
+}
+
+// GetAPIKey 取得 API 金鑰
+// @Summary 取得 API 金鑰
+// @Tags API Keys
+// @Produce json
+// @Param id path string true "API 金鑰 ID" format(uuid)
+// @Success 200 {object} vo.GetAPIKeyResponse "取得成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/auth/api-keys/{id} [get]
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	apiKey, err := h.service.GetAPIKey(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err, "取得 API 金鑰失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得 API 金鑰成功", apiKey)
+}
+
+// CreateAPIKey 建立 API 金鑰
+// @Summary 建立 API 金鑰
+// @Description 建立以 usip_ 開頭的 API 金鑰，可透過 X-API-Key 標頭存取 API。完整金鑰只會在此回應中出現一次
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param request body dto.APIKeyCreateRequest true "API 金鑰建立請求（expires_at 為 Unix 秒）"
+// @Success 201 {object} vo.CreateAPIKeyResponse "建立成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 409 {object} vo.BaseResponse{error=vo.ErrorVO} "金鑰數量已達上限"
+// @Security BearerAuth
+// @Router /api/v1/auth/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	apiKey, err := h.service.CreateAPIKey(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, err, "建立 API 金鑰失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusCreated, "API 金鑰建立成功，請妥善保存金鑰，之後將無法再次檢視", apiKey)
+}
+
+// UpdateAPIKey 更新 API 金鑰
+// @Summary 更新 API 金鑰
+// @Description 更新名稱、啟用狀態、到期時間（Unix 秒，0 表示永不過期）或配額；已撤銷的金鑰不可重新啟用
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API 金鑰 ID" format(uuid)
+// @Param request body dto.APIKeyUpdateRequest true "API 金鑰更新請求"
+// @Success 200 {object} vo.UpdateAPIKeyResponse "更新成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Failure 409 {object} vo.BaseResponse{error=vo.ErrorVO} "金鑰已撤銷或已輪替"
+// @Security BearerAuth
+// @Router /api/v1/auth/api-keys/{id} [put]
+func (h *APIKeyHandler) UpdateAPIKey(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.APIKeyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	apiKey, err := h.service.UpdateAPIKey(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "更新 API 金鑰失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "API 金鑰更新成功", apiKey)
+}
+
+// RevokeAPIKey 撤銷 API 金鑰
+// @Summary 撤銷 API 金鑰
+// @Description 立即停用金鑰且不可重新啟用，紀錄保留供稽核
+// @Tags API Keys
+// @Produce json
+// @Param id path string true "API 金鑰 ID" format(uuid)
+// @Success 200 {object} vo.DeleteAPIKeyResponse "撤銷成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/auth/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err, "撤銷 API 金鑰失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "API 金鑰已撤銷", nil)
+}
+
+// RotateAPIKey 輪替 API 金鑰
+// @Summary 輪替 API 金鑰
+// @Description 以相同名稱、配額與到期時間建立新金鑰；舊金鑰在重疊期間（overlap_minutes，未指定時使用系統預設）內仍可使用，0 表示立即撤銷。新金鑰只會在此回應中出現一次
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API 金鑰 ID" format(uuid)
+// @Param request body dto.APIKeyRotateRequest false "API 金鑰輪替請求"
+// @Success 200 {object} vo.BaseResponse{data=vo.RotateAPIKeyVO} "輪替成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Failure 409 {object} vo.BaseResponse{error=vo.ErrorVO} "金鑰已撤銷或已輪替"
+// @Security BearerAuth
+// @Router /api/v1/auth/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.APIKeyRotateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+			return
+		}
+	}
+
+	result, err := h.service.RotateAPIKey(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "輪替 API 金鑰失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "API 金鑰已輪替，請妥善保存新金鑰並於舊金鑰到期前完成替換", result)
+}
+
+// RegisterRoutes 註冊路由
+func (h *APIKeyHandler) RegisterRoutes(router *gin.RouterGroup) {
+	apiKeys := router.Group("/auth/api-keys")
+	{
+		apiKeys.GET("", h.ListAPIKeys)
+		apiKeys.POST("", h.CreateAPIKey)
+		apiKeys.GET("/:id", h.GetAPIKey)
+		apiKeys.PUT("/:id", h.UpdateAPIKey)
+		apiKeys.DELETE("/:id", h.RevokeAPIKey)
+		apiKeys.POST("/:id/rotate", h.RotateAPIKey)
+	}
+}
+
+// userID 取得目前使用者 ID
+func (h *APIKeyHandler) userID(c *gin.Context) (uuid.UUID, bool) {
+	userID, ok := c.Get("user_id")
+	if id, valid := userID.(uuid.UUID); ok && valid {
+		return id, true
+	}
+	h.respondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "使用者未認證", nil)
+	return uuid.Nil, false
+}
+
+// parseRequest 取得目前使用者 ID 與路徑中的金鑰 ID
+func (h *APIKeyHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_UUID", "無效的 UUID 格式", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+// handleError 依錯誤類型回傳適當的狀態碼
+func (h *APIKeyHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, dto.ErrAPIKeyNotFound):
+		h.respondError(c, http.StatusNotFound, "API_KEY_NOT_FOUND", "API 金鑰不存在", err)
+	case errors.Is(err, dto.ErrAPIKeyRevoked):
+		h.respondError(c, http.StatusConflict, "API_KEY_REVOKED", "API 金鑰已撤銷", err)
+	case errors.Is(err, dto.ErrAPIKeyRotated):
+		h.respondError(c, http.StatusConflict, "API_KEY_ROTATED", "API 金鑰已輪替，不可再次輪替或延長到期時間", err)
+	case errors.Is(err, dto.ErrAPIKeyLimitReached):
+		h.respondError(c, http.StatusConflict, "API_KEY_LIMIT_REACHED", "有效的 API 金鑰數量已達上限", err)
+	case errors.Is(err, dto.ErrInvalidQuota):
+		h.respondError(c, http.StatusBadRequest, "INVALID_QUOTA", "配額必須大於 0", err)
+	case errors.Is(err, dto.ErrInvalidExpiration):
+		h.respondError(c, http.StatusBadRequest, "INVALID_EXPIRATION", "到期時間必須晚於目前時間", err)
+	default:
+		h.respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", message, err)
+	}
+}
+
+// respondSuccess 回傳成功回應
+func (h *APIKeyHandler) respondSuccess(c *gin.Context, statusCode int, message string, data interface{}) {
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
+
+// respondError 回傳錯誤回應
+func (h *APIKeyHandler) respondError(c *gin.Context, statusCode int, code string, message string, err error) {
+	errorVO := vo.ErrorVO{
+		Code:    code,
+		Message: message,
+	}
+	if err != nil {
+		errorVO.Details = err.Error()
+	}
+
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   false,
+		Message:   "請求處理失敗",
+		Error:     &errorVO,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
@@ -69,12 +69,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login 使用者登入
 // @Summary 使用者登入
-// @Description 使用使用者名稱/郵箱和密碼進行登入
+// @Description 使用使用者名稱/郵箱和密碼進行登入；已啟用多因素驗證時回傳 202 與登入挑戰，需再呼叫 /auth/mfa/verify 取得令牌
 // @Tags 認證
 // @Accept json
 // @Produce json
 // @Param request body dto.LoginRequest true "登入資訊"
 // @Success 200 {object} vo.LoginResponse "登入成功"
+// @Success 202 {object} vo.MFAChallengeResponse "需要多因素驗證"
 // @Failure 400 {object} vo.BaseResponse "請求參數錯誤"
 // @Failure 401 {object} vo.BaseResponse "認證失敗"
 // @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
@@ -89,12 +90,64 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	result, err := h.authService.Login(&req)
+	result, challenge, err := h.authService.Login(&req)
 	if err != nil {
 		handleServiceError(c, err, "Login failed")
 		return
 	}
 
+	if challenge != nil {
+		c.JSON(http.StatusAccepted, vo.MFAChallengeResponse{
+			BaseResponse: vo.BaseResponse{
+				Success:   true,
+				Message:   "Multi-factor authentication required",
+				Timestamp: time.Now(),
+			},
+			Data: challenge,
+		})
+		return
+	}
+
+	response := vo.LoginResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "Login successful",
+			Timestamp: time.Now(),
+		},
+		Data: result,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// VerifyMFA 多因素驗證登入
+// @Summary 多因素驗證登入
+// @Description 以登入挑戰令牌與驗證器代碼或復原碼完成登入；每個挑戰的嘗試次數有限，用盡後需重新登入
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAVerifyRequest true "登入挑戰令牌與驗證碼"
+// @Success 200 {object} vo.LoginResponse "登入成功"
+// @Failure 400 {object} vo.BaseResponse "請求參數錯誤"
+// @Failure 401 {object} vo.BaseResponse "驗證碼錯誤或登入挑戰無效"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req dto.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkglogger.Error("Invalid MFA verify request", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request parameters", err)
+		return
+	}
+
+	result, err := h.authService.VerifyMFA(&req)
+	if err != nil {
+		handleServiceError(c, err, "MFA verification failed")
+		return
+	}
+
 	response := vo.LoginResponse{
 		BaseResponse: vo.BaseResponse{
 			Success:   true,
@@ -189,6 +242,39 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// LogoutAll 登出所有裝置
+// @Summary 登出所有裝置
+// @Description 使當前使用者目前為止簽發的所有存取令牌與刷新令牌失效，包含本次請求使用的令牌
+// @Tags 認證
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} vo.LogoutResponse "登出成功"
+// @Failure 401 {object} vo.BaseResponse "未授權"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", nil)
+		return
+	}
+
+	if err := h.authService.LogoutAll(userID.(uuid.UUID)); err != nil {
+		handleServiceError(c, err, "Logout failed")
+		return
+	}
+
+	response := vo.LogoutResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "Logged out of all sessions",
+			Timestamp: time.Now(),
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // ChangePassword 修改密碼
 // @Summary 修改密碼
 // @Description 修改當前使用者的密碼
@@ -237,7 +323,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 // ResetPassword 重設密碼
 // @Summary 重設密碼
-// @Description 發送密碼重設郵件
+// @Description 發送密碼重設郵件；為避免帳號探測，信箱不存在時同樣回傳成功
 // @Tags 認證
 // @Accept json
 // @Produce json
@@ -287,7 +373,8 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 // @Param request body dto.ConfirmResetPasswordRequest true "確認重設密碼資訊"
 // @Success 200 {object} vo.ConfirmResetPasswordResponse "密碼重設成功"
 // @Failure 400 {object} vo.BaseResponse "請求參數錯誤"
-// @Failure 401 {object} vo.BaseResponse "無效的重設令牌"
+// @Failure 401 {object} vo.BaseResponse "無效或已過期的重設令牌"
+// @Failure 403 {object} vo.BaseResponse "帳戶已停用"
 // @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
 // @Router /auth/confirm-reset-password [post]
 func (h *AuthHandler) ConfirmResetPassword(c *gin.Context) {
@@ -317,6 +404,78 @@ func (h *AuthHandler) ConfirmResetPassword(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// VerifyEmail 驗證信箱
+// @Summary 驗證信箱
+// @Description 使用驗證信中的令牌確認信箱；令牌只能使用一次，且信箱變更後舊令牌失效
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyEmailRequest true "驗證令牌"
+// @Success 200 {object} vo.VerifyEmailResponse "信箱驗證成功"
+// @Failure 400 {object} vo.BaseResponse "請求參數錯誤或令牌無效"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkglogger.Error("Invalid verify email request", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request parameters", err)
+		return
+	}
+
+	if err := h.authService.VerifyEmail(&req); err != nil {
+		handleServiceError(c, err, "Email verification failed")
+		return
+	}
+
+	response := vo.VerifyEmailResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "Email verified successfully",
+			Timestamp: time.Now(),
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResendVerification 重新發送驗證信
+// @Summary 重新發送驗證信
+// @Description 重新寄送驗證信到當前使用者的信箱，先前寄出的驗證連結隨即失效；受發送間隔與每小時上限限制
+// @Tags 認證
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} vo.ResendVerificationResponse "驗證信已發送"
+// @Failure 401 {object} vo.BaseResponse "未授權"
+// @Failure 409 {object} vo.BaseResponse "信箱已驗證"
+// @Failure 429 {object} vo.BaseResponse "發送過於頻繁"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", nil)
+		return
+	}
+
+	if err := h.authService.ResendVerificationEmail(userID.(uuid.UUID)); err != nil {
+		handleServiceError(c, err, "Failed to resend verification email")
+		return
+	}
+
+	response := vo.ResendVerificationResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "Verification email sent",
+			Timestamp: time.Now(),
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // GetProfile 取得個人檔案
 // @Summary 取得個人檔案
 // @Description 取得當前使用者的個人檔案資訊
@@ -401,8 +560,8 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// RegisterRoutes 註冊認證路由
-func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
+// RegisterRoutes 註冊認證路由，authMiddleware 套用於需要登入的個人檔案路由
+func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
 	auth := router.Group("/auth")
 	{
 		// 公開路由
@@ -411,16 +570,18 @@ func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
 		auth.POST("/refresh", h.RefreshToken)
 		auth.POST("/reset-password", h.ResetPassword)
 		auth.POST("/confirm-reset-password", h.ConfirmResetPassword)
-
-		// 需要認證的路由
-		// 這些路由需要在主程式中添加JWT中介軟體
+		auth.POST("/verify-email", h.VerifyEmail)
+		auth.POST("/mfa/verify", h.VerifyMFA)
 	}
 
 	// 個人檔案路由（需要認證）
 	profile := router.Group("/auth")
+	profile.Use(authMiddleware)
 	{
 		profile.POST("/logout", h.Logout)
+		profile.POST("/logout-all", h.LogoutAll)
 		profile.POST("/change-password", h.ChangePassword)
+		profile.POST("/resend-verification", h.ResendVerification)
 		profile.GET("/profile", h.GetProfile)
 		profile.PUT("/profile", h.UpdateProfile)
 	}
@@ -467,6 +628,40 @@ func handleServiceError(c *gin.Context, err error, message string) {
 		respondError(c, http.StatusConflict, "EMAIL_EXISTS", "Email already exists", err)
 	case errors.Is(err, dto.ErrInvalidRefreshToken):
 		respondError(c, http.StatusUnauthorized, "INVALID_REFRESH_TOKEN", "Invalid refresh token", err)
+	case errors.Is(err, dto.ErrRefreshTokenReused):
+		respondError(c, http.StatusUnauthorized, "REFRESH_TOKEN_REUSED", "Refresh token has already been used, please log in again", err)
+	case errors.Is(err, dto.ErrInvalidToken):
+		respondError(c, http.StatusUnauthorized, "INVALID_TOKEN", "Invalid token", err)
+	case errors.Is(err, dto.ErrInvalidResetToken):
+		respondError(c, http.StatusUnauthorized, "INVALID_RESET_TOKEN", "Invalid or expired password reset token", err)
+	case errors.Is(err, dto.ErrInvalidVerificationToken):
+		respondError(c, http.StatusBadRequest, "INVALID_VERIFICATION_TOKEN", "Invalid or expired email verification token", err)
+	case errors.Is(err, dto.ErrEmailAlreadyVerified):
+		respondError(c, http.StatusConflict, "EMAIL_ALREADY_VERIFIED", "Email already verified", err)
+	case errors.Is(err, dto.ErrVerificationRateLimited):
+		respondError(c, http.StatusTooManyRequests, "VERIFICATION_RATE_LIMITED", "Too many verification emails requested, please try again later", err)
+	case errors.Is(err, dto.ErrInvalidMFACode):
+		respondError(c, http.StatusUnauthorized, "INVALID_MFA_CODE", "Invalid MFA code", err)
+	case errors.Is(err, dto.ErrInvalidMFAChallenge):
+		respondError(c, http.StatusUnauthorized, "INVALID_MFA_CHALLENGE", "Invalid or expired MFA challenge, please log in again", err)
+	case errors.Is(err, dto.ErrMFANotSetup):
+		respondError(c, http.StatusBadRequest, "MFA_NOT_SETUP", "MFA setup has not been started", err)
+	case errors.Is(err, dto.ErrMFANotEnabled):
+		respondError(c, http.StatusBadRequest, "MFA_NOT_ENABLED", "MFA is not enabled", err)
+	case errors.Is(err, dto.ErrMFAAlreadyEnabled):
+		respondError(c, http.StatusConflict, "MFA_ALREADY_ENABLED", "MFA is already enabled", err)
+	case errors.Is(err, dto.ErrMFARequired):
+		respondError(c, http.StatusForbidden, "MFA_REQUIRED", "MFA is required for your role and cannot be disabled", err)
+	case errors.Is(err, dto.ErrInvalidOIDCState):
+		respondError(c, http.StatusBadRequest, "INVALID_SSO_STATE", "Invalid or expired SSO login state, please try again", err)
+	case errors.Is(err, dto.ErrOIDCAuthenticationFailed):
+		respondError(c, http.StatusUnauthorized, "SSO_AUTHENTICATION_FAILED", "SSO authentication failed", err)
+	case errors.Is(err, dto.ErrOIDCProviderUnavailable):
+		respondError(c, http.StatusBadGateway, "SSO_PROVIDER_UNAVAILABLE", "SSO identity provider is unavailable", err)
+	case errors.Is(err, dto.ErrOIDCEmailRequired):
+		respondError(c, http.StatusForbidden, "SSO_EMAIL_REQUIRED", "Identity provider did not return an email address", err)
+	case errors.Is(err, dto.ErrOIDCAccountConflict):
+		respondError(c, http.StatusConflict, "SSO_ACCOUNT_CONFLICT", "An account with this email exists but its email is not verified, please log in with your password and verify it first", err)
 	case errors.Is(err, dto.ErrInvalidCurrentPassword):
 		respondError(c, http.StatusBadRequest, "INVALID_CURRENT_PASSWORD", "Invalid current password", err)
 	default:
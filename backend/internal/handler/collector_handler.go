@@ -2,23 +2,21 @@ package handler
 
 import (
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/collector"
-	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
 )
 
 // CollectorHandler 收集器處理器
 type CollectorHandler struct {
-	threatIntelService service.ThreatIntelligenceService
+	registry *collector.Registry
 }
 
 // NewCollectorHandler 建立收集器處理器
-func NewCollectorHandler(threatIntelService service.ThreatIntelligenceService) *CollectorHandler {
+func NewCollectorHandler(registry *collector.Registry) *CollectorHandler {
 	return &CollectorHandler{
-		threatIntelService: threatIntelService,
+		registry: registry,
 	}
 }
 
@@ -50,16 +48,13 @@ func (h *CollectorHandler) CollectIPThreatIntel(c *gin.Context) {
 		return
 	}
 
-	// 檢查 AbuseIPDB API 金鑰
-	apiKey := os.Getenv("ABUSEIPDB_API_KEY")
-	if apiKey == "" {
-		h.respondError(c, http.StatusInternalServerError, "CONFIG_ERROR", "AbuseIPDB API 金鑰未設定", nil)
+	// 取得已綁定的 AbuseIPDB 收集器
+	abuseCollector, ok := h.ipCollector(collector.AbuseIPDBSourceName)
+	if !ok {
+		h.respondError(c, http.StatusInternalServerError, "CONFIG_ERROR", "AbuseIPDB 收集器未設定", nil)
 		return
 	}
 
-	// 建立收集器
-	abuseCollector := collector.NewAbuseIPDBCollector(apiKey, h.threatIntelService)
-
 	// 執行收集
 	err := abuseCollector.CollectIPThreatIntel(c.Request.Context(), req.IPAddress)
 	if err != nil {
@@ -91,16 +86,13 @@ func (h *CollectorHandler) CollectBulkIPThreatIntel(c *gin.Context) {
 		return
 	}
 
-	// 檢查 AbuseIPDB API 金鑰
-	apiKey := os.Getenv("ABUSEIPDB_API_KEY")
-	if apiKey == "" {
-		h.respondError(c, http.StatusInternalServerError, "CONFIG_ERROR", "AbuseIPDB API 金鑰未設定", nil)
+	// 取得已綁定的 AbuseIPDB 收集器
+	abuseCollector, ok := h.ipCollector(collector.AbuseIPDBSourceName)
+	if !ok {
+		h.respondError(c, http.StatusInternalServerError, "CONFIG_ERROR", "AbuseIPDB 收集器未設定", nil)
 		return
 	}
 
-	// 建立收集器
-	abuseCollector := collector.NewAbuseIPDBCollector(apiKey, h.threatIntelService)
-
 	// 執行批量收集
 	successful, failed := abuseCollector.CollectBulkIPThreatIntel(c.Request.Context(), req.IPAddresses)
 
@@ -113,6 +105,16 @@ func (h *CollectorHandler) CollectBulkIPThreatIntel(c *gin.Context) {
 	})
 }
 
+// ipCollector 從註冊表取得支援 IP 查詢的收集器
+func (h *CollectorHandler) ipCollector(name string) (collector.IPCollector, bool) {
+	c, ok := h.registry.Get(name)
+	if !ok {
+		return nil, false
+	}
+	ipCollector, ok := c.(collector.IPCollector)
+	return ipCollector, ok
+}
+
 // respondSuccess 回傳成功回應
 func (h *CollectorHandler) respondSuccess(c *gin.Context, statusCode int, message string, data interface{}) {
 	response := vo.BaseResponse{
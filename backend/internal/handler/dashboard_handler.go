@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// DashboardHandler 儀表板處理器
+type DashboardHandler struct {
+	service service.DashboardService
+}
+
+// NewDashboardHandler 建立儀表板處理器
+func NewDashboardHandler(service service.DashboardService) *DashboardHandler {
+	return &DashboardHandler{service: service}
+}
+
+// GetDashboard 取得儀表板資料
+// @Summary 取得儀表板資料
+// @Description 一次取得概覽數量、最近威脅、最近收集任務、統計、時間線與警報；結果會在伺服器端快取
+// @Tags Dashboard
+// @Produce json
+// @Success 200 {object} vo.BaseResponse{data=vo.DashboardVO} "取得成功"
+// @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
+// @Security BearerAuth
+// @Router /api/v1/dashboard [get]
+func (h *DashboardHandler) GetDashboard(c *gin.Context) {
+	dashboard, err := h.service.GetDashboard(c.Request.Context())
+	if err != nil {
+		h.respondError(c, http.StatusInternalServerError, "DASHBOARD_FAILED", "取得儀表板資料失敗", err)
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得儀表板資料成功", dashboard)
+}
+
+// GetMetrics 取得威脅情報指標
+// @Summary 取得威脅情報指標
+// @Description 取得各維度威脅數量、最常出現的惡意 IP 與域名、ASN 統計、最近 24 小時活動與收集狀態；結果會在伺服器端快取
+// @Tags Dashboard
+// @Produce json
+// @Success 200 {object} vo.BaseResponse{data=vo.ThreatIntelligenceMetricsVO} "取得成功"
+// @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
+// @Security BearerAuth
+// @Router /api/v1/dashboard/metrics [get]
+func (h *DashboardHandler) GetMetrics(c *gin.Context) {
+	metrics, err := h.service.GetMetrics(c.Request.Context())
+	if err != nil {
+		h.respondError(c, http.StatusInternalServerError, "METRICS_FAILED", "取得威脅情報指標失敗", err)
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得威脅情報指標成功", metrics)
+}
+
+// RegisterRoutes 註冊路由
+func (h *DashboardHandler) RegisterRoutes(router *gin.RouterGroup) {
+	dashboard := router.Group("/dashboard")
+	{
+		dashboard.GET("", h.GetDashboard)
+		dashboard.GET("/metrics", h.GetMetrics)
+	}
+}
+
+// respondSuccess 回傳成功回應
+func (h *DashboardHandler) respondSuccess(c *gin.Context, statusCode int, message string, data interface{}) {
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
+
+// respondError 回傳錯誤回應
+func (h *DashboardHandler) respondError(c *gin.Context, statusCode int, code string, message string, err error) {
+	errorVO := vo.ErrorVO{
+		Code:    code,
+		Message: message,
+	}
+	if err != nil {
+		errorVO.Details = err.Error()
+	}
+
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   false,
+		Message:   "請求處理失敗",
+		Error:     &errorVO,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
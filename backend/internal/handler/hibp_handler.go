@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/collector"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/middleware"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -308,13 +310,14 @@ func (h *HIBPHandler) HealthCheck(c *gin.Context) {
 	})
 }
 
-// RegisterRoutes 註冊路由
-func (h *HIBPHandler) RegisterRoutes(router *gin.RouterGroup) {
+// RegisterRoutes 註冊路由，所有端點需要 hibp:read，竊密程式紀錄另需 hibp:stealer:read
+func (h *HIBPHandler) RegisterRoutes(router *gin.RouterGroup, require middleware.PermissionGuard) {
 	hibp := router.Group("/hibp")
+	hibp.Use(require(model.PermissionHIBPRead))
 	{
 		// 帳戶相關
 		hibp.GET("/account/:account/breaches", h.CheckAccountBreaches)
-		hibp.POST("/account/:account/process", h.ProcessAccountBreaches)
+		hibp.POST("/account/:account/process", require(model.PermissionThreatWrite), h.ProcessAccountBreaches)
 		hibp.GET("/account/:account/pastes", h.GetAccountPastes)
 
 		// 域名相關
@@ -328,9 +331,11 @@ func (h *HIBPHandler) RegisterRoutes(router *gin.RouterGroup) {
 		hibp.GET("/dataclasses", h.GetDataClasses)
 
 		// Stealer Logs (需要高級訂閱)
-		hibp.GET("/stealer/email/:email", h.GetStealerLogsByEmail)
-		hibp.GET("/stealer/website/:domain", h.GetStealerLogsByWebsiteDomain)
-		hibp.GET("/stealer/emaildomain/:domain", h.GetStealerLogsByEmailDomain)
+		stealer := hibp.Group("/stealer")
+		stealer.Use(require(model.PermissionHIBPStealerRead))
+		stealer.GET("/email/:email", h.GetStealerLogsByEmail)
+		stealer.GET("/website/:domain", h.GetStealerLogsByWebsiteDomain)
+		stealer.GET("/emaildomain/:domain", h.GetStealerLogsByEmailDomain)
 
 		// 密碼檢查 (無需認證)
 		hibp.GET("/password/check", h.CheckPasswordHash)
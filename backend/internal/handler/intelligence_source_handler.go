@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// IntelligenceSourceHandler 情報來源與收集任務處理器
+type IntelligenceSourceHandler struct {
+	service service.IntelligenceSourceService
+}
+
+// NewIntelligenceSourceHandler 建立情報來源處理器
+func NewIntelligenceSourceHandler(service service.IntelligenceSourceService) *IntelligenceSourceHandler {
+	return &IntelligenceSourceHandler{service: service}
+}
+
+// ListSources 取得情報來源列表
+// @Summary 取得情報來源列表
+// @Description 取得所有情報來源及其收集狀態
+// @Tags Intelligence Sources
+// @Produce json
+// @Success 200 {object} vo.BaseResponse{data=[]vo.IntelligenceSourceVO} "取得成功"
+// @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
+// @Security BearerAuth
+// @Router /api/v1/sources [get]
+func (h *IntelligenceSourceHandler) ListSources(c *gin.Context) {
+	sources, err := h.service.ListSources(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "取得情報來源列表失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得情報來源列表成功", sources)
+}
+
+// GetSource 取得情報來源
+// @Summary 取得情報來源
+// @Description 根據 ID 取得情報來源詳情
+// @Tags Intelligence Sources
+// @Produce json
+// @Param id path string true "情報來源 ID" format(uuid)
+// @Success 200 {object} vo.BaseResponse{data=vo.IntelligenceSourceVO} "取得成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/sources/{id} [get]
+func (h *IntelligenceSourceHandler) GetSource(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	source, err := h.service.GetSource(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "取得情報來源失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得情報來源成功", source)
+}
+
+// CreateSource 建立情報來源
+// @Summary 建立情報來源
+// @Description 建立新的情報來源，名稱與已註冊收集器相同時會自動綁定
+// @Tags Intelligence Sources
+// @Accept json
+// @Produce json
+// @Param request body dto.IntelligenceSourceCreateRequest true "情報來源建立請求"
+// @Success 201 {object} vo.BaseResponse{data=vo.IntelligenceSourceVO} "建立成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤或位址不允許"
+// @Failure 409 {object} vo.BaseResponse{error=vo.ErrorVO} "名稱已存在"
+// @Security BearerAuth
+// @Router /api/v1/sources [post]
+func (h *IntelligenceSourceHandler) CreateSource(c *gin.Context) {
+	var req dto.IntelligenceSourceCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	source, err := h.service.CreateSource(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "建立情報來源失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusCreated, "情報來源建立成功", source)
+}
+
+// UpdateSource 更新情報來源
+// @Summary 更新情報來源
+// @Description 更新情報來源的位址、啟用狀態或收集間隔；已註冊收集器的來源只能改為官方主機的 HTTPS 位址
+// @Tags Intelligence Sources
+// @Accept json
+// @Produce json
+// @Param id path string true "情報來源 ID" format(uuid)
+// @Param request body dto.IntelligenceSourceUpdateRequest true "情報來源更新請求"
+// @Success 200 {object} vo.BaseResponse{data=vo.IntelligenceSourceVO} "更新成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤或位址不允許"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/sources/{id} [put]
+func (h *IntelligenceSourceHandler) UpdateSource(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.IntelligenceSourceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	source, err := h.service.UpdateSource(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleError(c, err, "更新情報來源失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "情報來源更新成功", source)
+}
+
+// DeleteSource 刪除情報來源
+// @Summary 刪除情報來源
+// @Description 刪除情報來源及其收集任務紀錄
+// @Tags Intelligence Sources
+// @Produce json
+// @Param id path string true "情報來源 ID" format(uuid)
+// @Success 200 {object} vo.BaseResponse "刪除成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/sources/{id} [delete]
+func (h *IntelligenceSourceHandler) DeleteSource(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSource(c.Request.Context(), id); err != nil {
+		h.handleError(c, err, "刪除情報來源失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "情報來源刪除成功", nil)
+}
+
+// EnableSource 啟用情報來源
+// @Summary 啟用情報來源
+// @Tags Intelligence Sources
+// @Produce json
+// @Param id path string true "情報來源 ID" format(uuid)
+// @Success 200 {object} vo.BaseResponse{data=vo.IntelligenceSourceVO} "啟用成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/sources/{id}/enable [post]
+func (h *IntelligenceSourceHandler) EnableSource(c *gin.Context) {
+	h.setActive(c, true, "情報來源已啟用")
+}
+
+// DisableSource 停用情報來源
+// @Summary 停用情報來源
+// @Tags Intelligence Sources
+// @Produce json
+// @Param id path string true "情報來源 ID" format(uuid)
+// @Success 200 {object} vo.BaseResponse{data=vo.IntelligenceSourceVO} "停用成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/sources/{id}/disable [post]
+func (h *IntelligenceSourceHandler) DisableSource(c *gin.Context) {
+	h.setActive(c, false, "情報來源已停用")
+}
+
+// CollectNow 立即收集
+// @Summary 立即觸發收集
+// @Description 不受收集間隔限制，立即為情報來源派發一次收集任務
+// @Tags Intelligence Sources
+// @Produce json
+// @Param id path string true "情報來源 ID" format(uuid)
+// @Success 202 {object} vo.BaseResponse{data=vo.CollectionJobVO} "已派發"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Failure 409 {object} vo.BaseResponse{error=vo.ErrorVO} "收集進行中"
+// @Failure 422 {object} vo.BaseResponse{error=vo.ErrorVO} "來源沒有可用的收集器"
+// @Security BearerAuth
+// @Router /api/v1/sources/{id}/collect [post]
+func (h *IntelligenceSourceHandler) CollectNow(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.CollectNow(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "觸發收集失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusAccepted, "收集任務已派發", job)
+}
+
+// ListJobs 取得收集任務列表
+// @Summary 取得收集任務列表
+// @Description 依情報來源與狀態篩選收集任務，包含執行時間與錯誤訊息
+// @Tags Collection Jobs
+// @Produce json
+// @Param source_id query string false "情報來源 ID" format(uuid)
+// @Param status query string false "任務狀態" Enums(pending, in_progress, completed, failed)
+// @Param page query int false "頁碼" default(1)
+// @Param page_size query int false "每頁數量" default(20)
+// @Success 200 {object} vo.BaseResponse{data=vo.CollectionJobListVO} "取得成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Security BearerAuth
+// @Router /api/v1/collection-jobs [get]
+func (h *IntelligenceSourceHandler) ListJobs(c *gin.Context) {
+	var req dto.CollectionJobQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	jobs, err := h.service.ListJobs(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "取得收集任務列表失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得收集任務列表成功", jobs)
+}
+
+// GetJob 取得收集任務
+// @Summary 取得收集任務
+// @Tags Collection Jobs
+// @Produce json
+// @Param id path string true "收集任務 ID" format(uuid)
+// @Success 200 {object} vo.BaseResponse{data=vo.CollectionJobVO} "取得成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/collection-jobs/{id} [get]
+func (h *IntelligenceSourceHandler) GetJob(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.GetJob(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "取得收集任務失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得收集任務成功", job)
+}
+
+// RegisterRoutes 註冊路由
+func (h *IntelligenceSourceHandler) RegisterRoutes(router *gin.RouterGroup) {
+	sources := router.Group("/sources")
+	{
+		sources.GET("", h.ListSources)
+		sources.POST("", h.CreateSource)
+		sources.GET("/:id", h.GetSource)
+		sources.PUT("/:id", h.UpdateSource)
+		sources.DELETE("/:id", h.DeleteSource)
+		sources.POST("/:id/enable", h.EnableSource)
+		sources.POST("/:id/disable", h.DisableSource)
+		sources.POST("/:id/collect", h.CollectNow)
+	}
+
+	jobs := router.Group("/collection-jobs")
+	{
+		jobs.GET("", h.ListJobs)
+		jobs.GET("/:id", h.GetJob)
+	}
+}
+
+// setActive 啟用或停用情報來源
+func (h *IntelligenceSourceHandler) setActive(c *gin.Context, active bool, message string) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	source, err := h.service.SetSourceActive(c.Request.Context(), id, active)
+	if err != nil {
+		h.handleError(c, err, "更新情報來源狀態失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, message, source)
+}
+
+// parseID 解析路徑中的 UUID
+func (h *IntelligenceSourceHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_UUID", "無效的 UUID 格式", err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// handleError 依錯誤類型回傳適當的狀態碼
+func (h *IntelligenceSourceHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, dto.ErrSourceNotFound):
+		h.respondError(c, http.StatusNotFound, "SOURCE_NOT_FOUND", "情報來源不存在", err)
+	case errors.Is(err, dto.ErrCollectionJobNotFound):
+		h.respondError(c, http.StatusNotFound, "JOB_NOT_FOUND", "收集任務不存在", err)
+	case errors.Is(err, dto.ErrSourceExists):
+		h.respondError(c, http.StatusConflict, "SOURCE_EXISTS", "情報來源名稱已存在", err)
+	case errors.Is(err, dto.ErrCollectionInProgress):
+		h.respondError(c, http.StatusConflict, "COLLECTION_IN_PROGRESS", "此來源的收集任務正在進行中", err)
+	case errors.Is(err, dto.ErrCollectorNotFound):
+		h.respondError(c, http.StatusUnprocessableEntity, "COLLECTOR_NOT_FOUND", "此來源沒有對應的收集器", err)
+	case errors.Is(err, dto.ErrCollectorNotConfigured):
+		h.respondError(c, http.StatusUnprocessableEntity, "COLLECTOR_NOT_CONFIGURED", "此來源的收集器尚未設定", err)
+	case errors.Is(err, dto.ErrSourceURLNotAllowed):
+		h.respondError(c, http.StatusBadRequest, "SOURCE_URL_NOT_ALLOWED", "此來源的 API 位址只能使用官方主機的 HTTPS 位址", err)
+	case errors.Is(err, dto.ErrInvalidUUID), errors.Is(err, dto.ErrInvalidCollectionInterval):
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+	default:
+		h.respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", message, err)
+	}
+}
+
+// respondSuccess 回傳成功回應
+func (h *IntelligenceSourceHandler) respondSuccess(c *gin.Context, statusCode int, message string, data interface{}) {
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
+
+// respondError 回傳錯誤回應
+func (h *IntelligenceSourceHandler) respondError(c *gin.Context, statusCode int, code string, message string, err error) {
+	errorVO := vo.ErrorVO{
+		Code:    code,
+		Message: message,
+	}
+	if err != nil {
+		errorVO.Details = err.Error()
+	}
+
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   false,
+		Message:   "請求處理失敗",
+		Error:     &errorVO,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// MFAHandler 多因素驗證設定處理器
+type MFAHandler struct {
+	mfaService service.MFAService
+}
+
+// NewMFAHandler 建立多因素驗證處理器
+func NewMFAHandler(mfaService service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// GetStatus 取得多因素驗證狀態
+// @Summary 取得多因素驗證狀態
+// @Description 取得當前使用者是否已啟用多因素驗證、角色是否要求啟用與剩餘復原碼數量
+// @Tags 多因素驗證
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} vo.MFAStatusResponse "取得成功"
+// @Failure 401 {object} vo.BaseResponse "未授權"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/mfa [get]
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.mfaService.GetStatus(c.Request.Context(), userID, c.GetString("role"))
+	if err != nil {
+		handleServiceError(c, err, "Failed to get MFA status")
+		return
+	}
+
+	c.JSON(http.StatusOK, vo.MFAStatusResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "MFA status retrieved successfully",
+			Timestamp: time.Now(),
+		},
+		Data: result,
+	})
+}
+
+// Setup 開始設定 TOTP
+// @Summary 開始設定 TOTP
+// @Description 產生新的共享密鑰與 otpauth 佈建網址，以驗證器掃描後呼叫 /auth/mfa/enable 確認；重複呼叫會取代尚未確認的密鑰
+// @Tags 多因素驗證
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} vo.MFASetupResponse "設定資訊"
+// @Failure 401 {object} vo.BaseResponse "未授權"
+// @Failure 409 {object} vo.BaseResponse "已啟用多因素驗證"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/mfa/setup [post]
+func (h *MFAHandler) Setup(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.mfaService.Setup(c.Request.Context(), userID, c.GetString("email"))
+	if err != nil {
+		handleServiceError(c, err, "Failed to set up MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, vo.MFASetupResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "Scan the QR code with your authenticator app and confirm with a code",
+			Timestamp: time.Now(),
+		},
+		Data: result,
+	})
+}
+
+// Enable 啟用多因素驗證
+// @Summary 啟用多因素驗證
+// @Description 以驗證器產生的代碼確認設定並啟用，回傳的復原碼只會顯示這一次；啟用後需重新登入才能取得已驗證的令牌
+// @Tags 多因素驗證
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "驗證碼"
+// @Success 200 {object} vo.MFARecoveryCodesResponse "啟用成功"
+// @Failure 400 {object} vo.BaseResponse "請求參數錯誤或尚未開始設定"
+// @Failure 401 {object} vo.BaseResponse "未授權或驗證碼錯誤"
+// @Failure 409 {object} vo.BaseResponse "已啟用多因素驗證"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/mfa/enable [post]
+func (h *MFAHandler) Enable(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.mfaService.Enable(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		handleServiceError(c, err, "Failed to enable MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, vo.MFARecoveryCodesResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "MFA enabled, store your recovery codes in a safe place",
+			Timestamp: time.Now(),
+		},
+		Data: result,
+	})
+}
+
+// Disable 停用多因素驗證
+// @Summary 停用多因素驗證
+// @Description 以驗證器代碼或復原碼確認後停用；角色要求多因素驗證時無法停用
+// @Tags 多因素驗證
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "驗證碼或復原碼"
+// @Success 200 {object} vo.DisableMFAResponse "停用成功"
+// @Failure 400 {object} vo.BaseResponse "請求參數錯誤或尚未啟用"
+// @Failure 401 {object} vo.BaseResponse "未授權或驗證碼錯誤"
+// @Failure 403 {object} vo.BaseResponse "角色要求多因素驗證"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaService.Disable(c.Request.Context(), userID, c.GetString("role"), req.Code); err != nil {
+		handleServiceError(c, err, "Failed to disable MFA")
+		return
+	}
+
+	c.JSON(http.StatusOK, vo.DisableMFAResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "MFA disabled",
+			Timestamp: time.Now(),
+		},
+	})
+}
+
+// RegenerateRecoveryCodes 重新產生復原碼
+// @Summary 重新產生復原碼
+// @Description 以驗證器代碼或復原碼確認後產生新的一組復原碼，舊的復原碼全部失效
+// @Tags 多因素驗證
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFACodeRequest true "驗證碼或復原碼"
+// @Success 200 {object} vo.MFARecoveryCodesResponse "產生成功"
+// @Failure 400 {object} vo.BaseResponse "請求參數錯誤或尚未啟用"
+// @Failure 401 {object} vo.BaseResponse "未授權或驗證碼錯誤"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Router /auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, req, ok := h.bindCode(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		handleServiceError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	c.JSON(http.StatusOK, vo.MFARecoveryCodesResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "Recovery codes regenerated, previous codes are no longer valid",
+			Timestamp: time.Now(),
+		},
+		Data: result,
+	})
+}
+
+// RegisterRoutes 註冊多因素驗證設定路由；登入第二步的 /auth/mfa/verify 為公開路由，由 AuthHandler 註冊
+// 設定路由不套用多因素驗證要求，角色要求啟用但尚未設定的使用者才能完成設定
+func (h *MFAHandler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	mfa := router.Group("/auth/mfa")
+	mfa.Use(authMiddleware)
+	{
+		mfa.GET("", h.GetStatus)
+		mfa.POST("/setup", h.Setup)
+		mfa.POST("/enable", h.Enable)
+		mfa.POST("/disable", h.Disable)
+		mfa.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+	}
+}
+
+// userID 取得目前使用者 ID
+func (h *MFAHandler) userID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get("user_id")
+	if userID, ok := value.(uuid.UUID); exists && ok {
+		return userID, true
+	}
+	respondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", nil)
+	return uuid.Nil, false
+}
+
+// bindCode 取得目前使用者 ID 並解析驗證碼請求
+func (h *MFAHandler) bindCode(c *gin.Context) (uuid.UUID, *dto.MFACodeRequest, bool) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return uuid.Nil, nil, false
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkglogger.Error("Invalid MFA code request", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request parameters", err)
+		return uuid.Nil, nil, false
+	}
+	return userID, &req, true
+}
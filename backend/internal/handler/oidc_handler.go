@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// OIDCHandler 單一登入處理器
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+// NewOIDCHandler 建立單一登入處理器
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// Authorize 開始單一登入
+// @Summary 開始單一登入
+// @Description 建立授權碼流程（PKCE）的授權請求，前端保存 state 後將使用者導向 authorization_url
+// @Tags 認證
+// @Produce json
+// @Success 200 {object} vo.OIDCAuthorizationResponse "授權請求"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Failure 502 {object} vo.BaseResponse "身分提供者無法使用"
+// @Router /auth/oidc/authorize [get]
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	result, err := h.oidcService.Authorize(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err, "Failed to start SSO login")
+		return
+	}
+
+	c.JSON(http.StatusOK, vo.OIDCAuthorizationResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "Redirect the user to the authorization URL",
+			Timestamp: time.Now(),
+		},
+		Data: result,
+	})
+}
+
+// Callback 完成單一登入
+// @Summary 完成單一登入
+// @Description 以身分提供者導回的 code 與 state 完成登入，首次登入時以已驗證信箱連結既有帳號或自動建立帳號；已啟用多因素驗證且身分提供者未完成多因素驗證時回傳 202 與登入挑戰
+// @Tags 認證
+// @Accept json
+// @Produce json
+// @Param request body dto.OIDCCallbackRequest true "授權碼與 state"
+// @Success 200 {object} vo.LoginResponse "登入成功"
+// @Success 202 {object} vo.MFAChallengeResponse "需要多因素驗證"
+// @Failure 400 {object} vo.BaseResponse "請求參數錯誤或 state 無效"
+// @Failure 401 {object} vo.BaseResponse "單一登入失敗或帳戶已停用"
+// @Failure 403 {object} vo.BaseResponse "身分提供者未提供信箱"
+// @Failure 409 {object} vo.BaseResponse "信箱已被未驗證的帳號使用"
+// @Failure 500 {object} vo.BaseResponse "內部伺服器錯誤"
+// @Failure 502 {object} vo.BaseResponse "身分提供者無法使用"
+// @Router /auth/oidc/callback [post]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req dto.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		pkglogger.Error("Invalid SSO callback request", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request parameters", err)
+		return
+	}
+
+	result, challenge, err := h.oidcService.Callback(c.Request.Context(), &req)
+	if err != nil {
+		handleServiceError(c, err, "SSO login failed")
+		return
+	}
+
+	if challenge != nil {
+		c.JSON(http.StatusAccepted, vo.MFAChallengeResponse{
+			BaseResponse: vo.BaseResponse{
+				Success:   true,
+				Message:   "Multi-factor authentication required",
+				Timestamp: time.Now(),
+			},
+			Data: challenge,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, vo.LoginResponse{
+		BaseResponse: vo.BaseResponse{
+			Success:   true,
+			Message:   "Login successful",
+			Timestamp: time.Now(),
+		},
+		Data: result,
+	})
+}
+
+// RegisterRoutes 註冊單一登入路由，兩者皆為公開路由
+func (h *OIDCHandler) RegisterRoutes(router *gin.RouterGroup) {
+	oidc := router.Group("/auth/oidc")
+	{
+		oidc.GET("/authorize", h.Authorize)
+		oidc.POST("/callback", h.Callback)
+	}
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/middleware"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// RoleHandler 角色與權限管理處理器
+type RoleHandler struct {
+	service service.RoleService
+}
+
+// NewRoleHandler 建立角色與權限管理處理器
+func NewRoleHandler(service service.RoleService) *RoleHandler {
+	return &RoleHandler{service: service}
+}
+
+// ListPermissions 取得所有權限
+// @Summary 取得所有權限
+// @Description 取得可指派給角色的權限清單
+// @Tags 角色管理
+// @Produce json
+// @Success 200 {object} vo.BaseResponse{data=[]vo.PermissionVO} "取得成功"
+// @Failure 403 {object} vo.BaseResponse{error=vo.ErrorVO} "權限不足"
+// @Security BearerAuth
+// @Router /api/v1/admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	h.respondSuccess(c, http.StatusOK, "取得權限列表成功", h.service.ListPermissions())
+}
+
+// ListRoles 取得所有角色
+// @Summary 取得所有角色
+// @Description 取得所有角色及其權限，內建角色排在前面
+// @Tags 角色管理
+// @Produce json
+// @Success 200 {object} vo.BaseResponse{data=[]vo.RoleVO} "取得成功"
+// @Failure 403 {object} vo.BaseResponse{error=vo.ErrorVO} "權限不足"
+// @Security BearerAuth
+// @Router /api/v1/admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.service.ListRoles(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err, "取得角色列表失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得角色列表成功", roles)
+}
+
+// GetRole 取得角色
+// @Summary 取得角色
+// @Tags 角色管理
+// @Produce json
+// @Param name path string true "角色名稱"
+// @Success 200 {object} vo.BaseResponse{data=vo.RoleVO} "取得成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "角色不存在"
+// @Security BearerAuth
+// @Router /api/v1/admin/roles/{name} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.service.GetRole(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.handleError(c, err, "取得角色失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得角色成功", role)
+}
+
+// CreateRole 建立角色
+// @Summary 建立角色
+// @Description 建立自訂角色，名稱為小寫英文字母開頭，可含數字、底線與連字號
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Param request body dto.RoleCreateRequest true "角色建立請求"
+// @Success 201 {object} vo.BaseResponse{data=vo.RoleVO} "建立成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤或權限不存在"
+// @Failure 409 {object} vo.BaseResponse{error=vo.ErrorVO} "角色已存在"
+// @Security BearerAuth
+// @Router /api/v1/admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req dto.RoleCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	role, err := h.service.CreateRole(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "建立角色失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusCreated, "角色建立成功", role)
+}
+
+// UpdateRole 更新角色
+// @Summary 更新角色
+// @Description 更新角色說明或權限，權限會整組取代並立即生效；admin 角色的權限不可修改
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Param name path string true "角色名稱"
+// @Param request body dto.RoleUpdateRequest true "角色更新請求"
+// @Success 200 {object} vo.BaseResponse{data=vo.RoleVO} "更新成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤或權限不存在"
+// @Failure 403 {object} vo.BaseResponse{error=vo.ErrorVO} "內建角色受保護"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "角色不存在"
+// @Security BearerAuth
+// @Router /api/v1/admin/roles/{name} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req dto.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	role, err := h.service.UpdateRole(c.Request.Context(), c.Param("name"), &req)
+	if err != nil {
+		h.handleError(c, err, "更新角色失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "角色更新成功", role)
+}
+
+// DeleteRole 刪除角色
+// @Summary 刪除角色
+// @Description 刪除自訂角色；內建角色與仍指派給使用者的角色不可刪除
+// @Tags 角色管理
+// @Produce json
+// @Param name path string true "角色名稱"
+// @Success 200 {object} vo.BaseResponse "刪除成功"
+// @Failure 403 {object} vo.BaseResponse{error=vo.ErrorVO} "內建角色受保護"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "角色不存在"
+// @Failure 409 {object} vo.BaseResponse{error=vo.ErrorVO} "角色仍有使用者"
+// @Security BearerAuth
+// @Router /api/v1/admin/roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.service.DeleteRole(c.Request.Context(), c.Param("name")); err != nil {
+		h.handleError(c, err, "刪除角色失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "角色刪除成功", nil)
+}
+
+// AssignUserRole 指派使用者角色
+// @Summary 指派使用者角色
+// @Description 變更使用者的角色並撤銷其所有登入階段，使用者重新登入後取得新角色；不可變更自己的角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Param id path string true "使用者 ID" format(uuid)
+// @Param request body dto.AssignRoleRequest true "角色指派請求"
+// @Success 200 {object} vo.BaseResponse{data=vo.UserRoleVO} "指派成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 403 {object} vo.BaseResponse{error=vo.ErrorVO} "權限不足或變更自己的角色"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "使用者或角色不存在"
+// @Security BearerAuth
+// @Router /api/v1/admin/users/{id}/role [put]
+func (h *RoleHandler) AssignUserRole(c *gin.Context) {
+	value, _ := c.Get("user_id")
+	actorID, ok := value.(uuid.UUID)
+	if !ok {
+		h.respondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "使用者未認證", nil)
+		return
+	}
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_UUID", "無效的 UUID 格式", err)
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	result, err := h.service.AssignUserRole(c.Request.Context(), actorID, userID, &req)
+	if err != nil {
+		h.handleError(c, err, "指派角色失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "角色指派成功，使用者需重新登入", result)
+}
+
+// RegisterRoutes 註冊路由，角色定義需要 role:manage，指派使用者角色需要 user:manage
+func (h *RoleHandler) RegisterRoutes(router *gin.RouterGroup, require middleware.PermissionGuard) {
+	admin := router.Group("/admin")
+	{
+		roles := admin.Group("")
+		roles.Use(require(model.PermissionRoleManage))
+		roles.GET("/permissions", h.ListPermissions)
+		roles.GET("/roles", h.ListRoles)
+		roles.POST("/roles", h.CreateRole)
+		roles.GET("/roles/:name", h.GetRole)
+		roles.PUT("/roles/:name", h.UpdateRole)
+		roles.DELETE("/roles/:name", h.DeleteRole)
+
+		admin.PUT("/users/:id/role", require(model.PermissionUserManage), h.AssignUserRole)
+	}
+}
+
+// handleError 依錯誤類型回傳適當的狀態碼
+func (h *RoleHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, dto.ErrRoleNotFound):
+		h.respondError(c, http.StatusNotFound, "ROLE_NOT_FOUND", "角色不存在", err)
+	case errors.Is(err, dto.ErrUserNotFound):
+		h.respondError(c, http.StatusNotFound, "USER_NOT_FOUND", "使用者不存在", err)
+	case errors.Is(err, dto.ErrRoleExists):
+		h.respondError(c, http.StatusConflict, "ROLE_EXISTS", "角色已存在", err)
+	case errors.Is(err, dto.ErrRoleInUse):
+		h.respondError(c, http.StatusConflict, "ROLE_IN_USE", "角色仍指派給使用者，請先變更這些使用者的角色", err)
+	case errors.Is(err, dto.ErrInvalidRoleName):
+		h.respondError(c, http.StatusBadRequest, "INVALID_ROLE_NAME", "角色名稱須為小寫英文字母開頭，只能包含小寫英數字、底線與連字號", err)
+	case errors.Is(err, dto.ErrInvalidPermission):
+		h.respondError(c, http.StatusBadRequest, "INVALID_PERMISSION", "權限不存在", err)
+	case errors.Is(err, dto.ErrSystemRoleProtected):
+		h.respondError(c, http.StatusForbidden, "SYSTEM_ROLE_PROTECTED", "內建角色不可刪除，admin 角色的權限不可修改", err)
+	case errors.Is(err, dto.ErrCannotChangeOwnRole):
+		h.respondError(c, http.StatusForbidden, "CANNOT_CHANGE_OWN_ROLE", "不可變更自己的角色", err)
+	default:
+		h.respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", message, err)
+	}
+}
+
+// respondSuccess 回傳成功回應
+func (h *RoleHandler) respondSuccess(c *gin.Context, statusCode int, message string, data interface{}) {
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
+
+// respondError 回傳錯誤回應
+func (h *RoleHandler) respondError(c *gin.Context, statusCode int, code string, message string, err error) {
+	errorVO := vo.ErrorVO{
+		Code:    code,
+		Message: message,
+	}
+	if err != nil {
+		errorVO.Details = err.Error()
+	}
+
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   false,
+		Message:   "請求處理失敗",
+		Error:     &errorVO,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
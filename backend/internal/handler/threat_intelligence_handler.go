@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dsl"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
@@ -43,6 +46,10 @@ func (h *ThreatIntelligenceHandler) CreateThreat(c *gin.Context) {
 
 	threat, err := h.service.CreateThreat(c.Request.Context(), &req)
 	if err != nil {
+		if isIndicatorError(err) {
+			h.respondError(c, http.StatusBadRequest, "INVALID_INDICATOR", "威脅指標格式錯誤", err)
+			return
+		}
 		h.respondError(c, http.StatusInternalServerError, "CREATE_FAILED", "建立威脅情報失敗", err)
 		return
 	}
@@ -107,7 +114,14 @@ func (h *ThreatIntelligenceHandler) UpdateThreat(c *gin.Context) {
 
 	threat, err := h.service.UpdateThreat(c.Request.Context(), id, &req)
 	if err != nil {
-		h.respondError(c, http.StatusInternalServerError, "UPDATE_FAILED", "更新威脅情報失敗", err)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			h.respondError(c, http.StatusNotFound, "NOT_FOUND", "威脅情報不存在", err)
+		case isValidationError(err):
+			h.respondError(c, http.StatusBadRequest, "VALIDATION_ERROR", "更新內容驗證失敗", err)
+		default:
+			h.respondError(c, http.StatusInternalServerError, "UPDATE_FAILED", "更新威脅情報失敗", err)
+		}
 		return
 	}
 
@@ -135,6 +149,10 @@ func (h *ThreatIntelligenceHandler) DeleteThreat(c *gin.Context) {
 
 	err = h.service.DeleteThreat(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, dto.ErrThreatNotFound) {
+			h.respondError(c, http.StatusNotFound, "NOT_FOUND", "威脅情報不存在", err)
+			return
+		}
 		h.respondError(c, http.StatusInternalServerError, "DELETE_FAILED", "刪除威脅情報失敗", err)
 		return
 	}
@@ -153,6 +171,7 @@ func (h *ThreatIntelligenceHandler) DeleteThreat(c *gin.Context) {
 // @Param severity query string false "嚴重程度" Enums(low, medium, high, critical)
 // @Param source query string false "來源"
 // @Param country_code query string false "國家代碼"
+// @Param query query string false "查詢語言，例如 severity:>=high AND tag:botnet AND country:CN"
 // @Param page query int false "頁碼" default(1)
 // @Param page_size query int false "每頁大小" default(20)
 // @Param sort_by query string false "排序欄位" default(created_at) Enums(created_at, updated_at, last_seen, confidence_score)
@@ -170,6 +189,11 @@ func (h *ThreatIntelligenceHandler) ListThreats(c *gin.Context) {
 
 	threats, err := h.service.ListThreats(c.Request.Context(), &req)
 	if err != nil {
+		var syntaxErr *dsl.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			h.respondQueryError(c, syntaxErr)
+			return
+		}
 		h.respondError(c, http.StatusInternalServerError, "LIST_FAILED", "取得威脅情報列表失敗", err)
 		return
 	}
@@ -229,12 +253,47 @@ func (h *ThreatIntelligenceHandler) LookupDomain(c *gin.Context) {
 	h.respondSuccess(c, http.StatusOK, "域名查詢成功", result)
 }
 
+// LookupIndicator 指標查詢
+// @Summary 指標威脅查詢
+// @Description 依指標類型查詢威脅情報，支援 IP、CIDR、域名、URL、檔案雜湊、Email 與 ASN
+// @Tags Threat Intelligence
+// @Produce json
+// @Param type path string true "指標類型" Enums(ip, ipv4, ipv6, cidr, domain, url, hash, md5, sha1, sha256, email, asn)
+// @Param value query string true "指標值"
+// @Success 200 {object} vo.BaseResponse{data=vo.ThreatIntelligenceIndicatorLookupVO} "查詢成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
+// @Router /api/v1/threats/lookup/{type} [get]
+func (h *ThreatIntelligenceHandler) LookupIndicator(c *gin.Context) {
+	req := dto.ThreatIntelligenceIndicatorLookupRequest{Type: c.Param("type")}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_QUERY", "查詢參數格式錯誤", err)
+		return
+	}
+
+	result, err := h.service.LookupIndicator(c.Request.Context(), &req)
+	if err != nil {
+		if isIndicatorError(err) {
+			h.respondError(c, http.StatusBadRequest, "INVALID_INDICATOR", "威脅指標格式錯誤", err)
+			return
+		}
+		h.respondError(c, http.StatusInternalServerError, "LOOKUP_FAILED", "指標查詢失敗", err)
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "指標查詢成功", result)
+}
+
 // GetStats 取得統計資料
 // @Summary 取得威脅情報統計
-// @Description 取得威脅情報的統計資料
+// @Description 取得指定時間範圍內威脅情報的彙總統計與依時間區間分桶的時間線，時間線可依嚴重程度或類型細分；未指定範圍時依間隔使用預設範圍
 // @Tags Threat Intelligence
 // @Produce json
-// @Param group_by query string false "分組依據" Enums(threat_type, severity, source, country_code)
+// @Param group_by query string false "時間線細分維度" Enums(threat_type, severity, source, country_code, indicator_type)
+// @Param interval query string false "時間線間隔" Enums(hour, day, week) default(day)
+// @Param time_field query string false "時間欄位" Enums(created_at, last_seen) default(created_at)
+// @Param start_time query string false "開始時間 (RFC 3339)"
+// @Param end_time query string false "結束時間 (RFC 3339)"
 // @Success 200 {object} vo.BaseResponse{data=vo.ThreatIntelligenceStatsVO} "取得成功"
 // @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
 // @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
@@ -248,6 +307,10 @@ func (h *ThreatIntelligenceHandler) GetStats(c *gin.Context) {
 
 	stats, err := h.service.GetStats(c.Request.Context(), &req)
 	if err != nil {
+		if errors.Is(err, dto.ErrInvalidDateRange) {
+			h.respondError(c, http.StatusBadRequest, "INVALID_DATE_RANGE", "時間範圍無效", err)
+			return
+		}
 		h.respondError(c, http.StatusInternalServerError, "STATS_FAILED", "取得統計資料失敗", err)
 		return
 	}
@@ -292,12 +355,16 @@ func (h *ThreatIntelligenceHandler) RegisterRoutes(router *gin.RouterGroup) {
 		threats.PUT("/:id", h.UpdateThreat)
 		threats.DELETE("/:id", h.DeleteThreat)
 		threats.POST("/bulk", h.BulkCreateThreats)
+		threats.PUT("/bulk", h.BulkUpdateThreats)
+		threats.DELETE("/bulk", h.BulkDeleteThreats)
+		threats.GET("/search", h.SearchThreats)
 		
 		// 查詢 API
 		lookup := threats.Group("/lookup")
 		{
 			lookup.GET("/ip", h.LookupIP)
 			lookup.GET("/domain", h.LookupDomain)
+			lookup.GET("/:type", h.LookupIndicator)
 		}
 		
 		// 統計 API
@@ -338,6 +405,39 @@ func (h *ThreatIntelligenceHandler) respondError(c *gin.Context, statusCode int,
 	c.JSON(statusCode, response)
 }
 
+// respondQueryError 回應查詢語言語法錯誤，附上錯誤位置
+func (h *ThreatIntelligenceHandler) respondQueryError(c *gin.Context, err *dsl.SyntaxError) {
+	response := vo.BaseResponse{
+		Success: false,
+		Message: "查詢語法錯誤",
+		Error: &vo.ErrorVO{
+			Code:    "INVALID_QUERY_SYNTAX",
+			Message: err.Error(),
+			Details: err,
+		},
+		Timestamp: time.Now(),
+		RequestID: h.getRequestID(c),
+	}
+	c.JSON(http.StatusBadRequest, response)
+}
+
+// isIndicatorError 檢查是否為指標格式錯誤
+func isIndicatorError(err error) bool {
+	return errors.Is(err, dto.ErrInvalidIndicator) ||
+		errors.Is(err, dto.ErrInvalidIndicatorType) ||
+		errors.Is(err, dto.ErrInvalidIPAddress) ||
+		errors.Is(err, dto.ErrInvalidDomain)
+}
+
+// isValidationError 檢查是否為欄位驗證錯誤
+func isValidationError(err error) bool {
+	return errors.Is(err, dto.ErrInvalidThreatType) ||
+		errors.Is(err, dto.ErrInvalidSeverity) ||
+		errors.Is(err, dto.ErrInvalidConfidence) ||
+		errors.Is(err, dto.ErrInvalidCountryCode) ||
+		errors.Is(err, dto.ErrInvalidASN)
+}
+
 // getRequestID 取得請求 ID
 func (h *ThreatIntelligenceHandler) getRequestID(c *gin.Context) string {
 	if requestID := c.GetHeader("X-Request-ID"); requestID != "" {
@@ -359,32 +459,68 @@ func (h *ThreatIntelligenceHandler) parseIntParam(c *gin.Context, param string,
 	return defaultValue
 }
 
+// parseBoolParam 解析布林查詢參數
+func (h *ThreatIntelligenceHandler) parseBoolParam(c *gin.Context, param string) bool {
+	value, err := strconv.ParseBool(c.Query(param))
+	return err == nil && value
+}
+
 // SearchThreats 搜尋威脅情報
+// @Summary 全文搜尋威脅情報
+// @Description 以全文檢索搜尋描述、域名、標籤、ISP 與元資料，支援引號片語、OR 與 -排除，可搭配篩選條件
+// @Tags Threat Intelligence
+// @Produce json
+// @Param q query string true "搜尋字串" example("botnet -test")
+// @Param indicator_type query string false "指標類型" Enums(ipv4, ipv6, cidr, domain, url, md5, sha1, sha256, email, asn)
+// @Param threat_type query string false "威脅類型" Enums(malware, phishing, spam, botnet, scanner, ddos, bruteforce, other)
+// @Param severity query string false "嚴重程度" Enums(low, medium, high, critical)
+// @Param source query string false "來源"
+// @Param country_code query string false "國家代碼"
+// @Param page query int false "頁碼" default(1)
+// @Param page_size query int false "每頁大小" default(20)
+// @Param sort_by query string false "排序欄位" default(relevance) Enums(relevance, created_at, updated_at, last_seen, confidence_score)
+// @Param sort_order query string false "排序順序" default(desc) Enums(asc, desc)
+// @Success 200 {object} vo.BaseResponse{data=vo.ThreatIntelligenceSearchVO} "搜尋成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
+// @Router /api/v1/threats/search [get]
 func (h *ThreatIntelligenceHandler) SearchThreats(c *gin.Context) {
-	query := c.Query("q")
-	if query == "" {
-		h.respondError(c, http.StatusBadRequest, "INVALID_QUERY", "搜尋查詢不能為空", nil)
+	var req dto.ThreatIntelligenceSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_QUERY", "查詢參數格式錯誤", err)
 		return
 	}
+	// 相容舊版的 limit 參數
+	if req.PageSize == 0 {
+		req.PageSize = h.parseIntParam(c, "limit", 0)
+		if req.PageSize < 0 {
+			req.PageSize = 0
+		} else if req.PageSize > 100 {
+			req.PageSize = 100
+		}
+	}
 
-	page := h.parseIntParam(c, "page", 1)
-	limit := h.parseIntParam(c, "limit", 10)
-
-	threats, total, err := h.service.SearchThreats(c.Request.Context(), query, page, limit)
+	result, err := h.service.SearchThreats(c.Request.Context(), &req)
 	if err != nil {
+		if errors.Is(err, dto.ErrInvalidSearchQuery) {
+			h.respondError(c, http.StatusBadRequest, "INVALID_QUERY", "搜尋查詢不能為空", err)
+			return
+		}
 		h.respondError(c, http.StatusInternalServerError, "SEARCH_FAILED", "搜尋威脅情報失敗", err)
 		return
 	}
 
-	h.respondSuccess(c, http.StatusOK, "搜尋完成", gin.H{
-		"threats": threats,
-		"total":   total,
-		"page":    page,
-		"limit":   limit,
-	})
+	h.respondSuccess(c, http.StatusOK, "搜尋完成", result)
 }
 
 // GetStatistics 取得統計資訊
+// @Summary 取得威脅情報整體統計
+// @Description 取得全部威脅情報的數量、風險分布與各維度統計
+// @Tags Threat Intelligence
+// @Produce json
+// @Success 200 {object} vo.BaseResponse{data=vo.ThreatStatisticsVO} "取得成功"
+// @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
+// @Router /api/v1/threat-intelligence/statistics [get]
 func (h *ThreatIntelligenceHandler) GetStatistics(c *gin.Context) {
 	stats, err := h.service.GetStatistics(c.Request.Context())
 	if err != nil {
@@ -396,12 +532,26 @@ func (h *ThreatIntelligenceHandler) GetStatistics(c *gin.Context) {
 }
 
 // BulkUpdateThreats 批量更新威脅情報
+// @Summary 批量更新威脅情報
+// @Description 在單一交易中更新多筆威脅情報，回傳每個項目的結果；atomic=true 時任一項目失敗即全部回滾
+// @Tags Threat Intelligence
+// @Accept json
+// @Produce json
+// @Param atomic query bool false "任一項目失敗即全部回滾"
+// @Param request body dto.ThreatIntelligenceBulkUpdateRequest true "批量更新請求"
+// @Success 200 {object} vo.BaseResponse{data=vo.ThreatIntelligenceBulkUpdateVO} "處理完成"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
+// @Router /api/v1/threats/bulk [put]
 func (h *ThreatIntelligenceHandler) BulkUpdateThreats(c *gin.Context) {
 	var req dto.ThreatIntelligenceBulkUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
 		return
 	}
+	if h.parseBoolParam(c, "atomic") {
+		req.Atomic = true
+	}
 
 	result, err := h.service.BulkUpdateThreats(c.Request.Context(), &req)
 	if err != nil {
@@ -413,12 +563,26 @@ func (h *ThreatIntelligenceHandler) BulkUpdateThreats(c *gin.Context) {
 }
 
 // BulkDeleteThreats 批量刪除威脅情報
+// @Summary 批量刪除威脅情報
+// @Description 在單一交易中刪除多筆威脅情報，回傳每個項目的結果；atomic=true 時任一項目失敗即全部回滾
+// @Tags Threat Intelligence
+// @Accept json
+// @Produce json
+// @Param atomic query bool false "任一項目失敗即全部回滾"
+// @Param request body dto.ThreatIntelligenceBulkDeleteRequest true "批量刪除請求"
+// @Success 200 {object} vo.BaseResponse{data=vo.ThreatIntelligenceBulkDeleteVO} "處理完成"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 500 {object} vo.BaseResponse{error=vo.ErrorVO} "內部服務器錯誤"
+// @Router /api/v1/threats/bulk [delete]
 func (h *ThreatIntelligenceHandler) BulkDeleteThreats(c *gin.Context) {
 	var req dto.ThreatIntelligenceBulkDeleteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
 		return
 	}
+	if h.parseBoolParam(c, "atomic") {
+		req.Atomic = true
+	}
 
 	result, err := h.service.BulkDeleteThreats(c.Request.Context(), &req)
 	if err != nil {
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/service"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// WebhookHandler Webhook 訂閱處理器
+type WebhookHandler struct {
+	service service.WebhookService
+}
+
+// NewWebhookHandler 建立 Webhook 訂閱處理器
+func NewWebhookHandler(service service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// ListWebhooks 取得 Webhook 訂閱列表
+// @Summary 取得 Webhook 訂閱列表
+// @Description 取得目前使用者註冊的 Webhook 訂閱及其投遞狀態
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {object} vo.BaseResponse{data=[]vo.WebhookVO} "取得成功"
+// @Failure 401 {object} vo.BaseResponse{error=vo.ErrorVO} "未授權"
+// @Security BearerAuth
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.service.ListWebhooks(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err, "取得 Webhook 列表失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得 Webhook 列表成功", webhooks)
+}
+
+// GetWebhook 取得 Webhook 訂閱
+// @Summary 取得 Webhook 訂閱
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Success 200 {object} vo.BaseResponse{data=vo.WebhookVO} "取得成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.GetWebhook(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err, "取得 Webhook 失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得 Webhook 成功", webhook)
+}
+
+// CreateWebhook 建立 Webhook 訂閱
+// @Summary 建立 Webhook 訂閱
+// @Description 註冊接收威脅事件的端點。請求本文為 ThreatNotification JSON，並附上 X-Webhook-Timestamp 與 X-Webhook-Signature（sha256=HMAC-SHA256(secret, "<timestamp>.<body>")）。簽章密鑰只會在建立時回傳一次
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param request body dto.WebhookCreateRequest true "Webhook 建立請求"
+// @Success 201 {object} vo.BaseResponse{data=vo.WebhookSecretVO} "建立成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Security BearerAuth
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.WebhookCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, err, "建立 Webhook 失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusCreated, "Webhook 建立成功，請妥善保存簽章密鑰", webhook)
+}
+
+// UpdateWebhook 更新 Webhook 訂閱
+// @Summary 更新 Webhook 訂閱
+// @Description 更新端點、過濾條件或啟用狀態；重新啟用被自動停用的 Webhook 會重設連續失敗次數
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Param request body dto.WebhookUpdateRequest true "Webhook 更新請求"
+// @Success 200 {object} vo.BaseResponse{data=vo.WebhookVO} "更新成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.WebhookUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	webhook, err := h.service.UpdateWebhook(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "更新 Webhook 失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "Webhook 更新成功", webhook)
+}
+
+// DeleteWebhook 刪除 Webhook 訂閱
+// @Summary 刪除 Webhook 訂閱
+// @Description 刪除 Webhook 訂閱及其投遞紀錄
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Success 200 {object} vo.BaseResponse "刪除成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhook(c.Request.Context(), userID, id); err != nil {
+		h.handleError(c, err, "刪除 Webhook 失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "Webhook 刪除成功", nil)
+}
+
+// RotateSecret 輪替簽章密鑰
+// @Summary 輪替 Webhook 簽章密鑰
+// @Description 產生新的簽章密鑰，舊密鑰立即失效；新密鑰只會在此回應中出現一次
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Success 200 {object} vo.BaseResponse{data=vo.WebhookSecretVO} "輪替成功"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.RotateSecret(c.Request.Context(), userID, id)
+	if err != nil {
+		h.handleError(c, err, "輪替簽章密鑰失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "簽章密鑰已輪替，請妥善保存新密鑰", webhook)
+}
+
+// ListDeliveries 取得 Webhook 投遞紀錄
+// @Summary 取得 Webhook 投遞紀錄
+// @Description 依狀態篩選投遞紀錄，包含嘗試次數、回應狀態碼與錯誤訊息
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Param status query string false "投遞狀態" Enums(pending, succeeded, failed)
+// @Param page query int false "頁碼" default(1)
+// @Param page_size query int false "每頁數量" default(20)
+// @Success 200 {object} vo.BaseResponse{data=vo.WebhookDeliveryListVO} "取得成功"
+// @Failure 400 {object} vo.BaseResponse{error=vo.ErrorVO} "請求參數錯誤"
+// @Failure 404 {object} vo.BaseResponse{error=vo.ErrorVO} "資源不存在"
+// @Security BearerAuth
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	userID, id, ok := h.parseRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.WebhookDeliveryQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_REQUEST", "請求參數格式錯誤", err)
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "取得投遞紀錄失敗")
+		return
+	}
+
+	h.respondSuccess(c, http.StatusOK, "取得投遞紀錄成功", deliveries)
+}
+
+// RegisterRoutes 註冊路由
+func (h *WebhookHandler) RegisterRoutes(router *gin.RouterGroup) {
+	webhooks := router.Group("/webhooks")
+	{
+		webhooks.GET("", h.ListWebhooks)
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("/:id", h.GetWebhook)
+		webhooks.PUT("/:id", h.UpdateWebhook)
+		webhooks.DELETE("/:id", h.DeleteWebhook)
+		webhooks.POST("/:id/rotate-secret", h.RotateSecret)
+		webhooks.GET("/:id/deliveries", h.ListDeliveries)
+	}
+}
+
+// userID 取得目前使用者 ID
+func (h *WebhookHandler) userID(c *gin.Context) (uuid.UUID, bool) {
+	userID, ok := c.Get("user_id")
+	if id, valid := userID.(uuid.UUID); ok && valid {
+		return id, true
+	}
+	h.respondError(c, http.StatusUnauthorized, "UNAUTHORIZED", "使用者未認證", nil)
+	return uuid.Nil, false
+}
+
+// parseRequest 取得目前使用者 ID 與路徑中的 Webhook ID
+func (h *WebhookHandler) parseRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := h.userID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.respondError(c, http.StatusBadRequest, "INVALID_UUID", "無效的 UUID 格式", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+// handleError 依錯誤類型回傳適當的狀態碼
+func (h *WebhookHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, dto.ErrWebhookNotFound):
+		h.respondError(c, http.StatusNotFound, "WEBHOOK_NOT_FOUND", "Webhook 不存在", err)
+	case errors.Is(err, dto.ErrInvalidWebhookURL):
+		h.respondError(c, http.StatusBadRequest, "INVALID_WEBHOOK_URL", "Webhook URL 必須使用 http 或 https", err)
+	case errors.Is(err, dto.ErrWebhookURLNotAllowed):
+		h.respondError(c, http.StatusBadRequest, "WEBHOOK_URL_NOT_ALLOWED", "Webhook URL 不可指向本機、內部網路或雲端中繼資料位址", err)
+	default:
+		h.respondError(c, http.StatusInternalServerError, "INTERNAL_ERROR", message, err)
+	}
+}
+
+// respondSuccess 回傳成功回應
+func (h *WebhookHandler) respondSuccess(c *gin.Context, statusCode int, message string, data interface{}) {
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
+
+// respondError 回傳錯誤回應
+func (h *WebhookHandler) respondError(c *gin.Context, statusCode int, code string, message string, err error) {
+	errorVO := vo.ErrorVO{
+		Code:    code,
+		Message: message,
+	}
+	if err != nil {
+		errorVO.Details = err.Error()
+	}
+
+	c.JSON(statusCode, vo.BaseResponse{
+		Success:   false,
+		Message:   "請求處理失敗",
+		Error:     &errorVO,
+		Timestamp: time.Now(),
+		RequestID: c.GetString("request_id"),
+	})
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// 信箱驗證政策的限制範圍
+const (
+	EmailVerificationScopeWrite = "write" // 未驗證時只能讀取
+	EmailVerificationScopeAll   = "all"   // 未驗證時不能使用受保護的功能
+)
+
+// EmailVerificationChecker 查詢使用者信箱是否已驗證
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// ParseEmailVerificationPolicy 解析「角色:範圍」格式的政策設定，例如 basic:write、premium:all
+func ParseEmailVerificationPolicy(entries []string) (map[string]string, error) {
+	policy := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		role, scope, ok := strings.Cut(entry, ":")
+		role, scope = strings.TrimSpace(role), strings.TrimSpace(scope)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("invalid email verification policy %q, expected role:scope", entry)
+		}
+		if scope != EmailVerificationScopeWrite && scope != EmailVerificationScopeAll {
+			return nil, fmt.Errorf("invalid email verification scope %q for role %s", scope, role)
+		}
+		policy[role] = scope
+	}
+	return policy, nil
+}
+
+// RequireVerifiedEmailMiddleware 依角色要求已驗證信箱的中介軟體，須放在認證中介軟體之後
+// policy 為角色對應的限制範圍，未列出的角色不受限制；驗證狀態每次查詢，驗證後立即生效不需重新登入
+func RequireVerifiedEmailMiddleware(checker EmailVerificationChecker, policy map[string]string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		role, _ := c.Get("role")
+		roleName, _ := role.(string)
+		scope, restricted := policy[roleName]
+		if !restricted || (scope == EmailVerificationScopeWrite && isReadOnlyMethod(c.Request.Method)) {
+			c.Next()
+			return
+		}
+
+		value, exists := c.Get("user_id")
+		userID, ok := value.(uuid.UUID)
+		if !exists || !ok {
+			respondUnauthorized(c, "UNAUTHORIZED", "User not authenticated")
+			return
+		}
+
+		verified, err := checker.IsEmailVerified(c.Request.Context(), userID)
+		if err != nil {
+			pkglogger.Error("Email verification check failed", pkglogger.Fields{
+				"error":   err.Error(),
+				"user_id": userID,
+			})
+			respondError(c, http.StatusInternalServerError, "Authentication error", "AUTH_ERROR", "Unable to verify email status")
+			return
+		}
+		if !verified {
+			respondForbidden(c, "EMAIL_NOT_VERIFIED", "Please verify your email address before using this feature")
+			return
+		}
+
+		c.Next()
+	})
+}
+
+// isReadOnlyMethod 判斷是否為不會變更資料的 HTTP 方法
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
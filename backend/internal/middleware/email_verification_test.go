@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubVerification 依使用者回傳固定的驗證狀態
+type stubVerification map[uuid.UUID]bool
+
+func (s stubVerification) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return s[userID], nil
+}
+
+func TestRequireVerifiedEmailMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verifiedUser, unverifiedUser := uuid.New(), uuid.New()
+	checker := stubVerification{verifiedUser: true}
+
+	policy, err := ParseEmailVerificationPolicy([]string{"basic:write", " premium : all "})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		role       string
+		userID     uuid.UUID
+		method     string
+		wantStatus int
+	}{
+		{name: "write scope allows reads", role: "basic", userID: unverifiedUser, method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "write scope blocks writes", role: "basic", userID: unverifiedUser, method: http.MethodPost, wantStatus: http.StatusForbidden},
+		{name: "all scope blocks reads", role: "premium", userID: unverifiedUser, method: http.MethodGet, wantStatus: http.StatusForbidden},
+		{name: "verified user passes", role: "premium", userID: verifiedUser, method: http.MethodDelete, wantStatus: http.StatusOK},
+		{name: "role without policy passes", role: "admin", userID: unverifiedUser, method: http.MethodPost, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("user_id", tt.userID)
+				c.Set("role", tt.role)
+			})
+			r.Use(RequireVerifiedEmailMiddleware(checker, policy))
+			r.Handle(tt.method, "/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, "/", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	_, err = ParseEmailVerificationPolicy([]string{"basic:premium"})
+	assert.Error(t, err)
+}
@@ -1,10 +1,14 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
 	pkgjwt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/jwt"
 	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
@@ -27,10 +31,10 @@ func JWTAuthMiddleware(jwtManager *pkgjwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// 驗證令牌
-		claims, err := jwtManager.VerifyToken(tokenString)
+		// 驗證令牌並檢查是否已撤銷
+		claims, err := jwtManager.VerifyTokenContext(c.Request.Context(), tokenString)
 		if err != nil {
-			respondUnauthorized(c, "INVALID_TOKEN", "Invalid or expired token")
+			respondTokenError(c, err)
 			return
 		}
 
@@ -68,7 +72,7 @@ func OptionalJWTAuthMiddleware(jwtManager *pkgjwt.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := jwtManager.VerifyToken(tokenString)
+		claims, err := jwtManager.VerifyTokenContext(c.Request.Context(), tokenString)
 		if err != nil {
 			c.Next()
 			return
@@ -85,83 +89,49 @@ func OptionalJWTAuthMiddleware(jwtManager *pkgjwt.JWTManager) gin.HandlerFunc {
 	})
 }
 
-// RequireRoleMiddleware 角色授權中介軟體
-func RequireRoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		userRole, exists := c.Get("role")
-		if !exists {
-			respondForbidden(c, "MISSING_ROLE", "User role not found in context")
-			return
-		}
-
-		role, ok := userRole.(string)
-		if !ok {
-			respondForbidden(c, "INVALID_ROLE", "Invalid user role format")
-			return
-		}
-
-		// 檢查是否有允許的角色
-		for _, allowedRole := range allowedRoles {
-			if role == allowedRole {
-				c.Next()
-				return
-			}
-		}
-
-		respondForbidden(c, "INSUFFICIENT_PERMISSIONS", "Insufficient permissions for this action")
-	})
-}
-
-// RequireAdminMiddleware 要求管理員權限的中介軟體
-func RequireAdminMiddleware() gin.HandlerFunc {
-	return RequireRoleMiddleware("admin")
-}
-
-// RequirePremiumMiddleware 要求進階用戶權限的中介軟體
-func RequirePremiumMiddleware() gin.HandlerFunc {
-	return RequireRoleMiddleware("admin", "premium")
+// APIKeyAuthenticator API金鑰驗證介面，驗證成功時回傳含所屬使用者的金鑰資料
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error)
 }
 
 // APIKeyAuthMiddleware API金鑰認證中介軟體
-func APIKeyAuthMiddleware() gin.HandlerFunc {
+func APIKeyAuthMiddleware(apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		// 從標頭或查詢參數提取API金鑰
-		apiKey := c.GetHeader("X-API-Key")
-		if apiKey == "" {
-			apiKey = c.Query("api_key")
-		}
-
+		apiKey := extractAPIKey(c)
 		if apiKey == "" {
 			respondUnauthorized(c, "MISSING_API_KEY", "API key is required")
 			return
 		}
 
-		// TODO: 實作API金鑰驗證邏輯
-		// 這裡需要查詢資料庫驗證API金鑰的有效性
-		
-		pkglogger.Debug("API key authentication", pkglogger.Fields{
-			"api_key_prefix": maskAPIKey(apiKey),
-		})
+		if !authenticateAPIKey(c, apiKeys, apiKey) {
+			return
+		}
 
 		c.Next()
 	})
 }
 
 // CombinedAuthMiddleware 組合認證中介軟體（JWT或API金鑰）
-func CombinedAuthMiddleware(jwtManager *pkgjwt.JWTManager) gin.HandlerFunc {
+func CombinedAuthMiddleware(jwtManager *pkgjwt.JWTManager, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		// 優先檢查JWT
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
 			tokenString, err := pkgjwt.ExtractTokenFromHeader(authHeader)
 			if err == nil {
-				claims, err := jwtManager.VerifyToken(tokenString)
+				claims, err := jwtManager.VerifyTokenContext(c.Request.Context(), tokenString)
+				if errors.Is(err, pkgjwt.ErrRevocationCheckFailed) {
+					respondTokenError(c, err)
+					return
+				}
 				if err == nil {
 					// JWT認證成功
 					c.Set("user_id", claims.UserID)
 					c.Set("username", claims.Username)
 					c.Set("email", claims.Email)
 					c.Set("role", claims.Role)
+					c.Set("token_claims", claims)
 					c.Set("auth_method", "jwt")
 					c.Next()
 					return
@@ -170,22 +140,78 @@ func CombinedAuthMiddleware(jwtManager *pkgjwt.JWTManager) gin.HandlerFunc {
 		}
 
 		// 檢查API金鑰
-		apiKey := c.GetHeader("X-API-Key")
-		if apiKey == "" {
-			apiKey = c.Query("api_key")
-		}
-
-		if apiKey != "" {
-			// TODO: 驗證API金鑰
-			c.Set("auth_method", "api_key")
+		if apiKey := extractAPIKey(c); apiKey != "" {
+			if !authenticateAPIKey(c, apiKeys, apiKey) {
+				return
+			}
 			c.Next()
 			return
 		}
 
+		if authHeader != "" {
+			respondUnauthorized(c, "INVALID_TOKEN", "Invalid or expired token")
+			return
+		}
 		respondUnauthorized(c, "MISSING_AUTH", "JWT token or API key is required")
 	})
 }
 
+// extractAPIKey 從標頭或查詢參數提取API金鑰
+func extractAPIKey(c *gin.Context) string {
+	apiKey := strings.TrimSpace(c.GetHeader("X-API-Key"))
+	if apiKey == "" {
+		apiKey = strings.TrimSpace(c.Query("api_key"))
+	}
+	return apiKey
+}
+
+// authenticateAPIKey 驗證API金鑰並以與JWT相同的鍵設定使用者資訊，失敗時回應錯誤並回傳 false
+func authenticateAPIKey(c *gin.Context, apiKeys APIKeyAuthenticator, rawKey string) bool {
+	apiKey, err := apiKeys.Authenticate(c.Request.Context(), rawKey)
+	if err != nil {
+		pkglogger.Debug("API key authentication failed", pkglogger.Fields{
+			"api_key_prefix": maskAPIKey(rawKey),
+			"error":          err.Error(),
+		})
+
+		switch {
+		case errors.Is(err, dto.ErrInvalidAPIKey):
+			respondUnauthorized(c, "INVALID_API_KEY", "Invalid API key")
+		case errors.Is(err, dto.ErrAPIKeyInactive):
+			respondUnauthorized(c, "API_KEY_INACTIVE", "API key is inactive")
+		case errors.Is(err, dto.ErrAPIKeyExpired):
+			respondUnauthorized(c, "API_KEY_EXPIRED", "API key has expired")
+		case errors.Is(err, dto.ErrUserInactive):
+			respondUnauthorized(c, "USER_INACTIVE", "User account is inactive")
+		case errors.Is(err, dto.ErrAPIKeyQuotaExceeded):
+			respondError(c, http.StatusTooManyRequests, "Quota exceeded", "API_KEY_QUOTA_EXCEEDED", "API key quota exceeded")
+		default:
+			pkglogger.Error("API key authentication error", pkglogger.Fields{
+				"api_key_prefix": maskAPIKey(rawKey),
+				"error":          err.Error(),
+			})
+			respondError(c, http.StatusInternalServerError, "Authentication failed", "AUTH_ERROR", "Failed to authenticate API key")
+		}
+		return false
+	}
+
+	// 將使用者資訊設定到上下文，鍵與型別與JWT認證一致
+	c.Set("user_id", apiKey.User.ID)
+	c.Set("username", apiKey.User.Username)
+	c.Set("email", apiKey.User.Email)
+	c.Set("role", string(apiKey.User.Role))
+	c.Set("auth_method", "api_key")
+	c.Set("api_key_id", apiKey.ID)
+
+	pkglogger.Debug("API key authentication successful", pkglogger.Fields{
+		"user_id":    apiKey.User.ID,
+		"username":   apiKey.User.Username,
+		"role":       apiKey.User.Role,
+		"api_key_id": apiKey.ID,
+	})
+	return true
+}
+
 // respondUnauthorized 回應未授權錯誤
 func respondUnauthorized(c *gin.Context, code string, message string) {
 	errorVO := vo.ErrorVO{
@@ -220,6 +246,38 @@ func respondForbidden(c *gin.Context, code string, message string) {
 	c.Abort()
 }
 
+// respondTokenError 依令牌驗證錯誤回應；撤銷儲存無法查詢時回應 503 而非放行
+func respondTokenError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, pkgjwt.ErrTokenRevoked):
+		respondUnauthorized(c, "TOKEN_REVOKED", "Token has been revoked")
+	case errors.Is(err, pkgjwt.ErrRevocationCheckFailed):
+		pkglogger.Error("Token revocation check failed", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		respondError(c, http.StatusServiceUnavailable, "Authentication unavailable", "AUTH_UNAVAILABLE", "Unable to verify token, please retry later")
+	default:
+		respondUnauthorized(c, "INVALID_TOKEN", "Invalid or expired token")
+	}
+}
+
+// respondError 回應指定狀態碼的錯誤
+func respondError(c *gin.Context, statusCode int, message string, code string, detail string) {
+	errorVO := vo.ErrorVO{
+		Code:    code,
+		Message: detail,
+	}
+
+	response := vo.BaseResponse{
+		Success: false,
+		Message: message,
+		Error:   &errorVO,
+	}
+
+	c.JSON(statusCode, response)
+	c.Abort()
+}
+
 // maskAPIKey 遮蔽API金鑰敏感資訊
 func maskAPIKey(apiKey string) string {
 	if len(apiKey) <= 8 {
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	pkgjwt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/jwt"
+)
+
+// stubAPIKeys 依原始金鑰回傳固定結果
+type stubAPIKeys map[string]error
+
+func (s stubAPIKeys) Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error) {
+	err, ok := s[rawKey]
+	if !ok {
+		return nil, dto.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.APIKey{
+		ID:   uuid.New(),
+		User: model.User{ID: uuid.New(), Username: "svc", Email: "svc@example.com", Role: model.RolePremium},
+	}, nil
+}
+
+func TestCombinedAuthMiddleware_APIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtManager := pkgjwt.NewJWTManager("test-secret", "test", 1)
+	apiKeys := stubAPIKeys{
+		"valid-key":     nil,
+		"inactive-key":  dto.ErrAPIKeyInactive,
+		"expired-key":   dto.ErrAPIKeyExpired,
+		"exhausted-key": dto.ErrAPIKeyQuotaExceeded,
+	}
+
+	r := gin.New()
+	r.GET("/", CombinedAuthMiddleware(jwtManager, apiKeys), func(c *gin.Context) {
+		_, hasUserID := c.Get("user_id")
+		c.JSON(http.StatusOK, gin.H{
+			"has_user_id": hasUserID,
+			"role":        c.GetString("role"),
+			"auth_method": c.GetString("auth_method"),
+		})
+	})
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		wantStatus int
+	}{
+		{name: "missing credentials", wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", apiKey: "wrong", wantStatus: http.StatusUnauthorized},
+		{name: "inactive key", apiKey: "inactive-key", wantStatus: http.StatusUnauthorized},
+		{name: "expired key", apiKey: "expired-key", wantStatus: http.StatusUnauthorized},
+		{name: "quota exceeded", apiKey: "exhausted-key", wantStatus: http.StatusTooManyRequests},
+		{name: "valid key", apiKey: "valid-key", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.JSONEq(t, `{"has_user_id":true,"role":"premium","auth_method":"api_key"}`, w.Body.String())
+			}
+		})
+	}
+}
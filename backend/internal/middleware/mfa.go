@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	pkgjwt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/jwt"
+)
+
+// RequireMFAMiddleware 要求指定角色以通過多因素驗證的登入存取，須放在認證中介軟體之後
+// 只檢查 JWT；API 金鑰只能在已通過多因素驗證的工作階段中管理，以金鑰存取時不再要求
+func RequireMFAMiddleware(roles []string) gin.HandlerFunc {
+	required := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		if role = strings.TrimSpace(role); role != "" {
+			required[role] = true
+		}
+	}
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		value, exists := c.Get("token_claims")
+		claims, ok := value.(*pkgjwt.JWTClaims)
+		if !exists || !ok || !required[claims.Role] || claims.MFA {
+			c.Next()
+			return
+		}
+
+		respondForbidden(c, "MFA_REQUIRED", "Multi-factor authentication is required for your role, please enable it and log in again")
+	})
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	pkgjwt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/jwt"
+)
+
+func TestRequireMFAMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		claims     *pkgjwt.JWTClaims
+		wantStatus int
+	}{
+		{name: "required role without mfa", claims: &pkgjwt.JWTClaims{Role: "admin"}, wantStatus: http.StatusForbidden},
+		{name: "required role with mfa", claims: &pkgjwt.JWTClaims{Role: "admin", MFA: true}, wantStatus: http.StatusOK},
+		{name: "role not required", claims: &pkgjwt.JWTClaims{Role: "basic"}, wantStatus: http.StatusOK},
+		{name: "api key access", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set("token_claims", tt.claims)
+				}
+			})
+			r.Use(RequireMFAMiddleware([]string{"admin"}))
+			r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
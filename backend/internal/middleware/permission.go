@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// PermissionChecker 查詢角色是否具備權限
+type PermissionChecker interface {
+	HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error)
+}
+
+// PermissionGuard 依所需權限產生授權中介軟體，供路由宣告端點需要的權限
+type PermissionGuard func(permissions ...string) gin.HandlerFunc
+
+// NewPermissionGuard 建立權限授權
+func NewPermissionGuard(checker PermissionChecker) PermissionGuard {
+	return func(permissions ...string) gin.HandlerFunc {
+		return RequirePermissionMiddleware(checker, permissions...)
+	}
+}
+
+// RequirePermissionMiddleware 要求目前角色具備所有指定權限，須放在認證中介軟體之後
+// 權限依角色即時查詢，管理員調整角色權限後不需重新登入即生效
+func RequirePermissionMiddleware(checker PermissionChecker, permissions ...string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		role := c.GetString("role")
+		if role == "" {
+			respondForbidden(c, "MISSING_ROLE", "User role not found in context")
+			return
+		}
+
+		allowed, err := checker.HasPermissions(c.Request.Context(), role, permissions...)
+		if err != nil {
+			pkglogger.Error("Permission check failed", pkglogger.Fields{
+				"error": err.Error(),
+				"role":  role,
+			})
+			respondError(c, http.StatusInternalServerError, "Authorization error", "AUTHZ_ERROR", "Unable to verify permissions")
+			return
+		}
+		if !allowed {
+			pkglogger.Warn("Permission denied", pkglogger.Fields{
+				"user_id":     c.Value("user_id"),
+				"role":        role,
+				"permissions": permissions,
+				"path":        c.FullPath(),
+			})
+			respondForbidden(c, "INSUFFICIENT_PERMISSIONS", "Insufficient permissions for this action")
+			return
+		}
+
+		c.Next()
+	})
+}
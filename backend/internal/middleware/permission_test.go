@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubPermissions 固定的角色權限
+type stubPermissions map[string][]string
+
+func (s stubPermissions) HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error) {
+	if role == "broken" {
+		return false, errors.New("database unavailable")
+	}
+	for _, permission := range permissions {
+		found := false
+		for _, granted := range s[role] {
+			found = found || granted == permission
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func TestRequirePermissionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	require := NewPermissionGuard(stubPermissions{
+		"analyst": {"threat:read", "threat:write"},
+		"viewer":  {"threat:read"},
+	})
+
+	tests := []struct {
+		name       string
+		role       string
+		wantStatus int
+	}{
+		{name: "all permissions granted", role: "analyst", wantStatus: http.StatusOK},
+		{name: "missing one permission", role: "viewer", wantStatus: http.StatusForbidden},
+		{name: "unknown role", role: "guest", wantStatus: http.StatusForbidden},
+		{name: "unauthenticated", wantStatus: http.StatusForbidden},
+		{name: "checker error", role: "broken", wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				if tt.role != "" {
+					c.Set("role", tt.role)
+				}
+			})
+			r.POST("/", require("threat:read", "threat:write"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
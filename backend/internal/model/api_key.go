@@ -9,16 +9,19 @@ import (
 
 // APIKey API 金鑰模型
 type APIKey struct {
-	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	UserID    uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
-	KeyHash   string     `gorm:"type:varchar(255);not null" json:"-"`
-	Name      string     `gorm:"type:varchar(100);not null" json:"name"`
-	IsActive  bool       `gorm:"default:true" json:"is_active"`
-	ExpiresAt *time.Time `gorm:"column:expires_at" json:"expires_at"`
-	Quota     int        `gorm:"default:1000" json:"quota"`
-	Usage     int        `gorm:"default:0" json:"usage"`
-	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
-	LastUsed  *time.Time `gorm:"column:last_used" json:"last_used"`
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	KeyHash       string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_api_keys_key_hash" json:"-"`
+	KeyPreview    string     `gorm:"type:varchar(64);not null;default:''" json:"key_preview"` // 遮蔽後的金鑰，供使用者辨識
+	Name          string     `gorm:"type:varchar(100);not null" json:"name"`
+	IsActive      bool       `gorm:"default:true" json:"is_active"`
+	ExpiresAt     *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	Quota         int        `gorm:"default:1000" json:"quota"`
+	Usage         int        `gorm:"default:0" json:"usage"`
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	LastUsed      *time.Time `gorm:"column:last_used" json:"last_used"`
+	RevokedAt     *time.Time `gorm:"column:revoked_at" json:"revoked_at"`                     // 撤銷後不可再啟用
+	RotatedFromID *uuid.UUID `gorm:"type:uuid;column:rotated_from_id" json:"rotated_from_id"` // 輪替前的舊金鑰
 
 	// 關聯
 	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
@@ -45,6 +48,18 @@ func (a *APIKey) IsExpired() bool {
 	return a.ExpiresAt.Before(time.Now())
 }
 
+// IsRevoked 檢查金鑰是否已撤銷
+func (a *APIKey) IsRevoked() bool {
+	return a.RevokedAt != nil
+}
+
+// Revoke 撤銷金鑰，立即停止使用
+func (a *APIKey) Revoke() {
+	now := time.Now()
+	a.IsActive = false
+	a.RevokedAt = &now
+}
+
 // IsValid 檢查金鑰是否有效
 func (a *APIKey) IsValid() bool {
 	return a.IsActive && !a.IsExpired()
@@ -63,4 +78,4 @@ func (a *APIKey) IncrementUsage() {
 	a.Usage++
 	now := time.Now()
 	a.LastUsed = &now
-} 
\ No newline at end of file
+}
@@ -25,6 +25,8 @@ type CollectionJob struct {
 	StartedAt        *time.Time       `gorm:"column:started_at" json:"started_at"`
 	CompletedAt      *time.Time       `gorm:"column:completed_at" json:"completed_at"`
 	RecordsCollected int              `gorm:"default:0" json:"records_collected"`
+	RecordsCreated   int              `gorm:"default:0" json:"records_created"`
+	RecordsUpdated   int              `gorm:"default:0" json:"records_updated"`
 	ErrorMessage     *string          `gorm:"type:text" json:"error_message"`
 	CreatedAt        time.Time        `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt        time.Time        `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmailVerificationToken 信箱驗證令牌，只保存令牌雜湊
+// 令牌綁定發送時的信箱，使用者之後變更信箱，舊信件中的連結即無法驗證新信箱
+type EmailVerificationToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_email_verification_tokens_user_created,priority:1" json:"user_id"`
+	Email     string     `gorm:"type:varchar(100);not null" json:"email"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_email_verification_tokens_token_hash" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"` // 已使用或因重新發送而作廢的時間
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP;index:idx_email_verification_tokens_user_created,priority:2" json:"created_at"`
+
+	// 關聯
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定資料表名稱
+func (EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
+
+// BeforeCreate 在建立前執行
+func (t *EmailVerificationToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsUsable 檢查令牌是否尚未使用且未過期
+func (t *EmailVerificationToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
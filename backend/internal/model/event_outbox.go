@@ -0,0 +1,37 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxStatus outbox 事件投遞狀態
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDelivered OutboxStatus = "delivered"
+	OutboxDead      OutboxStatus = "dead" // 超過重試上限，需人工處理
+)
+
+// EventOutbox 待投遞事件，與資料異動寫在同一交易中，由 relay 投遞到各接收端
+type EventOutbox struct {
+	ID             int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventType      string          `gorm:"type:varchar(20);not null" json:"event_type"`
+	AggregateID    uuid.UUID       `gorm:"type:uuid;not null" json:"aggregate_id"`
+	Payload        json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+	Status         OutboxStatus    `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Attempts       int             `gorm:"not null;default:0" json:"attempts"`
+	DeliveredSinks StringArray     `gorm:"type:text[]" json:"delivered_sinks"` // 已成功投遞的接收端，重試時略過
+	LastError      *string         `gorm:"type:text" json:"last_error"`
+	NextAttemptAt  time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP" json:"next_attempt_at"`
+	CreatedAt      time.Time       `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	DeliveredAt    *time.Time      `gorm:"column:delivered_at" json:"delivered_at"`
+}
+
+// TableName 指定資料表名稱
+func (EventOutbox) TableName() string {
+	return "event_outbox"
+}
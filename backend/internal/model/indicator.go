@@ -0,0 +1,257 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IndicatorType 指標類型
+type IndicatorType string
+
+const (
+	IndicatorIPv4   IndicatorType = "ipv4"
+	IndicatorIPv6   IndicatorType = "ipv6"
+	IndicatorCIDR   IndicatorType = "cidr"
+	IndicatorDomain IndicatorType = "domain"
+	IndicatorURL    IndicatorType = "url"
+	IndicatorMD5    IndicatorType = "md5"
+	IndicatorSHA1   IndicatorType = "sha1"
+	IndicatorSHA256 IndicatorType = "sha256"
+	IndicatorEmail  IndicatorType = "email"
+	IndicatorASN    IndicatorType = "asn"
+)
+
+// ErrInvalidIndicator 指標值與類型不符
+var ErrInvalidIndicator = errors.New("invalid indicator")
+
+var (
+	domainLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	hexPattern         = regexp.MustCompile(`^[0-9a-f]+$`)
+)
+
+// IndicatorTypes 取得所有支援的指標類型
+func IndicatorTypes() []IndicatorType {
+	return []IndicatorType{
+		IndicatorIPv4, IndicatorIPv6, IndicatorCIDR, IndicatorDomain, IndicatorURL,
+		IndicatorMD5, IndicatorSHA1, IndicatorSHA256, IndicatorEmail, IndicatorASN,
+	}
+}
+
+// IsValid 檢查指標類型是否受支援
+func (t IndicatorType) IsValid() bool {
+	for _, valid := range IndicatorTypes() {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIP 檢查是否為單一 IP 類型
+func (t IndicatorType) IsIP() bool {
+	return t == IndicatorIPv4 || t == IndicatorIPv6
+}
+
+// IsHash 檢查是否為檔案雜湊類型
+func (t IndicatorType) IsHash() bool {
+	return t == IndicatorMD5 || t == IndicatorSHA1 || t == IndicatorSHA256
+}
+
+// NormalizeIndicator 驗證並正規化指標值，回傳標準格式
+func NormalizeIndicator(t IndicatorType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%w: empty %s", ErrInvalidIndicator, t)
+	}
+
+	switch t {
+	case IndicatorIPv4, IndicatorIPv6:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", fmt.Errorf("%w: %q is not an IP address", ErrInvalidIndicator, value)
+		}
+		if (ip.To4() != nil) != (t == IndicatorIPv4) {
+			return "", fmt.Errorf("%w: %q is not %s", ErrInvalidIndicator, value, t)
+		}
+		return ip.String(), nil
+
+	case IndicatorCIDR:
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q is not a CIDR block", ErrInvalidIndicator, value)
+		}
+		return network.String(), nil
+
+	case IndicatorDomain:
+		return normalizeDomain(value)
+
+	case IndicatorURL:
+		return normalizeURL(value)
+
+	case IndicatorMD5, IndicatorSHA1, IndicatorSHA256:
+		hash := strings.ToLower(value)
+		if len(hash) != hashLength(t) || !hexPattern.MatchString(hash) {
+			return "", fmt.Errorf("%w: %q is not a %s hash", ErrInvalidIndicator, value, t)
+		}
+		return hash, nil
+
+	case IndicatorEmail:
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value || addr.Name != "" {
+			return "", fmt.Errorf("%w: %q is not an email address", ErrInvalidIndicator, value)
+		}
+		at := strings.LastIndex(addr.Address, "@")
+		domain, err := normalizeDomain(addr.Address[at+1:])
+		if err != nil {
+			return "", fmt.Errorf("%w: %q has an invalid domain", ErrInvalidIndicator, value)
+		}
+		return strings.ToLower(addr.Address[:at]) + "@" + domain, nil
+
+	case IndicatorASN:
+		asn, err := ParseASN(value)
+		if err != nil {
+			return "", err
+		}
+		return "AS" + strconv.FormatUint(uint64(asn), 10), nil
+	}
+
+	return "", fmt.Errorf("%w: unsupported indicator type %q", ErrInvalidIndicator, t)
+}
+
+// DetectIndicatorType 依指標值推斷類型，無法判斷時回傳 false
+func DetectIndicatorType(value string) (IndicatorType, bool) {
+	value = strings.TrimSpace(value)
+
+	if ip := net.ParseIP(value); ip != nil {
+		if ip.To4() != nil {
+			return IndicatorIPv4, true
+		}
+		return IndicatorIPv6, true
+	}
+	if strings.Contains(value, "/") {
+		if _, _, err := net.ParseCIDR(value); err == nil {
+			return IndicatorCIDR, true
+		}
+	}
+	if strings.Contains(value, "://") {
+		if _, err := normalizeURL(value); err == nil {
+			return IndicatorURL, true
+		}
+		return "", false
+	}
+	for _, t := range []IndicatorType{IndicatorMD5, IndicatorSHA1, IndicatorSHA256} {
+		if _, err := NormalizeIndicator(t, value); err == nil {
+			return t, true
+		}
+	}
+	if strings.Contains(value, "@") {
+		if _, err := NormalizeIndicator(IndicatorEmail, value); err == nil {
+			return IndicatorEmail, true
+		}
+		return "", false
+	}
+	if _, err := ParseASN(value); err == nil && strings.HasPrefix(strings.ToUpper(value), "AS") {
+		return IndicatorASN, true
+	}
+	if _, err := normalizeDomain(value); err == nil {
+		return IndicatorDomain, true
+	}
+
+	return "", false
+}
+
+// ParseASN 解析 ASN，接受 "AS13335" 或 "13335" 格式
+func ParseASN(value string) (uint32, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > 2 && strings.EqualFold(value[:2], "AS") {
+		value = value[2:]
+	}
+	asn, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || asn == 0 {
+		return 0, fmt.Errorf("%w: %q is not an ASN", ErrInvalidIndicator, value)
+	}
+	return uint32(asn), nil
+}
+
+// normalizeDomain 驗證並正規化域名（小寫、移除結尾的點）
+func normalizeDomain(value string) (string, error) {
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), ".")
+	if len(domain) == 0 || len(domain) > 253 || net.ParseIP(domain) != nil {
+		return "", fmt.Errorf("%w: %q is not a domain", ErrInvalidIndicator, value)
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("%w: %q is not a fully qualified domain", ErrInvalidIndicator, value)
+	}
+	for _, label := range labels {
+		if !domainLabelPattern.MatchString(label) {
+			return "", fmt.Errorf("%w: %q is not a domain", ErrInvalidIndicator, value)
+		}
+	}
+	return domain, nil
+}
+
+// normalizeURL 驗證並正規化 URL（小寫 scheme 與主機、移除預設埠與片段）
+func normalizeURL(value string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("%w: %q is not a URL", ErrInvalidIndicator, value)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	switch u.Scheme {
+	case "http", "https", "ftp":
+	default:
+		return "", fmt.Errorf("%w: unsupported URL scheme %q", ErrInvalidIndicator, u.Scheme)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") || (u.Scheme == "ftp" && port == "21") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+
+	u.Host = host
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+// URLHost 取得 URL 的主機名稱，非域名（例如 IP）時回傳 false
+func URLHost(value string) (string, bool) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", false
+	}
+	domain, err := normalizeDomain(u.Hostname())
+	if err != nil {
+		return "", false
+	}
+	return domain, true
+}
+
+// hashLength 取得雜湊類型的十六進位長度
+func hashLength(t IndicatorType) int {
+	switch t {
+	case IndicatorMD5:
+		return 32
+	case IndicatorSHA1:
+		return 40
+	case IndicatorSHA256:
+		return 64
+	}
+	return 0
+}
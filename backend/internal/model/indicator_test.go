@@ -0,0 +1,78 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeIndicator(t *testing.T) {
+	tests := []struct {
+		name      string
+		t         IndicatorType
+		value     string
+		expected  string
+		expectErr bool
+	}{
+		{name: "ipv4", t: IndicatorIPv4, value: " 192.0.2.1 ", expected: "192.0.2.1"},
+		{name: "ipv4 rejects ipv6", t: IndicatorIPv4, value: "2001:db8::1", expectErr: true},
+		{name: "ipv6 canonical", t: IndicatorIPv6, value: "2001:DB8:0:0:0:0:0:1", expected: "2001:db8::1"},
+		{name: "cidr masks host bits", t: IndicatorCIDR, value: "10.1.2.3/8", expected: "10.0.0.0/8"},
+		{name: "domain lowercased", t: IndicatorDomain, value: "Evil.Example.COM.", expected: "evil.example.com"},
+		{name: "domain requires tld", t: IndicatorDomain, value: "localhost", expectErr: true},
+		{name: "url default port and fragment", t: IndicatorURL, value: "HTTP://Evil.Example.com:80/a?b=1#x", expected: "http://evil.example.com/a?b=1"},
+		{name: "url rejects scheme", t: IndicatorURL, value: "javascript:alert(1)", expectErr: true},
+		{name: "md5", t: IndicatorMD5, value: "44D88612FEA8A8F36DE82E1278ABB02F", expected: "44d88612fea8a8f36de82e1278abb02f"},
+		{name: "sha1 wrong length", t: IndicatorSHA1, value: "44d88612fea8a8f36de82e1278abb02f", expectErr: true},
+		{name: "email", t: IndicatorEmail, value: "Victim@Example.COM", expected: "victim@example.com"},
+		{name: "email rejects display name", t: IndicatorEmail, value: "Victim <victim@example.com>", expectErr: true},
+		{name: "asn prefixed", t: IndicatorASN, value: "as13335", expected: "AS13335"},
+		{name: "asn numeric", t: IndicatorASN, value: "13335", expected: "AS13335"},
+		{name: "asn zero", t: IndicatorASN, value: "AS0", expectErr: true},
+		{name: "unknown type", t: IndicatorType("mutex"), value: "x", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NormalizeIndicator(tt.t, tt.value)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, ErrInvalidIndicator)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestDetectIndicatorType(t *testing.T) {
+	tests := map[string]IndicatorType{
+		"192.0.2.1":                        IndicatorIPv4,
+		"2001:db8::1":                      IndicatorIPv6,
+		"192.0.2.0/24":                     IndicatorCIDR,
+		"evil.example.com":                 IndicatorDomain,
+		"https://evil.com/x":               IndicatorURL,
+		"44d88612fea8a8f36de82e1278abb02f": IndicatorMD5,
+		"3395856ce81f2b7382dee72602f798b642f14140":                         IndicatorSHA1,
+		"275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f": IndicatorSHA256,
+		"victim@example.com": IndicatorEmail,
+		"AS13335":            IndicatorASN,
+	}
+
+	for value, expected := range tests {
+		detected, ok := DetectIndicatorType(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, expected, detected, value)
+	}
+
+	_, ok := DetectIndicatorType("not an indicator")
+	assert.False(t, ok)
+}
+
+func TestComputeFingerprintUsesIndicator(t *testing.T) {
+	a := &ThreatIntelligence{Source: "Manual", IndicatorType: IndicatorDomain, IndicatorValue: "evil.example.com"}
+	b := &ThreatIntelligence{Source: "Manual", IndicatorType: IndicatorURL, IndicatorValue: "evil.example.com"}
+
+	assert.NotEqual(t, a.ComputeFingerprint(), b.ComputeFingerprint())
+	assert.Equal(t, a.ComputeFingerprint(), (&ThreatIntelligence{Source: "Manual", IndicatorType: IndicatorDomain, IndicatorValue: "evil.example.com"}).ComputeFingerprint())
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserMFA 使用者的 TOTP 設定；共享密鑰以 AES-GCM 加密保存，EnabledAt 為空表示已產生密鑰但尚未完成啟用
+type UserMFA struct {
+	UserID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"user_id"`
+	SecretEncrypted string     `gorm:"type:text;not null" json:"-"`
+	EnabledAt       *time.Time `gorm:"column:enabled_at" json:"enabled_at"`
+	LastUsedStep    int64      `gorm:"not null;default:0" json:"-"` // 最後使用的時間步，防止同一代碼被重放
+	CreatedAt       time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// 關聯
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定資料表名稱
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// IsEnabled 檢查是否已完成啟用
+func (m *UserMFA) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
+
+// MFARecoveryCode 復原碼，只保存雜湊，每組只能使用一次
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_mfa_recovery_codes_user_id" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// 關聯
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定資料表名稱
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// BeforeCreate 在建立前執行
+func (c *MFARecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// MFAChallenge 密碼驗證通過後等待第二步驗證的登入挑戰，只保存令牌雜湊
+type MFAChallenge struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_mfa_challenges_user_id" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_mfa_challenges_token_hash" json:"-"`
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// 關聯
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定資料表名稱
+func (MFAChallenge) TableName() string {
+	return "mfa_challenges"
+}
+
+// BeforeCreate 在建立前執行
+func (c *MFAChallenge) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
@@ -12,6 +12,18 @@ func AllModels() []interface{} {
 		&ThreatIntelligence{},
 		&IntelligenceSource{},
 		&CollectionJob{},
+		&EventOutbox{},
+		&Webhook{},
+		&WebhookDelivery{},
+		&RefreshToken{},
+		&PasswordResetToken{},
+		&EmailVerificationToken{},
+		&UserMFA{},
+		&MFARecoveryCode{},
+		&MFAChallenge{},
+		&UserIdentity{},
+		&OIDCLoginState{},
+		&Role{},
 	}
 }
 
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity 使用者連結的外部身分，以身分提供者的發行者與 subject 唯一識別
+type UserIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_user_identities_user_id" json:"user_id"`
+	Issuer      string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject" json:"issuer"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject" json:"subject"`
+	Email       string     `gorm:"type:varchar(100)" json:"email"` // 最後一次登入時身分提供者回傳的信箱
+	CreatedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at" json:"last_login_at"`
+
+	// 關聯
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定資料表名稱
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// BeforeCreate 在建立前執行
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// OIDCLoginState 單一登入授權請求的狀態，保存 PKCE 驗證碼與 nonce；state 只保存雜湊，回呼時使用一次
+type OIDCLoginState struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	StateHash    string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_oidc_login_states_state_hash" json:"-"`
+	Nonce        string     `gorm:"type:varchar(128);not null" json:"-"`
+	CodeVerifier string     `gorm:"type:varchar(128);not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定資料表名稱
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
+
+// BeforeCreate 在建立前執行
+func (s *OIDCLoginState) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 刷新令牌撤銷原因
+const (
+	RefreshTokenRevokedReuse         = "reuse_detected" // 已輪替的令牌被再次使用，視為遭竊
+	RefreshTokenRevokedLogoutAll     = "logout_all"
+	RefreshTokenRevokedPasswordReset = "password_reset"
+)
+
+// RefreshToken 已簽發的刷新令牌，ID 即 JWT 的 jti
+// 同一次登入後輪替出的令牌屬於同一家族；每個令牌只能使用一次，重複使用會撤銷整個家族
+type RefreshToken struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	FamilyID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_refresh_tokens_family_id" json:"family_id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index:idx_refresh_tokens_user_id" json:"user_id"`
+	ParentID      *uuid.UUID `gorm:"type:uuid;column:parent_id" json:"parent_id"` // 輪替前的令牌，家族第一個令牌為空
+	ExpiresAt     time.Time  `gorm:"not null" json:"expires_at"`
+	RotatedAt     *time.Time `gorm:"column:rotated_at" json:"rotated_at"` // 已使用並換發新令牌的時間
+	RevokedAt     *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+	RevokedReason *string    `gorm:"type:varchar(50)" json:"revoked_reason"`
+	MFAVerified   bool       `gorm:"not null;default:false" json:"mfa_verified"` // 家族開始時是否通過多因素驗證，輪替時沿用
+	CreatedAt     time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// 關聯
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定資料表名稱
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsRotated 檢查令牌是否已被使用過
+func (t *RefreshToken) IsRotated() bool {
+	return t.RotatedAt != nil
+}
+
+// IsRevoked 檢查令牌是否已撤銷
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// IsExpired 檢查令牌是否已過期
+func (t *RefreshToken) IsExpired() bool {
+	return !time.Now().Before(t.ExpiresAt)
+}
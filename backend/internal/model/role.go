@@ -0,0 +1,109 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 權限，格式為 資源:動作；端點宣告所需權限，角色授予權限
+const (
+	PermissionThreatRead      = "threat:read"
+	PermissionThreatWrite     = "threat:write"
+	PermissionThreatDelete    = "threat:delete"
+	PermissionCollectorRun    = "collector:run"
+	PermissionHIBPRead        = "hibp:read"
+	PermissionHIBPStealerRead = "hibp:stealer:read"
+	PermissionDashboardRead   = "dashboard:read"
+	PermissionWebhookManage   = "webhook:manage"
+	PermissionSourceManage    = "source:manage"
+	PermissionRoleManage      = "role:manage"
+	PermissionUserManage      = "user:manage"
+)
+
+// PermissionDefinition 權限說明
+type PermissionDefinition struct {
+	Name        string
+	Description string
+}
+
+// permissionDefinitions 所有可指派的權限
+var permissionDefinitions = []PermissionDefinition{
+	{PermissionThreatRead, "查詢、搜尋威脅情報與統計"},
+	{PermissionThreatWrite, "建立與更新威脅情報"},
+	{PermissionThreatDelete, "刪除威脅情報"},
+	{PermissionCollectorRun, "手動觸發威脅情報收集"},
+	{PermissionHIBPRead, "查詢 HIBP 外洩資料"},
+	{PermissionHIBPStealerRead, "查詢 HIBP 竊密程式紀錄"},
+	{PermissionDashboardRead, "檢視儀表板"},
+	{PermissionWebhookManage, "管理自己的 Webhook 訂閱"},
+	{PermissionSourceManage, "管理情報來源與收集任務"},
+	{PermissionRoleManage, "管理角色定義"},
+	{PermissionUserManage, "指派使用者角色"},
+}
+
+// AllPermissions 取得所有可指派的權限
+func AllPermissions() []PermissionDefinition {
+	return append([]PermissionDefinition(nil), permissionDefinitions...)
+}
+
+// IsValidPermission 檢查是否為已定義的權限
+func IsValidPermission(permission string) bool {
+	for _, definition := range permissionDefinitions {
+		if definition.Name == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Role 角色定義，使用者的 Role 欄位對應 Name
+type Role struct {
+	ID          uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name        string      `gorm:"type:varchar(50);not null;uniqueIndex:idx_roles_name" json:"name"`
+	Description string      `gorm:"type:varchar(255)" json:"description"`
+	Permissions StringArray `gorm:"type:text[]" json:"permissions"`
+	IsSystem    bool        `gorm:"default:false" json:"is_system"` // 內建角色不可刪除
+	CreatedAt   time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName 指定資料表名稱
+func (Role) TableName() string {
+	return "roles"
+}
+
+// BeforeCreate 在建立前執行
+func (r *Role) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BuiltinRoles 內建角色及其預設權限；admin 永遠擁有所有權限，其餘角色可由管理員調整
+func BuiltinRoles() []Role {
+	basic := []string{
+		PermissionThreatRead,
+		PermissionThreatWrite,
+		PermissionCollectorRun,
+		PermissionHIBPRead,
+		PermissionDashboardRead,
+		PermissionWebhookManage,
+	}
+	premium := append(append([]string(nil), basic...),
+		PermissionThreatDelete,
+		PermissionHIBPStealerRead,
+	)
+	admin := make([]string, len(permissionDefinitions))
+	for i, definition := range permissionDefinitions {
+		admin[i] = definition.Name
+	}
+
+	return []Role{
+		{Name: string(RoleAdmin), Description: "系統管理員，擁有所有權限", Permissions: admin, IsSystem: true},
+		{Name: string(RolePremium), Description: "進階訂閱使用者", Permissions: premium, IsSystem: true},
+		{Name: string(RoleBasic), Description: "基本使用者，自行註冊的帳號皆為此角色", Permissions: basic, IsSystem: true},
+	}
+}
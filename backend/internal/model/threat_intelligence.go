@@ -1,14 +1,21 @@
 package model
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // ThreatType 威脅類型
@@ -61,51 +68,159 @@ func (j *JSONB) Scan(value interface{}) error {
 // StringArray 自訂字串陣列類型
 type StringArray []string
 
-// Value 實作 driver.Valuer 介面
+// Value 實作 driver.Valuer 介面，元素一律加上引號並跳脫反斜線與引號
 func (s StringArray) Value() (driver.Value, error) {
 	if len(s) == 0 {
 		return "{}", nil
 	}
-	result := "{"
+	var b strings.Builder
+	b.WriteByte('{')
 	for i, v := range s {
 		if i > 0 {
-			result += ","
+			b.WriteByte(',')
 		}
-		result += `"` + v + `"`
+		b.WriteByte('"')
+		b.WriteString(arrayElementEscaper.Replace(v))
+		b.WriteByte('"')
 	}
-	result += "}"
-	return result, nil
+	b.WriteByte('}')
+	return b.String(), nil
 }
 
-// Scan 實作 sql.Scanner 介面
+// arrayElementEscaper PostgreSQL 陣列字面值中引號內元素的跳脫規則
+var arrayElementEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// Scan 實作 sql.Scanner 介面，資料庫驅動程式可能以 string 或 []byte 回傳陣列字面值
 func (s *StringArray) Scan(value interface{}) error {
-	if value == nil {
+	var str string
+	switch v := value.(type) {
+	case nil:
 		*s = []string{}
 		return nil
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return fmt.Errorf("unsupported type %T for StringArray", value)
 	}
-	
-	bytes, ok := value.([]byte)
-	if !ok {
-		return errors.New("type assertion to []byte failed")
+
+	elements, err := parseArrayLiteral(str)
+	if err != nil {
+		return err
 	}
-	
-	// 簡單的 PostgreSQL 陣列解析
-	str := string(bytes)
-	if str == "{}" {
-		*s = []string{}
-		return nil
+	*s = elements
+	return nil
+}
+
+// parseArrayLiteral 解析一維 PostgreSQL 文字陣列字面值，例如 {a,"b c","d\"e"}
+// 未加引號的 NULL 視為空字串
+func parseArrayLiteral(str string) ([]string, error) {
+	if len(str) < 2 || str[0] != '{' || str[len(str)-1] != '}' {
+		return nil, fmt.Errorf("invalid array literal %q", str)
 	}
-	
-	// 移除大括號並分割
-	str = str[1 : len(str)-1]
-	return json.Unmarshal([]byte("["+str+"]"), s)
+	body := str[1 : len(str)-1]
+	elements := []string{}
+	if body == "" {
+		return elements, nil
+	}
+
+	for i := 0; ; {
+		var element strings.Builder
+		if i < len(body) && body[i] == '"' {
+			i++
+			for ; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' {
+					i++
+					if i == len(body) {
+						break
+					}
+				}
+				element.WriteByte(body[i])
+			}
+			if i >= len(body) {
+				return nil, fmt.Errorf("unterminated quoted element in %q", str)
+			}
+			i++
+			elements = append(elements, element.String())
+		} else {
+			j := strings.IndexByte(body[i:], ',')
+			if j < 0 {
+				j = len(body) - i
+			}
+			raw := strings.TrimSpace(body[i : i+j])
+			if strings.EqualFold(raw, "NULL") {
+				raw = ""
+			}
+			elements = append(elements, raw)
+			i += j
+		}
+
+		if i == len(body) {
+			return elements, nil
+		}
+		if body[i] != ',' {
+			return nil, fmt.Errorf("invalid array literal %q", str)
+		}
+		i++
+	}
+}
+
+// inetSerializer inet 欄位的 gorm 序列化器
+// database/sql 無法把驅動程式回傳的字串直接寫入 net.IP，讀取時改由此處解析
+type inetSerializer struct{}
+
+func init() {
+	schema.RegisterSerializer("inet", inetSerializer{})
+}
+
+// Scan 將資料庫回傳的 inet 字串解析為 net.IP
+func (inetSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var str string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		str = string(v)
+	case string:
+		str = v
+	default:
+		return fmt.Errorf("unsupported type %T for inet", dbValue)
+	}
+
+	var ip net.IP
+	if str != "" {
+		if strings.Contains(str, "/") {
+			parsed, _, err := net.ParseCIDR(str)
+			if err != nil {
+				return err
+			}
+			ip = parsed
+		} else if ip = net.ParseIP(str); ip == nil {
+			return fmt.Errorf("invalid inet value %q", str)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(reflect.ValueOf(ip))
+	return nil
+}
+
+// Value 寫入時保留 net.IP 交由驅動程式編碼，空值寫入 NULL
+func (inetSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	ip, _ := fieldValue.(net.IP)
+	if len(ip) == 0 {
+		return nil, nil
+	}
+	return ip, nil
 }
 
 // ThreatIntelligence 威脅情報模型
 type ThreatIntelligence struct {
 	ID              uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	IPAddress       net.IP        `gorm:"type:inet;not null" json:"ip_address"`
+	IndicatorType   IndicatorType `gorm:"type:varchar(20);not null;default:'ipv4';index" json:"indicator_type"`
+	IndicatorValue  string        `gorm:"type:varchar(2048);not null;index" json:"indicator_value"` // 正規化後的指標值
+	IPAddress       net.IP        `gorm:"type:inet;serializer:inet" json:"ip_address"`
 	Domain          *string       `gorm:"type:varchar(253)" json:"domain"`
+	URL             *string       `gorm:"type:text" json:"url"`
+	FileHash        *string       `gorm:"type:varchar(64);index" json:"file_hash"`
 	ThreatType      ThreatType    `gorm:"type:threat_type;not null" json:"threat_type"`
 	Severity        SeverityLevel `gorm:"type:severity_level;not null" json:"severity"`
 	ConfidenceScore int           `gorm:"check:confidence_score >= 0 AND confidence_score <= 100" json:"confidence_score"`
@@ -119,6 +234,7 @@ type ThreatIntelligence struct {
 	LastSeen        time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"last_seen"`
 	Tags            StringArray   `gorm:"type:text[]" json:"tags"`
 	Metadata        JSONB         `gorm:"type:jsonb" json:"metadata"`
+	Fingerprint     string        `gorm:"type:varchar(64);uniqueIndex:idx_threat_intelligence_fingerprint" json:"-"` // 去重自然鍵雜湊
 	CreatedAt       time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt       time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
@@ -136,6 +252,25 @@ func (t *ThreatIntelligence) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeSave 在儲存前執行，確保去重鍵與指標欄位一致
+func (t *ThreatIntelligence) BeforeSave(tx *gorm.DB) error {
+	t.Fingerprint = t.ComputeFingerprint()
+	return nil
+}
+
+// ComputeFingerprint 依自然鍵（來源 + 指標類型 + 指標值 + 外部 ID）計算去重雜湊
+// 計算方式需與資料庫遷移中的回填 SQL 保持一致
+func (t *ThreatIntelligence) ComputeFingerprint() string {
+	externalID := ""
+	if t.ExternalID != nil {
+		externalID = *t.ExternalID
+	}
+
+	key := strings.Join([]string{t.Source, string(t.IndicatorType), t.IndicatorValue, externalID}, "|")
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
 // IsHighRisk 檢查是否為高風險威脅
 func (t *ThreatIntelligence) IsHighRisk() bool {
 	return t.Severity == SeverityHigh || t.Severity == SeverityCritical
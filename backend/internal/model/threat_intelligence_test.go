@@ -0,0 +1,53 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringArray_Scan(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		expected  StringArray
+		expectErr bool
+	}{
+		{name: "nil", value: nil, expected: StringArray{}},
+		{name: "empty", value: "{}", expected: StringArray{}},
+		{name: "unquoted string", value: "{botnet,c2}", expected: StringArray{"botnet", "c2"}},
+		{name: "bytes", value: []byte(`{botnet,"c2"}`), expected: StringArray{"botnet", "c2"}},
+		{name: "quoted with separators", value: `{"a b","x,y",""}`, expected: StringArray{"a b", "x,y", ""}},
+		{name: "escaped quote and backslash", value: `{"say \"hi\"","C:\\temp"}`, expected: StringArray{`say "hi"`, `C:\temp`}},
+		{name: "null element", value: "{a,NULL}", expected: StringArray{"a", ""}},
+		{name: "not an array", value: "botnet", expectErr: true},
+		{name: "unterminated quote", value: `{"a}`, expectErr: true},
+		{name: "unsupported type", value: 42, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result StringArray
+			err := result.Scan(tt.value)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestStringArray_ValueRoundTrip(t *testing.T) {
+	original := StringArray{"botnet", `say "hi"`, `C:\temp`, "a,b", "{}"}
+
+	value, err := original.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `{"botnet","say \"hi\"","C:\\temp","a,b","{}"}`, value)
+
+	var scanned StringArray
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, original, scanned)
+}
@@ -7,7 +7,7 @@ import (
 	"gorm.io/gorm"
 )
 
-// UserRole 使用者角色
+// UserRole 使用者角色，對應 roles 資料表的角色名稱；以下為內建角色
 type UserRole string
 
 const (
@@ -22,7 +22,8 @@ type User struct {
 	Username              string     `gorm:"type:varchar(50);unique;not null" json:"username"`
 	Email                 string     `gorm:"type:varchar(100);unique;not null" json:"email"`
 	PasswordHash          string     `gorm:"type:varchar(255);not null" json:"-"`
-	Role                  UserRole   `gorm:"type:user_role;default:'basic'" json:"role"`
+	Role                  UserRole   `gorm:"type:varchar(50);default:'basic'" json:"role"`
+	RoleAssignedByAdmin   bool       `gorm:"not null;default:false" json:"-"` // 管理員指派的角色不會被單一登入的角色同步覆寫
 	IsActive              bool       `gorm:"default:true" json:"is_active"`
 	EmailVerified         bool       `gorm:"default:false" json:"email_verified"`
 	SubscriptionType      string     `gorm:"type:varchar(20);default:'basic'" json:"subscription_type"`
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookDeliveryStatus Webhook 投遞狀態
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // 超過重試上限
+)
+
+// Webhook 使用者註冊的 Webhook 訂閱，符合過濾條件的威脅事件會以 HMAC 簽章後 POST 到 URL
+type Webhook struct {
+	ID                  uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID              uuid.UUID   `gorm:"type:uuid;not null" json:"user_id"`
+	Name                string      `gorm:"type:varchar(100);not null" json:"name"`
+	URL                 string      `gorm:"type:varchar(500);not null" json:"url"`
+	Secret              string      `gorm:"type:varchar(100);not null" json:"-"` // 簽章用，需保留原文
+	ThreatTypes         StringArray `gorm:"type:text[]" json:"threat_types"`
+	Severities          StringArray `gorm:"type:text[]" json:"severities"`
+	MinConfidenceScore  int         `gorm:"default:0" json:"min_confidence_score"`
+	Sources             StringArray `gorm:"type:text[]" json:"sources"`
+	IsActive            bool        `gorm:"default:true" json:"is_active"`
+	ConsecutiveFailures int         `gorm:"default:0" json:"consecutive_failures"`
+	DisabledReason      *string     `gorm:"type:text" json:"disabled_reason"`
+	DisabledAt          *time.Time  `gorm:"column:disabled_at" json:"disabled_at"`
+	LastSuccessAt       *time.Time  `gorm:"column:last_success_at" json:"last_success_at"`
+	LastFailureAt       *time.Time  `gorm:"column:last_failure_at" json:"last_failure_at"`
+	CreatedAt           time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt           time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// 關聯
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定資料表名稱
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// BeforeCreate 在建立前執行
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebhookDelivery Webhook 投遞紀錄，同時作為待送佇列與投遞日誌
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	WebhookID      uuid.UUID             `gorm:"type:uuid;not null;uniqueIndex:uq_webhook_deliveries_event" json:"webhook_id"`
+	EventID        string                `gorm:"type:varchar(100);not null;uniqueIndex:uq_webhook_deliveries_event" json:"event_id"`
+	EventType      string                `gorm:"type:varchar(20);not null" json:"event_type"`
+	Payload        json.RawMessage       `gorm:"type:jsonb;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus *int                  `gorm:"column:response_status" json:"response_status"`
+	LastError      *string               `gorm:"type:text" json:"last_error"`
+	DurationMs     *int                  `gorm:"column:duration_ms" json:"duration_ms"`
+	NextAttemptAt  time.Time             `gorm:"not null;default:CURRENT_TIMESTAMP" json:"next_attempt_at"`
+	CreatedAt      time.Time             `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	DeliveredAt    *time.Time            `gorm:"column:delivered_at" json:"delivered_at"`
+
+	// 關聯
+	Webhook *Webhook `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定資料表名稱
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeCreate 在建立前執行
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// APIKeyRepository API 金鑰儲存庫介面
+type APIKeyRepository interface {
+	GetByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+	ConsumeUsage(ctx context.Context, id uuid.UUID) (bool, error)
+	Create(ctx context.Context, apiKey *model.APIKey) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.APIKey, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.APIKey, error)
+	HasSuccessor(ctx context.Context, id uuid.UUID) (bool, error)
+	List(ctx context.Context, filter *APIKeyFilter) ([]*model.APIKey, int64, error)
+	CountUsable(ctx context.Context, userID uuid.UUID) (int64, error)
+	Update(ctx context.Context, apiKey *model.APIKey) error
+	Transaction(ctx context.Context, fn func(repo APIKeyRepository) error) error
+}
+
+// APIKeyFilter API 金鑰篩選器
+type APIKeyFilter struct {
+	UserID   uuid.UUID
+	IsActive *bool
+	Page     int
+	PageSize int
+}
+
+// apiKeyRepository API 金鑰儲存庫實作
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository 建立 API 金鑰儲存庫
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// GetByHash 根據金鑰雜湊取得 API 金鑰，並載入所屬使用者
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	err := r.db.WithContext(ctx).Preload("User").Where("key_hash = ?", keyHash).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// ConsumeUsage 以單一條件更新累加使用次數並記錄最後使用時間，回傳是否仍在配額內
+// 配額檢查與累加在同一語句完成，併發請求不會超用
+func (r *apiKeyRepository) ConsumeUsage(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND usage < quota", id).
+		Updates(map[string]interface{}{
+			"usage":     gorm.Expr("usage + 1"),
+			"last_used": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Create 建立 API 金鑰
+func (r *apiKeyRepository) Create(ctx context.Context, apiKey *model.APIKey) error {
+	return r.db.WithContext(ctx).Omit("User").Create(apiKey).Error
+}
+
+// GetByID 根據 ID 取得 API 金鑰
+func (r *apiKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// GetByIDForUpdate 根據 ID 取得 API 金鑰並鎖定該列直到交易結束，須在 Transaction 中使用
+func (r *apiKeyRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// HasSuccessor 檢查金鑰是否已輪替出新金鑰
+func (r *apiKeyRepository) HasSuccessor(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.APIKey{}).Where("rotated_from_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// List 取得使用者的 API 金鑰，依建立時間由新到舊排序
+func (r *apiKeyRepository) List(ctx context.Context, filter *APIKeyFilter) ([]*model.APIKey, int64, error) {
+	var apiKeys []*model.APIKey
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.APIKey{}).Where("user_id = ?", filter.UserID)
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(filter.PageSize).
+		Find(&apiKeys).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return apiKeys, total, nil
+}
+
+// CountUsable 計算使用者仍可使用（啟用、未撤銷且未過期）的金鑰數量
+func (r *apiKeyRepository) CountUsable(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("user_id = ? AND is_active = ? AND revoked_at IS NULL", userID, true).
+		Where("expires_at IS NULL OR expires_at > NOW()").
+		Count(&count).Error
+	return count, err
+}
+
+// Update 更新可由使用者管理的欄位；使用次數與最後使用時間由 ConsumeUsage 維護，不在此覆寫
+func (r *apiKeyRepository) Update(ctx context.Context, apiKey *model.APIKey) error {
+	return r.db.WithContext(ctx).
+		Model(apiKey).
+		Select("name", "is_active", "expires_at", "quota", "revoked_at").
+		Updates(apiKey).Error
+}
+
+// Transaction 在交易中執行 fn，fn 回傳錯誤時回滾
+func (r *apiKeyRepository) Transaction(ctx context.Context, fn func(repo APIKeyRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&apiKeyRepository{db: tx})
+	})
+}
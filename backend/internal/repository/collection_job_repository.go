@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// CollectionJobRepository 收集任務儲存庫介面
+type CollectionJobRepository interface {
+	Create(ctx context.Context, job *model.CollectionJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.CollectionJob, error)
+	Update(ctx context.Context, job *model.CollectionJob) error
+	List(ctx context.Context, filter *CollectionJobFilter) ([]*model.CollectionJob, int64, error)
+	FailUnfinished(ctx context.Context, message string) (int64, error)
+}
+
+// CollectionJobFilter 收集任務篩選器
+type CollectionJobFilter struct {
+	SourceID *uuid.UUID
+	Status   *string
+	Page     int
+	PageSize int
+}
+
+// collectionJobRepository 收集任務儲存庫實作
+type collectionJobRepository struct {
+	db *gorm.DB
+}
+
+// NewCollectionJobRepository 建立收集任務儲存庫
+func NewCollectionJobRepository(db *gorm.DB) CollectionJobRepository {
+	return &collectionJobRepository{db: db}
+}
+
+// Create 建立收集任務
+func (r *collectionJobRepository) Create(ctx context.Context, job *model.CollectionJob) error {
+	return r.db.WithContext(ctx).Omit("Source").Create(job).Error
+}
+
+// GetByID 根據 ID 取得收集任務
+func (r *collectionJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.CollectionJob, error) {
+	var job model.CollectionJob
+	err := r.db.WithContext(ctx).Preload("Source").Where("id = ?", id).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Update 更新收集任務
+func (r *collectionJobRepository) Update(ctx context.Context, job *model.CollectionJob) error {
+	return r.db.WithContext(ctx).Omit("Source").Save(job).Error
+}
+
+// FailUnfinished 將仍為待執行或執行中的任務標記為失敗，回傳更新筆數
+// 供排程器啟動時清理上次異常結束遺留、已不會再有人更新的任務
+func (r *collectionJobRepository) FailUnfinished(ctx context.Context, message string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.CollectionJob{}).
+		Where("status IN ?", []model.CollectionStatus{model.StatusPending, model.StatusInProgress}).
+		Updates(map[string]interface{}{
+			"status":        model.StatusFailed,
+			"error_message": message,
+			"completed_at":  gorm.Expr("NOW()"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// List 取得收集任務列表，依建立時間由新到舊排序
+func (r *collectionJobRepository) List(ctx context.Context, filter *CollectionJobFilter) ([]*model.CollectionJob, int64, error) {
+	var jobs []*model.CollectionJob
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.CollectionJob{})
+
+	if filter.SourceID != nil {
+		query = query.Where("source_id = ?", *filter.SourceID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	err := query.Preload("Source").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(filter.PageSize).
+		Find(&jobs).Error
+
+	return jobs, total, err
+}
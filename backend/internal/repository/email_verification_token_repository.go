@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// EmailVerificationTokenRepository 信箱驗證令牌儲存庫介面
+type EmailVerificationTokenRepository interface {
+	Issue(ctx context.Context, token *model.EmailVerificationToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error)
+	CountIssuedWithin(ctx context.Context, userID uuid.UUID, window time.Duration) (int64, error)
+	Redeem(ctx context.Context, token *model.EmailVerificationToken) (bool, error)
+}
+
+// emailVerificationTokenRepository 信箱驗證令牌儲存庫實作
+type emailVerificationTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailVerificationTokenRepository 建立信箱驗證令牌儲存庫
+func NewEmailVerificationTokenRepository(db *gorm.DB) EmailVerificationTokenRepository {
+	return &emailVerificationTokenRepository{db: db}
+}
+
+// Issue 建立新令牌並作廢使用者先前尚未使用的令牌，同一時間只有最新一封驗證信有效
+func (r *emailVerificationTokenRepository) Issue(ctx context.Context, token *model.EmailVerificationToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.EmailVerificationToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", gorm.Expr("NOW()")).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(token).Error
+	})
+}
+
+// GetByHash 根據令牌雜湊取得驗證令牌
+func (r *emailVerificationTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.EmailVerificationToken, error) {
+	var token model.EmailVerificationToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CountIssuedWithin 計算使用者在最近 window 內收到的驗證信數量，以資料庫時間計算
+func (r *emailVerificationTokenRepository) CountIssuedWithin(ctx context.Context, userID uuid.UUID, window time.Duration) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.EmailVerificationToken{}).
+		Where("user_id = ? AND created_at > NOW() - ? * INTERVAL '1 millisecond'", userID, window.Milliseconds()).
+		Count(&count).Error
+	return count, err
+}
+
+// Redeem 使用令牌並將使用者信箱標記為已驗證，兩者在同一交易中完成
+// 令牌已使用、過期，或使用者信箱已不是令牌綁定的信箱時回傳 false 且不做任何變更
+func (r *emailVerificationTokenRepository) Redeem(ctx context.Context, token *model.EmailVerificationToken) (bool, error) {
+	redeemed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.EmailVerificationToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > NOW()", token.ID).
+			Update("used_at", gorm.Expr("NOW()"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		result = tx.Model(&model.User{}).
+			Where("id = ? AND email = ?", token.UserID, token.Email).
+			Updates(map[string]interface{}{
+				"email_verified": true,
+				"updated_at":     gorm.Expr("NOW()"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// 信箱已變更，保留令牌已使用的狀態
+			return nil
+		}
+		redeemed = true
+		return nil
+	})
+	return redeemed, err
+}
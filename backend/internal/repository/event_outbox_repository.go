@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// EventOutboxRepository outbox 事件儲存庫介面，供 relay 領取與回報投遞結果
+type EventOutboxRepository interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.EventOutbox, error)
+	MarkDelivered(ctx context.Context, id int64, sinks []string) error
+	MarkFailed(ctx context.Context, id int64, sinks []string, attempts int, retryAfter time.Duration, lastErr string) error
+	MarkDead(ctx context.Context, id int64, sinks []string, attempts int, lastErr string) error
+	PurgeDelivered(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// eventOutboxRepository outbox 事件儲存庫實作
+type eventOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewEventOutboxRepository 建立 outbox 事件儲存庫
+func NewEventOutboxRepository(db *gorm.DB) EventOutboxRepository {
+	return &eventOutboxRepository{db: db}
+}
+
+// Claim 領取到期的待投遞事件，並將下次嘗試時間延後 lease 作為租約
+// 使用 SKIP LOCKED 讓多個執行個體可同時運作而不會領到同一筆；租約到期前未回報的事件會被重新領取
+func (r *eventOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.EventOutbox, error) {
+	var events []*model.EventOutbox
+	due := r.db.Model(&model.EventOutbox{}).
+		Select("id").
+		Where("status = ? AND next_attempt_at <= NOW()", model.OutboxPending).
+		Order("id").
+		Limit(limit).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+	err := r.db.WithContext(ctx).
+		Model(&events).
+		Clauses(clause.Returning{}).
+		Where("id IN (?)", due).
+		Update("next_attempt_at", gorm.Expr("NOW() + ? * INTERVAL '1 millisecond'", lease.Milliseconds())).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+// MarkDelivered 標記事件已投遞到所有接收端
+func (r *eventOutboxRepository) MarkDelivered(ctx context.Context, id int64, sinks []string) error {
+	return r.db.WithContext(ctx).Model(&model.EventOutbox{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          model.OutboxDelivered,
+		"delivered_sinks": model.StringArray(sinks),
+		"last_error":      nil,
+		"delivered_at":    gorm.Expr("NOW()"),
+	}).Error
+}
+
+// MarkFailed 記錄投遞失敗，保留已成功的接收端並於 retryAfter 後重試
+func (r *eventOutboxRepository) MarkFailed(ctx context.Context, id int64, sinks []string, attempts int, retryAfter time.Duration, lastErr string) error {
+	return r.db.WithContext(ctx).Model(&model.EventOutbox{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"delivered_sinks": model.StringArray(sinks),
+		"last_error":      lastErr,
+		"next_attempt_at": gorm.Expr("NOW() + ? * INTERVAL '1 millisecond'", retryAfter.Milliseconds()),
+	}).Error
+}
+
+// MarkDead 超過重試上限，移入死信狀態不再自動重試
+func (r *eventOutboxRepository) MarkDead(ctx context.Context, id int64, sinks []string, attempts int, lastErr string) error {
+	return r.db.WithContext(ctx).Model(&model.EventOutbox{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          model.OutboxDead,
+		"attempts":        attempts,
+		"delivered_sinks": model.StringArray(sinks),
+		"last_error":      lastErr,
+	}).Error
+}
+
+// PurgeDelivered 刪除投遞完成超過保留期限的事件
+func (r *eventOutboxRepository) PurgeDelivered(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND delivered_at < NOW() - ? * INTERVAL '1 millisecond'", model.OutboxDelivered, olderThan.Milliseconds()).
+		Delete(&model.EventOutbox{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// IntelligenceSourceRepository 情報來源儲存庫介面
+type IntelligenceSourceRepository interface {
+	Create(ctx context.Context, source *model.IntelligenceSource) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.IntelligenceSource, error)
+	GetByName(ctx context.Context, name string) (*model.IntelligenceSource, error)
+	List(ctx context.Context) ([]*model.IntelligenceSource, error)
+	Update(ctx context.Context, source *model.IntelligenceSource) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	RecordCollection(ctx context.Context, id uuid.UUID, collectedAt time.Time, count int) error
+}
+
+// intelligenceSourceRepository 情報來源儲存庫實作
+type intelligenceSourceRepository struct {
+	db *gorm.DB
+}
+
+// NewIntelligenceSourceRepository 建立情報來源儲存庫
+func NewIntelligenceSourceRepository(db *gorm.DB) IntelligenceSourceRepository {
+	return &intelligenceSourceRepository{db: db}
+}
+
+// Create 建立情報來源
+func (r *intelligenceSourceRepository) Create(ctx context.Context, source *model.IntelligenceSource) error {
+	return r.db.WithContext(ctx).Create(source).Error
+}
+
+// GetByID 根據 ID 取得情報來源
+func (r *intelligenceSourceRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.IntelligenceSource, error) {
+	var source model.IntelligenceSource
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&source).Error
+	if err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+// GetByName 根據名稱取得情報來源
+func (r *intelligenceSourceRepository) GetByName(ctx context.Context, name string) (*model.IntelligenceSource, error) {
+	var source model.IntelligenceSource
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&source).Error
+	if err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+// List 取得所有情報來源
+func (r *intelligenceSourceRepository) List(ctx context.Context) ([]*model.IntelligenceSource, error) {
+	var sources []*model.IntelligenceSource
+	err := r.db.WithContext(ctx).Order("name ASC").Find(&sources).Error
+	return sources, err
+}
+
+// Update 更新情報來源
+func (r *intelligenceSourceRepository) Update(ctx context.Context, source *model.IntelligenceSource) error {
+	return r.db.WithContext(ctx).Save(source).Error
+}
+
+// Delete 刪除情報來源，相關收集任務會一併刪除
+func (r *intelligenceSourceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&model.IntelligenceSource{}, "id = ?", id).Error
+}
+
+// RecordCollection 記錄一次收集結果，僅更新最後收集時間並累加收集數量
+// 避免覆寫管理員同時對來源所做的其他修改
+func (r *intelligenceSourceRepository) RecordCollection(ctx context.Context, id uuid.UUID, collectedAt time.Time, count int) error {
+	return r.db.WithContext(ctx).
+		Model(&model.IntelligenceSource{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_collection": collectedAt,
+			"total_collected": gorm.Expr("total_collected + ?", count),
+			"updated_at":      time.Now(),
+		}).Error
+}
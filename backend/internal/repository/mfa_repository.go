@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// MFARepository 多因素驗證儲存庫介面
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*model.UserMFA, error)
+	SavePending(ctx context.Context, mfa *model.UserMFA) error
+	Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) (bool, error)
+	Disable(ctx context.Context, userID uuid.UUID) error
+	ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error)
+	CreateChallenge(ctx context.Context, challenge *model.MFAChallenge) error
+	GetChallengeByHash(ctx context.Context, tokenHash string) (*model.MFAChallenge, error)
+	ClaimChallengeAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error)
+	CompleteChallenge(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// mfaRepository 多因素驗證儲存庫實作
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+// NewMFARepository 建立多因素驗證儲存庫
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+// GetByUserID 取得使用者的 TOTP 設定
+func (r *mfaRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*model.UserMFA, error) {
+	var mfa model.UserMFA
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// SavePending 保存尚未啟用的共享密鑰，覆寫先前未完成的設定；已啟用的設定不會被覆寫
+func (r *mfaRepository) SavePending(ctx context.Context, mfa *model.UserMFA) error {
+	return r.db.WithContext(ctx).Omit("User").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"secret_encrypted": mfa.SecretEncrypted,
+			"last_used_step":   0,
+			"updated_at":       gorm.Expr("NOW()"),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_mfa.enabled_at IS NULL"}}},
+	}).Create(mfa).Error
+}
+
+// Enable 啟用 TOTP 並建立復原碼；step 為驗證成功的時間步，設定已啟用時回傳 false
+func (r *mfaRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) (bool, error) {
+	enabled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.UserMFA{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"enabled_at":     gorm.Expr("NOW()"),
+				"last_used_step": step,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+			return err
+		}
+		enabled = true
+		return nil
+	})
+	return enabled, err
+}
+
+// Disable 移除 TOTP 設定、復原碼與未完成的登入挑戰
+func (r *mfaRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFAChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.UserMFA{}).Error
+	})
+}
+
+// ConsumeStep 記錄已使用的時間步；只接受比上次更新的時間步，同一代碼或較舊的代碼回傳 false
+func (r *mfaRepository) ConsumeStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.UserMFA{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReplaceRecoveryCodes 以新的一組復原碼取代舊的復原碼
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// ConsumeRecoveryCode 使用一組復原碼，不存在或已使用時回傳 false
+func (r *mfaRepository) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", gorm.Expr("NOW()"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountRecoveryCodes 計算尚未使用的復原碼數量
+func (r *mfaRepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// CreateChallenge 建立登入挑戰
+func (r *mfaRepository) CreateChallenge(ctx context.Context, challenge *model.MFAChallenge) error {
+	return r.db.WithContext(ctx).Omit("User").Create(challenge).Error
+}
+
+// GetChallengeByHash 根據令牌雜湊取得登入挑戰
+func (r *mfaRepository) GetChallengeByHash(ctx context.Context, tokenHash string) (*model.MFAChallenge, error) {
+	var challenge model.MFAChallenge
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// ClaimChallengeAttempt 在驗證代碼前先佔用一次嘗試次數；挑戰已完成、過期或次數用盡時回傳 false
+// 以單一條件更新完成檢查與累加，併發猜測也不會超過上限
+func (r *mfaRepository) ClaimChallengeAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > NOW() AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CompleteChallenge 將挑戰標記為已完成，同一挑戰只能換發一次令牌
+func (r *mfaRepository) CompleteChallenge(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", gorm.Expr("NOW()"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// replaceRecoveryCodes 在交易中刪除舊復原碼並建立新復原碼
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+	codes := make([]*model.MFARecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, &model.MFARecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Omit("User").Create(&codes).Error
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// OIDCRepository 單一登入儲存庫介面
+type OIDCRepository interface {
+	CreateState(ctx context.Context, state *model.OIDCLoginState) error
+	ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, bool, error)
+	GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error)
+	LinkIdentity(ctx context.Context, identity *model.UserIdentity) error
+	TouchIdentity(ctx context.Context, id uuid.UUID, email string) error
+	ProvisionUser(ctx context.Context, user *model.User, identity *model.UserIdentity) error
+}
+
+// oidcRepository 單一登入儲存庫實作
+type oidcRepository struct {
+	db *gorm.DB
+}
+
+// NewOIDCRepository 建立單一登入儲存庫
+func NewOIDCRepository(db *gorm.DB) OIDCRepository {
+	return &oidcRepository{db: db}
+}
+
+// CreateState 保存授權請求狀態
+func (r *oidcRepository) CreateState(ctx context.Context, state *model.OIDCLoginState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// ConsumeState 取出並標記授權請求狀態為已使用；不存在、已使用或已過期時回傳 false
+// 以單一條件更新完成檢查與標記，同一 state 併發回呼也只有一個請求成功
+func (r *oidcRepository) ConsumeState(ctx context.Context, stateHash string) (*model.OIDCLoginState, bool, error) {
+	var state model.OIDCLoginState
+	result := r.db.WithContext(ctx).Model(&state).
+		Clauses(clause.Returning{}).
+		Where("state_hash = ? AND used_at IS NULL AND expires_at > NOW()", stateHash).
+		Update("used_at", gorm.Expr("NOW()"))
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, false, nil
+	}
+	return &state, true, nil
+}
+
+// GetIdentity 根據發行者與 subject 取得外部身分
+func (r *oidcRepository) GetIdentity(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.db.WithContext(ctx).
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// LinkIdentity 將外部身分連結到既有使用者
+func (r *oidcRepository) LinkIdentity(ctx context.Context, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Omit("User").Create(identity).Error
+}
+
+// TouchIdentity 記錄外部身分的最後登入時間與信箱
+func (r *oidcRepository) TouchIdentity(ctx context.Context, id uuid.UUID, email string) error {
+	return r.db.WithContext(ctx).Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"email":         email,
+			"last_login_at": gorm.Expr("NOW()"),
+		}).Error
+}
+
+// ProvisionUser 在同一交易中建立使用者與其外部身分
+func (r *oidcRepository) ProvisionUser(ctx context.Context, user *model.User, identity *model.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Omit("User").Create(identity).Error
+	})
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// PasswordResetTokenRepository 密碼重設令牌儲存庫介面
+type PasswordResetTokenRepository interface {
+	Issue(ctx context.Context, token *model.PasswordResetToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	Redeem(ctx context.Context, tokenID, userID uuid.UUID, passwordHash string) (bool, error)
+}
+
+// passwordResetTokenRepository 密碼重設令牌儲存庫實作
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetTokenRepository 建立密碼重設令牌儲存庫
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+// Issue 建立新令牌並作廢使用者先前尚未使用的令牌，同一時間只有最新一封重設信有效
+func (r *passwordResetTokenRepository) Issue(ctx context.Context, token *model.PasswordResetToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", gorm.Expr("NOW()")).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(token).Error
+	})
+}
+
+// GetByHash 根據令牌雜湊取得重設令牌
+func (r *passwordResetTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Redeem 使用令牌並更新使用者密碼，兩者在同一交易中完成
+// 以條件更新搶占令牌，令牌已使用或過期時回傳 false 且不變更密碼，同一令牌不會被使用兩次
+func (r *passwordResetTokenRepository) Redeem(ctx context.Context, tokenID, userID uuid.UUID, passwordHash string) (bool, error) {
+	redeemed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > NOW()", tokenID).
+			Update("used_at", gorm.Expr("NOW()"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		err := tx.Model(&model.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"password_hash": passwordHash,
+				"updated_at":    gorm.Expr("NOW()"),
+			}).Error
+		if err != nil {
+			return err
+		}
+		redeemed = true
+		return nil
+	})
+	return redeemed, err
+}
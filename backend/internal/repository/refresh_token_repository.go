@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// RefreshTokenRepository 刷新令牌儲存庫介面
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.RefreshToken, error)
+	Rotate(ctx context.Context, id uuid.UUID, next *model.RefreshToken) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) (int64, error)
+	RevokeByUser(ctx context.Context, userID uuid.UUID, reason string) (int64, error)
+}
+
+// refreshTokenRepository 刷新令牌儲存庫實作
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository 建立刷新令牌儲存庫
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create 建立刷新令牌紀錄
+func (r *refreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	return r.db.WithContext(ctx).Omit("User").Create(token).Error
+}
+
+// GetByID 根據 jti 取得刷新令牌
+func (r *refreshTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate 將令牌標記為已使用並在同一交易中建立下一個令牌
+// 以條件更新搶占，令牌已被使用、撤銷或過期時回傳 false 且不建立新令牌，併發請求只有一個會成功
+func (r *refreshTokenRepository) Rotate(ctx context.Context, id uuid.UUID, next *model.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()", id).
+			Update("rotated_at", gorm.Expr("NOW()"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Omit("User").Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+// RevokeFamily 撤銷家族中所有尚未撤銷的令牌，回傳撤銷數量
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]interface{}{
+			"revoked_at":     gorm.Expr("NOW()"),
+			"revoked_reason": reason,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// RevokeByUser 撤銷使用者所有尚未撤銷的令牌，回傳撤銷數量
+func (r *refreshTokenRepository) RevokeByUser(ctx context.Context, userID uuid.UUID, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at":     gorm.Expr("NOW()"),
+			"revoked_reason": reason,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// RoleRepository 角色儲存庫介面
+type RoleRepository interface {
+	List(ctx context.Context) ([]*model.Role, error)
+	GetByName(ctx context.Context, name string) (*model.Role, error)
+	Create(ctx context.Context, role *model.Role) (bool, error)
+	Update(ctx context.Context, name string, updates map[string]interface{}) (bool, error)
+	Delete(ctx context.Context, name string) (bool, error)
+	EnsureRoles(ctx context.Context, roles []model.Role) error
+	AssignUserRole(ctx context.Context, userID uuid.UUID, name string) (bool, error)
+	SyncUserRole(ctx context.Context, userID uuid.UUID, name string) (bool, error)
+}
+
+// roleRepository 角色儲存庫實作
+type roleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository 建立角色儲存庫
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// List 取得所有角色，內建角色排在前面
+func (r *roleRepository) List(ctx context.Context) ([]*model.Role, error) {
+	var roles []*model.Role
+	err := r.db.WithContext(ctx).Order("is_system DESC, name ASC").Find(&roles).Error
+	return roles, err
+}
+
+// GetByName 根據名稱取得角色
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// Create 建立角色；名稱已存在時回傳 false
+func (r *roleRepository) Create(ctx context.Context, role *model.Role) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(role)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Update 更新角色；角色不存在時回傳 false
+func (r *roleRepository) Update(ctx context.Context, name string, updates map[string]interface{}) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Role{}).Where("name = ?", name).Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete 刪除未被使用的自訂角色；內建角色、仍有使用者或角色不存在時回傳 false
+// 以單一條件刪除完成檢查，避免檢查後到刪除前又有使用者被指派此角色
+func (r *roleRepository) Delete(ctx context.Context, name string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("name = ? AND is_system = ?", name, false).
+		Where("NOT EXISTS (SELECT 1 FROM users WHERE users.role = ?)", name).
+		Delete(&model.Role{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// EnsureRoles 建立尚不存在的角色，已存在的角色維持不變
+func (r *roleRepository) EnsureRoles(ctx context.Context, roles []model.Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(&roles).Error
+}
+
+// AssignUserRole 由管理員指派使用者角色，之後不再由單一登入同步；使用者或角色不存在時回傳 false
+func (r *roleRepository) AssignUserRole(ctx context.Context, userID uuid.UUID, name string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", userID).
+		Where("EXISTS (SELECT 1 FROM roles WHERE roles.name = ?)", name).
+		Updates(map[string]interface{}{
+			"role":                   name,
+			"role_assigned_by_admin": true,
+			"updated_at":             gorm.Expr("NOW()"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// SyncUserRole 以外部身分提供者的角色更新使用者角色
+// 管理員指派過角色、角色未變更、使用者或角色不存在時回傳 false；條件在同一個 UPDATE 中判斷，避免覆寫同時進行的管理員指派
+func (r *roleRepository) SyncUserRole(ctx context.Context, userID uuid.UUID, name string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND role <> ? AND role_assigned_by_admin = ?", userID, name, false).
+		Where("EXISTS (SELECT 1 FROM roles WHERE roles.name = ?)", name).
+		Updates(map[string]interface{}{
+			"role":       name,
+			"updated_at": gorm.Expr("NOW()"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/testutil"
+)
+
+// indicatorTypesMigration 將 IP 欄位改為指標類型的遷移版本
+const indicatorTypesMigration = "20241215000000"
+
+func TestIndicatorTypesMigration_HIBPPlaceholders(t *testing.T) {
+	db := testutil.OpenPostgresAt(t, indicatorTypesMigration)
+
+	// 舊版 HIBP 收集器以 0.0.0.0 佔位，沒有域名的事件寫入空字串；每個帳號各寫入一筆
+	require.NoError(t, db.Exec(`
+		INSERT INTO threat_intelligence (ip_address, domain, threat_type, severity, source, external_id, fingerprint)
+		VALUES ('0.0.0.0', 'Adobe.com', 'other', 'critical', 'HaveIBeenPwned', 'Adobe', 'legacy-1'),
+		       ('0.0.0.0', 'Adobe.com', 'other', 'critical', 'HaveIBeenPwned', 'Adobe', 'legacy-2'),
+		       ('0.0.0.0', '', 'other', 'high', 'HaveIBeenPwned', 'Collection1', 'legacy-3'),
+		       ('0.0.0.0', NULL, 'other', 'low', 'HaveIBeenPwned', 'SpamList', 'legacy-4'),
+		       ('198.51.100.7', NULL, 'botnet', 'high', 'AbuseIPDB', NULL, 'legacy-5')
+	`).Error)
+
+	testutil.MigratePostgres(t, db, indicatorTypesMigration)
+	repo := NewThreatIntelligenceRepository(db)
+	ctx := context.Background()
+
+	placeholders, err := repo.GetByIP(ctx, net.ParseIP("0.0.0.0"))
+	require.NoError(t, err)
+	assert.Empty(t, placeholders, "breaches must not match an IP lookup for 0.0.0.0")
+
+	var breaches []*model.ThreatIntelligence
+	require.NoError(t, db.Where("source = ?", "HaveIBeenPwned").Find(&breaches).Error)
+	require.Len(t, breaches, 1)
+	assert.Equal(t, model.IndicatorDomain, breaches[0].IndicatorType)
+	assert.Equal(t, "adobe.com", breaches[0].IndicatorValue)
+	assert.Nil(t, breaches[0].IPAddress)
+
+	ipThreats, err := repo.GetByIP(ctx, net.ParseIP("198.51.100.7"))
+	require.NoError(t, err)
+	require.Len(t, ipThreats, 1)
+	assert.Equal(t, model.IndicatorIPv4, ipThreats[0].IndicatorType)
+}
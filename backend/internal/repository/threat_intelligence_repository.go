@@ -3,34 +3,47 @@ package repository
 import (
 	"context"
 	"fmt"
+	"html"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dsl"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
 )
 
 // ThreatIntelligenceRepository 威脅情報儲存庫介面
 type ThreatIntelligenceRepository interface {
 	Create(ctx context.Context, threat *model.ThreatIntelligence) error
+	Upsert(ctx context.Context, threat *model.ThreatIntelligence) (bool, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*model.ThreatIntelligence, error)
 	GetByIP(ctx context.Context, ip net.IP) ([]*model.ThreatIntelligence, error)
 	GetByDomain(ctx context.Context, domain string) ([]*model.ThreatIntelligence, error)
+	GetByIndicator(ctx context.Context, indicatorType model.IndicatorType, value string) ([]*model.ThreatIntelligence, error)
 	Update(ctx context.Context, threat *model.ThreatIntelligence) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, filter *ThreatIntelligenceFilter) ([]*model.ThreatIntelligence, int64, error)
+	Search(ctx context.Context, query string, filter *ThreatIntelligenceFilter) ([]*ThreatSearchResult, int64, error)
 	BulkCreate(ctx context.Context, threats []*model.ThreatIntelligence) error
+	BulkUpsert(ctx context.Context, threats []*model.ThreatIntelligence) ([]bool, error)
 	GetStats(ctx context.Context, filter *StatsFilter) (*ThreatIntelligenceStats, error)
+	GetTimeline(ctx context.Context, filter *TimelineFilter) ([]TimelinePoint, error)
+	GetTopValues(ctx context.Context, field string, limit int) ([]ValueCount, error)
 	GetRecentThreats(ctx context.Context, hours int, limit int) ([]*model.ThreatIntelligence, error)
 	GetHighRiskThreats(ctx context.Context, limit int) ([]*model.ThreatIntelligence, error)
+	AddOutboxEvents(ctx context.Context, events ...*model.EventOutbox) error
+	Transaction(ctx context.Context, fn func(repo ThreatIntelligenceRepository) error) error
 }
 
 // ThreatIntelligenceFilter 威脅情報篩選器
 type ThreatIntelligenceFilter struct {
-	IPAddress   *string
-	Domain      *string
+	IPAddress     *string
+	Domain        *string
+	IndicatorType *string
 	ThreatType  *string
 	Severity    *string
 	Source      *string
@@ -38,37 +51,101 @@ type ThreatIntelligenceFilter struct {
 	Tags        []string
 	StartTime   *time.Time
 	EndTime     *time.Time
+	Query       dsl.Node // 查詢語言條件，與其他篩選條件以 AND 結合
 	Page        int
 	PageSize    int
 	SortBy      string
 	SortOrder   string
 }
 
+// ThreatSearchResult 全文搜尋結果
+type ThreatSearchResult struct {
+	Threat     *model.ThreatIntelligence
+	Rank       float64
+	Highlights map[string]string // 已 HTML 跳脫的命中片段，僅以 <mark></mark> 標示命中字詞
+}
+
 // StatsFilter 統計篩選器
 type StatsFilter struct {
-	GroupBy   string
+	TimeField string // 時間範圍套用的欄位：created_at 或 last_seen
 	StartTime *time.Time
 	EndTime   *time.Time
 }
 
+// TimelineFilter 時間線篩選器
+type TimelineFilter struct {
+	Interval  string // hour、day 或 week
+	TimeField string // created_at 或 last_seen
+	GroupBy   string // 細分維度，空值表示不細分
+	StartTime time.Time
+	EndTime   time.Time
+}
+
 // ThreatIntelligenceStats 威脅情報統計
 type ThreatIntelligenceStats struct {
-	TotalThreats    int64
-	HighRiskCount   int64
-	RecentCount     int64
-	CountByType     map[string]int64
-	CountBySeverity map[string]int64
-	CountBySource   map[string]int64
-	CountByCountry  map[string]int64
-	Timeline        []TimelinePoint
+	TotalThreats         int64
+	ActiveCount          int64
+	HighRiskCount        int64
+	MediumRiskCount      int64
+	LowRiskCount         int64
+	RecentCount          int64
+	CountByType          map[string]int64
+	CountBySeverity      map[string]int64
+	CountBySource        map[string]int64
+	CountByCountry       map[string]int64
+	CountByIndicatorType map[string]int64
 }
 
 // TimelinePoint 時間線點
 type TimelinePoint struct {
-	Date  string
+	Bucket    time.Time
+	Count     int64
+	Breakdown map[string]int64
+}
+
+// ValueCount 欄位值出現次數
+type ValueCount struct {
+	Value string
 	Count int64
 }
 
+// Timeline 相關常數
+const (
+	// MaxTimelineBuckets 單次查詢的時間區間數量上限
+	MaxTimelineBuckets = 1000
+	// ActiveThreatWindow 最後出現時間在此範圍內的威脅視為活躍
+	ActiveThreatWindow = 30 * 24 * time.Hour
+)
+
+// statsTimeColumns 可用於統計時間範圍的欄位
+var statsTimeColumns = map[string]string{
+	"created_at": "created_at",
+	"last_seen":  "last_seen",
+}
+
+// statsGroupColumns 可用於分組統計的欄位，threat_type 與 severity 為 ENUM，與文字比較前須轉型
+var statsGroupColumns = map[string]string{
+	"threat_type":    "threat_type",
+	"severity":       "severity",
+	"source":         "source",
+	"country_code":   "country_code",
+	"indicator_type": "indicator_type",
+}
+
+// topValueColumns 可用於排行的欄位，值皆轉為文字
+var topValueColumns = map[string]string{
+	"ip_address": "host(ip_address)",
+	"domain":     "domain",
+	"asn":        "asn::text",
+}
+
+// timelineSteps 時間線間隔
+var timelineSteps = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
 // threatIntelligenceRepository 威脅情報儲存庫實作
 type threatIntelligenceRepository struct {
 	db *gorm.DB
@@ -84,6 +161,60 @@ func (r *threatIntelligenceRepository) Create(ctx context.Context, threat *model
 	return r.db.WithContext(ctx).Create(threat).Error
 }
 
+// Upsert 依自然鍵建立或更新威脅情報，回傳是否為新建立的記錄
+// 重複出現時僅推進 LastSeen、合併 Tags 與 Metadata，並保留原本的 FirstSeen
+func (r *threatIntelligenceRepository) Upsert(ctx context.Context, threat *model.ThreatIntelligence) (bool, error) {
+	return upsertThreat(r.db.WithContext(ctx), threat)
+}
+
+// BulkUpsert 在單一交易中批量建立或更新威脅情報，回傳每筆是否為新建立的記錄
+func (r *threatIntelligenceRepository) BulkUpsert(ctx context.Context, threats []*model.ThreatIntelligence) ([]bool, error) {
+	created := make([]bool, len(threats))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, threat := range threats {
+			isNew, err := upsertThreat(tx, threat)
+			if err != nil {
+				return err
+			}
+			created[i] = isNew
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// upsertThreat 以 fingerprint 衝突處理執行 upsert
+// 新記錄的 ID 由應用程式產生，若回傳的 ID 不同代表命中既有記錄
+func upsertThreat(db *gorm.DB, threat *model.ThreatIntelligence) (bool, error) {
+	newID := uuid.New()
+	threat.ID = newID
+
+	err := db.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "fingerprint"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "last_seen"}, Value: gorm.Expr("GREATEST(threat_intelligence.last_seen, EXCLUDED.last_seen)")},
+				{Column: clause.Column{Name: "tags"}, Value: gorm.Expr("ARRAY(SELECT DISTINCT unnest(COALESCE(threat_intelligence.tags, '{}') || COALESCE(EXCLUDED.tags, '{}')))")},
+				{Column: clause.Column{Name: "metadata"}, Value: gorm.Expr("COALESCE(NULLIF(threat_intelligence.metadata, 'null'::jsonb), '{}'::jsonb) || COALESCE(NULLIF(EXCLUDED.metadata, 'null'::jsonb), '{}'::jsonb)")},
+				{Column: clause.Column{Name: "description"}, Value: gorm.Expr("COALESCE(EXCLUDED.description, threat_intelligence.description)")},
+				{Column: clause.Column{Name: "country_code"}, Value: gorm.Expr("COALESCE(EXCLUDED.country_code, threat_intelligence.country_code)")},
+				{Column: clause.Column{Name: "asn"}, Value: gorm.Expr("COALESCE(EXCLUDED.asn, threat_intelligence.asn)")},
+				{Column: clause.Column{Name: "isp"}, Value: gorm.Expr("COALESCE(EXCLUDED.isp, threat_intelligence.isp)")},
+				{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("CURRENT_TIMESTAMP")},
+			},
+		},
+		clause.Returning{},
+	).Create(threat).Error
+	if err != nil {
+		return false, err
+	}
+
+	return threat.ID == newID, nil
+}
+
 // GetByID 根據 ID 取得威脅情報
 func (r *threatIntelligenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.ThreatIntelligence, error) {
 	var threat model.ThreatIntelligence
@@ -94,10 +225,14 @@ func (r *threatIntelligenceRepository) GetByID(ctx context.Context, id uuid.UUID
 	return &threat, nil
 }
 
-// GetByIP 根據 IP 取得威脅情報
+// GetByIP 根據 IP 取得威脅情報，包含涵蓋該 IP 的 CIDR 網段指標
 func (r *threatIntelligenceRepository) GetByIP(ctx context.Context, ip net.IP) ([]*model.ThreatIntelligence, error) {
 	var threats []*model.ThreatIntelligence
-	err := r.db.WithContext(ctx).Where("ip_address = ?", ip).Find(&threats).Error
+	err := r.db.WithContext(ctx).
+		Where("ip_address = ?::inet", ip.String()).
+		Or("indicator_type = ? AND indicator_value::cidr >>= ?::inet", model.IndicatorCIDR, ip.String()).
+		Order("last_seen DESC").
+		Find(&threats).Error
 	return threats, err
 }
 
@@ -108,14 +243,63 @@ func (r *threatIntelligenceRepository) GetByDomain(ctx context.Context, domain s
 	return threats, err
 }
 
+// GetByIndicator 根據正規化後的指標取得威脅情報
+// 除完全相符的指標外，也會帶出相關記錄：IP 所屬的網段、網段內的 IP、域名下的 URL/Email、ASN 內的 IP
+func (r *threatIntelligenceRepository) GetByIndicator(ctx context.Context, indicatorType model.IndicatorType, value string) ([]*model.ThreatIntelligence, error) {
+	if indicatorType.IsIP() {
+		return r.GetByIP(ctx, net.ParseIP(value))
+	}
+
+	query := r.db.WithContext(ctx).Where("indicator_type = ? AND indicator_value = ?", indicatorType, value)
+	switch {
+	case indicatorType == model.IndicatorCIDR:
+		query = query.Or("ip_address <<= ?::cidr", value)
+	case indicatorType == model.IndicatorDomain:
+		query = query.Or("domain = ?", value)
+	case indicatorType.IsHash():
+		query = query.Or("file_hash = ?", value)
+	case indicatorType == model.IndicatorASN:
+		if asn, err := model.ParseASN(value); err == nil {
+			query = query.Or("asn = ?", asn)
+		}
+	}
+
+	var threats []*model.ThreatIntelligence
+	err := query.Order("last_seen DESC").Find(&threats).Error
+	return threats, err
+}
+
 // Update 更新威脅情報
 func (r *threatIntelligenceRepository) Update(ctx context.Context, threat *model.ThreatIntelligence) error {
 	return r.db.WithContext(ctx).Save(threat).Error
 }
 
-// Delete 刪除威脅情報
+// Delete 刪除威脅情報，記錄不存在時回傳 gorm.ErrRecordNotFound
 func (r *threatIntelligenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&model.ThreatIntelligence{}, id).Error
+	result := r.db.WithContext(ctx).Delete(&model.ThreatIntelligence{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Transaction 在交易中執行 fn，fn 回傳錯誤時回滾
+// 在交易內再次呼叫時會建立 savepoint，可只回滾單一步驟
+func (r *threatIntelligenceRepository) Transaction(ctx context.Context, fn func(repo ThreatIntelligenceRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&threatIntelligenceRepository{db: tx})
+	})
+}
+
+// AddOutboxEvents 寫入待投遞事件；須在與資料異動相同的交易中呼叫，確保兩者一併提交或回滾
+func (r *threatIntelligenceRepository) AddOutboxEvents(ctx context.Context, events ...*model.EventOutbox) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(events).Error
 }
 
 // List 取得威脅情報列表
@@ -149,6 +333,124 @@ func (r *threatIntelligenceRepository) List(ctx context.Context, filter *ThreatI
 	return threats, total, err
 }
 
+// searchTSQuery 將使用者輸入轉換為 tsquery，支援引號片語、OR 與 -排除
+// 設定檔需與 search_vector 觸發器一致
+const searchTSQuery = "websearch_to_tsquery('simple', ?)"
+
+// searchHeadlineOptions 命中片段的標記設定
+// 以控制字元 STX/ETX 作為標記，跳脫原文後再換成 <mark>，避免情資內容中的 HTML 原樣輸出
+const searchHeadlineOptions = "'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxWords=35, MinWords=15, MaxFragments=2'"
+
+const (
+	searchHighlightStart = "\x02"
+	searchHighlightStop  = "\x03"
+)
+
+// searchHighlightReplacer 將控制字元標記換成 HTML 標籤
+var searchHighlightReplacer = strings.NewReplacer(searchHighlightStart, "<mark>", searchHighlightStop, "</mark>")
+
+// searchSortColumns 搜尋允許的排序欄位
+var searchSortColumns = map[string]string{
+	"created_at":       "created_at",
+	"updated_at":       "updated_at",
+	"last_seen":        "last_seen",
+	"confidence_score": "confidence_score",
+}
+
+// searchRow 搜尋查詢的掃描結構
+type searchRow struct {
+	model.ThreatIntelligence `gorm:"embedded"`
+	Rank                     float64
+	DescriptionHighlight     string
+	DomainHighlight          string
+	ISPHighlight             string
+	TagsHighlight            string
+	MetadataHighlight        string
+}
+
+// Search 以 PostgreSQL 全文檢索搜尋描述、域名、標籤、ISP 與元資料
+// 結果依相關度排序並附上命中片段，可搭配篩選器縮小範圍
+func (r *threatIntelligenceRepository) Search(ctx context.Context, query string, filter *ThreatIntelligenceFilter) ([]*ThreatSearchResult, int64, error) {
+	newQuery := func() *gorm.DB {
+		q := r.db.WithContext(ctx).
+			Model(&model.ThreatIntelligence{}).
+			Where("search_vector @@ "+searchTSQuery, query)
+		return r.applyFilter(q, filter)
+	}
+
+	var total int64
+	if err := newQuery().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*ThreatSearchResult{}, 0, nil
+	}
+
+	headline := func(column string) string {
+		// 先移除原文中的標記字元，避免偽造命中標記
+		return fmt.Sprintf("ts_headline('simple', translate(COALESCE(%s, ''), chr(2) || chr(3), ''), %s, %s)", column, searchTSQuery, searchHeadlineOptions)
+	}
+	selectSQL := strings.Join([]string{
+		"threat_intelligence.*",
+		"ts_rank_cd(search_vector, " + searchTSQuery + ", 32) AS rank",
+		headline("description") + " AS description_highlight",
+		headline("domain") + " AS domain_highlight",
+		headline("isp") + " AS isp_highlight",
+		headline("array_to_string(tags, ' ')") + " AS tags_highlight",
+		headline("metadata::text") + " AS metadata_highlight",
+	}, ", ")
+	args := make([]interface{}, 6)
+	for i := range args {
+		args[i] = query
+	}
+
+	rowsQuery := newQuery().Select(selectSQL, args...)
+
+	direction := "DESC"
+	if strings.EqualFold(filter.SortOrder, "asc") {
+		direction = "ASC"
+	}
+	if column, ok := searchSortColumns[filter.SortBy]; ok {
+		rowsQuery = rowsQuery.Order(fmt.Sprintf("%s %s", column, direction)).Order("rank DESC")
+	} else {
+		rowsQuery = rowsQuery.Order("rank " + direction).Order("last_seen DESC")
+	}
+
+	if filter.Page > 0 && filter.PageSize > 0 {
+		rowsQuery = rowsQuery.Offset((filter.Page - 1) * filter.PageSize).Limit(filter.PageSize)
+	}
+
+	var rows []searchRow
+	if err := rowsQuery.Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]*ThreatSearchResult, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		highlights := make(map[string]string)
+		for field, fragment := range map[string]string{
+			"description": row.DescriptionHighlight,
+			"domain":      row.DomainHighlight,
+			"isp":         row.ISPHighlight,
+			"tags":        row.TagsHighlight,
+			"metadata":    row.MetadataHighlight,
+		} {
+			// ts_headline 在未命中時仍會回傳原文開頭，只保留真正命中的欄位
+			if strings.Contains(fragment, searchHighlightStart) {
+				highlights[field] = searchHighlightReplacer.Replace(html.EscapeString(fragment))
+			}
+		}
+		results[i] = &ThreatSearchResult{
+			Threat:     &row.ThreatIntelligence,
+			Rank:       row.Rank,
+			Highlights: highlights,
+		}
+	}
+
+	return results, total, nil
+}
+
 // BulkCreate 批量建立威脅情報
 func (r *threatIntelligenceRepository) BulkCreate(ctx context.Context, threats []*model.ThreatIntelligence) error {
 	return r.db.WithContext(ctx).CreateInBatches(threats, 100).Error
@@ -156,45 +458,199 @@ func (r *threatIntelligenceRepository) BulkCreate(ctx context.Context, threats [
 
 // GetStats 取得統計資料
 func (r *threatIntelligenceRepository) GetStats(ctx context.Context, filter *StatsFilter) (*ThreatIntelligenceStats, error) {
+	timeColumn, ok := statsTimeColumns[filter.TimeField]
+	if !ok {
+		timeColumn = "created_at"
+	}
+
+	// 每次統計都需要全新的查詢，避免條件與 SELECT 在鏈式呼叫間累積
+	newQuery := func() *gorm.DB {
+		query := r.db.WithContext(ctx).Model(&model.ThreatIntelligence{})
+		if filter.StartTime != nil {
+			query = query.Where(timeColumn+" >= ?", *filter.StartTime)
+		}
+		if filter.EndTime != nil {
+			query = query.Where(timeColumn+" < ?", *filter.EndTime)
+		}
+		return query
+	}
+
+	now := time.Now()
+	var totals struct {
+		Total      int64
+		Active     int64
+		HighRisk   int64
+		MediumRisk int64
+		LowRisk    int64
+		Recent     int64
+	}
+	err := newQuery().Select(
+		"COUNT(*) AS total, "+
+			"COUNT(*) FILTER (WHERE last_seen >= ?) AS active, "+
+			"COUNT(*) FILTER (WHERE severity IN ('high', 'critical')) AS high_risk, "+
+			"COUNT(*) FILTER (WHERE severity = 'medium') AS medium_risk, "+
+			"COUNT(*) FILTER (WHERE severity = 'low') AS low_risk, "+
+			"COUNT(*) FILTER (WHERE last_seen >= ?) AS recent",
+		now.Add(-ActiveThreatWindow), now.Add(-24*time.Hour),
+	).Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count threats: %w", err)
+	}
+
 	stats := &ThreatIntelligenceStats{
-		CountByType:     make(map[string]int64),
-		CountBySeverity: make(map[string]int64),
-		CountBySource:   make(map[string]int64),
-		CountByCountry:  make(map[string]int64),
+		TotalThreats:    totals.Total,
+		ActiveCount:     totals.Active,
+		HighRiskCount:   totals.HighRisk,
+		MediumRiskCount: totals.MediumRisk,
+		LowRiskCount:    totals.LowRisk,
+		RecentCount:     totals.Recent,
 	}
 
-	query := r.db.WithContext(ctx).Model(&model.ThreatIntelligence{})
-	
-	// 應用時間篩選
-	if filter.StartTime != nil {
-		query = query.Where("created_at >= ?", filter.StartTime)
+	groups := []struct {
+		field  string
+		result *map[string]int64
+	}{
+		{"threat_type", &stats.CountByType},
+		{"severity", &stats.CountBySeverity},
+		{"source", &stats.CountBySource},
+		{"country_code", &stats.CountByCountry},
+		{"indicator_type", &stats.CountByIndicatorType},
 	}
-	if filter.EndTime != nil {
-		query = query.Where("created_at <= ?", filter.EndTime)
+	for _, group := range groups {
+		counts, err := r.getCountByField(newQuery(), group.field)
+		if err != nil {
+			return nil, err
+		}
+		*group.result = counts
 	}
 
-	// 總威脅數
-	query.Count(&stats.TotalThreats)
+	return stats, nil
+}
 
-	// 高風險威脅數
-	query.Where("severity IN ?", []string{"high", "critical"}).Count(&stats.HighRiskCount)
+// GetTimeline 取得依時間區間彙總的威脅數量，無資料的區間補零
+func (r *threatIntelligenceRepository) GetTimeline(ctx context.Context, filter *TimelineFilter) ([]TimelinePoint, error) {
+	if _, ok := timelineSteps[filter.Interval]; !ok {
+		return nil, fmt.Errorf("unsupported timeline interval %q", filter.Interval)
+	}
+	timeColumn, ok := statsTimeColumns[filter.TimeField]
+	if !ok {
+		return nil, fmt.Errorf("unsupported timeline field %q", filter.TimeField)
+	}
+	labelExpr := "''"
+	if filter.GroupBy != "" {
+		column, ok := statsGroupColumns[filter.GroupBy]
+		if !ok {
+			return nil, fmt.Errorf("unsupported timeline group %q", filter.GroupBy)
+		}
+		labelExpr = "COALESCE(NULLIF(" + column + "::text, ''), 'unknown')"
+	}
 
-	// 最近威脅數（24小時內）
-	query.Where("last_seen >= ?", time.Now().Add(-24*time.Hour)).Count(&stats.RecentCount)
+	if !filter.EndTime.After(filter.StartTime) {
+		return nil, fmt.Errorf("timeline end time must be after start time")
+	}
+	start := truncateTimelineBucket(filter.StartTime, filter.Interval)
+	buckets := TimelineBucketCount(filter.StartTime, filter.EndTime, filter.Interval)
+	if buckets > MaxTimelineBuckets {
+		return nil, fmt.Errorf("timeline range produces %d buckets, limit is %d", buckets, MaxTimelineBuckets)
+	}
 
-	// 按類型統計
-	r.getCountByField(ctx, query, "threat_type", stats.CountByType)
-	
-	// 按嚴重程度統計
-	r.getCountByField(ctx, query, "severity", stats.CountBySeverity)
-	
-	// 按來源統計
-	r.getCountByField(ctx, query, "source", stats.CountBySource)
-	
-	// 按國家統計
-	r.getCountByField(ctx, query, "country_code", stats.CountByCountry)
+	var rows []struct {
+		Bucket time.Time
+		Label  string
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.ThreatIntelligence{}).
+		Select("date_trunc(?, "+timeColumn+") AS bucket, "+labelExpr+" AS label, COUNT(*) AS count", filter.Interval).
+		Where(timeColumn+" >= ? AND "+timeColumn+" < ?", filter.StartTime, filter.EndTime).
+		Group("bucket, label").
+		Order("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeline: %w", err)
+	}
 
-	return stats, nil
+	points := make([]TimelinePoint, 0, buckets)
+	index := make(map[int64]int, buckets)
+	for bucket := start; bucket.Before(filter.EndTime); bucket = nextTimelineBucket(bucket, filter.Interval) {
+		point := TimelinePoint{Bucket: bucket}
+		if filter.GroupBy != "" {
+			point.Breakdown = make(map[string]int64)
+		}
+		index[bucket.Unix()] = len(points)
+		points = append(points, point)
+	}
+
+	for _, row := range rows {
+		i, ok := index[truncateTimelineBucket(row.Bucket, filter.Interval).Unix()]
+		if !ok {
+			continue
+		}
+		points[i].Count += row.Count
+		if points[i].Breakdown != nil {
+			points[i].Breakdown[row.Label] += row.Count
+		}
+	}
+
+	return points, nil
+}
+
+// GetTopValues 取得出現在最多威脅記錄中的欄位值，次數相同時以最高信心分數排序
+func (r *threatIntelligenceRepository) GetTopValues(ctx context.Context, field string, limit int) ([]ValueCount, error) {
+	column, ok := topValueColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported top value field %q", field)
+	}
+
+	var values []ValueCount
+	err := r.db.WithContext(ctx).
+		Model(&model.ThreatIntelligence{}).
+		Select(column+" AS value, COUNT(*) AS count").
+		Where(column + " IS NOT NULL AND " + column + " <> ''").
+		Group("value").
+		Order("count DESC, MAX(confidence_score) DESC, value").
+		Limit(limit).
+		Scan(&values).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top %s values: %w", field, err)
+	}
+	return values, nil
+}
+
+// TimelineBucketCount 計算時間範圍涵蓋的區間數量
+func TimelineBucketCount(start, end time.Time, interval string) int {
+	step, ok := timelineSteps[interval]
+	if !ok || !end.After(start) {
+		return 0
+	}
+	first := truncateTimelineBucket(start, interval)
+	return int((end.Sub(first) + step - 1) / step)
+}
+
+// truncateTimelineBucket 將時間截斷至所屬區間的起點（UTC，週以星期一為起點，與 PostgreSQL date_trunc 一致）
+func truncateTimelineBucket(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case "hour":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.UTC)
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// nextTimelineBucket 取得下一個區間起點
+func nextTimelineBucket(t time.Time, interval string) time.Time {
+	switch interval {
+	case "hour":
+		return t.Add(time.Hour)
+	case "week":
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
 }
 
 // GetRecentThreats 取得最近威脅
@@ -232,6 +688,9 @@ func (r *threatIntelligenceRepository) applyFilter(query *gorm.DB, filter *Threa
 	if filter.Domain != nil {
 		query = query.Where("domain = ?", *filter.Domain)
 	}
+	if filter.IndicatorType != nil {
+		query = query.Where("indicator_type = ?", *filter.IndicatorType)
+	}
 	if filter.ThreatType != nil {
 		query = query.Where("threat_type = ?", *filter.ThreatType)
 	}
@@ -245,7 +704,8 @@ func (r *threatIntelligenceRepository) applyFilter(query *gorm.DB, filter *Threa
 		query = query.Where("country_code = ?", *filter.CountryCode)
 	}
 	if len(filter.Tags) > 0 {
-		query = query.Where("tags && ?", filter.Tags)
+		// 以單一 text[] 參數綁定，避免 gorm 將切片展開為 (?, ?) 列表
+		query = query.Where("tags && ?::text[]", model.StringArray(filter.Tags))
 	}
 	if filter.StartTime != nil {
 		query = query.Where("created_at >= ?", filter.StartTime)
@@ -253,21 +713,36 @@ func (r *threatIntelligenceRepository) applyFilter(query *gorm.DB, filter *Threa
 	if filter.EndTime != nil {
 		query = query.Where("created_at <= ?", filter.EndTime)
 	}
+	if filter.Query != nil {
+		expr, err := compileThreatQuery(filter.Query)
+		if err != nil {
+			_ = query.AddError(err)
+			return query
+		}
+		query = query.Where(expr)
+	}
 	return query
 }
 
-// getCountByField 根據欄位取得統計數據
-func (r *threatIntelligenceRepository) getCountByField(ctx context.Context, query *gorm.DB, field string, result map[string]int64) {
+// getCountByField 根據欄位取得統計數據，忽略欄位為空的記錄
+// threat_type 與 severity 為 ENUM 欄位，須先轉為文字才能與空字串比較
+func (r *threatIntelligenceRepository) getCountByField(query *gorm.DB, field string) (map[string]int64, error) {
 	var counts []struct {
 		Field string
 		Count int64
 	}
-	
-	query.Select(fmt.Sprintf("%s as field, count(*) as count", field)).
+
+	err := query.Select(fmt.Sprintf("%s::text AS field, COUNT(*) AS count", field)).
+		Where(fmt.Sprintf("%s IS NOT NULL AND %s::text <> ''", field, field)).
 		Group(field).
-		Scan(&counts)
-	
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count threats by %s: %w", field, err)
+	}
+
+	result := make(map[string]int64, len(counts))
 	for _, count := range counts {
 		result[count.Field] = count.Count
 	}
-} 
\ No newline at end of file
+	return result, nil
+}
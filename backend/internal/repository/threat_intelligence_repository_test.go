@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/testutil"
+)
+
+// newTestThreat 建立測試用 IPv4 威脅情報
+func newTestThreat(ip string, threatType model.ThreatType, severity model.SeverityLevel) *model.ThreatIntelligence {
+	now := time.Now()
+	return &model.ThreatIntelligence{
+		IndicatorType:   model.IndicatorIPv4,
+		IndicatorValue:  ip,
+		IPAddress:       net.ParseIP(ip),
+		ThreatType:      threatType,
+		Severity:        severity,
+		ConfidenceScore: 80,
+		Source:          "test",
+		FirstSeen:       now,
+		LastSeen:        now,
+	}
+}
+
+// seedThreats 寫入測試用威脅情報
+func seedThreats(t *testing.T, repo ThreatIntelligenceRepository, threats ...*model.ThreatIntelligence) {
+	t.Helper()
+	for _, threat := range threats {
+		require.NoError(t, repo.Create(context.Background(), threat))
+	}
+}
+
+func TestThreatIntelligenceRepository_GetStats(t *testing.T) {
+	repo := NewThreatIntelligenceRepository(testutil.OpenPostgres(t))
+	seedThreats(t, repo,
+		newTestThreat("203.0.113.1", model.ThreatMalware, model.SeverityCritical),
+		newTestThreat("203.0.113.2", model.ThreatMalware, model.SeverityHigh),
+		newTestThreat("203.0.113.3", model.ThreatPhishing, model.SeverityLow),
+	)
+
+	stats, err := repo.GetStats(context.Background(), &StatsFilter{TimeField: "created_at"})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), stats.TotalThreats)
+	assert.Equal(t, int64(2), stats.HighRiskCount)
+	assert.Equal(t, map[string]int64{"malware": 2, "phishing": 1}, stats.CountByType)
+	assert.Equal(t, map[string]int64{"critical": 1, "high": 1, "low": 1}, stats.CountBySeverity)
+	assert.Equal(t, map[string]int64{"test": 3}, stats.CountBySource)
+	assert.Equal(t, map[string]int64{"ipv4": 3}, stats.CountByIndicatorType)
+	assert.Empty(t, stats.CountByCountry)
+}
+
+func TestThreatIntelligenceRepository_GetTimeline(t *testing.T) {
+	repo := NewThreatIntelligenceRepository(testutil.OpenPostgres(t))
+	seedThreats(t, repo,
+		newTestThreat("203.0.113.1", model.ThreatMalware, model.SeverityCritical),
+		newTestThreat("203.0.113.2", model.ThreatBotnet, model.SeverityCritical),
+		newTestThreat("203.0.113.3", model.ThreatBotnet, model.SeverityLow),
+	)
+
+	now := time.Now()
+	for _, tc := range []struct {
+		groupBy string
+		want    map[string]int64
+	}{
+		{"severity", map[string]int64{"critical": 2, "low": 1}},
+		{"threat_type", map[string]int64{"malware": 1, "botnet": 2}},
+		{"source", map[string]int64{"test": 3}},
+	} {
+		t.Run(tc.groupBy, func(t *testing.T) {
+			points, err := repo.GetTimeline(context.Background(), &TimelineFilter{
+				Interval:  "hour",
+				TimeField: "created_at",
+				GroupBy:   tc.groupBy,
+				StartTime: now.Add(-3 * time.Hour),
+				EndTime:   now.Add(time.Hour),
+			})
+			require.NoError(t, err)
+
+			var total int64
+			breakdown := make(map[string]int64)
+			for _, point := range points {
+				total += point.Count
+				for label, count := range point.Breakdown {
+					breakdown[label] += count
+				}
+			}
+			assert.Equal(t, int64(3), total)
+			assert.Equal(t, tc.want, breakdown)
+		})
+	}
+}
+
+func TestThreatIntelligenceRepository_Search(t *testing.T) {
+	repo := NewThreatIntelligenceRepository(testutil.OpenPostgres(t))
+
+	tagged := newTestThreat("203.0.113.1", model.ThreatBotnet, model.SeverityHigh)
+	description := "Tom & Jerry's \"botnet\" node \x02fake\x03"
+	tagged.Description = &description
+	tagged.Tags = model.StringArray{"c2", "say \"hi\""}
+	untagged := newTestThreat("203.0.113.2", model.ThreatBotnet, model.SeverityLow)
+	other := "another botnet node"
+	untagged.Description = &other
+	untagged.Tags = model.StringArray{"spam"}
+	seedThreats(t, repo, tagged, untagged)
+
+	t.Run("tags filter", func(t *testing.T) {
+		results, total, err := repo.Search(context.Background(), "botnet", &ThreatIntelligenceFilter{Tags: []string{"c2", "unused"}})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), total)
+		require.Len(t, results, 1)
+
+		threat := results[0].Threat
+		assert.Equal(t, tagged.ID, threat.ID)
+		assert.Equal(t, model.StringArray{"c2", "say \"hi\""}, threat.Tags)
+		assert.Equal(t, "203.0.113.1", threat.IPAddress.String())
+	})
+
+	t.Run("highlights are escaped", func(t *testing.T) {
+		results, total, err := repo.Search(context.Background(), "botnet", &ThreatIntelligenceFilter{})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), total)
+
+		for _, result := range results {
+			if result.Threat.ID == tagged.ID {
+				assert.Equal(t, "Tom &amp; Jerry&#39;s &#34;<mark>botnet</mark>&#34; node fake", result.Highlights["description"])
+				assert.NotContains(t, result.Highlights, "domain")
+				return
+			}
+		}
+		t.Fatal("tagged threat not found")
+	})
+}
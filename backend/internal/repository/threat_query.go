@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"fmt"
+	"net"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dsl"
+)
+
+// riskScoreSQL 與 model.ThreatIntelligence.GetRiskScore 相同的風險分數計算
+const riskScoreSQL = "LEAST(100, GREATEST(0, FLOOR(" +
+	"FLOOR(confidence_score * CASE severity WHEN 'critical' THEN 1.5 WHEN 'high' THEN 1.3 WHEN 'medium' THEN 1.1 ELSE 0.9 END)" +
+	" * CASE WHEN last_seen > NOW() - INTERVAL '24 hours' THEN 1.2 ELSE 1 END)))"
+
+// queryColumns 查詢欄位對應的資料表欄位
+var queryColumns = map[string]string{
+	dsl.FieldSeverity:        "severity",
+	dsl.FieldThreatType:      "threat_type",
+	dsl.FieldIndicatorType:   "indicator_type",
+	dsl.FieldIndicator:       "indicator_value",
+	dsl.FieldCountry:         "country_code",
+	dsl.FieldSource:          "source",
+	dsl.FieldDomain:          "domain",
+	dsl.FieldASN:             "asn",
+	dsl.FieldConfidenceScore: "confidence_score",
+	dsl.FieldRiskScore:       riskScoreSQL,
+	dsl.FieldFirstSeen:       "first_seen",
+	dsl.FieldLastSeen:        "last_seen",
+	dsl.FieldCreatedAt:       "created_at",
+}
+
+// queryComparisons 比較運算子對應的 SQL 運算子
+var queryComparisons = map[dsl.Operator]string{
+	dsl.OpEq:  "=",
+	dsl.OpGt:  ">",
+	dsl.OpGte: ">=",
+	dsl.OpLt:  "<",
+	dsl.OpLte: "<=",
+}
+
+// compileThreatQuery 將查詢語法樹編譯為 GORM 條件
+// 欄位名稱與運算子皆來自固定對應表，使用者輸入的值一律以參數綁定
+func compileThreatQuery(node dsl.Node) (clause.Expression, error) {
+	switch n := node.(type) {
+	case *dsl.BinaryNode:
+		left, err := compileThreatQuery(n.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := compileThreatQuery(n.Right)
+		if err != nil {
+			return nil, err
+		}
+		return clause.Expr{SQL: fmt.Sprintf("(? %s ?)", n.Op), Vars: []interface{}{left, right}}, nil
+
+	case *dsl.NotNode:
+		expr, err := compileThreatQuery(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		// 欄位為 NULL 時條件結果為 NULL，視為不符合後再取反
+		return clause.Expr{SQL: "NOT COALESCE(?, FALSE)", Vars: []interface{}{expr}}, nil
+
+	case *dsl.TextNode:
+		if n.Phrase {
+			return clause.Expr{SQL: "(search_vector @@ phraseto_tsquery('simple', ?))", Vars: []interface{}{n.Text}}, nil
+		}
+		return clause.Expr{SQL: "(search_vector @@ plainto_tsquery('simple', ?))", Vars: []interface{}{n.Text}}, nil
+
+	case *dsl.TermNode:
+		expr, err := compileThreatTerm(n)
+		if err != nil {
+			return nil, err
+		}
+		if n.Op == dsl.OpNotEq {
+			return clause.Expr{SQL: "NOT COALESCE(?, FALSE)", Vars: []interface{}{expr}}, nil
+		}
+		return expr, nil
+	}
+
+	return nil, fmt.Errorf("unsupported query node %T", node)
+}
+
+// compileThreatTerm 編譯欄位條件（不等於由呼叫端取反）
+func compileThreatTerm(n *dsl.TermNode) (clause.Expression, error) {
+	switch n.Field {
+	case dsl.FieldTag:
+		return clause.Expr{SQL: "(? = ANY(tags))", Vars: []interface{}{n.Value}}, nil
+
+	case dsl.FieldIP:
+		switch value := n.Value.(type) {
+		case *net.IPNet:
+			return clause.Expr{SQL: "(ip_address <<= ?::cidr)", Vars: []interface{}{value.String()}}, nil
+		case net.IP:
+			return clause.Expr{SQL: "(ip_address = ?::inet)", Vars: []interface{}{value.String()}}, nil
+		}
+		return nil, fmt.Errorf("unsupported ip value %T", n.Value)
+	}
+
+	column, ok := queryColumns[n.Field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported query field %q", n.Field)
+	}
+
+	switch n.Op {
+	case dsl.OpIn:
+		return clause.Expr{SQL: "(" + column + " IN ?)", Vars: []interface{}{n.Value}}, nil
+	case dsl.OpNotIn:
+		return clause.Expr{SQL: "NOT COALESCE(" + column + " IN ?, FALSE)", Vars: []interface{}{n.Value}}, nil
+	case dsl.OpLike:
+		return clause.Expr{SQL: "(" + column + ` ILIKE ? ESCAPE '\')`, Vars: []interface{}{n.Value}}, nil
+	case dsl.OpNotEq:
+		return clause.Expr{SQL: "(" + column + " = ?)", Vars: []interface{}{n.Value}}, nil
+	}
+
+	comparison, ok := queryComparisons[n.Op]
+	if !ok {
+		return nil, fmt.Errorf("unsupported operator %q", n.Op)
+	}
+	return clause.Expr{SQL: "(" + column + " " + comparison + " ?)", Vars: []interface{}{n.Value}}, nil
+}
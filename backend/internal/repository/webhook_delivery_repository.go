@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// WebhookDeliveryRepository Webhook 投遞紀錄儲存庫介面
+type WebhookDeliveryRepository interface {
+	Enqueue(ctx context.Context, deliveries ...*model.WebhookDelivery) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery, retryAfter time.Duration) error
+	List(ctx context.Context, filter *WebhookDeliveryFilter) ([]*model.WebhookDelivery, int64, error)
+	Purge(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// WebhookDeliveryFilter 投遞紀錄篩選器
+type WebhookDeliveryFilter struct {
+	WebhookID uuid.UUID
+	Status    *string
+	Page      int
+	PageSize  int
+}
+
+// webhookDeliveryRepository Webhook 投遞紀錄儲存庫實作
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryRepository 建立 Webhook 投遞紀錄儲存庫
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+// Enqueue 寫入待送紀錄；同一 Webhook 的相同事件已存在時略過，讓上游重送不會造成重複投遞
+func (r *webhookDeliveryRepository) Enqueue(ctx context.Context, deliveries ...*model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Omit("Webhook").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}},
+			DoNothing: true,
+		}).
+		Create(deliveries).Error
+}
+
+// Claim 領取可投遞 Webhook 的到期待送紀錄，並將下次嘗試時間延後 lease 作為租約
+// 停用或擁有者失去權限的 Webhook 其紀錄保持待送，恢復後繼續投遞
+func (r *webhookDeliveryRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	active := deliverableWebhooks(r.db).Select("webhooks.id")
+	due := r.db.Model(&model.WebhookDelivery{}).
+		Select("id").
+		Where("status = ? AND next_attempt_at <= NOW() AND webhook_id IN (?)", model.WebhookDeliveryPending, active).
+		Order("next_attempt_at").
+		Limit(limit).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+	err := r.db.WithContext(ctx).
+		Model(&deliveries).
+		Clauses(clause.Returning{}).
+		Where("id IN (?)", due).
+		Update("next_attempt_at", gorm.Expr("NOW() + ? * INTERVAL '1 millisecond'", lease.Milliseconds())).Error
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.WebhookID)
+	}
+	var webhooks []*model.Webhook
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*model.Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		byID[webhook.ID] = webhook
+	}
+	for _, delivery := range deliveries {
+		delivery.Webhook = byID[delivery.WebhookID]
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt) })
+	return deliveries, nil
+}
+
+// RecordAttempt 記錄一次投遞結果；狀態仍為待送時於 retryAfter 後重試
+func (r *webhookDeliveryRepository) RecordAttempt(ctx context.Context, delivery *model.WebhookDelivery, retryAfter time.Duration) error {
+	updates := map[string]interface{}{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"response_status": delivery.ResponseStatus,
+		"last_error":      delivery.LastError,
+		"duration_ms":     delivery.DurationMs,
+	}
+	switch delivery.Status {
+	case model.WebhookDeliverySucceeded:
+		updates["delivered_at"] = gorm.Expr("NOW()")
+	case model.WebhookDeliveryPending:
+		updates["next_attempt_at"] = gorm.Expr("NOW() + ? * INTERVAL '1 millisecond'", retryAfter.Milliseconds())
+	}
+	return r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error
+}
+
+// List 取得 Webhook 的投遞紀錄，依建立時間由新到舊排序
+func (r *webhookDeliveryRepository) List(ctx context.Context, filter *WebhookDeliveryFilter) ([]*model.WebhookDelivery, int64, error) {
+	var deliveries []*model.WebhookDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("webhook_id = ?", filter.WebhookID)
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(filter.PageSize).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// Purge 刪除超過保留期限且已結束的投遞紀錄
+func (r *webhookDeliveryRepository) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status <> ? AND created_at < NOW() - ? * INTERVAL '1 millisecond'", model.WebhookDeliveryPending, olderThan.Milliseconds()).
+		Delete(&model.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+)
+
+// WebhookRepository Webhook 訂閱儲存庫介面
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *model.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Webhook, error)
+	ListActive(ctx context.Context) ([]*model.Webhook, error)
+	Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*model.Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	RecordSuccess(ctx context.Context, id uuid.UUID) error
+	RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string) (bool, error)
+}
+
+// webhookRepository Webhook 訂閱儲存庫實作
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository 建立 Webhook 訂閱儲存庫
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// Create 建立 Webhook 訂閱
+func (r *webhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	return r.db.WithContext(ctx).Omit("User").Create(webhook).Error
+}
+
+// GetByID 根據 ID 取得 Webhook 訂閱
+func (r *webhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	var webhook model.Webhook
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListByUser 取得使用者的 Webhook 訂閱，依建立時間由新到舊排序
+func (r *webhookRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// ListActive 取得可投遞的 Webhook 訂閱，擁有者已停用或失去權限的訂閱不列入
+func (r *webhookRepository) ListActive(ctx context.Context) ([]*model.Webhook, error) {
+	var webhooks []*model.Webhook
+	err := deliverableWebhooks(r.db.WithContext(ctx)).Find(&webhooks).Error
+	return webhooks, err
+}
+
+// webhookOwnerPermissions 投遞 Webhook 時擁有者必須具備的權限
+var webhookOwnerPermissions = model.StringArray{model.PermissionWebhookManage, model.PermissionThreatRead}
+
+// deliverableWebhooks 查詢可投遞的 Webhook：訂閱本身啟用，且擁有者仍為啟用帳號、角色具備所需權限
+// 帳號停用或角色權限調整後立即生效，重新取得權限時訂閱自動恢復
+func deliverableWebhooks(db *gorm.DB) *gorm.DB {
+	return db.Model(&model.Webhook{}).
+		Joins("JOIN users ON users.id = webhooks.user_id AND users.is_active").
+		Joins("JOIN roles ON roles.name = users.role").
+		Where("webhooks.is_active = ? AND roles.permissions @> ?::text[]", true, webhookOwnerPermissions)
+}
+
+// Update 只更新指定欄位並回傳更新後的訂閱，不覆寫派送器同時寫入的失敗計數與停用狀態
+func (r *webhookRepository) Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) (*model.Webhook, error) {
+	var webhook model.Webhook
+	result := r.db.WithContext(ctx).
+		Model(&webhook).
+		Clauses(clause.Returning{}).
+		Where("id = ?", id).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &webhook, nil
+}
+
+// Delete 刪除 Webhook 訂閱，投遞紀錄一併刪除
+func (r *webhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Webhook{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RecordSuccess 投遞成功，重設連續失敗次數
+func (r *webhookRepository) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&model.Webhook{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"consecutive_failures": 0,
+		"last_success_at":      gorm.Expr("NOW()"),
+	}).Error
+}
+
+// RecordFailure 累加連續失敗次數，達到 disableAfter 時自動停用，回傳本次是否將其停用
+func (r *webhookRepository) RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string) (bool, error) {
+	var webhook model.Webhook
+	err := r.db.WithContext(ctx).
+		Model(&webhook).
+		Clauses(clause.Returning{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+			"last_failure_at":      gorm.Expr("NOW()"),
+		}).Error
+	if err != nil || !webhook.IsActive || webhook.ConsecutiveFailures < disableAfter {
+		return false, err
+	}
+
+	result := r.db.WithContext(ctx).Model(&model.Webhook{}).
+		Where("id = ? AND is_active = ?", id, true).
+		Updates(map[string]interface{}{
+			"is_active":       false,
+			"disabled_reason": reason,
+			"disabled_at":     gorm.Expr("NOW()"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/testutil"
+)
+
+// createWebhookOwner 建立指定角色與啟用狀態的使用者及其 Webhook
+func createWebhookOwner(t *testing.T, db *gorm.DB, repo WebhookRepository, name string, role model.UserRole, active bool) *model.Webhook {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &model.User{Username: name, Email: name + "@example.com", PasswordHash: "x", Role: role}
+	require.NoError(t, db.Create(user).Error)
+	// is_active 有資料庫預設值，零值需另外更新
+	require.NoError(t, db.Model(user).Update("is_active", active).Error)
+
+	webhook := &model.Webhook{UserID: user.ID, Name: name, URL: "https://hooks.example.com/" + name, Secret: "secret"}
+	require.NoError(t, repo.Create(ctx, webhook))
+	return webhook
+}
+
+func TestWebhookRepository_DeliverableWebhooks(t *testing.T) {
+	db := testutil.OpenPostgres(t)
+	ctx := context.Background()
+	repo := NewWebhookRepository(db)
+	deliveryRepo := NewWebhookDeliveryRepository(db)
+
+	roles := append(model.BuiltinRoles(), model.Role{Name: "viewer", Permissions: model.StringArray{model.PermissionThreatRead}})
+	require.NoError(t, NewRoleRepository(db).EnsureRoles(ctx, roles))
+
+	eligible := createWebhookOwner(t, db, repo, "eligible", model.RoleBasic, true)
+	deactivated := createWebhookOwner(t, db, repo, "deactivated", model.RoleBasic, false)
+	unprivileged := createWebhookOwner(t, db, repo, "unprivileged", "viewer", true)
+
+	webhooks, err := repo.ListActive(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, eligible.ID, webhooks[0].ID)
+
+	var deliveries []*model.WebhookDelivery
+	for _, webhook := range []*model.Webhook{eligible, deactivated, unprivileged} {
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   "event-1",
+			EventType: "created",
+			Payload:   json.RawMessage(`{}`),
+			Status:    model.WebhookDeliveryPending,
+		})
+	}
+	require.NoError(t, deliveryRepo.Enqueue(ctx, deliveries...))
+
+	claimed, err := deliveryRepo.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, eligible.ID, claimed[0].WebhookID)
+}
@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// apiKeyPrefix API 金鑰前綴，方便使用者與密鑰掃描工具辨識
+const apiKeyPrefix = "usip_"
+
+// defaultAPIKeyQuota 未指定配額時的預設值，與資料表預設一致
+const defaultAPIKeyQuota = 1000
+
+// APIKeyService API 金鑰服務介面，使用者只能管理自己的金鑰
+type APIKeyService interface {
+	Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID uuid.UUID, req *dto.APIKeyListRequest) (*vo.APIKeyListVO, error)
+	GetAPIKey(ctx context.Context, userID, id uuid.UUID) (*vo.ExtendedAPIKeyVO, error)
+	CreateAPIKey(ctx context.Context, userID uuid.UUID, req *dto.APIKeyCreateRequest) (*vo.ExtendedAPIKeyVO, error)
+	UpdateAPIKey(ctx context.Context, userID, id uuid.UUID, req *dto.APIKeyUpdateRequest) (*vo.ExtendedAPIKeyVO, error)
+	RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error
+	RotateAPIKey(ctx context.Context, userID, id uuid.UUID, req *dto.APIKeyRotateRequest) (*vo.RotateAPIKeyVO, error)
+}
+
+// apiKeyService API 金鑰服務實作
+type apiKeyService struct {
+	repo            repository.APIKeyRepository
+	maxPerUser      int
+	rotationOverlap time.Duration
+}
+
+// NewAPIKeyService 建立 API 金鑰服務
+// maxPerUser 為每位使用者可同時持有的有效金鑰上限，小於等於 0 表示不限制；rotationOverlap 為輪替時舊金鑰的預設保留時間
+func NewAPIKeyService(repo repository.APIKeyRepository, maxPerUser int, rotationOverlap time.Duration) APIKeyService {
+	return &apiKeyService{
+		repo:            repo,
+		maxPerUser:      maxPerUser,
+		rotationOverlap: rotationOverlap,
+	}
+}
+
+// Authenticate 驗證原始 API 金鑰並計入一次使用，回傳含所屬使用者的金鑰資料
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*model.APIKey, error) {
+	if rawKey == "" {
+		return nil, dto.ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.repo.GetByHash(ctx, HashAPIKey(rawKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if !apiKey.IsActive {
+		return nil, dto.ErrAPIKeyInactive
+	}
+	if apiKey.IsExpired() {
+		return nil, dto.ErrAPIKeyExpired
+	}
+	if !apiKey.User.IsActive {
+		return nil, dto.ErrUserInactive
+	}
+
+	consumed, err := s.repo.ConsumeUsage(ctx, apiKey.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record API key usage: %w", err)
+	}
+	if !consumed {
+		return nil, dto.ErrAPIKeyQuotaExceeded
+	}
+	apiKey.IncrementUsage()
+
+	return apiKey, nil
+}
+
+// ListAPIKeys 取得使用者的 API 金鑰，金鑰內容一律遮蔽
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, userID uuid.UUID, req *dto.APIKeyListRequest) (*vo.APIKeyListVO, error) {
+	req.SetDefaults()
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+
+	apiKeys, total, err := s.repo.List(ctx, &repository.APIKeyFilter{
+		UserID:   userID,
+		IsActive: req.IsActive,
+		Page:     req.Page,
+		PageSize: req.GetLimit(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeyVOs := make([]vo.ExtendedAPIKeyVO, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		apiKeyVOs[i] = *s.apiKeyToVO(apiKey)
+	}
+
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize > 0 {
+		totalPages++
+	}
+
+	return &vo.APIKeyListVO{
+		APIKeys: apiKeyVOs,
+		Pagination: vo.PaginationVO{
+			CurrentPage:  req.Page,
+			PageSize:     req.PageSize,
+			TotalPages:   totalPages,
+			TotalRecords: total,
+			HasNext:      req.Page < totalPages,
+			HasPrevious:  req.Page > 1,
+		},
+	}, nil
+}
+
+// GetAPIKey 取得 API 金鑰
+func (s *apiKeyService) GetAPIKey(ctx context.Context, userID, id uuid.UUID) (*vo.ExtendedAPIKeyVO, error) {
+	apiKey, err := s.getAPIKey(ctx, s.repo, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.apiKeyToVO(apiKey), nil
+}
+
+// CreateAPIKey 建立 API 金鑰；完整金鑰只會在此回應中出現一次
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, userID uuid.UUID, req *dto.APIKeyCreateRequest) (*vo.ExtendedAPIKeyVO, error) {
+	apiKey := &model.APIKey{
+		UserID:   userID,
+		Name:     req.Name,
+		IsActive: true,
+		Quota:    defaultAPIKeyQuota,
+	}
+	if req.Quota != nil {
+		if *req.Quota < 1 {
+			return nil, dto.ErrInvalidQuota
+		}
+		apiKey.Quota = *req.Quota
+	}
+	if req.ExpiresAt != nil {
+		expiresAt, err := parseAPIKeyExpiration(*req.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		apiKey.ExpiresAt = expiresAt
+	}
+
+	if err := s.checkLimit(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	rawKey, err := s.issue(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	result := s.apiKeyToVO(apiKey)
+	result.Key = rawKey
+	return result, nil
+}
+
+// UpdateAPIKey 更新名稱、啟用狀態、到期時間或配額；已撤銷的金鑰不可重新啟用
+// 已輪替的舊金鑰只能提前到期，不可清除或延長重疊期間的到期時間，否則輪替形同無效
+func (s *apiKeyService) UpdateAPIKey(ctx context.Context, userID, id uuid.UUID, req *dto.APIKeyUpdateRequest) (*vo.ExtendedAPIKeyVO, error) {
+	if req.Quota != nil && *req.Quota < 1 {
+		return nil, dto.ErrInvalidQuota
+	}
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil && *req.ExpiresAt != 0 {
+		parsed, err := parseAPIKeyExpiration(*req.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt = parsed
+	}
+
+	var updated *model.APIKey
+	err := s.repo.Transaction(ctx, func(repo repository.APIKeyRepository) error {
+		// 鎖定金鑰列，避免與同時進行的輪替交錯
+		apiKey, err := s.lockAPIKey(ctx, repo, userID, id)
+		if err != nil {
+			return err
+		}
+
+		if req.Name != nil {
+			apiKey.Name = *req.Name
+		}
+		if req.Quota != nil {
+			apiKey.Quota = *req.Quota
+		}
+		if req.ExpiresAt != nil {
+			if extendsExpiration(apiKey.ExpiresAt, expiresAt) {
+				rotated, err := repo.HasSuccessor(ctx, apiKey.ID)
+				if err != nil {
+					return err
+				}
+				if rotated {
+					return dto.ErrAPIKeyRotated
+				}
+			}
+			apiKey.ExpiresAt = expiresAt
+		}
+		if req.IsActive != nil {
+			if *req.IsActive && apiKey.IsRevoked() {
+				return dto.ErrAPIKeyRevoked
+			}
+			apiKey.IsActive = *req.IsActive
+		}
+
+		if err := repo.Update(ctx, apiKey); err != nil {
+			return err
+		}
+		updated = apiKey
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.apiKeyToVO(updated), nil
+}
+
+// RevokeAPIKey 撤銷 API 金鑰，立即失效且不可重新啟用；紀錄保留供稽核
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error {
+	apiKey, err := s.getAPIKey(ctx, s.repo, userID, id)
+	if err != nil {
+		return err
+	}
+	if apiKey.IsRevoked() {
+		return nil
+	}
+
+	apiKey.Revoke()
+	return s.repo.Update(ctx, apiKey)
+}
+
+// RotateAPIKey 以相同設定建立新金鑰，舊金鑰在重疊期間內仍可使用以便逐步替換
+// 重疊時間為 0 時舊金鑰立即撤銷；舊金鑰原本較早到期時維持原到期時間
+// 每把金鑰只能輪替一次，並發的輪替以列鎖排序，後到者回傳 ErrAPIKeyRotated
+func (s *apiKeyService) RotateAPIKey(ctx context.Context, userID, id uuid.UUID, req *dto.APIKeyRotateRequest) (*vo.RotateAPIKeyVO, error) {
+	overlap := s.rotationOverlap
+	if req.OverlapMinutes != nil {
+		if *req.OverlapMinutes < 0 {
+			return nil, dto.ErrInvalidExpiration
+		}
+		overlap = time.Duration(*req.OverlapMinutes) * time.Minute
+	}
+
+	var previous, rotated *model.APIKey
+	var rawKey string
+	err := s.repo.Transaction(ctx, func(repo repository.APIKeyRepository) error {
+		apiKey, err := s.lockAPIKey(ctx, repo, userID, id)
+		if err != nil {
+			return err
+		}
+		if apiKey.IsRevoked() {
+			return dto.ErrAPIKeyRevoked
+		}
+		rotatedBefore, err := repo.HasSuccessor(ctx, apiKey.ID)
+		if err != nil {
+			return err
+		}
+		if rotatedBefore {
+			return dto.ErrAPIKeyRotated
+		}
+
+		previousID := apiKey.ID
+		rotated = &model.APIKey{
+			UserID:        userID,
+			Name:          apiKey.Name,
+			IsActive:      true,
+			ExpiresAt:     apiKey.ExpiresAt,
+			Quota:         apiKey.Quota,
+			RotatedFromID: &previousID,
+		}
+		if rawKey, err = s.issue(rotated); err != nil {
+			return err
+		}
+		if err := repo.Create(ctx, rotated); err != nil {
+			return err
+		}
+
+		if overlap <= 0 {
+			apiKey.Revoke()
+		} else if graceEnd := time.Now().Add(overlap); apiKey.ExpiresAt == nil || apiKey.ExpiresAt.After(graceEnd) {
+			apiKey.ExpiresAt = &graceEnd
+		}
+		if err := repo.Update(ctx, apiKey); err != nil {
+			return err
+		}
+		previous = apiKey
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &vo.RotateAPIKeyVO{
+		APIKey:         *s.apiKeyToVO(rotated),
+		PreviousAPIKey: *s.apiKeyToVO(previous),
+	}
+	result.APIKey.Key = rawKey
+	return result, nil
+}
+
+// getAPIKey 取得使用者的 API 金鑰；不屬於該使用者時視同不存在
+func (s *apiKeyService) getAPIKey(ctx context.Context, repo repository.APIKeyRepository, userID, id uuid.UUID) (*model.APIKey, error) {
+	apiKey, err := repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	if apiKey.UserID != userID {
+		return nil, dto.ErrAPIKeyNotFound
+	}
+	return apiKey, nil
+}
+
+// lockAPIKey 取得並鎖定使用者的 API 金鑰，須在交易中使用；不屬於該使用者時視同不存在
+func (s *apiKeyService) lockAPIKey(ctx context.Context, repo repository.APIKeyRepository, userID, id uuid.UUID) (*model.APIKey, error) {
+	apiKey, err := repo.GetByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	if apiKey.UserID != userID {
+		return nil, dto.ErrAPIKeyNotFound
+	}
+	return apiKey, nil
+}
+
+// extendsExpiration 檢查新的到期時間是否比目前晚；nil 表示永不過期
+func extendsExpiration(current, next *time.Time) bool {
+	if current == nil {
+		return false
+	}
+	return next == nil || next.After(*current)
+}
+
+// checkLimit 檢查使用者的有效金鑰是否已達上限
+func (s *apiKeyService) checkLimit(ctx context.Context, userID uuid.UUID) error {
+	if s.maxPerUser <= 0 {
+		return nil
+	}
+	count, err := s.repo.CountUsable(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count >= int64(s.maxPerUser) {
+		return dto.ErrAPIKeyLimitReached
+	}
+	return nil
+}
+
+// issue 產生新金鑰，寫入雜湊與遮蔽預覽後回傳原始金鑰
+func (s *apiKeyService) issue(apiKey *model.APIKey) (string, error) {
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		return "", err
+	}
+	preview := vo.ExtendedAPIKeyVO{Key: rawKey}
+	preview.MaskAPIKey()
+
+	apiKey.KeyHash = HashAPIKey(rawKey)
+	apiKey.KeyPreview = preview.KeyPreview
+	return rawKey, nil
+}
+
+// apiKeyToVO 將 API 金鑰模型轉換為 VO，不含完整金鑰
+func (s *apiKeyService) apiKeyToVO(apiKey *model.APIKey) *vo.ExtendedAPIKeyVO {
+	return &vo.ExtendedAPIKeyVO{
+		APIKeyVO: vo.APIKeyVO{
+			ID:        apiKey.ID,
+			Name:      apiKey.Name,
+			IsActive:  apiKey.IsActive,
+			ExpiresAt: apiKey.ExpiresAt,
+			Quota:     apiKey.Quota,
+			Usage:     apiKey.Usage,
+			CreatedAt: apiKey.CreatedAt,
+			LastUsed:  apiKey.LastUsed,
+		},
+		KeyPreview:    apiKey.KeyPreview,
+		UsedQuota:     apiKey.Usage,
+		RevokedAt:     apiKey.RevokedAt,
+		RotatedFromID: apiKey.RotatedFromID,
+	}
+}
+
+// parseAPIKeyExpiration 將 Unix 秒轉為到期時間，必須晚於目前時間
+func parseAPIKeyExpiration(unix int64) (*time.Time, error) {
+	expiresAt := time.Unix(unix, 0)
+	if !expiresAt.After(time.Now()) {
+		return nil, dto.ErrInvalidExpiration
+	}
+	return &expiresAt, nil
+}
+
+// generateAPIKey 產生帶有前綴的隨機 API 金鑰
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+// HashAPIKey 計算 API 金鑰雜湊；金鑰本身為高熵亂數，使用 SHA-256 即可供索引查詢
+func HashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,8 +1,13 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,36 +17,75 @@ import (
 
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
 	pkgjwt "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/jwt"
 	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/mail"
 )
 
 // AuthServiceInterface 認證服務介面
 type AuthServiceInterface interface {
 	Register(req *dto.RegisterRequest) (*vo.AuthTokenResponse, error)
-	Login(req *dto.LoginRequest) (*vo.AuthTokenResponse, error)
+	Login(req *dto.LoginRequest) (*vo.AuthTokenResponse, *vo.MFAChallengeVO, error)
+	VerifyMFA(req *dto.MFAVerifyRequest) (*vo.AuthTokenResponse, error)
+	CompleteLogin(ctx context.Context, user *model.User, mfaVerified bool) (*vo.AuthTokenResponse, *vo.MFAChallengeVO, error)
 	RefreshToken(req *dto.RefreshTokenRequest) (*vo.AuthTokenResponse, error)
 	Logout(userID uuid.UUID, token string) error
+	LogoutAll(userID uuid.UUID) error
 	ChangePassword(userID uuid.UUID, req *dto.ChangePasswordRequest) error
 	ResetPassword(req *dto.ResetPasswordRequest) error
 	ConfirmResetPassword(req *dto.ConfirmResetPasswordRequest) error
+	VerifyEmail(req *dto.VerifyEmailRequest) error
+	ResendVerificationEmail(userID uuid.UUID) error
+	IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error)
 	GetProfile(userID uuid.UUID) (*vo.ExtendedUserVO, error)
 	UpdateProfile(userID uuid.UUID, req *dto.UpdateProfileRequest) (*vo.ExtendedUserVO, error)
 	ValidateToken(token string) (*pkgjwt.JWTClaims, error)
 }
 
+// AuthServiceConfig 認證服務配置
+type AuthServiceConfig struct {
+	PasswordResetTTL           time.Duration // 重設令牌有效期間
+	PasswordResetURL           string        // 重設頁面網址，令牌以 token 查詢參數附加
+	EmailVerificationTTL       time.Duration // 驗證令牌有效期間
+	EmailVerificationURL       string        // 驗證頁面網址，令牌以 token 查詢參數附加
+	VerificationResendCooldown time.Duration // 兩次發送驗證信的最短間隔，0 表示不限制
+	VerificationResendPerHour  int           // 每小時最多發送的驗證信數量，0 表示不限制
+}
+
 // AuthService 認證服務實作
 type AuthService struct {
-	db         *gorm.DB
-	jwtManager *pkgjwt.JWTManager
+	db                 *gorm.DB
+	jwtManager         *pkgjwt.JWTManager
+	refreshTokens      repository.RefreshTokenRepository
+	passwordResets     repository.PasswordResetTokenRepository
+	emailVerifications repository.EmailVerificationTokenRepository
+	mailer             mail.Sender
+	mfa                MFAService
+	config             AuthServiceConfig
 }
 
 // NewAuthService 建立認證服務
-func NewAuthService(db *gorm.DB, jwtManager *pkgjwt.JWTManager) AuthServiceInterface {
+func NewAuthService(
+	db *gorm.DB,
+	jwtManager *pkgjwt.JWTManager,
+	refreshTokens repository.RefreshTokenRepository,
+	passwordResets repository.PasswordResetTokenRepository,
+	emailVerifications repository.EmailVerificationTokenRepository,
+	mailer mail.Sender,
+	mfa MFAService,
+	config AuthServiceConfig,
+) AuthServiceInterface {
 	return &AuthService{
-		db:         db,
-		jwtManager: jwtManager,
+		db:                 db,
+		jwtManager:         jwtManager,
+		refreshTokens:      refreshTokens,
+		passwordResets:     passwordResets,
+		emailVerifications: emailVerifications,
+		mailer:             mailer,
+		mfa:                mfa,
+		config:             config,
 	}
 }
 
@@ -72,11 +116,8 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*vo.AuthTokenResponse,
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// 設定預設角色
-	role := "basic"
-	if req.Role != "" {
-		role = req.Role
-	}
+	// 自行註冊一律為基本角色，不接受呼叫者指定
+	role := string(model.RoleBasic)
 
 	// 建立使用者
 	user := model.User{
@@ -104,6 +145,14 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*vo.AuthTokenResponse,
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// 發送驗證信；失敗時使用者仍可登入後重新發送，不影響註冊
+	if err := s.sendVerificationEmail(context.Background(), &user); err != nil {
+		pkglogger.Error("Failed to send verification email", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": user.ID,
+		})
+	}
+
 	// 生成令牌
 	accessToken, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Email, string(user.Role))
 	if err != nil {
@@ -114,7 +163,7 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*vo.AuthTokenResponse,
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	refreshToken, err := s.startRefreshTokenFamily(context.Background(), user.ID, false)
 	if err != nil {
 		pkglogger.Error("Failed to generate refresh token", pkglogger.Fields{
 			"error":   err.Error(),
@@ -157,10 +206,10 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*vo.AuthTokenResponse,
 }
 
 // Login 使用者登入
-func (s *AuthService) Login(req *dto.LoginRequest) (*vo.AuthTokenResponse, error) {
+func (s *AuthService) Login(req *dto.LoginRequest) (*vo.AuthTokenResponse, *vo.MFAChallengeVO, error) {
 	// 驗證請求
 	if err := req.ValidateCredentials(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// 查找使用者
@@ -168,28 +217,109 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*vo.AuthTokenResponse, error
 	err := s.db.Where("username = ? OR email = ?", req.Username, req.Username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, dto.ErrInvalidCredentials
+			return nil, nil, dto.ErrInvalidCredentials
 		}
 		pkglogger.Error("Failed to find user", pkglogger.Fields{
 			"error":    err.Error(),
 			"username": req.Username,
 		})
-		return nil, fmt.Errorf("failed to find user: %w", err)
+		return nil, nil, fmt.Errorf("failed to find user: %w", err)
 	}
 
 	// 檢查帳戶是否活躍
 	if !user.IsActive {
-		return nil, dto.ErrUserInactive
+		return nil, nil, dto.ErrUserInactive
 	}
 
 	// 驗證密碼
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
-		return nil, dto.ErrInvalidCredentials
+		return nil, nil, dto.ErrInvalidCredentials
 	}
 
-	// 生成令牌
-	accessToken, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Email, string(user.Role))
+	return s.CompleteLogin(context.Background(), &user, false)
+}
+
+// CompleteLogin 完成第一步認證後的登入流程，密碼登入與單一登入共用
+// 已啟用多因素驗證時只回傳登入挑戰，通過第二步驗證後才簽發令牌；mfaVerified 表示身分提供者已完成多因素驗證
+func (s *AuthService) CompleteLogin(ctx context.Context, user *model.User, mfaVerified bool) (*vo.AuthTokenResponse, *vo.MFAChallengeVO, error) {
+	mfaEnabled, err := s.mfa.IsEnabled(ctx, user.ID)
+	if err != nil {
+		pkglogger.Error("Failed to check MFA status", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": user.ID,
+		})
+		return nil, nil, fmt.Errorf("failed to check MFA status: %w", err)
+	}
+	if mfaEnabled && !mfaVerified {
+		challenge, err := s.mfa.CreateChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		pkglogger.Info("MFA challenge issued", pkglogger.Fields{
+			"user_id":  user.ID,
+			"username": user.Username,
+		})
+		return nil, challenge, nil
+	}
+
+	response, err := s.issueSession(ctx, user, mfaVerified)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkglogger.Info("User logged in successfully", pkglogger.Fields{
+		"user_id":  user.ID,
+		"username": user.Username,
+	})
+
+	return response, nil, nil
+}
+
+// VerifyMFA 登入第二步，以登入挑戰令牌與驗證碼或復原碼換發令牌
+func (s *AuthService) VerifyMFA(req *dto.MFAVerifyRequest) (*vo.AuthTokenResponse, error) {
+	ctx := context.Background()
+
+	userID, err := s.mfa.CompleteChallenge(ctx, req.MFAToken, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	err = s.db.First(&user, "id = ?", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrUserNotFound
+		}
+		pkglogger.Error("Failed to find user for MFA verification", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	// 挑戰建立後帳戶可能已被停用
+	if !user.IsActive {
+		return nil, dto.ErrUserInactive
+	}
+
+	response, err := s.issueSession(ctx, &user, true)
+	if err != nil {
+		return nil, err
+	}
+
+	pkglogger.Info("User logged in with MFA", pkglogger.Fields{
+		"user_id":  user.ID,
+		"username": user.Username,
+	})
+
+	return response, nil
+}
+
+// issueSession 簽發存取令牌並開啟新的刷新令牌家族，更新最後登入時間
+// mfaVerified 表示本次登入已通過多因素驗證，會寫入存取令牌並沿用到同一家族輪替出的令牌
+func (s *AuthService) issueSession(ctx context.Context, user *model.User, mfaVerified bool) (*vo.AuthTokenResponse, error) {
+	accessToken, err := s.jwtManager.GenerateTokenWithMFA(user.ID, user.Username, user.Email, string(user.Role), mfaVerified)
 	if err != nil {
 		pkglogger.Error("Failed to generate access token", pkglogger.Fields{
 			"error":   err.Error(),
@@ -198,7 +328,7 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*vo.AuthTokenResponse, error
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	refreshToken, err := s.startRefreshTokenFamily(ctx, user.ID, mfaVerified)
 	if err != nil {
 		pkglogger.Error("Failed to generate refresh token", pkglogger.Fields{
 			"error":   err.Error(),
@@ -210,52 +340,78 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*vo.AuthTokenResponse, error
 	// 更新最後登入時間
 	now := time.Now()
 	user.LastLogin = &now
-	s.db.Save(&user)
+	s.db.Save(user)
 
 	// 轉換為 VO
 	var userVO vo.UserVO
-	if err := copier.Copy(&userVO, &user); err != nil {
+	if err := copier.Copy(&userVO, user); err != nil {
 		pkglogger.Error("Failed to copy user to VO", pkglogger.Fields{
 			"error": err.Error(),
 		})
 		return nil, fmt.Errorf("failed to copy user data: %w", err)
 	}
 
-	response := &vo.AuthTokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    3600, // 1小時
-		ExpiresAt:    time.Now().Add(time.Hour),
-		User:         userVO,
-	}
-
-	pkglogger.Info("User logged in successfully", pkglogger.Fields{
-		"user_id":  user.ID,
-		"username": user.Username,
-	})
-
-	return response, nil
+	return &vo.AuthTokenResponse{
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             3600, // 1小時
+		ExpiresAt:             time.Now().Add(time.Hour),
+		User:                  userVO,
+		MFAEnrollmentRequired: !mfaVerified && s.mfa.IsRequired(string(user.Role)),
+	}, nil
 }
 
 // RefreshToken 刷新令牌
+// 每個刷新令牌只能使用一次並輪替為同一家族的新令牌；已輪替的令牌再次出現代表可能遭竊，撤銷整個家族
 func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*vo.AuthTokenResponse, error) {
-	// 驗證刷新令牌
-	userID, err := s.jwtManager.VerifyRefreshToken(req.RefreshToken)
+	ctx := context.Background()
+
+	// 驗證刷新令牌，登出所有裝置之前簽發的刷新令牌一併失效
+	claims, err := s.jwtManager.VerifyRefreshTokenContext(ctx, req.RefreshToken)
 	if err != nil {
+		if errors.Is(err, pkgjwt.ErrRevocationCheckFailed) {
+			return nil, err
+		}
+		return nil, dto.ErrInvalidRefreshToken
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, dto.ErrInvalidRefreshToken
+	}
+	stored, err := s.refreshTokens.GetByID(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrInvalidRefreshToken
+		}
+		pkglogger.Error("Failed to find refresh token", pkglogger.Fields{
+			"error":    err.Error(),
+			"token_id": tokenID,
+		})
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+	if stored.UserID.String() != claims.Subject {
+		return nil, dto.ErrInvalidRefreshToken
+	}
+	if stored.IsRotated() {
+		s.revokeReusedRefreshToken(ctx, stored)
+		return nil, dto.ErrRefreshTokenReused
+	}
+	if stored.IsRevoked() || stored.IsExpired() {
 		return nil, dto.ErrInvalidRefreshToken
 	}
 
 	// 查找使用者
 	var user model.User
-	err = s.db.First(&user, "id = ?", userID).Error
+	err = s.db.First(&user, "id = ?", stored.UserID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, dto.ErrUserNotFound
 		}
 		pkglogger.Error("Failed to find user for refresh token", pkglogger.Fields{
 			"error":   err.Error(),
-			"user_id": userID,
+			"user_id": stored.UserID,
 		})
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
@@ -265,8 +421,8 @@ func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*vo.AuthTokenR
 		return nil, dto.ErrUserInactive
 	}
 
-	// 生成新的令牌
-	accessToken, err := s.jwtManager.GenerateToken(user.ID, user.Username, user.Email, string(user.Role))
+	// 生成新的令牌，沿用家族登入時的多因素驗證狀態
+	accessToken, err := s.jwtManager.GenerateTokenWithMFA(user.ID, user.Username, user.Email, string(user.Role), stored.MFAVerified)
 	if err != nil {
 		pkglogger.Error("Failed to generate new access token", pkglogger.Fields{
 			"error":   err.Error(),
@@ -275,7 +431,7 @@ func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*vo.AuthTokenR
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID)
+	refreshToken, next, err := s.newRefreshToken(user.ID, stored)
 	if err != nil {
 		pkglogger.Error("Failed to generate new refresh token", pkglogger.Fields{
 			"error":   err.Error(),
@@ -284,6 +440,21 @@ func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*vo.AuthTokenR
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
+	rotated, err := s.refreshTokens.Rotate(ctx, stored.ID, next)
+	if err != nil {
+		pkglogger.Error("Failed to rotate refresh token", pkglogger.Fields{
+			"error":     err.Error(),
+			"user_id":   user.ID,
+			"family_id": stored.FamilyID,
+		})
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// 查詢之後被其他請求搶先使用，同一令牌被併發使用同樣視為重複使用
+		s.revokeReusedRefreshToken(ctx, stored)
+		return nil, dto.ErrRefreshTokenReused
+	}
+
 	// 轉換為 VO
 	var userVO vo.UserVO
 	if err := copier.Copy(&userVO, &user); err != nil {
@@ -294,12 +465,13 @@ func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*vo.AuthTokenR
 	}
 
 	response := &vo.AuthTokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    3600,
-		ExpiresAt:    time.Now().Add(time.Hour),
-		User:         userVO,
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             3600,
+		ExpiresAt:             time.Now().Add(time.Hour),
+		User:                  userVO,
+		MFAEnrollmentRequired: !stored.MFAVerified && s.mfa.IsRequired(string(user.Role)),
 	}
 
 	pkglogger.Info("Token refreshed successfully", pkglogger.Fields{
@@ -309,16 +481,118 @@ func (s *AuthService) RefreshToken(req *dto.RefreshTokenRequest) (*vo.AuthTokenR
 	return response, nil
 }
 
-// Logout 使用者登出
+// Logout 使用者登出，撤銷目前的存取令牌直到其原本的到期時間
 func (s *AuthService) Logout(userID uuid.UUID, token string) error {
-	// TODO: 實作令牌黑名單機制
-	// 目前只記錄登出事件
+	claims, err := s.jwtManager.VerifyToken(token)
+	if err != nil {
+		return dto.ErrInvalidToken
+	}
+	if claims.UserID != userID {
+		return dto.ErrInvalidToken
+	}
+
+	if err := s.jwtManager.RevokeToken(context.Background(), claims); err != nil {
+		pkglogger.Error("Failed to revoke token", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
 	pkglogger.Info("User logged out", pkglogger.Fields{
 		"user_id": userID,
 	})
 	return nil
 }
 
+// LogoutAll 登出所有裝置，使使用者目前為止簽發的所有存取與刷新令牌失效
+func (s *AuthService) LogoutAll(userID uuid.UUID) error {
+	if err := s.revokeAllSessions(context.Background(), userID, model.RefreshTokenRevokedLogoutAll); err != nil {
+		return err
+	}
+
+	pkglogger.Info("User logged out of all sessions", pkglogger.Fields{
+		"user_id": userID,
+	})
+	return nil
+}
+
+// revokeAllSessions 撤銷使用者目前為止簽發的存取令牌與所有刷新令牌家族
+func (s *AuthService) revokeAllSessions(ctx context.Context, userID uuid.UUID, reason string) error {
+	if err := s.jwtManager.RevokeAllTokens(ctx, userID); err != nil {
+		pkglogger.Error("Failed to revoke all tokens", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return fmt.Errorf("failed to revoke all tokens: %w", err)
+	}
+	if _, err := s.refreshTokens.RevokeByUser(ctx, userID, reason); err != nil {
+		pkglogger.Error("Failed to revoke refresh token families", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// startRefreshTokenFamily 登入或註冊時簽發刷新令牌並開啟新的令牌家族
+func (s *AuthService) startRefreshTokenFamily(ctx context.Context, userID uuid.UUID, mfaVerified bool) (string, error) {
+	refreshToken, record, err := s.newRefreshToken(userID, nil)
+	if err != nil {
+		return "", err
+	}
+	record.MFAVerified = mfaVerified
+	if err := s.refreshTokens.Create(ctx, record); err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return refreshToken, nil
+}
+
+// newRefreshToken 簽發刷新令牌並建立對應紀錄（尚未保存）；parent 不為空時沿用其家族與多因素驗證狀態
+func (s *AuthService) newRefreshToken(userID uuid.UUID, parent *model.RefreshToken) (string, *model.RefreshToken, error) {
+	refreshToken, claims, err := s.jwtManager.IssueRefreshToken(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid refresh token ID: %w", err)
+	}
+
+	record := &model.RefreshToken{
+		ID:        tokenID,
+		FamilyID:  uuid.New(),
+		UserID:    userID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if parent != nil {
+		record.FamilyID = parent.FamilyID
+		record.ParentID = &parent.ID
+		record.MFAVerified = parent.MFAVerified
+	}
+	return refreshToken, record, nil
+}
+
+// revokeReusedRefreshToken 偵測到已輪替的刷新令牌被再次使用時撤銷整個家族並記錄安全事件
+// 合法使用者與竊取者此時都持有同一家族的令牌，無法分辨，因此雙方都須重新登入
+func (s *AuthService) revokeReusedRefreshToken(ctx context.Context, token *model.RefreshToken) {
+	revoked, err := s.refreshTokens.RevokeFamily(ctx, token.FamilyID, model.RefreshTokenRevokedReuse)
+	fields := pkglogger.Fields{
+		"event":     "refresh_token_reuse",
+		"user_id":   token.UserID,
+		"family_id": token.FamilyID,
+		"token_id":  token.ID,
+		"revoked":   revoked,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		pkglogger.Error("Refresh token reuse detected but failed to revoke family", fields)
+		return
+	}
+	pkglogger.Warn("Refresh token reuse detected, token family revoked", fields)
+}
+
 // ChangePassword 修改密碼
 func (s *AuthService) ChangePassword(userID uuid.UUID, req *dto.ChangePasswordRequest) error {
 	// 查找使用者
@@ -367,29 +641,299 @@ func (s *AuthService) ChangePassword(userID uuid.UUID, req *dto.ChangePasswordRe
 	return nil
 }
 
-// ResetPassword 重設密碼
+// ResetPassword 申請重設密碼
+// 無論信箱是否存在、帳號是否停用都回傳成功；令牌簽發與寄信都在背景進行，各情況在請求中都只查詢一次使用者，回應內容與時間都無法用來探測帳號
 func (s *AuthService) ResetPassword(req *dto.ResetPasswordRequest) error {
-	// TODO: 實作密碼重設邏輯
-	// 1. 查找使用者
-	// 2. 生成重設令牌
-	// 3. 發送重設郵件
+	var user model.User
+	err := s.db.Where("email = ?", req.Email).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			pkglogger.Info("Password reset requested for unknown email", pkglogger.Fields{
+				"email": req.Email,
+			})
+			return nil
+		}
+		pkglogger.Error("Failed to find user for password reset", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.IsActive {
+		pkglogger.Info("Password reset requested for inactive user", pkglogger.Fields{
+			"user_id": user.ID,
+		})
+		return nil
+	}
+
+	go s.sendPasswordResetEmail(user)
+
 	pkglogger.Info("Password reset requested", pkglogger.Fields{
-		"email": req.Email,
+		"user_id": user.ID,
 	})
 	return nil
 }
 
-// ConfirmResetPassword 確認重設密碼
+// sendPasswordResetEmail 在背景簽發密碼重設令牌並寄送重設信，失敗只記錄日誌
+func (s *AuthService) sendPasswordResetEmail(user model.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+
+	rawToken, err := generateOneTimeToken()
+	if err != nil {
+		pkglogger.Error("Failed to generate password reset token", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": user.ID,
+		})
+		return
+	}
+	token := &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashOneTimeToken(rawToken),
+		ExpiresAt: time.Now().Add(s.config.PasswordResetTTL),
+	}
+	if err := s.passwordResets.Issue(ctx, token); err != nil {
+		pkglogger.Error("Failed to store password reset token", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": user.ID,
+		})
+		return
+	}
+
+	s.sendMail(&mail.Message{
+		To:      []string{user.Email},
+		Subject: "重設您的密碼",
+		Body: fmt.Sprintf("您好 %s，\n\n我們收到重設您帳號密碼的申請。請在 %d 分鐘內開啟以下連結設定新密碼：\n\n%s\n\n如果您沒有提出申請，請忽略此郵件，您的密碼不會變更。\n",
+			user.Username, int(s.config.PasswordResetTTL.Minutes()), tokenLink(s.config.PasswordResetURL, rawToken)),
+	}, user.ID)
+}
+
+// ConfirmResetPassword 確認重設密碼，成功後使所有既有登入階段失效
 func (s *AuthService) ConfirmResetPassword(req *dto.ConfirmResetPasswordRequest) error {
-	// TODO: 實作密碼重設確認邏輯
-	// 1. 驗證重設令牌
-	// 2. 更新密碼
+	ctx := context.Background()
+
+	token, err := s.passwordResets.GetByHash(ctx, hashOneTimeToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ErrInvalidResetToken
+		}
+		pkglogger.Error("Failed to find password reset token", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return fmt.Errorf("failed to find password reset token: %w", err)
+	}
+	if !token.IsUsable() {
+		return dto.ErrInvalidResetToken
+	}
+
+	var user model.User
+	if err := s.db.First(&user, "id = ?", token.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if !user.IsActive {
+		return dto.ErrUserInactive
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		pkglogger.Error("Failed to hash new password", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": user.ID,
+		})
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	redeemed, err := s.passwordResets.Redeem(ctx, token.ID, user.ID, string(hashedPassword))
+	if err != nil {
+		pkglogger.Error("Failed to reset password", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": user.ID,
+		})
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+	if !redeemed {
+		return dto.ErrInvalidResetToken
+	}
+
+	// 密碼可能已外洩，讓所有既有登入階段失效
+	if err := s.revokeAllSessions(ctx, user.ID, model.RefreshTokenRevokedPasswordReset); err != nil {
+		return err
+	}
+
 	pkglogger.Info("Password reset confirmed", pkglogger.Fields{
-		"token": req.Token[:8] + "...",
+		"user_id": user.ID,
 	})
 	return nil
 }
 
+// VerifyEmail 以驗證信中的令牌確認信箱
+func (s *AuthService) VerifyEmail(req *dto.VerifyEmailRequest) error {
+	ctx := context.Background()
+
+	token, err := s.emailVerifications.GetByHash(ctx, hashOneTimeToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ErrInvalidVerificationToken
+		}
+		pkglogger.Error("Failed to find email verification token", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return fmt.Errorf("failed to find email verification token: %w", err)
+	}
+	if !token.IsUsable() {
+		return dto.ErrInvalidVerificationToken
+	}
+
+	redeemed, err := s.emailVerifications.Redeem(ctx, token)
+	if err != nil {
+		pkglogger.Error("Failed to verify email", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": token.UserID,
+		})
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	if !redeemed {
+		return dto.ErrInvalidVerificationToken
+	}
+
+	pkglogger.Info("Email verified", pkglogger.Fields{
+		"user_id": token.UserID,
+	})
+	return nil
+}
+
+// ResendVerificationEmail 重新發送驗證信，受發送間隔與每小時上限限制
+func (s *AuthService) ResendVerificationEmail(userID uuid.UUID) error {
+	ctx := context.Background()
+
+	var user model.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user.EmailVerified {
+		return dto.ErrEmailAlreadyVerified
+	}
+
+	if s.config.VerificationResendCooldown > 0 {
+		recent, err := s.emailVerifications.CountIssuedWithin(ctx, userID, s.config.VerificationResendCooldown)
+		if err != nil {
+			return fmt.Errorf("failed to check verification email rate: %w", err)
+		}
+		if recent > 0 {
+			return dto.ErrVerificationRateLimited
+		}
+	}
+	if s.config.VerificationResendPerHour > 0 {
+		recent, err := s.emailVerifications.CountIssuedWithin(ctx, userID, time.Hour)
+		if err != nil {
+			return fmt.Errorf("failed to check verification email rate: %w", err)
+		}
+		if recent >= int64(s.config.VerificationResendPerHour) {
+			return dto.ErrVerificationRateLimited
+		}
+	}
+
+	if err := s.sendVerificationEmail(ctx, &user); err != nil {
+		pkglogger.Error("Failed to send verification email", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return err
+	}
+
+	pkglogger.Info("Verification email resent", pkglogger.Fields{
+		"user_id": userID,
+	})
+	return nil
+}
+
+// IsEmailVerified 查詢使用者信箱是否已驗證，供驗證政策中介軟體使用；找不到使用者時視為未驗證
+func (s *AuthService) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var user model.User
+	err := s.db.WithContext(ctx).Select("email_verified").First(&user, "id = ?", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.EmailVerified, nil
+}
+
+// sendVerificationEmail 簽發驗證令牌並在背景寄送驗證信到使用者目前的信箱
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	rawToken, err := generateOneTimeToken()
+	if err != nil {
+		return err
+	}
+	token := &model.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashOneTimeToken(rawToken),
+		ExpiresAt: time.Now().Add(s.config.EmailVerificationTTL),
+	}
+	if err := s.emailVerifications.Issue(ctx, token); err != nil {
+		return fmt.Errorf("failed to store email verification token: %w", err)
+	}
+
+	msg := &mail.Message{
+		To:      []string{user.Email},
+		Subject: "驗證您的電子郵件信箱",
+		Body: fmt.Sprintf("您好 %s，\n\n請在 %d 小時內開啟以下連結完成信箱驗證：\n\n%s\n\n如果您沒有註冊或變更信箱，請忽略此郵件。\n",
+			user.Username, int(s.config.EmailVerificationTTL.Hours()), tokenLink(s.config.EmailVerificationURL, rawToken)),
+	}
+	go s.sendMail(msg, user.ID)
+	return nil
+}
+
+// tokenLink 將令牌以 token 查詢參數附加到頁面網址
+func tokenLink(pageURL, rawToken string) string {
+	link, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL + "?token=" + url.QueryEscape(rawToken)
+	}
+	query := link.Query()
+	query.Set("token", rawToken)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// mailSendTimeout 背景發送郵件的逾時
+const mailSendTimeout = time.Minute
+
+// sendMail 在背景發送郵件，失敗只記錄日誌
+func (s *AuthService) sendMail(msg *mail.Message, userID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		pkglogger.Error("Failed to send mail", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+			"subject": msg.Subject,
+		})
+	}
+}
+
+// generateOneTimeToken 產生 256 位元的隨機令牌，用於密碼重設與信箱驗證
+func generateOneTimeToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashOneTimeToken 計算一次性令牌雜湊；令牌為高熵亂數，使用 SHA-256 即可供索引查詢
+func hashOneTimeToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
 // GetProfile 取得個人檔案
 func (s *AuthService) GetProfile(userID uuid.UUID) (*vo.ExtendedUserVO, error) {
 	var user model.User
@@ -452,8 +996,10 @@ func (s *AuthService) UpdateProfile(userID uuid.UUID, req *dto.UpdateProfileRequ
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("failed to check email uniqueness: %w", err)
 		}
-		updates["email"] = *req.Email
-		updates["email_verified"] = false // 需要重新驗證郵箱
+		if *req.Email != user.Email {
+			updates["email"] = *req.Email
+			updates["email_verified"] = false // 需要重新驗證郵箱
+		}
 	}
 
 	// 執行更新
@@ -468,6 +1014,16 @@ func (s *AuthService) UpdateProfile(userID uuid.UUID, req *dto.UpdateProfileRequ
 		return nil, fmt.Errorf("failed to reload user: %w", err)
 	}
 
+	// 信箱變更後寄送驗證信到新信箱
+	if _, changed := updates["email"]; changed {
+		if err := s.sendVerificationEmail(context.Background(), &user); err != nil {
+			pkglogger.Error("Failed to send verification email", pkglogger.Fields{
+				"error":   err.Error(),
+				"user_id": userID,
+			})
+		}
+	}
+
 	var userVO vo.ExtendedUserVO
 	if err := copier.Copy(&userVO.UserVO, &user); err != nil {
 		return nil, fmt.Errorf("failed to copy user data: %w", err)
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// 儀表板內容數量
+const (
+	dashboardRecentThreats = 10
+	dashboardRecentJobs    = 5
+	dashboardAlertLimit    = 10
+	dashboardTopValues     = 10
+)
+
+// 快取鍵
+const (
+	dashboardCacheKey = "dashboard"
+	metricsCacheKey   = "metrics"
+)
+
+// DashboardService 儀表板服務介面
+type DashboardService interface {
+	GetDashboard(ctx context.Context) (*vo.DashboardVO, error)
+	GetMetrics(ctx context.Context) (*vo.ThreatIntelligenceMetricsVO, error)
+}
+
+// dashboardService 儀表板服務實作
+// 彙總結果會在記憶體中快取 ttl 時間，同時到達的請求共用同一次查詢
+type dashboardService struct {
+	threatService ThreatIntelligenceService
+	sourceService IntelligenceSourceService
+	threatRepo    repository.ThreatIntelligenceRepository
+	ttl           time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]dashboardCacheEntry
+	group singleflight.Group
+}
+
+// dashboardCacheEntry 快取項目
+type dashboardCacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewDashboardService 建立儀表板服務，ttl 小於等於 0 時停用快取
+func NewDashboardService(
+	threatService ThreatIntelligenceService,
+	sourceService IntelligenceSourceService,
+	threatRepo repository.ThreatIntelligenceRepository,
+	ttl time.Duration,
+) DashboardService {
+	return &dashboardService{
+		threatService: threatService,
+		sourceService: sourceService,
+		threatRepo:    threatRepo,
+		ttl:           ttl,
+		cache:         make(map[string]dashboardCacheEntry),
+	}
+}
+
+// GetDashboard 取得儀表板資料
+func (s *dashboardService) GetDashboard(ctx context.Context) (*vo.DashboardVO, error) {
+	value, err := s.cached(ctx, dashboardCacheKey, func(ctx context.Context) (interface{}, error) {
+		return s.buildDashboard(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*vo.DashboardVO), nil
+}
+
+// GetMetrics 取得威脅情報指標
+func (s *dashboardService) GetMetrics(ctx context.Context) (*vo.ThreatIntelligenceMetricsVO, error) {
+	value, err := s.cached(ctx, metricsCacheKey, func(ctx context.Context) (interface{}, error) {
+		return s.buildMetrics(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*vo.ThreatIntelligenceMetricsVO), nil
+}
+
+// cached 取得快取值，過期或不存在時載入
+// 載入使用不受請求取消影響的 context，避免第一個請求中斷時連帶讓共用結果的請求失敗
+func (s *dashboardService) cached(ctx context.Context, key string, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if s.ttl <= 0 {
+		return load(ctx)
+	}
+
+	s.mu.RLock()
+	entry, ok := s.cache[key]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	result := s.group.DoChan(key, func() (interface{}, error) {
+		value, err := load(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.cache[key] = dashboardCacheEntry{value: value, expiresAt: time.Now().Add(s.ttl)}
+		s.mu.Unlock()
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		return res.Val, res.Err
+	}
+}
+
+// buildDashboard 組合儀表板資料，概覽涵蓋全部威脅，時間線為最近 30 天
+func (s *dashboardService) buildDashboard(ctx context.Context) (*vo.DashboardVO, error) {
+	statistics, err := s.threatService.GetStatistics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threat statistics: %w", err)
+	}
+
+	timeline, err := s.threatService.GetStats(ctx, &dto.ThreatIntelligenceStatsRequest{GroupBy: "severity"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threat timeline: %w", err)
+	}
+
+	recent, err := s.threatService.ListThreats(ctx, &dto.ThreatIntelligenceQueryRequest{
+		PageSize:  dashboardRecentThreats,
+		SortBy:    "last_seen",
+		SortOrder: "desc",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent threats: %w", err)
+	}
+
+	sources, err := s.sourceService.ListSources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list intelligence sources: %w", err)
+	}
+
+	jobs, err := s.sourceService.ListJobs(ctx, &dto.CollectionJobQueryRequest{PageSize: dashboardRecentJobs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection jobs: %w", err)
+	}
+
+	jobCounts := make(map[model.CollectionStatus]int)
+	for _, status := range []model.CollectionStatus{model.StatusPending, model.StatusInProgress, model.StatusCompleted} {
+		if jobCounts[status], err = s.countJobs(ctx, status); err != nil {
+			return nil, err
+		}
+	}
+
+	alerts, err := s.buildAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := vo.OverviewVO{
+		TotalThreats:    statistics.TotalThreats,
+		ActiveThreats:   statistics.ActiveThreats,
+		HighRiskThreats: statistics.HighRiskThreats,
+		RecentThreats:   statistics.RecentThreats,
+		TotalSources:    len(sources),
+		PendingJobs:     jobCounts[model.StatusPending] + jobCounts[model.StatusInProgress],
+		CompletedJobs:   jobCounts[model.StatusCompleted],
+	}
+	for _, source := range sources {
+		if source.IsActive {
+			overview.ActiveSources++
+		}
+	}
+
+	return &vo.DashboardVO{
+		Overview:      overview,
+		RecentThreats: recent.Data,
+		RecentJobs:    jobs.Data,
+		Statistics:    statistics.ThreatTypeStats,
+		Timeline:      timeline.Timeline,
+		Alerts:        alerts,
+		GeneratedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// buildMetrics 組合威脅情報指標，分布統計涵蓋全部威脅，近期活動為最近 24 小時
+func (s *dashboardService) buildMetrics(ctx context.Context) (*vo.ThreatIntelligenceMetricsVO, error) {
+	stats, err := s.threatRepo.GetStats(ctx, &repository.StatsFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threat stats: %w", err)
+	}
+
+	activity, err := s.threatService.GetStats(ctx, &dto.ThreatIntelligenceStatsRequest{Interval: "hour"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get threat activity: %w", err)
+	}
+
+	topIPs, err := s.topValues(ctx, "ip_address")
+	if err != nil {
+		return nil, err
+	}
+	topDomains, err := s.topValues(ctx, "domain")
+	if err != nil {
+		return nil, err
+	}
+	asns, err := s.threatRepo.GetTopValues(ctx, "asn", dashboardTopValues)
+	if err != nil {
+		return nil, err
+	}
+	byASN := make(map[string]int, len(asns))
+	for _, asn := range asns {
+		byASN[asn.Value] = int(asn.Count)
+	}
+
+	sources, err := s.sourceService.ListSources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list intelligence sources: %w", err)
+	}
+	collectionStatus := make(map[string]interface{}, len(sources))
+	for _, source := range sources {
+		collectionStatus[source.Name] = map[string]interface{}{
+			"is_active":       source.IsActive,
+			"last_collection": source.LastCollection,
+			"total_collected": source.TotalCollected,
+		}
+	}
+
+	return &vo.ThreatIntelligenceMetricsVO{
+		TotalThreats:        int(stats.TotalThreats),
+		ActiveThreats:       int(stats.ActiveCount),
+		HighRiskThreats:     int(stats.HighRiskCount),
+		RecentThreats:       int(stats.RecentCount),
+		ThreatsByType:       toIntMap(stats.CountByType),
+		ThreatsBySeverity:   toIntMap(stats.CountBySeverity),
+		ThreatsBySource:     toIntMap(stats.CountBySource),
+		ThreatsByCountry:    toIntMap(stats.CountByCountry),
+		ThreatsByASN:        byASN,
+		TopMaliciousIPs:     topIPs,
+		TopMaliciousDomains: topDomains,
+		RecentActivity:      activity.Timeline,
+		CollectionStatus:    collectionStatus,
+		GeneratedAt:         time.Now().UTC(),
+	}, nil
+}
+
+// buildAlerts 依最近 24 小時的重大威脅與失敗的收集任務產生警報
+func (s *dashboardService) buildAlerts(ctx context.Context) ([]vo.AlertVO, error) {
+	alerts := []vo.AlertVO{}
+
+	critical, err := s.threatService.ListThreats(ctx, &dto.ThreatIntelligenceQueryRequest{
+		Query:     "severity:>=high last_seen:>=now-24h",
+		PageSize:  dashboardAlertLimit,
+		SortBy:    "last_seen",
+		SortOrder: "desc",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list high risk threats: %w", err)
+	}
+	for _, threat := range critical.Data {
+		level := "warning"
+		if threat.Severity == string(model.SeverityCritical) {
+			level = "critical"
+		}
+		alerts = append(alerts, vo.AlertVO{
+			ID:        threat.ID,
+			Level:     level,
+			Title:     fmt.Sprintf("%s %s threat detected", threat.Severity, threat.ThreatType),
+			Message:   fmt.Sprintf("%s %s reported by %s (risk score %d)", threat.IndicatorType, threat.IndicatorValue, threat.Source, threat.RiskScore),
+			Source:    "threat_analyzer",
+			CreatedAt: threat.LastSeen,
+		})
+	}
+
+	failed := string(model.StatusFailed)
+	jobs, err := s.sourceService.ListJobs(ctx, &dto.CollectionJobQueryRequest{Status: &failed, PageSize: dashboardAlertLimit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed collection jobs: %w", err)
+	}
+	since := time.Now().Add(-24 * time.Hour)
+	for _, job := range jobs.Data {
+		if job.CreatedAt.Before(since) {
+			continue
+		}
+		message := "collection job failed"
+		if job.ErrorMessage != nil {
+			message = *job.ErrorMessage
+		}
+		alerts = append(alerts, vo.AlertVO{
+			ID:        job.ID,
+			Level:     "error",
+			Title:     fmt.Sprintf("Collection from %s failed", job.SourceName),
+			Message:   message,
+			Source:    "collector",
+			CreatedAt: job.CreatedAt,
+		})
+	}
+
+	return alerts, nil
+}
+
+// countJobs 取得指定狀態的收集任務數量
+func (s *dashboardService) countJobs(ctx context.Context, status model.CollectionStatus) (int, error) {
+	value := string(status)
+	jobs, err := s.sourceService.ListJobs(ctx, &dto.CollectionJobQueryRequest{Status: &value, PageSize: 1})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count %s collection jobs: %w", status, err)
+	}
+	return int(jobs.Pagination.TotalRecords), nil
+}
+
+// topValues 取得排行欄位值
+func (s *dashboardService) topValues(ctx context.Context, field string) ([]string, error) {
+	values, err := s.threatRepo.GetTopValues(ctx, field, dashboardTopValues)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = value.Value
+	}
+	return result, nil
+}
+
+// toIntMap 轉換計數對應表
+func toIntMap(m map[string]int64) map[string]int {
+	result := make(map[string]int, len(m))
+	for k, v := range m {
+		result[k] = int(v)
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/testutil"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+)
+
+// dashboardFixture 儀表板整合測試環境
+type dashboardFixture struct {
+	threatRepo    repository.ThreatIntelligenceRepository
+	threatService ThreatIntelligenceService
+	sourceService IntelligenceSourceService
+}
+
+// newDashboardFixture 建立連接測試資料庫的儀表板相依服務，並寫入一個來源、兩筆收集任務與三筆威脅
+func newDashboardFixture(t *testing.T) *dashboardFixture {
+	t.Helper()
+	ctx := context.Background()
+	db := testutil.OpenPostgres(t)
+
+	threatRepo := repository.NewThreatIntelligenceRepository(db)
+	sourceRepo := repository.NewIntelligenceSourceRepository(db)
+	jobRepo := repository.NewCollectionJobRepository(db)
+
+	source := &model.IntelligenceSource{Name: "AbuseIPDB", IsActive: true, CollectionInterval: 3600}
+	require.NoError(t, sourceRepo.Create(ctx, source))
+
+	errorMessage := "upstream returned 503"
+	require.NoError(t, jobRepo.Create(ctx, &model.CollectionJob{SourceID: source.ID, Status: model.StatusCompleted, RecordsCollected: 3}))
+	require.NoError(t, jobRepo.Create(ctx, &model.CollectionJob{SourceID: source.ID, Status: model.StatusFailed, ErrorMessage: &errorMessage}))
+
+	now := time.Now()
+	domain := "evil.example"
+	for _, threat := range []*model.ThreatIntelligence{
+		{IndicatorType: model.IndicatorIPv4, IndicatorValue: "203.0.113.1", IPAddress: net.ParseIP("203.0.113.1"), ThreatType: model.ThreatBotnet, Severity: model.SeverityCritical},
+		{IndicatorType: model.IndicatorIPv4, IndicatorValue: "203.0.113.2", IPAddress: net.ParseIP("203.0.113.2"), ThreatType: model.ThreatBotnet, Severity: model.SeverityMedium},
+		{IndicatorType: model.IndicatorDomain, IndicatorValue: domain, Domain: &domain, ThreatType: model.ThreatPhishing, Severity: model.SeverityLow},
+	} {
+		threat.Source = source.Name
+		threat.ConfidenceScore = 90
+		threat.FirstSeen = now
+		threat.LastSeen = now
+		require.NoError(t, threatRepo.Create(ctx, threat))
+	}
+
+	return &dashboardFixture{
+		threatRepo:    threatRepo,
+		threatService: NewThreatIntelligenceService(threatRepo, nil),
+		sourceService: NewIntelligenceSourceService(sourceRepo, jobRepo, nil),
+	}
+}
+
+// newService 建立儀表板服務
+func (f *dashboardFixture) newService(ttl time.Duration) DashboardService {
+	return NewDashboardService(f.threatService, f.sourceService, f.threatRepo, ttl)
+}
+
+// timelineTotal 加總時間線數量
+func timelineTotal(timeline []vo.ThreatTimelineVO) int {
+	total := 0
+	for _, point := range timeline {
+		total += point.Count
+	}
+	return total
+}
+
+func TestDashboardService_GetDashboard(t *testing.T) {
+	fixture := newDashboardFixture(t)
+
+	dashboard, err := fixture.newService(0).GetDashboard(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, vo.OverviewVO{
+		TotalThreats:    3,
+		ActiveThreats:   3,
+		HighRiskThreats: 1,
+		RecentThreats:   3,
+		TotalSources:    1,
+		ActiveSources:   1,
+		PendingJobs:     0,
+		CompletedJobs:   1,
+	}, dashboard.Overview)
+	assert.Len(t, dashboard.RecentThreats, 3)
+	assert.Len(t, dashboard.RecentJobs, 2)
+	assert.Equal(t, []vo.StatsVO{
+		{Label: "botnet", Value: 2, Percentage: float64(2) * 100 / 3},
+		{Label: "phishing", Value: 1, Percentage: float64(1) * 100 / 3},
+	}, dashboard.Statistics)
+	assert.Equal(t, 3, timelineTotal(dashboard.Timeline))
+
+	require.Len(t, dashboard.Alerts, 2)
+	assert.Equal(t, "critical", dashboard.Alerts[0].Level)
+	assert.Equal(t, "threat_analyzer", dashboard.Alerts[0].Source)
+	assert.Equal(t, "error", dashboard.Alerts[1].Level)
+	assert.Equal(t, "upstream returned 503", dashboard.Alerts[1].Message)
+}
+
+func TestDashboardService_GetMetrics(t *testing.T) {
+	fixture := newDashboardFixture(t)
+
+	metrics, err := fixture.newService(0).GetMetrics(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, metrics.TotalThreats)
+	assert.Equal(t, 1, metrics.HighRiskThreats)
+	assert.Equal(t, map[string]int{"botnet": 2, "phishing": 1}, metrics.ThreatsByType)
+	assert.Equal(t, map[string]int{"critical": 1, "medium": 1, "low": 1}, metrics.ThreatsBySeverity)
+	assert.Equal(t, map[string]int{"AbuseIPDB": 3}, metrics.ThreatsBySource)
+	assert.ElementsMatch(t, []string{"203.0.113.1", "203.0.113.2"}, metrics.TopMaliciousIPs)
+	assert.Equal(t, []string{"evil.example"}, metrics.TopMaliciousDomains)
+	assert.Equal(t, 3, timelineTotal(metrics.RecentActivity))
+	assert.Contains(t, metrics.CollectionStatus, "AbuseIPDB")
+}
+
+func TestDashboardService_CachesResults(t *testing.T) {
+	fixture := newDashboardFixture(t)
+	ctx := context.Background()
+	cached := fixture.newService(time.Hour)
+	uncached := fixture.newService(0)
+
+	first, err := cached.GetDashboard(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, fixture.threatRepo.Create(ctx, &model.ThreatIntelligence{
+		IndicatorType:  model.IndicatorIPv4,
+		IndicatorValue: "203.0.113.3",
+		IPAddress:      net.ParseIP("203.0.113.3"),
+		ThreatType:     model.ThreatScanner,
+		Severity:       model.SeverityLow,
+		Source:         "AbuseIPDB",
+	}))
+
+	second, err := cached.GetDashboard(ctx)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, 3, second.Overview.TotalThreats)
+
+	fresh, err := uncached.GetDashboard(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, fresh.Overview.TotalThreats)
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// CollectionRunner 收集執行者介面，由收集排程器實作
+type CollectionRunner interface {
+	// Validate 檢查情報來源設定能否用於其收集器，例如覆寫的 API 位址是否允許
+	Validate(source *model.IntelligenceSource) error
+	// Bind 依據情報來源資料列重新綁定收集器
+	Bind(source *model.IntelligenceSource) error
+	// Trigger 立即派發一次收集
+	Trigger(ctx context.Context, source *model.IntelligenceSource) (*model.CollectionJob, error)
+}
+
+// IntelligenceSourceService 情報來源服務介面
+type IntelligenceSourceService interface {
+	ListSources(ctx context.Context) ([]vo.IntelligenceSourceVO, error)
+	GetSource(ctx context.Context, id uuid.UUID) (*vo.IntelligenceSourceVO, error)
+	CreateSource(ctx context.Context, req *dto.IntelligenceSourceCreateRequest) (*vo.IntelligenceSourceVO, error)
+	UpdateSource(ctx context.Context, id uuid.UUID, req *dto.IntelligenceSourceUpdateRequest) (*vo.IntelligenceSourceVO, error)
+	DeleteSource(ctx context.Context, id uuid.UUID) error
+	SetSourceActive(ctx context.Context, id uuid.UUID, active bool) (*vo.IntelligenceSourceVO, error)
+	CollectNow(ctx context.Context, id uuid.UUID) (*vo.CollectionJobVO, error)
+	ListJobs(ctx context.Context, req *dto.CollectionJobQueryRequest) (*vo.CollectionJobListVO, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*vo.CollectionJobVO, error)
+}
+
+// intelligenceSourceService 情報來源服務實作
+type intelligenceSourceService struct {
+	sourceRepo repository.IntelligenceSourceRepository
+	jobRepo    repository.CollectionJobRepository
+	runner     CollectionRunner
+}
+
+// NewIntelligenceSourceService 建立情報來源服務
+func NewIntelligenceSourceService(
+	sourceRepo repository.IntelligenceSourceRepository,
+	jobRepo repository.CollectionJobRepository,
+	runner CollectionRunner,
+) IntelligenceSourceService {
+	return &intelligenceSourceService{
+		sourceRepo: sourceRepo,
+		jobRepo:    jobRepo,
+		runner:     runner,
+	}
+}
+
+// ListSources 取得所有情報來源
+func (s *intelligenceSourceService) ListSources(ctx context.Context) ([]vo.IntelligenceSourceVO, error) {
+	sources, err := s.sourceRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]vo.IntelligenceSourceVO, len(sources))
+	for i, source := range sources {
+		result[i] = *s.sourceToVO(source)
+	}
+	return result, nil
+}
+
+// GetSource 根據 ID 取得情報來源
+func (s *intelligenceSourceService) GetSource(ctx context.Context, id uuid.UUID) (*vo.IntelligenceSourceVO, error) {
+	source, err := s.getSource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.sourceToVO(source), nil
+}
+
+// CreateSource 建立情報來源
+func (s *intelligenceSourceService) CreateSource(ctx context.Context, req *dto.IntelligenceSourceCreateRequest) (*vo.IntelligenceSourceVO, error) {
+	_, err := s.sourceRepo.GetByName(ctx, req.Name)
+	if err == nil {
+		return nil, dto.ErrSourceExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check source name: %w", err)
+	}
+
+	source := &model.IntelligenceSource{
+		Name:               req.Name,
+		URL:                req.URL,
+		APIKeyRequired:     req.APIKeyRequired,
+		IsActive:           true,
+		CollectionInterval: req.CollectionInterval,
+	}
+	if req.IsActive != nil {
+		source.IsActive = *req.IsActive
+	}
+	if source.CollectionInterval == 0 {
+		source.CollectionInterval = 3600
+	}
+	if err := s.runner.Validate(source); err != nil {
+		return nil, err
+	}
+
+	if err := s.sourceRepo.Create(ctx, source); err != nil {
+		return nil, err
+	}
+
+	s.bind(source)
+
+	return s.sourceToVO(source), nil
+}
+
+// UpdateSource 更新情報來源
+func (s *intelligenceSourceService) UpdateSource(ctx context.Context, id uuid.UUID, req *dto.IntelligenceSourceUpdateRequest) (*vo.IntelligenceSourceVO, error) {
+	source, err := s.getSource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		source.URL = req.URL
+	}
+	if req.APIKeyRequired != nil {
+		source.APIKeyRequired = *req.APIKeyRequired
+	}
+	if req.IsActive != nil {
+		source.IsActive = *req.IsActive
+	}
+	if req.CollectionInterval != nil {
+		if *req.CollectionInterval <= 0 {
+			return nil, dto.ErrInvalidCollectionInterval
+		}
+		source.CollectionInterval = *req.CollectionInterval
+	}
+	if req.URL != nil {
+		if err := s.runner.Validate(source); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.sourceRepo.Update(ctx, source); err != nil {
+		return nil, err
+	}
+
+	// URL 變更需重新綁定收集器
+	if req.URL != nil {
+		s.bind(source)
+	}
+
+	return s.sourceToVO(source), nil
+}
+
+// DeleteSource 刪除情報來源
+func (s *intelligenceSourceService) DeleteSource(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.getSource(ctx, id); err != nil {
+		return err
+	}
+	return s.sourceRepo.Delete(ctx, id)
+}
+
+// SetSourceActive 啟用或停用情報來源
+func (s *intelligenceSourceService) SetSourceActive(ctx context.Context, id uuid.UUID, active bool) (*vo.IntelligenceSourceVO, error) {
+	return s.UpdateSource(ctx, id, &dto.IntelligenceSourceUpdateRequest{IsActive: &active})
+}
+
+// CollectNow 立即觸發情報來源收集
+func (s *intelligenceSourceService) CollectNow(ctx context.Context, id uuid.UUID) (*vo.CollectionJobVO, error) {
+	source, err := s.getSource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.runner.Trigger(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	job.Source = *source
+
+	return s.jobToVO(job), nil
+}
+
+// ListJobs 取得收集任務列表
+func (s *intelligenceSourceService) ListJobs(ctx context.Context, req *dto.CollectionJobQueryRequest) (*vo.CollectionJobListVO, error) {
+	req.SetDefaults()
+
+	filter := &repository.CollectionJobFilter{
+		Status:   req.Status,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+	if req.SourceID != nil {
+		sourceID, err := uuid.Parse(*req.SourceID)
+		if err != nil {
+			return nil, dto.ErrInvalidUUID
+		}
+		filter.SourceID = &sourceID
+	}
+
+	jobs, total, err := s.jobRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	jobVOs := make([]vo.CollectionJobVO, len(jobs))
+	for i, job := range jobs {
+		jobVOs[i] = *s.jobToVO(job)
+	}
+
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize > 0 {
+		totalPages++
+	}
+
+	return &vo.CollectionJobListVO{
+		Data: jobVOs,
+		Pagination: vo.PaginationVO{
+			CurrentPage:  req.Page,
+			PageSize:     req.PageSize,
+			TotalPages:   totalPages,
+			TotalRecords: total,
+			HasNext:      req.Page < totalPages,
+			HasPrevious:  req.Page > 1,
+		},
+	}, nil
+}
+
+// GetJob 根據 ID 取得收集任務
+func (s *intelligenceSourceService) GetJob(ctx context.Context, id uuid.UUID) (*vo.CollectionJobVO, error) {
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrCollectionJobNotFound
+		}
+		return nil, err
+	}
+	return s.jobToVO(job), nil
+}
+
+// getSource 取得情報來源並轉換找不到的錯誤
+func (s *intelligenceSourceService) getSource(ctx context.Context, id uuid.UUID) (*model.IntelligenceSource, error) {
+	source, err := s.sourceRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrSourceNotFound
+		}
+		return nil, err
+	}
+	return source, nil
+}
+
+// bind 重新綁定收集器，沒有對應收集器的來源（例如手動輸入）只記錄除錯訊息
+func (s *intelligenceSourceService) bind(source *model.IntelligenceSource) {
+	if err := s.runner.Bind(source); err != nil {
+		pkglogger.Debug("情報來源未綁定收集器", pkglogger.Fields{
+			"source": source.Name,
+			"error":  err.Error(),
+		})
+	}
+}
+
+// sourceToVO 將情報來源模型轉換為 VO
+func (s *intelligenceSourceService) sourceToVO(source *model.IntelligenceSource) *vo.IntelligenceSourceVO {
+	return &vo.IntelligenceSourceVO{
+		ID:                 source.ID,
+		Name:               source.Name,
+		URL:                source.URL,
+		APIKeyRequired:     source.APIKeyRequired,
+		IsActive:           source.IsActive,
+		CollectionInterval: source.CollectionInterval,
+		LastCollection:     source.LastCollection,
+		TotalCollected:     source.TotalCollected,
+		CreatedAt:          source.CreatedAt,
+		UpdatedAt:          source.UpdatedAt,
+	}
+}
+
+// jobToVO 將收集任務模型轉換為 VO
+func (s *intelligenceSourceService) jobToVO(job *model.CollectionJob) *vo.CollectionJobVO {
+	jobVO := &vo.CollectionJobVO{
+		ID:               job.ID,
+		SourceID:         job.SourceID,
+		SourceName:       job.Source.Name,
+		Status:           string(job.Status),
+		StartedAt:        job.StartedAt,
+		CompletedAt:      job.CompletedAt,
+		RecordsCollected: job.RecordsCollected,
+		RecordsCreated:   job.RecordsCreated,
+		RecordsUpdated:   job.RecordsUpdated,
+		ErrorMessage:     job.ErrorMessage,
+		CreatedAt:        job.CreatedAt,
+		UpdatedAt:        job.UpdatedAt,
+	}
+
+	if duration := job.GetDuration(); duration != nil {
+		formatted := duration.Round(time.Millisecond).String()
+		jobVO.Duration = &formatted
+	}
+
+	return jobVO
+}
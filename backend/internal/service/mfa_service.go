@@ -0,0 +1,446 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/totp"
+)
+
+// 復原碼參數：10 組、每組 80 位元隨機值，以 Base32 分成四段顯示
+const (
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 10
+)
+
+// mfaCodeSkew 驗證碼容許的時鐘誤差（前後各一個時間步）
+const mfaCodeSkew = 1
+
+// MFAService 多因素驗證服務介面
+type MFAService interface {
+	GetStatus(ctx context.Context, userID uuid.UUID, role string) (*vo.MFAStatusVO, error)
+	Setup(ctx context.Context, userID uuid.UUID, account string) (*vo.MFASetupVO, error)
+	Enable(ctx context.Context, userID uuid.UUID, code string) (*vo.MFARecoveryCodesVO, error)
+	Disable(ctx context.Context, userID uuid.UUID, role, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*vo.MFARecoveryCodesVO, error)
+	IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	IsRequired(role string) bool
+	CreateChallenge(ctx context.Context, userID uuid.UUID) (*vo.MFAChallengeVO, error)
+	CompleteChallenge(ctx context.Context, rawToken, code string) (uuid.UUID, error)
+}
+
+// MFAServiceConfig 多因素驗證服務配置
+type MFAServiceConfig struct {
+	Issuer        string        // 驗證器中顯示的發行者名稱
+	EncryptionKey string        // 加密共享密鑰的金鑰，經 SHA-256 衍生為 AES-256 金鑰
+	RequiredRoles []string      // 必須啟用多因素驗證的角色
+	ChallengeTTL  time.Duration // 登入挑戰有效期間
+	MaxAttempts   int           // 每個登入挑戰可嘗試的次數
+}
+
+// mfaService 多因素驗證服務實作
+type mfaService struct {
+	repo          repository.MFARepository
+	aead          cipher.AEAD
+	issuer        string
+	requiredRoles map[string]bool
+	challengeTTL  time.Duration
+	maxAttempts   int
+}
+
+// NewMFAService 建立多因素驗證服務
+func NewMFAService(repo repository.MFARepository, config MFAServiceConfig) (MFAService, error) {
+	if config.EncryptionKey == "" {
+		return nil, errors.New("MFA encryption key is required")
+	}
+	key := sha256.Sum256([]byte(config.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MFA cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MFA cipher: %w", err)
+	}
+
+	requiredRoles := make(map[string]bool, len(config.RequiredRoles))
+	for _, role := range config.RequiredRoles {
+		if role = strings.TrimSpace(role); role != "" {
+			requiredRoles[role] = true
+		}
+	}
+
+	return &mfaService{
+		repo:          repo,
+		aead:          aead,
+		issuer:        config.Issuer,
+		requiredRoles: requiredRoles,
+		challengeTTL:  config.ChallengeTTL,
+		maxAttempts:   config.MaxAttempts,
+	}, nil
+}
+
+// GetStatus 取得使用者的多因素驗證狀態
+func (s *mfaService) GetStatus(ctx context.Context, userID uuid.UUID, role string) (*vo.MFAStatusVO, error) {
+	status := &vo.MFAStatusVO{Required: s.IsRequired(role)}
+
+	mfa, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return status, nil
+		}
+		return nil, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	if !mfa.IsEnabled() {
+		return status, nil
+	}
+
+	remaining, err := s.repo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	status.Enabled = true
+	status.EnabledAt = mfa.EnabledAt
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+// Setup 產生新的共享密鑰，使用者以驗證碼確認後才會啟用；重複呼叫會取代尚未確認的密鑰
+func (s *mfaService) Setup(ctx context.Context, userID uuid.UUID, account string) (*vo.MFASetupVO, error) {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, dto.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.encryptSecret(userID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SavePending(ctx, &model.UserMFA{UserID: userID, SecretEncrypted: encrypted}); err != nil {
+		pkglogger.Error("Failed to save MFA secret", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to save MFA secret: %w", err)
+	}
+
+	return &vo.MFASetupVO{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, account, secret),
+	}, nil
+}
+
+// Enable 以驗證器產生的代碼確認設定並啟用多因素驗證，回傳只顯示一次的復原碼
+func (s *mfaService) Enable(ctx context.Context, userID uuid.UUID, code string) (*vo.MFARecoveryCodesVO, error) {
+	mfa, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrMFANotSetup
+		}
+		return nil, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	if mfa.IsEnabled() {
+		return nil, dto.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := s.decryptSecret(userID, mfa.SecretEncrypted)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, normalizeMFACode(code), time.Now(), mfaCodeSkew)
+	if !ok {
+		return nil, dto.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.repo.Enable(ctx, userID, step, hashes)
+	if err != nil {
+		pkglogger.Error("Failed to enable MFA", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to enable MFA: %w", err)
+	}
+	if !enabled {
+		// 併發請求已先完成啟用
+		return nil, dto.ErrMFAAlreadyEnabled
+	}
+
+	pkglogger.Info("MFA enabled", pkglogger.Fields{
+		"user_id": userID,
+	})
+	return &vo.MFARecoveryCodesVO{RecoveryCodes: codes}, nil
+}
+
+// Disable 驗證代碼後停用多因素驗證；角色要求多因素驗證時不允許停用
+func (s *mfaService) Disable(ctx context.Context, userID uuid.UUID, role, code string) error {
+	if s.IsRequired(role) {
+		return dto.ErrMFARequired
+	}
+
+	mfa, err := s.getEnabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.verifyCode(ctx, mfa, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return dto.ErrInvalidMFACode
+	}
+
+	if err := s.repo.Disable(ctx, userID); err != nil {
+		pkglogger.Error("Failed to disable MFA", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return fmt.Errorf("failed to disable MFA: %w", err)
+	}
+
+	pkglogger.Info("MFA disabled", pkglogger.Fields{
+		"user_id": userID,
+	})
+	return nil
+}
+
+// RegenerateRecoveryCodes 驗證代碼後產生新的一組復原碼，舊的復原碼全部失效
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*vo.MFARecoveryCodesVO, error) {
+	mfa, err := s.getEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.verifyCode(ctx, mfa, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, dto.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		pkglogger.Error("Failed to replace recovery codes", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to replace recovery codes: %w", err)
+	}
+	return &vo.MFARecoveryCodesVO{RecoveryCodes: codes}, nil
+}
+
+// IsEnabled 檢查使用者是否已啟用多因素驗證
+func (s *mfaService) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	mfa, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	return mfa.IsEnabled(), nil
+}
+
+// IsRequired 檢查角色是否必須啟用多因素驗證
+func (s *mfaService) IsRequired(role string) bool {
+	return s.requiredRoles[role]
+}
+
+// CreateChallenge 密碼驗證通過後建立登入挑戰，令牌只回傳給客戶端，資料庫保存雜湊
+func (s *mfaService) CreateChallenge(ctx context.Context, userID uuid.UUID) (*vo.MFAChallengeVO, error) {
+	rawToken, err := generateOneTimeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.challengeTTL)
+	challenge := &model.MFAChallenge{
+		UserID:    userID,
+		TokenHash: hashOneTimeToken(rawToken),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.CreateChallenge(ctx, challenge); err != nil {
+		pkglogger.Error("Failed to create MFA challenge", pkglogger.Fields{
+			"error":   err.Error(),
+			"user_id": userID,
+		})
+		return nil, fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+
+	return &vo.MFAChallengeVO{
+		MFARequired: true,
+		MFAToken:    rawToken,
+		ExpiresIn:   int(s.challengeTTL.Seconds()),
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// CompleteChallenge 驗證登入挑戰與代碼，成功時回傳使用者 ID
+// 每次驗證前先佔用一次嘗試次數，用盡後挑戰失效，需重新輸入密碼
+func (s *mfaService) CompleteChallenge(ctx context.Context, rawToken, code string) (uuid.UUID, error) {
+	challenge, err := s.repo.GetChallengeByHash(ctx, hashOneTimeToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, dto.ErrInvalidMFAChallenge
+		}
+		return uuid.Nil, fmt.Errorf("failed to get MFA challenge: %w", err)
+	}
+
+	claimed, err := s.repo.ClaimChallengeAttempt(ctx, challenge.ID, s.maxAttempts)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to claim MFA challenge attempt: %w", err)
+	}
+	if !claimed {
+		return uuid.Nil, dto.ErrInvalidMFAChallenge
+	}
+
+	mfa, err := s.getEnabled(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, dto.ErrMFANotEnabled) {
+			// 挑戰建立後使用者停用了多因素驗證
+			return uuid.Nil, dto.ErrInvalidMFAChallenge
+		}
+		return uuid.Nil, err
+	}
+	ok, err := s.verifyCode(ctx, mfa, code)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !ok {
+		pkglogger.Warn("Invalid MFA code", pkglogger.Fields{
+			"event":        "mfa_verification_failed",
+			"user_id":      challenge.UserID,
+			"challenge_id": challenge.ID,
+			"attempt":      challenge.Attempts + 1,
+		})
+		return uuid.Nil, dto.ErrInvalidMFACode
+	}
+
+	completed, err := s.repo.CompleteChallenge(ctx, challenge.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to complete MFA challenge: %w", err)
+	}
+	if !completed {
+		return uuid.Nil, dto.ErrInvalidMFAChallenge
+	}
+	return challenge.UserID, nil
+}
+
+// getEnabled 取得已啟用的多因素驗證設定
+func (s *mfaService) getEnabled(ctx context.Context, userID uuid.UUID) (*model.UserMFA, error) {
+	mfa, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrMFANotEnabled
+		}
+		return nil, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	if !mfa.IsEnabled() {
+		return nil, dto.ErrMFANotEnabled
+	}
+	return mfa, nil
+}
+
+// verifyCode 驗證 TOTP 代碼或復原碼；TOTP 代碼的時間步只能使用一次，復原碼使用後即失效
+func (s *mfaService) verifyCode(ctx context.Context, mfa *model.UserMFA, code string) (bool, error) {
+	code = normalizeMFACode(code)
+
+	if len(code) != totp.Digits || strings.Trim(code, "0123456789") != "" {
+		used, err := s.repo.ConsumeRecoveryCode(ctx, mfa.UserID, hashOneTimeToken(code))
+		if err != nil {
+			return false, fmt.Errorf("failed to consume recovery code: %w", err)
+		}
+		return used, nil
+	}
+
+	secret, err := s.decryptSecret(mfa.UserID, mfa.SecretEncrypted)
+	if err != nil {
+		return false, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), mfaCodeSkew)
+	if !ok {
+		return false, nil
+	}
+	consumed, err := s.repo.ConsumeStep(ctx, mfa.UserID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record MFA code use: %w", err)
+	}
+	return consumed, nil
+}
+
+// encryptSecret 以 AES-GCM 加密共享密鑰，使用者 ID 作為附加資料，密文無法搬到其他帳號使用
+func (s *mfaService) encryptSecret(userID uuid.UUID, secret string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(secret), userID[:])
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptSecret 解密共享密鑰
+func (s *mfaService) decryptSecret(userID uuid.UUID, encrypted string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", errors.New("invalid encrypted MFA secret")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, userID[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt MFA secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+// generateRecoveryCodes 產生復原碼，回傳顯示用的代碼與保存用的雜湊
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		raw := encoding.EncodeToString(buf)
+		codes = append(codes, strings.Join([]string{raw[0:4], raw[4:8], raw[8:12], raw[12:16]}, "-"))
+		hashes = append(hashes, hashOneTimeToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeMFACode 移除空白與分隔符號並轉為大寫，復原碼輸入時可省略連字號
+func normalizeMFACode(code string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/oidc"
+)
+
+// 自動建立帳號時使用者名稱的長度限制，與註冊請求一致
+const (
+	oidcUsernameMinLength = 3
+	oidcUsernameMaxLength = 50
+)
+
+// oidcUsernameInvalidChars 使用者名稱不允許的字元
+var oidcUsernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// OIDCService 單一登入服務介面
+type OIDCService interface {
+	Authorize(ctx context.Context) (*vo.OIDCAuthorizationVO, error)
+	Callback(ctx context.Context, req *dto.OIDCCallbackRequest) (*vo.AuthTokenResponse, *vo.MFAChallengeVO, error)
+}
+
+// OIDCRoleMapping 身分提供者的角色宣告值對應的平台角色
+type OIDCRoleMapping struct {
+	ClaimValue string
+	Role       model.UserRole
+}
+
+// OIDCServiceConfig 單一登入服務配置
+type OIDCServiceConfig struct {
+	RoleClaim   string            // 角色宣告路徑，以點分隔巢狀欄位，例如 realm_access.roles；為空時不同步角色
+	RoleMapping []OIDCRoleMapping // 角色對應，宣告中有多個可對應的值時以排在前面的對應為準
+	DefaultRole model.UserRole    // 沒有可對應的角色值時使用的角色
+	StateTTL    time.Duration     // 授權請求有效期間
+}
+
+// oidcService 單一登入服務實作
+type oidcService struct {
+	db       *gorm.DB
+	provider *oidc.Provider
+	repo     repository.OIDCRepository
+	auth     AuthServiceInterface
+	roles    RoleService
+	config   OIDCServiceConfig
+}
+
+// NewOIDCService 建立單一登入服務；角色同步經由 roles 進行，以撤銷帶有舊角色的登入階段
+func NewOIDCService(
+	db *gorm.DB,
+	provider *oidc.Provider,
+	repo repository.OIDCRepository,
+	auth AuthServiceInterface,
+	roles RoleService,
+	config OIDCServiceConfig,
+) OIDCService {
+	if config.DefaultRole == "" {
+		config.DefaultRole = model.UserRoleBasic
+	}
+	return &oidcService{
+		db:       db,
+		provider: provider,
+		repo:     repo,
+		auth:     auth,
+		roles:    roles,
+		config:   config,
+	}
+}
+
+// ParseOIDCRoleMapping 解析 "身分提供者角色:平台角色" 格式的角色對應，保留設定順序作為優先順序
+// 角色是否存在由 ValidateOIDCRoles 檢查
+func ParseOIDCRoleMapping(entries []string) ([]OIDCRoleMapping, error) {
+	mapping := make([]OIDCRoleMapping, 0, len(entries))
+	for _, entry := range entries {
+		idx := strings.LastIndex(entry, ":")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid OIDC role mapping %q, expected <claim value>:<role>", entry)
+		}
+		role := model.UserRole(strings.TrimSpace(entry[idx+1:]))
+		if role == "" {
+			return nil, fmt.Errorf("invalid OIDC role mapping %q, role is required", entry)
+		}
+		mapping = append(mapping, OIDCRoleMapping{
+			ClaimValue: strings.TrimSpace(entry[:idx]),
+			Role:       role,
+		})
+	}
+	return mapping, nil
+}
+
+// ValidateOIDCRoles 確認角色對應與預設角色引用的角色都已定義（包含自訂角色）
+func ValidateOIDCRoles(ctx context.Context, roles RoleService, mapping []OIDCRoleMapping, defaultRole model.UserRole) error {
+	names := []string{string(defaultRole)}
+	for _, m := range mapping {
+		names = append(names, string(m.Role))
+	}
+	return roles.ValidateRoles(ctx, names...)
+}
+
+// Authorize 建立授權請求，回傳導向身分提供者的網址
+// 伺服器只保存 state 的雜湊，回呼時以 state 取回 nonce 與 PKCE 驗證碼，且只能使用一次
+func (s *oidcService) Authorize(ctx context.Context) (*vo.OIDCAuthorizationVO, error) {
+	state, err := generateOneTimeToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := generateOneTimeToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PKCE verifier: %w", err)
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		pkglogger.Error("Failed to build OIDC authorization URL", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return nil, dto.ErrOIDCProviderUnavailable
+	}
+
+	expiresAt := time.Now().Add(s.config.StateTTL)
+	if err := s.repo.CreateState(ctx, &model.OIDCLoginState{
+		StateHash:    hashOneTimeToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    expiresAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save OIDC login state: %w", err)
+	}
+
+	return &vo.OIDCAuthorizationVO{
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresAt:        expiresAt,
+	}, nil
+}
+
+// Callback 以授權碼換取並驗證 ID 令牌，找到或建立對應的使用者後完成登入
+// 身分提供者回報已完成多因素驗證（amr 包含 mfa）時不再要求平台的第二步驗證
+func (s *oidcService) Callback(ctx context.Context, req *dto.OIDCCallbackRequest) (*vo.AuthTokenResponse, *vo.MFAChallengeVO, error) {
+	state, ok, err := s.repo.ConsumeState(ctx, hashOneTimeToken(req.State))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to consume OIDC login state: %w", err)
+	}
+	if !ok {
+		return nil, nil, dto.ErrInvalidOIDCState
+	}
+
+	token, err := s.provider.Exchange(ctx, req.Code, state.CodeVerifier)
+	if err != nil {
+		return nil, nil, s.providerError("Failed to exchange OIDC authorization code", err)
+	}
+	idToken, err := s.provider.VerifyIDToken(ctx, token.IDToken, state.Nonce)
+	if err != nil {
+		return nil, nil, s.providerError("Failed to verify OIDC ID token", err)
+	}
+
+	user, err := s.resolveUser(ctx, idToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.IsActive {
+		return nil, nil, dto.ErrUserInactive
+	}
+
+	return s.auth.CompleteLogin(ctx, user, idToken.HasMFA())
+}
+
+// resolveUser 依外部身分找到使用者；尚未連結時以已驗證信箱連結既有帳號，否則自動建立帳號
+func (s *oidcService) resolveUser(ctx context.Context, idToken *oidc.IDToken) (*model.User, error) {
+	identity, err := s.repo.GetIdentity(ctx, idToken.Issuer, idToken.Subject)
+	if err == nil {
+		var user model.User
+		if err := s.db.WithContext(ctx).First(&user, "id = ?", identity.UserID).Error; err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if err := s.repo.TouchIdentity(ctx, identity.ID, idToken.Email); err != nil {
+			return nil, fmt.Errorf("failed to update identity: %w", err)
+		}
+		return &user, s.syncRole(ctx, &user, idToken)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	if idToken.Email == "" {
+		return nil, dto.ErrOIDCEmailRequired
+	}
+
+	now := time.Now()
+	identity = &model.UserIdentity{
+		Issuer:      idToken.Issuer,
+		Subject:     idToken.Subject,
+		Email:       idToken.Email,
+		LastLoginAt: &now,
+	}
+
+	var user model.User
+	err = s.db.WithContext(ctx).Where("LOWER(email) = LOWER(?)", idToken.Email).First(&user).Error
+	if err == nil {
+		// 雙方都驗證過信箱才自動連結，避免以他人信箱預先註冊或在身分提供者設定未驗證信箱接管帳號
+		if !idToken.EmailVerified || !user.EmailVerified {
+			pkglogger.Warn("OIDC identity not linked to existing account with unverified email", pkglogger.Fields{
+				"user_id": user.ID,
+				"issuer":  idToken.Issuer,
+				"subject": idToken.Subject,
+			})
+			return nil, dto.ErrOIDCAccountConflict
+		}
+		identity.UserID = user.ID
+		if err := s.repo.LinkIdentity(ctx, identity); err != nil {
+			return nil, fmt.Errorf("failed to link identity: %w", err)
+		}
+		pkglogger.Info("OIDC identity linked to existing account", pkglogger.Fields{
+			"user_id": user.ID,
+			"issuer":  idToken.Issuer,
+		})
+		return &user, s.syncRole(ctx, &user, idToken)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return s.provisionUser(ctx, idToken, identity)
+}
+
+// provisionUser 以身分提供者的資料自動建立帳號，密碼設為隨機值，需使用重設密碼才能以密碼登入
+func (s *oidcService) provisionUser(ctx context.Context, idToken *oidc.IDToken, identity *model.UserIdentity) (*model.User, error) {
+	username, err := s.availableUsername(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+	password, err := generateOneTimeToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	role := s.mapRole(idToken)
+	if err := s.roles.ValidateRoles(ctx, string(role)); err != nil {
+		if !errors.Is(err, dto.ErrRoleNotFound) {
+			return nil, err
+		}
+		// 啟動後角色被刪除，改用一定存在的內建 basic 角色
+		pkglogger.Warn("Mapped OIDC role no longer exists, falling back to basic", pkglogger.Fields{
+			"role":   role,
+			"issuer": idToken.Issuer,
+		})
+		role = model.RoleBasic
+	}
+	subscription := string(model.RoleBasic)
+	if role == model.RolePremium || role == model.RoleAdmin {
+		subscription = string(role)
+	}
+	user := &model.User{
+		ID:               uuid.New(),
+		Username:         username,
+		Email:            idToken.Email,
+		PasswordHash:     string(hashedPassword),
+		Role:             role,
+		IsActive:         true,
+		EmailVerified:    idToken.EmailVerified,
+		SubscriptionType: subscription,
+		APIQuota:         getDefaultAPIQuota(string(role)),
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if err := s.repo.ProvisionUser(ctx, user, identity); err != nil {
+		pkglogger.Error("Failed to provision OIDC user", pkglogger.Fields{
+			"error":    err.Error(),
+			"username": username,
+			"issuer":   idToken.Issuer,
+		})
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	pkglogger.Info("User provisioned from OIDC login", pkglogger.Fields{
+		"user_id":  user.ID,
+		"username": user.Username,
+		"role":     user.Role,
+		"issuer":   idToken.Issuer,
+	})
+	return user, nil
+}
+
+// availableUsername 由 preferred_username 或信箱帳號部分產生未被使用的使用者名稱
+func (s *oidcService) availableUsername(ctx context.Context, idToken *oidc.IDToken) (string, error) {
+	base := idToken.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(idToken.Email, "@", 2)[0]
+	}
+	base = oidcUsernameInvalidChars.ReplaceAllString(base, "")
+	if len(base) > oidcUsernameMaxLength-5 {
+		base = base[:oidcUsernameMaxLength-5]
+	}
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		if len(candidate) >= oidcUsernameMinLength {
+			var count int64
+			if err := s.db.WithContext(ctx).Model(&model.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+				return "", fmt.Errorf("failed to check username: %w", err)
+			}
+			if count == 0 {
+				return candidate, nil
+			}
+		}
+
+		suffix := make([]byte, 2)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", fmt.Errorf("failed to generate username: %w", err)
+		}
+		candidate = base + "-" + hex.EncodeToString(suffix)
+	}
+	return "", errors.New("failed to generate an available username")
+}
+
+// mapRole 依角色宣告對應平台角色，宣告中有多個可對應的值時取設定中排在最前面的對應
+func (s *oidcService) mapRole(idToken *oidc.IDToken) model.UserRole {
+	if s.config.RoleClaim == "" {
+		return s.config.DefaultRole
+	}
+	values := make(map[string]bool)
+	for _, value := range oidc.StringClaims(idToken.Claims, s.config.RoleClaim) {
+		values[value] = true
+	}
+	for _, m := range s.config.RoleMapping {
+		if values[m.ClaimValue] {
+			return m.Role
+		}
+	}
+	return s.config.DefaultRole
+}
+
+// syncRole 設定角色宣告時每次登入同步使用者角色；管理員指派過角色的使用者以管理員指派為準，不同步
+// 角色經由角色服務變更，與管理員指派相同會撤銷使用者既有的登入階段
+func (s *oidcService) syncRole(ctx context.Context, user *model.User, idToken *oidc.IDToken) error {
+	if s.config.RoleClaim == "" || user.RoleAssignedByAdmin {
+		return nil
+	}
+	role := s.mapRole(idToken)
+	if role == user.Role {
+		return nil
+	}
+
+	synced, err := s.roles.SyncUserRole(ctx, user.ID, string(role))
+	if errors.Is(err, dto.ErrRoleNotFound) {
+		pkglogger.Warn("Mapped OIDC role no longer exists, keeping current role", pkglogger.Fields{
+			"user_id": user.ID,
+			"role":    role,
+		})
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sync user role: %w", err)
+	}
+	if !synced {
+		return nil // 管理員在此期間指派了角色
+	}
+	pkglogger.Info("User role synced from OIDC claim", pkglogger.Fields{
+		"user_id":  user.ID,
+		"old_role": user.Role,
+		"new_role": role,
+	})
+	user.Role = role
+	return nil
+}
+
+// providerError 將身分提供者錯誤轉換為服務錯誤
+func (s *oidcService) providerError(message string, err error) error {
+	pkglogger.Warn(message, pkglogger.Fields{
+		"error": err.Error(),
+	})
+	if errors.Is(err, oidc.ErrProviderUnavailable) {
+		return dto.ErrOIDCProviderUnavailable
+	}
+	return dto.ErrOIDCAuthenticationFailed
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// roleNamePattern 角色名稱格式：小寫英文字母開頭，可含數字、底線與連字號
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// SessionRevoker 撤銷使用者所有登入階段，角色變更後使既有令牌失效
+type SessionRevoker interface {
+	LogoutAll(userID uuid.UUID) error
+}
+
+// RoleService 角色與權限服務介面
+type RoleService interface {
+	HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error)
+	EnsureBuiltinRoles(ctx context.Context) error
+	ListPermissions() []vo.PermissionVO
+	ListRoles(ctx context.Context) ([]vo.RoleVO, error)
+	GetRole(ctx context.Context, name string) (*vo.RoleVO, error)
+	CreateRole(ctx context.Context, req *dto.RoleCreateRequest) (*vo.RoleVO, error)
+	UpdateRole(ctx context.Context, name string, req *dto.RoleUpdateRequest) (*vo.RoleVO, error)
+	DeleteRole(ctx context.Context, name string) error
+	AssignUserRole(ctx context.Context, actorID, userID uuid.UUID, req *dto.AssignRoleRequest) (*vo.UserRoleVO, error)
+	SyncUserRole(ctx context.Context, userID uuid.UUID, role string) (bool, error)
+	ValidateRoles(ctx context.Context, names ...string) error
+}
+
+// roleService 角色與權限服務實作
+type roleService struct {
+	repo     repository.RoleRepository
+	sessions SessionRevoker
+	cacheTTL time.Duration
+
+	mu          sync.RWMutex
+	permissions map[string]map[string]bool // 角色名稱對應權限集合
+	loadedAt    time.Time
+}
+
+// NewRoleService 建立角色與權限服務；角色權限快取 cacheTTL，於本執行個體變更角色時立即失效
+func NewRoleService(repo repository.RoleRepository, sessions SessionRevoker, cacheTTL time.Duration) RoleService {
+	return &roleService{
+		repo:     repo,
+		sessions: sessions,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// HasPermissions 檢查角色是否具備所有指定權限；未定義的角色沒有任何權限
+func (s *roleService) HasPermissions(ctx context.Context, role string, permissions ...string) (bool, error) {
+	granted, err := s.rolePermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, permission := range permissions {
+		if !granted[role][permission] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// EnsureBuiltinRoles 建立尚不存在的內建角色，並確保 admin 擁有所有權限（包含新版本新增的權限）
+func (s *roleService) EnsureBuiltinRoles(ctx context.Context) error {
+	builtin := model.BuiltinRoles()
+	if err := s.repo.EnsureRoles(ctx, builtin); err != nil {
+		return fmt.Errorf("failed to create built-in roles: %w", err)
+	}
+	for _, role := range builtin {
+		if role.Name != string(model.RoleAdmin) {
+			continue
+		}
+		if _, err := s.repo.Update(ctx, role.Name, map[string]interface{}{
+			"permissions": role.Permissions,
+			"is_system":   true,
+		}); err != nil {
+			return fmt.Errorf("failed to update admin role: %w", err)
+		}
+	}
+	s.invalidate()
+	return nil
+}
+
+// ListPermissions 取得所有可指派的權限
+func (s *roleService) ListPermissions() []vo.PermissionVO {
+	definitions := model.AllPermissions()
+	result := make([]vo.PermissionVO, len(definitions))
+	for i, definition := range definitions {
+		result[i] = vo.PermissionVO{Name: definition.Name, Description: definition.Description}
+	}
+	return result
+}
+
+// ListRoles 取得所有角色
+func (s *roleService) ListRoles(ctx context.Context) ([]vo.RoleVO, error) {
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]vo.RoleVO, len(roles))
+	for i, role := range roles {
+		result[i] = *s.roleToVO(role)
+	}
+	return result, nil
+}
+
+// GetRole 取得角色
+func (s *roleService) GetRole(ctx context.Context, name string) (*vo.RoleVO, error) {
+	role, err := s.getRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.roleToVO(role), nil
+}
+
+// CreateRole 建立自訂角色
+func (s *roleService) CreateRole(ctx context.Context, req *dto.RoleCreateRequest) (*vo.RoleVO, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, dto.ErrInvalidRoleName
+	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	created, err := s.repo.Create(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, dto.ErrRoleExists
+	}
+	s.invalidate()
+
+	pkglogger.Info("Role created", pkglogger.Fields{
+		"role":        role.Name,
+		"permissions": []string(permissions),
+	})
+	return s.GetRole(ctx, role.Name)
+}
+
+// UpdateRole 更新角色說明或權限；admin 的權限固定為全部權限，不可修改
+func (s *roleService) UpdateRole(ctx context.Context, name string, req *dto.RoleUpdateRequest) (*vo.RoleVO, error) {
+	updates := map[string]interface{}{}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Permissions != nil {
+		if name == string(model.RoleAdmin) {
+			return nil, dto.ErrSystemRoleProtected
+		}
+		permissions, err := normalizePermissions(*req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		updates["permissions"] = permissions
+	}
+	if len(updates) == 0 {
+		return s.GetRole(ctx, name)
+	}
+
+	updated, err := s.repo.Update(ctx, name, updates)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, dto.ErrRoleNotFound
+	}
+	s.invalidate()
+
+	pkglogger.Info("Role updated", pkglogger.Fields{
+		"role":    name,
+		"updates": updates,
+	})
+	return s.GetRole(ctx, name)
+}
+
+// DeleteRole 刪除自訂角色；內建角色與仍有使用者的角色不可刪除
+func (s *roleService) DeleteRole(ctx context.Context, name string) error {
+	deleted, err := s.repo.Delete(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		role, err := s.getRole(ctx, name)
+		if err != nil {
+			return err
+		}
+		if role.IsSystem {
+			return dto.ErrSystemRoleProtected
+		}
+		return dto.ErrRoleInUse
+	}
+	s.invalidate()
+
+	pkglogger.Info("Role deleted", pkglogger.Fields{
+		"role": name,
+	})
+	return nil
+}
+
+// AssignUserRole 指派使用者角色並撤銷其所有登入階段，新角色在重新登入後生效；不可變更自己的角色
+// 管理員指派的角色優先於單一登入的角色宣告，之後登入不再被同步覆寫
+func (s *roleService) AssignUserRole(ctx context.Context, actorID, userID uuid.UUID, req *dto.AssignRoleRequest) (*vo.UserRoleVO, error) {
+	if actorID == userID {
+		return nil, dto.ErrCannotChangeOwnRole
+	}
+	if _, err := s.getRole(ctx, req.Role); err != nil {
+		return nil, err
+	}
+
+	assigned, err := s.repo.AssignUserRole(ctx, userID, req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if !assigned {
+		return nil, dto.ErrUserNotFound
+	}
+
+	pkglogger.Info("User role assigned", pkglogger.Fields{
+		"user_id":  userID,
+		"role":     req.Role,
+		"actor_id": actorID,
+	})
+
+	// 存取令牌帶有角色，撤銷既有登入階段避免舊角色在令牌到期前繼續生效
+	if err := s.sessions.LogoutAll(userID); err != nil {
+		return nil, fmt.Errorf("role assigned but failed to revoke sessions: %w", err)
+	}
+	return &vo.UserRoleVO{UserID: userID, Role: req.Role}, nil
+}
+
+// SyncUserRole 以外部身分提供者的角色更新使用者角色，變更時撤銷其所有登入階段並回傳 true
+// 管理員以 AssignUserRole 指派過角色的使用者以管理員指派為準，不會被覆寫
+func (s *roleService) SyncUserRole(ctx context.Context, userID uuid.UUID, role string) (bool, error) {
+	if _, err := s.getRole(ctx, role); err != nil {
+		return false, err
+	}
+
+	synced, err := s.repo.SyncUserRole(ctx, userID, role)
+	if err != nil || !synced {
+		return false, err
+	}
+
+	pkglogger.Info("User role synced from identity provider", pkglogger.Fields{
+		"user_id": userID,
+		"role":    role,
+	})
+
+	// 與 AssignUserRole 相同，撤銷帶有舊角色的令牌
+	if err := s.sessions.LogoutAll(userID); err != nil {
+		return true, fmt.Errorf("role synced but failed to revoke sessions: %w", err)
+	}
+	return true, nil
+}
+
+// ValidateRoles 確認角色都已定義，供啟動時檢查設定檔中引用的角色
+func (s *roleService) ValidateRoles(ctx context.Context, names ...string) error {
+	for _, name := range names {
+		if _, err := s.getRole(ctx, name); err != nil {
+			return fmt.Errorf("role %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// rolePermissions 取得角色權限快取，過期時重新載入
+func (s *roleService) rolePermissions(ctx context.Context) (map[string]map[string]bool, error) {
+	s.mu.RLock()
+	permissions, loadedAt := s.permissions, s.loadedAt
+	s.mu.RUnlock()
+	if permissions != nil && time.Since(loadedAt) < s.cacheTTL {
+		return permissions, nil
+	}
+
+	roles, err := s.repo.List(ctx)
+	if err != nil {
+		pkglogger.Error("Failed to load role permissions", pkglogger.Fields{
+			"error": err.Error(),
+		})
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+	permissions = make(map[string]map[string]bool, len(roles))
+	for _, role := range roles {
+		granted := make(map[string]bool, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			granted[permission] = true
+		}
+		permissions[role.Name] = granted
+	}
+
+	s.mu.Lock()
+	s.permissions, s.loadedAt = permissions, time.Now()
+	s.mu.Unlock()
+	return permissions, nil
+}
+
+// invalidate 使角色權限快取失效
+func (s *roleService) invalidate() {
+	s.mu.Lock()
+	s.permissions = nil
+	s.mu.Unlock()
+}
+
+// getRole 取得角色，不存在時回傳 ErrRoleNotFound
+func (s *roleService) getRole(ctx context.Context, name string) (*model.Role, error) {
+	role, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// roleToVO 轉換為回應格式
+func (s *roleService) roleToVO(role *model.Role) *vo.RoleVO {
+	permissions := []string(role.Permissions)
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &vo.RoleVO{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		IsSystem:    role.IsSystem,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// normalizePermissions 驗證權限名稱，去除重複並排序
+func normalizePermissions(permissions []string) (model.StringArray, error) {
+	seen := make(map[string]bool, len(permissions))
+	result := model.StringArray{}
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if !model.IsValidPermission(permission) {
+			return nil, fmt.Errorf("%w: %s", dto.ErrInvalidPermission, permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			result = append(result, permission)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
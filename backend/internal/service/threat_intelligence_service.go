@@ -2,12 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/jinzhu/copier"
+	"gorm.io/gorm"
 
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dsl"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/event"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
 	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
@@ -22,61 +29,154 @@ type ThreatIntelligenceService interface {
 	ListThreats(ctx context.Context, req *dto.ThreatIntelligenceQueryRequest) (*vo.ThreatIntelligenceListVO, error)
 	LookupIP(ctx context.Context, req *dto.ThreatIntelligenceIPLookupRequest) (*vo.ThreatIntelligenceIPLookupVO, error)
 	LookupDomain(ctx context.Context, req *dto.ThreatIntelligenceDomainLookupRequest) (*vo.ThreatIntelligenceDomainLookupVO, error)
+	LookupIndicator(ctx context.Context, req *dto.ThreatIntelligenceIndicatorLookupRequest) (*vo.ThreatIntelligenceIndicatorLookupVO, error)
 	GetStats(ctx context.Context, req *dto.ThreatIntelligenceStatsRequest) (*vo.ThreatIntelligenceStatsVO, error)
 	BulkCreateThreats(ctx context.Context, req *dto.ThreatIntelligenceBulkCreateRequest) (*vo.ThreatIntelligenceBulkCreateVO, error)
-	SearchThreats(ctx context.Context, query string, page, limit int) ([]*vo.ThreatIntelligenceVO, int64, error)
-	GetStatistics(ctx context.Context) (map[string]interface{}, error)
+	SearchThreats(ctx context.Context, req *dto.ThreatIntelligenceSearchRequest) (*vo.ThreatIntelligenceSearchVO, error)
+	GetStatistics(ctx context.Context) (*vo.ThreatStatisticsVO, error)
 	BulkUpdateThreats(ctx context.Context, req *dto.ThreatIntelligenceBulkUpdateRequest) (*vo.ThreatIntelligenceBulkUpdateVO, error)
 	BulkDeleteThreats(ctx context.Context, req *dto.ThreatIntelligenceBulkDeleteRequest) (*vo.ThreatIntelligenceBulkDeleteVO, error)
 }
 
 // threatIntelligenceService 威脅情報服務實作
 type threatIntelligenceService struct {
-	repo repository.ThreatIntelligenceRepository
+	repo     repository.ThreatIntelligenceRepository
+	notifier event.Notifier
 }
 
 // NewThreatIntelligenceService 建立威脅情報服務
-func NewThreatIntelligenceService(repo repository.ThreatIntelligenceRepository) ThreatIntelligenceService {
-	return &threatIntelligenceService{repo: repo}
+// 異動事件一律寫入 outbox；notifier 用於提交後喚醒 relay 立即投遞，為 nil 時由 relay 輪詢
+func NewThreatIntelligenceService(repo repository.ThreatIntelligenceRepository, notifier event.Notifier) ThreatIntelligenceService {
+	return &threatIntelligenceService{repo: repo, notifier: notifier}
 }
 
-// CreateThreat 建立威脅情報
+// CreateThreat 建立威脅情報，相同自然鍵的記錄會被合併而非重複建立
 func (s *threatIntelligenceService) CreateThreat(ctx context.Context, req *dto.ThreatIntelligenceCreateRequest) (*vo.ThreatIntelligenceVO, error) {
-	// 驗證 IP 地址
-	ip := net.ParseIP(req.IPAddress)
-	if ip == nil {
-		return nil, dto.ErrInvalidIPAddress
+	threat, err := s.requestToModel(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 依自然鍵建立或更新威脅情報，並在同一交易中寫入異動事件
+	var threatVO *vo.ThreatIntelligenceVO
+	err = s.repo.Transaction(ctx, func(txRepo repository.ThreatIntelligenceRepository) error {
+		created, err := txRepo.Upsert(ctx, threat)
+		if err != nil {
+			return err
+		}
+		threatVO = s.modelToVO(threat)
+		return s.enqueue(ctx, txRepo, upsertEvent(created, threatVO))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return threatVO, nil
+}
+
+// requestToModel 將建立請求轉換為威脅情報模型
+func (s *threatIntelligenceService) requestToModel(req *dto.ThreatIntelligenceCreateRequest) (*model.ThreatIntelligence, error) {
+	indicatorType, value, err := resolveIndicator(req)
+	if err != nil {
+		return nil, err
 	}
 
 	// 建立威脅情報模型
 	threat := &model.ThreatIntelligence{
-		IPAddress:       ip,
+		IndicatorType:   indicatorType,
+		IndicatorValue:  value,
 		ThreatType:      model.ThreatType(req.ThreatType),
 		Severity:        model.SeverityLevel(req.Severity),
 		ConfidenceScore: req.ConfidenceScore,
+		Description:     req.Description,
 		Source:          req.Source,
+		ExternalID:      req.ExternalID,
+		CountryCode:     req.CountryCode,
+		ASN:             req.ASN,
+		ISP:             req.ISP,
+		Tags:            model.StringArray(req.Tags),
 	}
 
-	// 複製其他欄位
-	if err := copier.Copy(threat, req); err != nil {
-		return nil, err
+	// 附帶的 IP 與域名欄位
+	if req.IPAddress != "" {
+		threat.IPAddress = net.ParseIP(req.IPAddress)
+		if threat.IPAddress == nil {
+			return nil, dto.ErrInvalidIPAddress
+		}
+	}
+	if req.Domain != nil && *req.Domain != "" {
+		domain, err := model.NormalizeIndicator(model.IndicatorDomain, *req.Domain)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", dto.ErrInvalidDomain, err)
+		}
+		threat.Domain = &domain
 	}
 
-	// 設定標籤
-	threat.Tags = model.StringArray(req.Tags)
+	// 依指標類型填入對應的欄位，方便以 IP / 域名 / 雜湊等欄位直接查詢
+	switch {
+	case indicatorType.IsIP():
+		threat.IPAddress = net.ParseIP(value)
+	case indicatorType == model.IndicatorDomain:
+		threat.Domain = &value
+	case indicatorType == model.IndicatorURL:
+		threat.URL = &value
+		if host, ok := model.URLHost(value); ok && threat.Domain == nil {
+			threat.Domain = &host
+		}
+	case indicatorType.IsHash():
+		threat.FileHash = &value
+	case indicatorType == model.IndicatorEmail:
+		if threat.Domain == nil {
+			domain := value[strings.LastIndex(value, "@")+1:]
+			threat.Domain = &domain
+		}
+	case indicatorType == model.IndicatorASN:
+		asn, _ := model.ParseASN(value)
+		asnValue := int(asn)
+		threat.ASN = &asnValue
+	}
 
 	// 設定元資料
 	if req.Metadata != nil {
 		threat.Metadata = model.JSONB(req.Metadata)
 	}
 
-	// 建立威脅情報
-	if err := s.repo.Create(ctx, threat); err != nil {
-		return nil, err
+	return threat, nil
+}
+
+// resolveIndicator 決定請求的指標類型並正規化指標值
+func resolveIndicator(req *dto.ThreatIntelligenceCreateRequest) (model.IndicatorType, string, error) {
+	indicatorType := model.IndicatorType(req.IndicatorType)
+	value := req.IndicatorValue
+
+	// 未提供指標值時沿用 IP 或域名欄位
+	if value == "" {
+		switch {
+		case req.IPAddress != "" && (indicatorType == "" || indicatorType.IsIP()):
+			value = req.IPAddress
+		case req.Domain != nil && (indicatorType == "" || indicatorType == model.IndicatorDomain):
+			value = *req.Domain
+		default:
+			return "", "", fmt.Errorf("%w: indicator value is required", dto.ErrInvalidIndicator)
+		}
 	}
 
-	// 轉換為 VO
-	return s.modelToVO(threat), nil
+	if indicatorType == "" {
+		detected, ok := model.DetectIndicatorType(value)
+		if !ok {
+			return "", "", fmt.Errorf("%w: cannot determine indicator type of %q", dto.ErrInvalidIndicatorType, value)
+		}
+		indicatorType = detected
+	} else if !indicatorType.IsValid() {
+		return "", "", fmt.Errorf("%w: %q", dto.ErrInvalidIndicatorType, indicatorType)
+	}
+
+	normalized, err := model.NormalizeIndicator(indicatorType, value)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", dto.ErrInvalidIndicator, err)
+	}
+	return indicatorType, normalized, nil
 }
 
 // GetThreatByID 根據 ID 取得威脅情報
@@ -97,22 +197,61 @@ func (s *threatIntelligenceService) UpdateThreat(ctx context.Context, id uuid.UU
 	}
 
 	// 更新欄位
+	wasHighRisk := threat.IsHighRisk()
+	if err := applyThreatUpdate(threat, req); err != nil {
+		return nil, err
+	}
+
+	// 更新威脅情報，並在同一交易中寫入異動事件
+	var threatVO *vo.ThreatIntelligenceVO
+	err = s.repo.Transaction(ctx, func(txRepo repository.ThreatIntelligenceRepository) error {
+		if err := txRepo.Update(ctx, threat); err != nil {
+			return err
+		}
+		threatVO = s.modelToVO(threat)
+		return s.enqueue(ctx, txRepo, updateEvent(wasHighRisk, threatVO))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return threatVO, nil
+}
+
+// applyThreatUpdate 驗證更新請求並套用到威脅情報
+func applyThreatUpdate(threat *model.ThreatIntelligence, req *dto.ThreatIntelligenceUpdateRequest) error {
 	if req.ThreatType != nil {
+		if !validThreatTypes[*req.ThreatType] {
+			return dto.ErrInvalidThreatType
+		}
 		threat.ThreatType = model.ThreatType(*req.ThreatType)
 	}
 	if req.Severity != nil {
+		if !validSeverities[*req.Severity] {
+			return dto.ErrInvalidSeverity
+		}
 		threat.Severity = model.SeverityLevel(*req.Severity)
 	}
 	if req.ConfidenceScore != nil {
+		if *req.ConfidenceScore < 0 || *req.ConfidenceScore > 100 {
+			return dto.ErrInvalidConfidence
+		}
 		threat.ConfidenceScore = *req.ConfidenceScore
 	}
 	if req.Description != nil {
 		threat.Description = req.Description
 	}
 	if req.CountryCode != nil {
+		if len(*req.CountryCode) != 2 {
+			return dto.ErrInvalidCountryCode
+		}
 		threat.CountryCode = req.CountryCode
 	}
 	if req.ASN != nil {
+		if *req.ASN < 1 {
+			return dto.ErrInvalidASN
+		}
 		threat.ASN = req.ASN
 	}
 	if req.ISP != nil {
@@ -124,18 +263,51 @@ func (s *threatIntelligenceService) UpdateThreat(ctx context.Context, id uuid.UU
 	if req.Metadata != nil {
 		threat.Metadata = model.JSONB(req.Metadata)
 	}
+	return nil
+}
 
-	// 更新威脅情報
-	if err := s.repo.Update(ctx, threat); err != nil {
-		return nil, err
-	}
+// validThreatTypes 可接受的威脅類型
+var validThreatTypes = map[string]bool{
+	string(model.ThreatMalware):    true,
+	string(model.ThreatPhishing):   true,
+	string(model.ThreatSpam):       true,
+	string(model.ThreatBotnet):     true,
+	string(model.ThreatScanner):    true,
+	string(model.ThreatDDoS):       true,
+	string(model.ThreatBruteforce): true,
+	string(model.ThreatOther):      true,
+}
 
-	return s.modelToVO(threat), nil
+// validSeverities 可接受的嚴重程度
+var validSeverities = map[string]bool{
+	string(model.SeverityLow):      true,
+	string(model.SeverityMedium):   true,
+	string(model.SeverityHigh):     true,
+	string(model.SeverityCritical): true,
 }
 
 // DeleteThreat 刪除威脅情報
 func (s *threatIntelligenceService) DeleteThreat(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	// 先取得內容，刪除事件才能讓訂閱者依類型與嚴重程度過濾
+	err := s.repo.Transaction(ctx, func(txRepo repository.ThreatIntelligenceRepository) error {
+		threat, err := txRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := txRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.enqueue(ctx, txRepo, event.ThreatEvent{Type: event.ThreatDeleted, Threat: *s.modelToVO(threat)})
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ErrThreatNotFound
+		}
+		return err
+	}
+
+	s.notify()
+	return nil
 }
 
 // ListThreats 取得威脅情報列表
@@ -143,6 +315,16 @@ func (s *threatIntelligenceService) ListThreats(ctx context.Context, req *dto.Th
 	// 設定預設值
 	req.SetDefaults()
 
+	// 解析查詢語言，語法錯誤帶有位置資訊並回傳給呼叫端
+	var queryNode dsl.Node
+	if strings.TrimSpace(req.Query) != "" {
+		node, err := dsl.Parse(req.Query)
+		if err != nil {
+			return nil, err
+		}
+		queryNode = node
+	}
+
 	// 建立篩選器
 	filter := &repository.ThreatIntelligenceFilter{
 		IPAddress:   req.IPAddress,
@@ -154,6 +336,7 @@ func (s *threatIntelligenceService) ListThreats(ctx context.Context, req *dto.Th
 		Tags:        req.Tags,
 		StartTime:   req.StartTime,
 		EndTime:     req.EndTime,
+		Query:       queryNode,
 		Page:        req.Page,
 		PageSize:    req.PageSize,
 		SortBy:      req.SortBy,
@@ -254,7 +437,12 @@ func (s *threatIntelligenceService) LookupIP(ctx context.Context, req *dto.Threa
 
 // LookupDomain 域名查詢
 func (s *threatIntelligenceService) LookupDomain(ctx context.Context, req *dto.ThreatIntelligenceDomainLookupRequest) (*vo.ThreatIntelligenceDomainLookupVO, error) {
-	threats, err := s.repo.GetByDomain(ctx, req.Domain)
+	domain, err := model.NormalizeIndicator(model.IndicatorDomain, req.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", dto.ErrInvalidDomain, err)
+	}
+
+	threats, err := s.repo.GetByDomain(ctx, domain)
 	if err != nil {
 		return nil, err
 	}
@@ -300,84 +488,269 @@ func (s *threatIntelligenceService) LookupDomain(ctx context.Context, req *dto.T
 	return result, nil
 }
 
-// GetStats 取得統計資料
+// LookupIndicator 依指標類型查詢威脅情報
+func (s *threatIntelligenceService) LookupIndicator(ctx context.Context, req *dto.ThreatIntelligenceIndicatorLookupRequest) (*vo.ThreatIntelligenceIndicatorLookupVO, error) {
+	indicatorType, err := resolveLookupType(req.Type, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := model.NormalizeIndicator(indicatorType, req.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", dto.ErrInvalidIndicator, err)
+	}
+
+	threats, err := s.repo.GetByIndicator(ctx, indicatorType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &vo.ThreatIntelligenceIndicatorLookupVO{
+		IndicatorType:  string(indicatorType),
+		IndicatorValue: value,
+		IsKnownThreat:  len(threats) > 0,
+		ThreatCount:    len(threats),
+		Details:        make([]vo.ThreatIntelligenceVO, len(threats)),
+	}
+
+	severityOrder := map[string]int{"low": 1, "medium": 2, "high": 3, "critical": 4}
+	maxOrder := 0
+	sources := make(map[string]bool)
+
+	for i, threat := range threats {
+		result.Details[i] = *s.modelToVO(threat)
+
+		if !sources[threat.Source] {
+			sources[threat.Source] = true
+			result.Sources = append(result.Sources, threat.Source)
+		}
+
+		if order := severityOrder[string(threat.Severity)]; order > maxOrder {
+			maxOrder = order
+			result.HighestSeverity = string(threat.Severity)
+		}
+
+		if result.FirstSeen == nil || threat.FirstSeen.Before(*result.FirstSeen) {
+			result.FirstSeen = &threat.FirstSeen
+		}
+		if result.LastSeen == nil || threat.LastSeen.After(*result.LastSeen) {
+			result.LastSeen = &threat.LastSeen
+		}
+	}
+
+	return result, nil
+}
+
+// resolveLookupType 將查詢路徑中的類型轉換為指標類型
+// ip 與 hash 為便利別名，依查詢值判斷實際類型
+func resolveLookupType(kind, value string) (model.IndicatorType, error) {
+	switch kind {
+	case "ip":
+		ip := net.ParseIP(strings.TrimSpace(value))
+		if ip == nil {
+			return "", dto.ErrInvalidIPAddress
+		}
+		if ip.To4() != nil {
+			return model.IndicatorIPv4, nil
+		}
+		return model.IndicatorIPv6, nil
+	case "hash":
+		for _, t := range []model.IndicatorType{model.IndicatorMD5, model.IndicatorSHA1, model.IndicatorSHA256} {
+			if _, err := model.NormalizeIndicator(t, value); err == nil {
+				return t, nil
+			}
+		}
+		return "", fmt.Errorf("%w: %q is not an MD5, SHA-1 or SHA-256 hash", dto.ErrInvalidIndicator, value)
+	}
+
+	indicatorType := model.IndicatorType(kind)
+	if !indicatorType.IsValid() {
+		return "", fmt.Errorf("%w: %q", dto.ErrInvalidIndicatorType, kind)
+	}
+	return indicatorType, nil
+}
+
+// timelineDefaultSpans 未指定時間範圍時各間隔的預設時間線長度
+var timelineDefaultSpans = map[string]time.Duration{
+	"hour": 24 * time.Hour,
+	"day":  30 * 24 * time.Hour,
+	"week": 12 * 7 * 24 * time.Hour,
+}
+
+// GetStats 取得統計資料與時間線
+// 時間範圍只解析一次，彙總統計與時間線使用相同的範圍
 func (s *threatIntelligenceService) GetStats(ctx context.Context, req *dto.ThreatIntelligenceStatsRequest) (*vo.ThreatIntelligenceStatsVO, error) {
-	filter := &repository.StatsFilter{
-		GroupBy:   req.GroupBy,
-		StartTime: req.StartTime,
-		EndTime:   req.EndTime,
+	req.SetDefaults()
+
+	end := time.Now().UTC()
+	if req.EndTime != nil {
+		end = req.EndTime.UTC()
+	}
+	start := end.Add(-timelineDefaultSpans[req.Interval])
+	if req.StartTime != nil {
+		start = req.StartTime.UTC()
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("%w: end_time must be after start_time", dto.ErrInvalidDateRange)
+	}
+
+	if buckets := repository.TimelineBucketCount(start, end, req.Interval); buckets > repository.MaxTimelineBuckets {
+		return nil, fmt.Errorf("%w: range spans %d %s buckets, limit is %d", dto.ErrInvalidDateRange, buckets, req.Interval, repository.MaxTimelineBuckets)
+	}
+
+	stats, err := s.repo.GetStats(ctx, &repository.StatsFilter{
+		TimeField: req.TimeField,
+		StartTime: &start,
+		EndTime:   &end,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	stats, err := s.repo.GetStats(ctx, filter)
+	points, err := s.repo.GetTimeline(ctx, &repository.TimelineFilter{
+		Interval:  req.Interval,
+		TimeField: req.TimeField,
+		GroupBy:   req.GroupBy,
+		StartTime: start,
+		EndTime:   end,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &vo.ThreatIntelligenceStatsVO{
-		TotalThreats:    int(stats.TotalThreats),
-		HighRiskCount:   int(stats.HighRiskCount),
-		RecentCount:     int(stats.RecentCount),
-		CountByType:     s.convertCountMap(stats.CountByType),
-		CountBySeverity: s.convertCountMap(stats.CountBySeverity),
-		CountBySource:   s.convertCountMap(stats.CountBySource),
-		CountByCountry:  s.convertCountMap(stats.CountByCountry),
+		TotalThreats:         int(stats.TotalThreats),
+		ActiveCount:          int(stats.ActiveCount),
+		HighRiskCount:        int(stats.HighRiskCount),
+		RecentCount:          int(stats.RecentCount),
+		CountByType:          s.convertCountMap(stats.CountByType),
+		CountBySeverity:      s.convertCountMap(stats.CountBySeverity),
+		CountBySource:        s.convertCountMap(stats.CountBySource),
+		CountByCountry:       s.convertCountMap(stats.CountByCountry),
+		CountByIndicatorType: s.convertCountMap(stats.CountByIndicatorType),
+		Interval:             req.Interval,
+		TimeField:            req.TimeField,
+		GroupBy:              req.GroupBy,
+		StartTime:            start,
+		EndTime:              end,
+		Timeline:             s.timelineToVO(points, req.Interval),
 	}, nil
 }
 
-// BulkCreateThreats 批量建立威脅情報
+// GetStatistics 取得全部威脅情報的整體統計
+func (s *threatIntelligenceService) GetStatistics(ctx context.Context) (*vo.ThreatStatisticsVO, error) {
+	stats, err := s.repo.GetStats(ctx, &repository.StatsFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vo.ThreatStatisticsVO{
+		TotalThreats:       int(stats.TotalThreats),
+		ActiveThreats:      int(stats.ActiveCount),
+		HighRiskThreats:    int(stats.HighRiskCount),
+		MediumRiskThreats:  int(stats.MediumRiskCount),
+		LowRiskThreats:     int(stats.LowRiskCount),
+		RecentThreats:      int(stats.RecentCount),
+		ThreatTypeStats:    countMapToStats(stats.CountByType, stats.TotalThreats),
+		SeverityStats:      countMapToStats(stats.CountBySeverity, stats.TotalThreats),
+		SourceStats:        countMapToStats(stats.CountBySource, stats.TotalThreats),
+		CountryStats:       countMapToStats(stats.CountByCountry, stats.TotalThreats),
+		IndicatorTypeStats: countMapToStats(stats.CountByIndicatorType, stats.TotalThreats),
+		GeneratedAt:        time.Now().UTC(),
+	}, nil
+}
+
+// timelineToVO 將時間線轉換為 VO，小時區間以 RFC 3339 表示，其餘以日期表示
+func (s *threatIntelligenceService) timelineToVO(points []repository.TimelinePoint, interval string) []vo.ThreatTimelineVO {
+	layout := "2006-01-02"
+	if interval == "hour" {
+		layout = time.RFC3339
+	}
+
+	timeline := make([]vo.ThreatTimelineVO, len(points))
+	for i, point := range points {
+		timeline[i] = vo.ThreatTimelineVO{
+			Date:      point.Bucket.Format(layout),
+			Timestamp: point.Bucket,
+			Count:     int(point.Count),
+		}
+		if point.Breakdown != nil {
+			timeline[i].Breakdown = s.convertCountMap(point.Breakdown)
+		}
+	}
+	return timeline
+}
+
+// countMapToStats 將計數對應表轉換為依數量排序的統計項目
+func countMapToStats(counts map[string]int64, total int64) []vo.StatsVO {
+	stats := make([]vo.StatsVO, 0, len(counts))
+	for label, count := range counts {
+		item := vo.StatsVO{Label: label, Value: int(count)}
+		if total > 0 {
+			item.Percentage = float64(count) * 100 / float64(total)
+		}
+		stats = append(stats, item)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Value != stats[j].Value {
+			return stats[i].Value > stats[j].Value
+		}
+		return stats[i].Label < stats[j].Label
+	})
+	return stats
+}
+
+// BulkCreateThreats 批量建立威脅情報，已存在的記錄會被合併並計入更新數量
 func (s *threatIntelligenceService) BulkCreateThreats(ctx context.Context, req *dto.ThreatIntelligenceBulkCreateRequest) (*vo.ThreatIntelligenceBulkCreateVO, error) {
 	var successThreats []vo.ThreatIntelligenceVO
 	var failedErrors []vo.BulkOperationError
 
 	threats := make([]*model.ThreatIntelligence, 0, len(req.Items))
 
-	for i, item := range req.Items {
+	for i := range req.Items {
 		// 驗證並轉換每個項目
-		ip := net.ParseIP(item.IPAddress)
-		if ip == nil {
-			failedErrors = append(failedErrors, vo.BulkOperationError{
-				Index:   i,
-				Error:   "INVALID_IP",
-				Message: "Invalid IP address: " + item.IPAddress,
-			})
+		threat, err := s.requestToModel(&req.Items[i])
+		if err != nil {
+			failedErrors = append(failedErrors, newBulkOperationError(i, "", err))
 			continue
 		}
 
-		threat := &model.ThreatIntelligence{
-			IPAddress:       ip,
-			ThreatType:      model.ThreatType(item.ThreatType),
-			Severity:        model.SeverityLevel(item.Severity),
-			ConfidenceScore: item.ConfidenceScore,
-			Source:          item.Source,
-		}
-
-		if err := copier.Copy(threat, &item); err != nil {
-			failedErrors = append(failedErrors, vo.BulkOperationError{
-				Index:   i,
-				Error:   "COPY_ERROR",
-				Message: err.Error(),
-			})
-			continue
-		}
-
-		threat.Tags = model.StringArray(item.Tags)
-		if item.Metadata != nil {
-			threat.Metadata = model.JSONB(item.Metadata)
-		}
-
 		threats = append(threats, threat)
 	}
 
-	// 批量建立威脅情報
+	createdCount := 0
+	updatedCount := 0
+
+	// 批量建立或更新威脅情報，並在同一交易中寫入異動事件
 	if len(threats) > 0 {
-		if err := s.repo.BulkCreate(ctx, threats); err != nil {
+		var created []bool
+		err := s.repo.Transaction(ctx, func(txRepo repository.ThreatIntelligenceRepository) error {
+			var err error
+			created, err = txRepo.BulkUpsert(ctx, threats)
+			if err != nil {
+				return err
+			}
+
+			events := make([]event.ThreatEvent, 0, len(threats))
+			for i, threat := range threats {
+				events = append(events, upsertEvent(created[i], s.modelToVO(threat)))
+			}
+			return s.enqueue(ctx, txRepo, events...)
+		})
+		if err != nil {
 			return nil, err
 		}
 
 		// 轉換成功的項目
-		for _, threat := range threats {
+		for i, threat := range threats {
+			if created[i] {
+				createdCount++
+			} else {
+				updatedCount++
+			}
 			successThreats = append(successThreats, *s.modelToVO(threat))
 		}
+		s.notify()
 	}
 
 	return &vo.ThreatIntelligenceBulkCreateVO{
@@ -386,6 +759,8 @@ func (s *threatIntelligenceService) BulkCreateThreats(ctx context.Context, req *
 		TotalCount:   len(req.Items),
 		SuccessCount: len(successThreats),
 		FailedCount:  len(failedErrors),
+		CreatedCount: createdCount,
+		UpdatedCount: updatedCount,
 	}, nil
 }
 
@@ -393,7 +768,10 @@ func (s *threatIntelligenceService) BulkCreateThreats(ctx context.Context, req *
 func (s *threatIntelligenceService) modelToVO(threat *model.ThreatIntelligence) *vo.ThreatIntelligenceVO {
 	threatVO := &vo.ThreatIntelligenceVO{
 		ID:              threat.ID,
-		IPAddress:       threat.IPAddress.String(),
+		IndicatorType:   string(threat.IndicatorType),
+		IndicatorValue:  threat.IndicatorValue,
+		URL:             threat.URL,
+		FileHash:        threat.FileHash,
 		ThreatType:      string(threat.ThreatType),
 		Severity:        string(threat.Severity),
 		ConfidenceScore: threat.ConfidenceScore,
@@ -410,6 +788,9 @@ func (s *threatIntelligenceService) modelToVO(threat *model.ThreatIntelligence)
 	}
 
 	// 複製指標欄位
+	if threat.IPAddress != nil {
+		threatVO.IPAddress = threat.IPAddress.String()
+	}
 	if threat.Domain != nil {
 		threatVO.Domain = threat.Domain
 	}
@@ -441,46 +822,281 @@ func (s *threatIntelligenceService) convertCountMap(m map[string]int64) map[stri
 	return result
 }
 
-// SearchThreats 搜尋威脅情報
-func (s *threatIntelligenceService) SearchThreats(ctx context.Context, query string, page, limit int) ([]*vo.ThreatIntelligenceVO, int64, error) {
-	// 實現搜尋邏輯
-	// TODO: 實現實際的搜尋功能
-	return []*vo.ThreatIntelligenceVO{}, 0, nil
-}
+// SearchThreats 全文搜尋威脅情報，結果依相關度排序並附上命中片段
+func (s *threatIntelligenceService) SearchThreats(ctx context.Context, req *dto.ThreatIntelligenceSearchRequest) (*vo.ThreatIntelligenceSearchVO, error) {
+	// 設定預設值
+	req.SetDefaults()
+
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, dto.ErrInvalidSearchQuery
+	}
+
+	// 建立篩選器
+	filter := &repository.ThreatIntelligenceFilter{
+		IPAddress:     req.IPAddress,
+		Domain:        req.Domain,
+		IndicatorType: req.IndicatorType,
+		ThreatType:    req.ThreatType,
+		Severity:      req.Severity,
+		Source:        req.Source,
+		CountryCode:   req.CountryCode,
+		Tags:          req.Tags,
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+		Page:          req.Page,
+		PageSize:      req.PageSize,
+		SortBy:        req.SortBy,
+		SortOrder:     req.SortOrder,
+	}
+
+	results, total, err := s.repo.Search(ctx, query, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]vo.ThreatIntelligenceSearchHitVO, len(results))
+	for i, result := range results {
+		hits[i] = vo.ThreatIntelligenceSearchHitVO{
+			ThreatIntelligenceVO: *s.modelToVO(result.Threat),
+			Rank:                 result.Rank,
+			Highlights:           result.Highlights,
+		}
+	}
+
+	// 建立分頁資訊
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize > 0 {
+		totalPages++
+	}
 
-// GetStatistics 取得統計資訊
-func (s *threatIntelligenceService) GetStatistics(ctx context.Context) (map[string]interface{}, error) {
-	// 實現統計邏輯
-	// TODO: 實現實際的統計功能
-	return map[string]interface{}{
-		"total_threats": 0,
-		"high_risk":     0,
-		"recent":        0,
+	return &vo.ThreatIntelligenceSearchVO{
+		Query: query,
+		Data:  hits,
+		Pagination: vo.PaginationVO{
+			CurrentPage:  req.Page,
+			PageSize:     req.PageSize,
+			TotalPages:   totalPages,
+			TotalRecords: total,
+			HasNext:      req.Page < totalPages,
+			HasPrevious:  req.Page > 1,
+		},
 	}, nil
 }
 
+// errBulkAborted 原子模式下有項目失敗，用於中止並回滾整個交易
+var errBulkAborted = errors.New("bulk operation aborted")
+
 // BulkUpdateThreats 批量更新威脅情報
+// 所有項目在同一交易中執行；非原子模式下每個項目使用獨立的 savepoint，失敗時只回滾該項目
 func (s *threatIntelligenceService) BulkUpdateThreats(ctx context.Context, req *dto.ThreatIntelligenceBulkUpdateRequest) (*vo.ThreatIntelligenceBulkUpdateVO, error) {
-	// 實現批量更新邏輯
-	// TODO: 實現實際的批量更新功能
-	return &vo.ThreatIntelligenceBulkUpdateVO{
-		Success:      []vo.ThreatIntelligenceVO{},
-		Failed:       []vo.BulkOperationError{},
-		TotalCount:   0,
-		SuccessCount: 0,
-		FailedCount:  0,
-	}, nil
+	result := &vo.ThreatIntelligenceBulkUpdateVO{
+		Success:    []vo.ThreatIntelligenceVO{},
+		Failed:     []vo.BulkOperationError{},
+		TotalCount: len(req.Items),
+		Atomic:     req.Atomic,
+	}
+	succeeded := make([]int, 0, len(req.Items))
+	var events []event.ThreatEvent
+
+	err := s.repo.Transaction(ctx, func(txRepo repository.ThreatIntelligenceRepository) error {
+		for i := range req.Items {
+			item := &req.Items[i]
+
+			var updated *model.ThreatIntelligence
+			var wasHighRisk bool
+			itemErr := runBulkItem(ctx, txRepo, req.Atomic, func(repo repository.ThreatIntelligenceRepository) error {
+				id, err := uuid.Parse(item.ID)
+				if err != nil {
+					return dto.ErrInvalidUUID
+				}
+				threat, err := repo.GetByID(ctx, id)
+				if err != nil {
+					return err
+				}
+				wasHighRisk = threat.IsHighRisk()
+				if err := applyThreatUpdate(threat, &item.Data); err != nil {
+					return err
+				}
+				if err := repo.Update(ctx, threat); err != nil {
+					return err
+				}
+				updated = threat
+				return nil
+			})
+
+			if itemErr != nil {
+				result.Failed = append(result.Failed, newBulkOperationError(i, item.ID, itemErr))
+				if req.Atomic {
+					return errBulkAborted
+				}
+				continue
+			}
+
+			succeeded = append(succeeded, i)
+			threatVO := s.modelToVO(updated)
+			result.Success = append(result.Success, *threatVO)
+			events = append(events, updateEvent(wasHighRisk, threatVO))
+		}
+		return s.enqueue(ctx, txRepo, events...)
+	})
+
+	if err != nil {
+		if !errors.Is(err, errBulkAborted) {
+			return nil, err
+		}
+		// 交易已回滾，先前成功的項目也一併標記為失敗
+		result.RolledBack = true
+		result.Success = []vo.ThreatIntelligenceVO{}
+		result.Failed = append(result.Failed, rolledBackErrors(succeeded, func(i int) string { return req.Items[i].ID })...)
+	} else {
+		s.notify()
+	}
+
+	result.SuccessCount = len(result.Success)
+	result.FailedCount = len(result.Failed)
+	return result, nil
 }
 
 // BulkDeleteThreats 批量刪除威脅情報
+// 所有項目在同一交易中執行；非原子模式下每個項目使用獨立的 savepoint，失敗時只回滾該項目
 func (s *threatIntelligenceService) BulkDeleteThreats(ctx context.Context, req *dto.ThreatIntelligenceBulkDeleteRequest) (*vo.ThreatIntelligenceBulkDeleteVO, error) {
-	// 實現批量刪除邏輯
-	// TODO: 實現實際的批量刪除功能
-	return &vo.ThreatIntelligenceBulkDeleteVO{
-		Success:      []string{},
-		Failed:       []vo.BulkOperationError{},
-		TotalCount:   0,
-		SuccessCount: 0,
-		FailedCount:  0,
-	}, nil
-} 
\ No newline at end of file
+	result := &vo.ThreatIntelligenceBulkDeleteVO{
+		Success:    []string{},
+		Failed:     []vo.BulkOperationError{},
+		TotalCount: len(req.IDs),
+		Atomic:     req.Atomic,
+	}
+	succeeded := make([]int, 0, len(req.IDs))
+	var events []event.ThreatEvent
+
+	err := s.repo.Transaction(ctx, func(txRepo repository.ThreatIntelligenceRepository) error {
+		for i, rawID := range req.IDs {
+			var deleted *model.ThreatIntelligence
+			itemErr := runBulkItem(ctx, txRepo, req.Atomic, func(repo repository.ThreatIntelligenceRepository) error {
+				id, err := uuid.Parse(rawID)
+				if err != nil {
+					return dto.ErrInvalidUUID
+				}
+				threat, err := repo.GetByID(ctx, id)
+				if err != nil {
+					return err
+				}
+				if err := repo.Delete(ctx, id); err != nil {
+					return err
+				}
+				deleted = threat
+				return nil
+			})
+
+			if itemErr != nil {
+				result.Failed = append(result.Failed, newBulkOperationError(i, rawID, itemErr))
+				if req.Atomic {
+					return errBulkAborted
+				}
+				continue
+			}
+
+			succeeded = append(succeeded, i)
+			result.Success = append(result.Success, rawID)
+			events = append(events, event.ThreatEvent{Type: event.ThreatDeleted, Threat: *s.modelToVO(deleted)})
+		}
+		return s.enqueue(ctx, txRepo, events...)
+	})
+
+	if err != nil {
+		if !errors.Is(err, errBulkAborted) {
+			return nil, err
+		}
+		result.RolledBack = true
+		result.Success = []string{}
+		result.Failed = append(result.Failed, rolledBackErrors(succeeded, func(i int) string { return req.IDs[i] })...)
+	} else {
+		s.notify()
+	}
+
+	result.SuccessCount = len(result.Success)
+	result.FailedCount = len(result.Failed)
+	return result, nil
+}
+
+// enqueue 將異動事件寫入 outbox；須在資料異動的交易中呼叫，事件隨交易一併提交或回滾
+func (s *threatIntelligenceService) enqueue(ctx context.Context, txRepo repository.ThreatIntelligenceRepository, events ...event.ThreatEvent) error {
+	records := make([]*model.EventOutbox, 0, len(events))
+	for _, e := range events {
+		record, err := event.NewOutboxRecord(e)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+	return txRepo.AddOutboxEvents(ctx, records...)
+}
+
+// notify 交易提交後喚醒 relay 投遞新事件
+func (s *threatIntelligenceService) notify() {
+	if s.notifier != nil {
+		s.notifier.Notify()
+	}
+}
+
+// upsertEvent 依是否新建決定事件類型；合併既有記錄不會變更嚴重程度，因此只有新建才可能成為高風險
+func upsertEvent(created bool, threat *vo.ThreatIntelligenceVO) event.ThreatEvent {
+	if created {
+		return event.ThreatEvent{Type: event.ThreatCreated, Threat: *threat, BecameHighRisk: threat.IsHighRisk}
+	}
+	return event.ThreatEvent{Type: event.ThreatUpdated, Threat: *threat}
+}
+
+// updateEvent 建立更新事件，並標記是否因本次更新成為高風險
+func updateEvent(wasHighRisk bool, threat *vo.ThreatIntelligenceVO) event.ThreatEvent {
+	return event.ThreatEvent{Type: event.ThreatUpdated, Threat: *threat, BecameHighRisk: !wasHighRisk && threat.IsHighRisk}
+}
+
+// runBulkItem 執行單一批量項目；非原子模式下以 savepoint 隔離，失敗不影響其他項目
+func runBulkItem(ctx context.Context, txRepo repository.ThreatIntelligenceRepository, atomic bool, fn func(repo repository.ThreatIntelligenceRepository) error) error {
+	if atomic {
+		return fn(txRepo)
+	}
+	return txRepo.Transaction(ctx, fn)
+}
+
+// newBulkOperationError 將項目錯誤轉換為批量操作錯誤
+func newBulkOperationError(index int, id string, err error) vo.BulkOperationError {
+	code := "OPERATION_FAILED"
+	switch {
+	case errors.Is(err, dto.ErrInvalidUUID):
+		code = "INVALID_UUID"
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, dto.ErrThreatNotFound):
+		code = "NOT_FOUND"
+		err = dto.ErrThreatNotFound
+	case errors.Is(err, dto.ErrInvalidIPAddress):
+		code = "INVALID_IP"
+	case errors.Is(err, dto.ErrInvalidIndicator), errors.Is(err, dto.ErrInvalidIndicatorType), errors.Is(err, dto.ErrInvalidDomain):
+		code = "INVALID_INDICATOR"
+	case errors.Is(err, dto.ErrInvalidThreatType), errors.Is(err, dto.ErrInvalidSeverity),
+		errors.Is(err, dto.ErrInvalidConfidence), errors.Is(err, dto.ErrInvalidCountryCode),
+		errors.Is(err, dto.ErrInvalidASN):
+		code = "VALIDATION_ERROR"
+	}
+	return vo.BulkOperationError{
+		Index:   index,
+		ID:      id,
+		Error:   code,
+		Message: err.Error(),
+	}
+}
+
+// rolledBackErrors 為原子模式下已執行但被回滾的項目建立錯誤
+func rolledBackErrors(indexes []int, idOf func(int) string) []vo.BulkOperationError {
+	errs := make([]vo.BulkOperationError, len(indexes))
+	for i, index := range indexes {
+		errs[i] = vo.BulkOperationError{
+			Index:   index,
+			ID:      idOf(index),
+			Error:   "ROLLED_BACK",
+			Message: "transaction rolled back because another item failed",
+		}
+	}
+	return errs
+}
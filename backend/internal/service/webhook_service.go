@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/vo"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/netguard"
+)
+
+// webhookSecretPrefix 簽章密鑰前綴，方便辨識外洩的密鑰
+const webhookSecretPrefix = "whsec_"
+
+// WebhookService Webhook 訂閱服務介面，使用者只能存取自己的 Webhook
+type WebhookService interface {
+	ListWebhooks(ctx context.Context, userID uuid.UUID) ([]vo.WebhookVO, error)
+	GetWebhook(ctx context.Context, userID, id uuid.UUID) (*vo.WebhookVO, error)
+	CreateWebhook(ctx context.Context, userID uuid.UUID, req *dto.WebhookCreateRequest) (*vo.WebhookSecretVO, error)
+	UpdateWebhook(ctx context.Context, userID, id uuid.UUID, req *dto.WebhookUpdateRequest) (*vo.WebhookVO, error)
+	DeleteWebhook(ctx context.Context, userID, id uuid.UUID) error
+	RotateSecret(ctx context.Context, userID, id uuid.UUID) (*vo.WebhookSecretVO, error)
+	ListDeliveries(ctx context.Context, userID, id uuid.UUID, req *dto.WebhookDeliveryQueryRequest) (*vo.WebhookDeliveryListVO, error)
+}
+
+// webhookService Webhook 訂閱服務實作
+type webhookService struct {
+	webhookRepo          repository.WebhookRepository
+	deliveryRepo         repository.WebhookDeliveryRepository
+	allowPrivateNetworks bool
+}
+
+// NewWebhookService 建立 Webhook 訂閱服務；allowPrivateNetworks 為 false 時拒絕指向本機或內部網路的 URL
+func NewWebhookService(webhookRepo repository.WebhookRepository, deliveryRepo repository.WebhookDeliveryRepository, allowPrivateNetworks bool) WebhookService {
+	return &webhookService{
+		webhookRepo:          webhookRepo,
+		deliveryRepo:         deliveryRepo,
+		allowPrivateNetworks: allowPrivateNetworks,
+	}
+}
+
+// ListWebhooks 取得使用者的 Webhook 訂閱
+func (s *webhookService) ListWebhooks(ctx context.Context, userID uuid.UUID) ([]vo.WebhookVO, error) {
+	webhooks, err := s.webhookRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]vo.WebhookVO, len(webhooks))
+	for i, webhook := range webhooks {
+		result[i] = *s.webhookToVO(webhook)
+	}
+	return result, nil
+}
+
+// GetWebhook 取得 Webhook 訂閱
+func (s *webhookService) GetWebhook(ctx context.Context, userID, id uuid.UUID) (*vo.WebhookVO, error) {
+	webhook, err := s.getWebhook(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhookToVO(webhook), nil
+}
+
+// CreateWebhook 建立 Webhook 訂閱並產生簽章密鑰
+func (s *webhookService) CreateWebhook(ctx context.Context, userID uuid.UUID, req *dto.WebhookCreateRequest) (*vo.WebhookSecretVO, error) {
+	if err := s.validateWebhookURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &model.Webhook{
+		UserID:             userID,
+		Name:               req.Name,
+		URL:                req.URL,
+		Secret:             secret,
+		ThreatTypes:        model.StringArray(req.ThreatTypes),
+		Severities:         model.StringArray(req.Severities),
+		MinConfidenceScore: req.MinConfidenceScore,
+		Sources:            model.StringArray(req.Sources),
+		IsActive:           true,
+	}
+	if req.IsActive != nil {
+		webhook.IsActive = *req.IsActive
+	}
+
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return &vo.WebhookSecretVO{WebhookVO: *s.webhookToVO(webhook), Secret: secret}, nil
+}
+
+// UpdateWebhook 更新 Webhook 訂閱；重新啟用時清除自動停用的狀態
+func (s *webhookService) UpdateWebhook(ctx context.Context, userID, id uuid.UUID, req *dto.WebhookUpdateRequest) (*vo.WebhookVO, error) {
+	webhook, err := s.getWebhook(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.URL != nil {
+		if err := s.validateWebhookURL(ctx, *req.URL); err != nil {
+			return nil, err
+		}
+		updates["url"] = *req.URL
+	}
+	if req.ThreatTypes != nil {
+		updates["threat_types"] = model.StringArray(*req.ThreatTypes)
+	}
+	if req.Severities != nil {
+		updates["severities"] = model.StringArray(*req.Severities)
+	}
+	if req.MinConfidenceScore != nil {
+		updates["min_confidence_score"] = *req.MinConfidenceScore
+	}
+	if req.Sources != nil {
+		updates["sources"] = model.StringArray(*req.Sources)
+	}
+	if req.IsActive != nil {
+		if *req.IsActive {
+			// 依資料列當下的狀態判斷是否為重新啟用，派送器在讀取後才自動停用時同樣會清除
+			updates["consecutive_failures"] = gorm.Expr("CASE WHEN is_active THEN consecutive_failures ELSE 0 END")
+			updates["disabled_reason"] = gorm.Expr("CASE WHEN is_active THEN disabled_reason END")
+			updates["disabled_at"] = gorm.Expr("CASE WHEN is_active THEN disabled_at END")
+		}
+		updates["is_active"] = *req.IsActive
+	}
+	if len(updates) == 0 {
+		return s.webhookToVO(webhook), nil
+	}
+
+	updated, err := s.webhookRepo.Update(ctx, webhook.ID, updates)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return s.webhookToVO(updated), nil
+}
+
+// DeleteWebhook 刪除 Webhook 訂閱及其投遞紀錄
+func (s *webhookService) DeleteWebhook(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getWebhook(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.webhookRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ErrWebhookNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// RotateSecret 產生新的簽章密鑰，舊密鑰立即失效
+func (s *webhookService) RotateSecret(ctx context.Context, userID, id uuid.UUID) (*vo.WebhookSecretVO, error) {
+	webhook, err := s.getWebhook(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.webhookRepo.Update(ctx, webhook.ID, map[string]interface{}{"secret": secret})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &vo.WebhookSecretVO{WebhookVO: *s.webhookToVO(updated), Secret: secret}, nil
+}
+
+// ListDeliveries 取得 Webhook 的投遞紀錄
+func (s *webhookService) ListDeliveries(ctx context.Context, userID, id uuid.UUID, req *dto.WebhookDeliveryQueryRequest) (*vo.WebhookDeliveryListVO, error) {
+	if _, err := s.getWebhook(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	req.SetDefaults()
+
+	deliveries, total, err := s.deliveryRepo.List(ctx, &repository.WebhookDeliveryFilter{
+		WebhookID: id,
+		Status:    req.Status,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryVOs := make([]vo.WebhookDeliveryVO, len(deliveries))
+	for i, delivery := range deliveries {
+		deliveryVOs[i] = *s.deliveryToVO(delivery)
+	}
+
+	totalPages := int(total) / req.PageSize
+	if int(total)%req.PageSize > 0 {
+		totalPages++
+	}
+
+	return &vo.WebhookDeliveryListVO{
+		Data: deliveryVOs,
+		Pagination: vo.PaginationVO{
+			CurrentPage:  req.Page,
+			PageSize:     req.PageSize,
+			TotalPages:   totalPages,
+			TotalRecords: total,
+			HasNext:      req.Page < totalPages,
+			HasPrevious:  req.Page > 1,
+		},
+	}, nil
+}
+
+// getWebhook 取得使用者的 Webhook；不屬於該使用者時視同不存在
+func (s *webhookService) getWebhook(ctx context.Context, userID, id uuid.UUID) (*model.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, dto.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	if webhook.UserID != userID {
+		return nil, dto.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// validateWebhookURL 只接受 http 與 https，並拒絕指向本機、內部網路或雲端中繼資料服務的主機
+// 派送時仍會再檢查實際連線的位址，此處讓使用者在建立時就得到明確的錯誤
+func (s *webhookService) validateWebhookURL(ctx context.Context, raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return dto.ErrInvalidWebhookURL
+	}
+	if s.allowPrivateNetworks {
+		return nil
+	}
+	if err := netguard.CheckHost(ctx, parsed.Hostname()); err != nil {
+		return fmt.Errorf("%w: %v", dto.ErrWebhookURLNotAllowed, err)
+	}
+	return nil
+}
+
+// generateWebhookSecret 產生簽章密鑰
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(buf), nil
+}
+
+// webhookToVO 將 Webhook 模型轉換為 VO
+func (s *webhookService) webhookToVO(webhook *model.Webhook) *vo.WebhookVO {
+	return &vo.WebhookVO{
+		ID:                  webhook.ID,
+		Name:                webhook.Name,
+		URL:                 webhook.URL,
+		ThreatTypes:         nonNilStrings(webhook.ThreatTypes),
+		Severities:          nonNilStrings(webhook.Severities),
+		MinConfidenceScore:  webhook.MinConfidenceScore,
+		Sources:             nonNilStrings(webhook.Sources),
+		IsActive:            webhook.IsActive,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		DisabledReason:      webhook.DisabledReason,
+		DisabledAt:          webhook.DisabledAt,
+		LastSuccessAt:       webhook.LastSuccessAt,
+		LastFailureAt:       webhook.LastFailureAt,
+		CreatedAt:           webhook.CreatedAt,
+		UpdatedAt:           webhook.UpdatedAt,
+	}
+}
+
+// deliveryToVO 將投遞紀錄模型轉換為 VO
+func (s *webhookService) deliveryToVO(delivery *model.WebhookDelivery) *vo.WebhookDeliveryVO {
+	deliveryVO := &vo.WebhookDeliveryVO{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		DurationMs:     delivery.DurationMs,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+	if delivery.Status == model.WebhookDeliveryPending {
+		next := delivery.NextAttemptAt
+		deliveryVO.NextAttemptAt = &next
+	}
+	return deliveryVO
+}
+
+// nonNilStrings 將 nil 轉為空切片，讓 JSON 回應一律為陣列
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/dto"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/model"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/repository"
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/internal/testutil"
+)
+
+func TestWebhookService_UpdateKeepsDispatcherState(t *testing.T) {
+	db := testutil.OpenPostgres(t)
+	ctx := context.Background()
+	webhookRepo := repository.NewWebhookRepository(db)
+	service := NewWebhookService(webhookRepo, repository.NewWebhookDeliveryRepository(db), true)
+
+	user := &model.User{Username: "owner", Email: "owner@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(user).Error)
+	created, err := service.CreateWebhook(ctx, user.ID, &dto.WebhookCreateRequest{Name: "siem", URL: "https://hooks.example.com/siem"})
+	require.NoError(t, err)
+
+	// 派送器在使用者編輯期間自動停用 Webhook
+	disabled, err := webhookRepo.RecordFailure(ctx, created.ID, 1, "endpoint down")
+	require.NoError(t, err)
+	require.True(t, disabled)
+
+	name := "renamed"
+	updated, err := service.UpdateWebhook(ctx, user.ID, created.ID, &dto.WebhookUpdateRequest{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", updated.Name)
+	assert.False(t, updated.IsActive)
+	assert.Equal(t, 1, updated.ConsecutiveFailures)
+	require.NotNil(t, updated.DisabledReason)
+	assert.Equal(t, "endpoint down", *updated.DisabledReason)
+
+	rotated, err := service.RotateSecret(ctx, user.ID, created.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Secret, rotated.Secret)
+	assert.False(t, rotated.IsActive)
+
+	active := true
+	reactivated, err := service.UpdateWebhook(ctx, user.ID, created.ID, &dto.WebhookUpdateRequest{IsActive: &active})
+	require.NoError(t, err)
+	assert.True(t, reactivated.IsActive)
+	assert.Equal(t, 0, reactivated.ConsecutiveFailures)
+	assert.Nil(t, reactivated.DisabledReason)
+	assert.Equal(t, "renamed", reactivated.Name)
+
+	stored, err := webhookRepo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.Secret, stored.Secret)
+}
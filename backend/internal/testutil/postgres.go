@@ -0,0 +1,128 @@
+package testutil
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PostgresDSNEnv 整合測試使用的 PostgreSQL 連線字串環境變數
+const PostgresDSNEnv = "TEST_DATABASE_DSN"
+
+// OpenPostgres 連接整合測試資料庫，在獨立的 schema 中依序執行所有 up 遷移並清除種子資料後回傳連線
+// 未設定 TEST_DATABASE_DSN 時略過測試；測試結束後刪除該 schema
+func OpenPostgres(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db := OpenPostgresAt(t, "")
+	MigratePostgres(t, db, "")
+	// 初始遷移附帶開發用的種子資料，清除後每個測試都從空資料開始；內建角色由服務啟動時建立，需要的測試自行寫入
+	require.NoError(t, db.Exec("TRUNCATE threat_intelligence, intelligence_sources, users CASCADE").Error)
+
+	return db
+}
+
+// OpenPostgresAt 連接整合測試資料庫，在獨立的 schema 中只執行版本早於 version 的 up 遷移
+// 供資料遷移測試先寫入舊結構的資料，再以 MigratePostgres 執行其餘遷移；version 為空字串時不執行任何遷移
+func OpenPostgresAt(t testing.TB, version string) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(PostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set, skipping PostgreSQL integration test", PostgresDSNEnv)
+	}
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	admin := openPostgres(t, dsn)
+	// 擴充套件建立在 public，遷移中的 CREATE EXTENSION IF NOT EXISTS 因而略過，刪除 schema 時也不會一併移除
+	for _, stmt := range []string{
+		`CREATE EXTENSION IF NOT EXISTS "uuid-ossp" SCHEMA public`,
+		`CREATE EXTENSION IF NOT EXISTS "pgcrypto" SCHEMA public`,
+		"CREATE SCHEMA " + schema,
+	} {
+		require.NoError(t, admin.Exec(stmt).Error)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+	})
+
+	// search_path 以連線參數設定，連線池中的每條連線都使用測試 schema
+	db := openPostgres(t, withSearchPath(dsn, schema+",public"))
+	if version != "" {
+		applyMigrations(t, db, func(name string) bool { return name < version })
+	}
+	return db
+}
+
+// MigratePostgres 執行版本不早於 version 的 up 遷移；version 為空字串時執行所有遷移
+func MigratePostgres(t testing.TB, db *gorm.DB, version string) {
+	t.Helper()
+	applyMigrations(t, db, func(name string) bool { return name >= version })
+}
+
+// applyMigrations 依版本順序執行符合條件的 up 遷移檔
+func applyMigrations(t testing.TB, db *gorm.DB, include func(name string) bool) {
+	t.Helper()
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(migrationsDir(), "*.up.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no migrations found")
+	sort.Strings(files)
+	for _, file := range files {
+		if !include(filepath.Base(file)) {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		// 不帶參數時使用簡單查詢協定，可一次執行整個遷移檔
+		_, err = sqlDB.Exec(string(content))
+		require.NoError(t, err, filepath.Base(file))
+	}
+}
+
+// openPostgres 開啟連線，測試結束時關閉
+func openPostgres(t testing.TB, dsn string) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB.Close()
+	})
+	return db
+}
+
+// withSearchPath 在連線字串加上 search_path 參數，支援 URL 與 key=value 兩種格式
+func withSearchPath(dsn, searchPath string) string {
+	if strings.Contains(dsn, "://") {
+		if u, err := url.Parse(dsn); err == nil {
+			query := u.Query()
+			query.Set("search_path", searchPath)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path=" + searchPath
+}
+
+// migrationsDir 取得資料庫遷移目錄
+func migrationsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "database", "migrations")
+}
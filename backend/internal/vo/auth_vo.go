@@ -3,6 +3,8 @@ package vo
 import (
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // AuthTokenResponse 認證令牌回應
@@ -13,6 +15,84 @@ type AuthTokenResponse struct {
 	ExpiresIn    int       `json:"expires_in" example:"3600"`
 	ExpiresAt    time.Time `json:"expires_at" example:"2024-12-01T15:00:00Z"`
 	User         UserVO    `json:"user"`
+	// MFAEnrollmentRequired 角色要求多因素驗證但尚未啟用，需先完成設定才能使用受保護的功能
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty" example:"false"`
+}
+
+// MFAChallengeVO 多因素驗證登入挑戰，以 mfa_token 與驗證碼換發令牌
+type MFAChallengeVO struct {
+	MFARequired bool      `json:"mfa_required" example:"true"`
+	MFAToken    string    `json:"mfa_token" example:"3f9a1c..."`
+	ExpiresIn   int       `json:"expires_in" example:"300"`
+	ExpiresAt   time.Time `json:"expires_at" example:"2024-12-01T14:05:00Z"`
+}
+
+// MFAChallengeResponse 多因素驗證登入挑戰回應
+// @Description 密碼正確但需要第二步驗證時的回應資料
+type MFAChallengeResponse struct {
+	BaseResponse
+	Data *MFAChallengeVO `json:"data,omitempty"`
+}
+
+// OIDCAuthorizationVO 單一登入授權請求，前端將使用者導向 authorization_url
+// 前端應保存 state 並在回呼時比對，確認回呼來自自己發起的登入
+type OIDCAuthorizationVO struct {
+	AuthorizationURL string    `json:"authorization_url" example:"https://idp.example.com/authorize?response_type=code&client_id=..."`
+	State            string    `json:"state" example:"9c1f..."`
+	ExpiresAt        time.Time `json:"expires_at" example:"2024-12-01T14:10:00Z"`
+}
+
+// OIDCAuthorizationResponse 單一登入授權請求回應
+// @Description 開始單一登入後的回應資料
+type OIDCAuthorizationResponse struct {
+	BaseResponse
+	Data *OIDCAuthorizationVO `json:"data,omitempty"`
+}
+
+// MFAStatusVO 多因素驗證狀態
+type MFAStatusVO struct {
+	Enabled                bool       `json:"enabled" example:"true"`
+	Required               bool       `json:"required" example:"false"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty" example:"2024-12-01T14:00:00Z"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining" example:"10"`
+}
+
+// MFAStatusResponse 多因素驗證狀態回應
+// @Description 多因素驗證狀態的回應資料
+type MFAStatusResponse struct {
+	BaseResponse
+	Data *MFAStatusVO `json:"data,omitempty"`
+}
+
+// MFASetupVO TOTP 設定資訊，共享密鑰只在設定時回傳
+type MFASetupVO struct {
+	Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/USIP:user@example.com?secret=...&issuer=USIP"`
+}
+
+// MFASetupResponse TOTP 設定回應
+// @Description 開始設定 TOTP 後的回應資料，provisioning_uri 可轉為 QR Code
+type MFASetupResponse struct {
+	BaseResponse
+	Data *MFASetupVO `json:"data,omitempty"`
+}
+
+// MFARecoveryCodesVO 復原碼，只在產生時回傳一次
+type MFARecoveryCodesVO struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"ABCD-EFGH-IJKL-MNOP"`
+}
+
+// MFARecoveryCodesResponse 復原碼回應
+// @Description 啟用多因素驗證或重新產生復原碼後的回應資料
+type MFARecoveryCodesResponse struct {
+	BaseResponse
+	Data *MFARecoveryCodesVO `json:"data,omitempty"`
+}
+
+// DisableMFAResponse 停用多因素驗證回應
+// @Description 停用多因素驗證後的回應
+type DisableMFAResponse struct {
+	BaseResponse
 }
 
 // LoginResponse 登入回應
@@ -73,10 +153,18 @@ type GetUserListResponse struct {
 // ExtendedAPIKeyVO 擴展的API金鑰資訊（包含額外欄位）
 type ExtendedAPIKeyVO struct {
 	APIKeyVO
-	Key         string  `json:"key,omitempty" example:"sk_live_..."`
-	KeyPreview  string  `json:"key_preview" example:"sk_live_****_****_1234"`
-	UsedQuota   int     `json:"used_quota" example:"1250"`
-	LastUsedIP  *string `json:"last_used_ip,omitempty" example:"192.168.1.100"`
+	Key           string     `json:"key,omitempty" example:"usip_..."`
+	KeyPreview    string     `json:"key_preview" example:"usip_1a2****************************9f3c"`
+	UsedQuota     int        `json:"used_quota" example:"1250"`
+	LastUsedIP    *string    `json:"last_used_ip,omitempty" example:"192.168.1.100"`
+	RevokedAt     *time.Time `json:"revoked_at" example:"2024-01-01T10:00:00Z"`
+	RotatedFromID *uuid.UUID `json:"rotated_from_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+// RotateAPIKeyVO 輪替API金鑰結果，新金鑰只會在此時回傳完整內容
+type RotateAPIKeyVO struct {
+	APIKey         ExtendedAPIKeyVO `json:"api_key"`
+	PreviousAPIKey ExtendedAPIKeyVO `json:"previous_api_key"`
 }
 
 // CreateAPIKeyResponse 建立API金鑰回應
@@ -134,6 +222,18 @@ type ConfirmResetPasswordResponse struct {
 	BaseResponse
 }
 
+// VerifyEmailResponse 驗證信箱回應
+// @Description 驗證信箱後的回應
+type VerifyEmailResponse struct {
+	BaseResponse
+}
+
+// ResendVerificationResponse 重新發送驗證信回應
+// @Description 重新發送驗證信後的回應
+type ResendVerificationResponse struct {
+	BaseResponse
+}
+
 // LogoutResponse 登出回應
 // @Description 登出後的回應
 type LogoutResponse struct {
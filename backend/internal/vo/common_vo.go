@@ -75,7 +75,7 @@ type UserVO struct {
 	ID                    uuid.UUID  `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
 	Username              string     `json:"username" example:"admin"`
 	Email                 string     `json:"email" example:"admin@example.com"`
-	Role                  string     `json:"role" example:"admin" enums:"admin,premium,basic"`
+	Role                  string     `json:"role" example:"admin"`
 	IsActive              bool       `json:"is_active" example:"true"`
 	SubscriptionExpiresAt *time.Time `json:"subscription_expires_at" example:"2024-12-31T23:59:59Z"`
 	APIQuota              int        `json:"api_quota" example:"10000"`
@@ -120,12 +120,20 @@ type CollectionJobVO struct {
 	StartedAt        *time.Time `json:"started_at" example:"2024-01-01T10:00:00Z"`
 	CompletedAt      *time.Time `json:"completed_at" example:"2024-01-01T10:05:00Z"`
 	RecordsCollected int        `json:"records_collected" example:"250"`
+	RecordsCreated   int        `json:"records_created" example:"40"`
+	RecordsUpdated   int        `json:"records_updated" example:"210"`
 	ErrorMessage     *string    `json:"error_message" example:"Connection timeout"`
 	Duration         *string    `json:"duration" example:"5m30s"`
 	CreatedAt        time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
 	UpdatedAt        time.Time  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
 
+// CollectionJobListVO 收集任務列表回應
+type CollectionJobListVO struct {
+	Data       []CollectionJobVO `json:"data"`
+	Pagination PaginationVO      `json:"pagination"`
+}
+
 // StatsVO 統計回應
 type StatsVO struct {
 	Label string `json:"label" example:"Malware"`
@@ -141,6 +149,7 @@ type DashboardVO struct {
 	Statistics    []StatsVO              `json:"statistics"`
 	Timeline      []ThreatTimelineVO     `json:"timeline"`
 	Alerts        []AlertVO              `json:"alerts"`
+	GeneratedAt   time.Time              `json:"generated_at" example:"2024-01-01T00:00:00Z"`
 }
 
 // OverviewVO 概覽回應
@@ -0,0 +1,29 @@
+package vo
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PermissionVO 權限回應
+type PermissionVO struct {
+	Name        string `json:"name" example:"threat:write"`
+	Description string `json:"description" example:"建立與更新威脅情報"`
+}
+
+// RoleVO 角色回應
+type RoleVO struct {
+	Name        string    `json:"name" example:"analyst"`
+	Description string    `json:"description" example:"SOC 分析師"`
+	Permissions []string  `json:"permissions" example:"threat:read,threat:write"`
+	IsSystem    bool      `json:"is_system" example:"false"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// UserRoleVO 指派角色後的使用者角色回應
+type UserRoleVO struct {
+	UserID uuid.UUID `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Role   string    `json:"role" example:"analyst"`
+}
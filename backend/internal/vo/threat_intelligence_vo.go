@@ -9,8 +9,12 @@ import (
 // ThreatIntelligenceVO 威脅情報回應
 type ThreatIntelligenceVO struct {
 	ID              uuid.UUID              `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
-	IPAddress       string                 `json:"ip_address" example:"192.168.1.100"`
+	IndicatorType   string                 `json:"indicator_type" example:"ipv4" enums:"ipv4,ipv6,cidr,domain,url,md5,sha1,sha256,email,asn"`
+	IndicatorValue  string                 `json:"indicator_value" example:"192.168.1.100"`
+	IPAddress       string                 `json:"ip_address,omitempty" example:"192.168.1.100"`
 	Domain          *string                `json:"domain" example:"malicious.example.com"`
+	URL             *string                `json:"url" example:"http://malicious.example.com/payload.exe"`
+	FileHash        *string                `json:"file_hash" example:"44d88612fea8a8f36de82e1278abb02f"`
 	ThreatType      string                 `json:"threat_type" example:"malware" enums:"malware,phishing,spam,botnet,scanner,ddos,bruteforce,other"`
 	Severity        string                 `json:"severity" example:"high" enums:"low,medium,high,critical"`
 	ConfidenceScore int                    `json:"confidence_score" example:"85" minimum:"0" maximum:"100"`
@@ -37,22 +41,62 @@ type ThreatIntelligenceListVO struct {
 	Pagination PaginationVO           `json:"pagination"`
 }
 
+// ThreatIntelligenceSearchVO 全文搜尋回應
+type ThreatIntelligenceSearchVO struct {
+	Query      string                           `json:"query" example:"botnet \"command and control\""`
+	Data       []ThreatIntelligenceSearchHitVO `json:"data"`
+	Pagination PaginationVO                     `json:"pagination"`
+}
+
+// ThreatIntelligenceSearchHitVO 搜尋結果項目，包含相關度與命中片段
+// 命中片段已經過 HTML 跳脫，除 <mark> 標籤外不含任何標記
+type ThreatIntelligenceSearchHitVO struct {
+	ThreatIntelligenceVO
+	Rank       float64           `json:"rank" example:"0.42"`
+	Highlights map[string]string `json:"highlights" example:"{\"description\":\"known <mark>botnet</mark> node\"}"`
+}
+
 // ThreatIntelligenceStatsVO 威脅情報統計回應
 type ThreatIntelligenceStatsVO struct {
-	TotalThreats   int                    `json:"total_threats" example:"1000"`
-	HighRiskCount  int                    `json:"high_risk_count" example:"250"`
-	RecentCount    int                    `json:"recent_count" example:"50"`
-	CountByType    map[string]int         `json:"count_by_type" example:"{\"malware\":300,\"phishing\":200}"`
-	CountBySeverity map[string]int        `json:"count_by_severity" example:"{\"high\":250,\"medium\":500}"`
-	CountBySource  map[string]int         `json:"count_by_source" example:"{\"AbuseIPDB\":600,\"Manual\":400}"`
-	CountByCountry map[string]int         `json:"count_by_country" example:"{\"TW\":100,\"US\":200}"`
-	Timeline       []ThreatTimelineVO     `json:"timeline"`
+	TotalThreats         int                `json:"total_threats" example:"1000"`
+	ActiveCount          int                `json:"active_count" example:"600"`
+	HighRiskCount        int                `json:"high_risk_count" example:"250"`
+	RecentCount          int                `json:"recent_count" example:"50"`
+	CountByType          map[string]int     `json:"count_by_type" example:"{\"malware\":300,\"phishing\":200}"`
+	CountBySeverity      map[string]int     `json:"count_by_severity" example:"{\"high\":250,\"medium\":500}"`
+	CountBySource        map[string]int     `json:"count_by_source" example:"{\"AbuseIPDB\":600,\"Manual\":400}"`
+	CountByCountry       map[string]int     `json:"count_by_country" example:"{\"TW\":100,\"US\":200}"`
+	CountByIndicatorType map[string]int     `json:"count_by_indicator_type" example:"{\"ipv4\":800,\"domain\":200}"`
+	Interval             string             `json:"interval" example:"day" enums:"hour,day,week"`
+	TimeField            string             `json:"time_field" example:"created_at" enums:"created_at,last_seen"`
+	GroupBy              string             `json:"group_by,omitempty" example:"severity"`
+	StartTime            time.Time          `json:"start_time" example:"2024-01-01T00:00:00Z"`
+	EndTime              time.Time          `json:"end_time" example:"2024-01-31T00:00:00Z"`
+	Timeline             []ThreatTimelineVO `json:"timeline"`
 }
 
 // ThreatTimelineVO 威脅時間線回應
 type ThreatTimelineVO struct {
-	Date  string `json:"date" example:"2024-01-01"`
-	Count int    `json:"count" example:"25"`
+	Date      string         `json:"date" example:"2024-01-01"`
+	Timestamp time.Time      `json:"timestamp" example:"2024-01-01T00:00:00Z"`
+	Count     int            `json:"count" example:"25"`
+	Breakdown map[string]int `json:"breakdown,omitempty" example:"{\"high\":5,\"medium\":20}"`
+}
+
+// ThreatStatisticsVO 威脅情報整體統計回應
+type ThreatStatisticsVO struct {
+	TotalThreats       int       `json:"total_threats" example:"10000"`
+	ActiveThreats      int       `json:"active_threats" example:"5000"`
+	HighRiskThreats    int       `json:"high_risk_threats" example:"1000"`
+	MediumRiskThreats  int       `json:"medium_risk_threats" example:"3000"`
+	LowRiskThreats     int       `json:"low_risk_threats" example:"6000"`
+	RecentThreats      int       `json:"recent_threats" example:"500"`
+	ThreatTypeStats    []StatsVO `json:"threat_type_stats"`
+	SeverityStats      []StatsVO `json:"severity_stats"`
+	SourceStats        []StatsVO `json:"source_stats"`
+	CountryStats       []StatsVO `json:"country_stats"`
+	IndicatorTypeStats []StatsVO `json:"indicator_type_stats"`
+	GeneratedAt        time.Time `json:"generated_at" example:"2024-01-01T00:00:00Z"`
 }
 
 // ThreatIntelligenceIPLookupVO IP 查詢回應
@@ -79,6 +123,19 @@ type ThreatIntelligenceDomainLookupVO struct {
 	Details         []ThreatIntelligenceVO `json:"details"`
 }
 
+// ThreatIntelligenceIndicatorLookupVO 指標查詢回應
+type ThreatIntelligenceIndicatorLookupVO struct {
+	IndicatorType   string                 `json:"indicator_type" example:"sha256"`
+	IndicatorValue  string                 `json:"indicator_value" example:"275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f"`
+	IsKnownThreat   bool                   `json:"is_known_threat" example:"true"`
+	ThreatCount     int                    `json:"threat_count" example:"1"`
+	HighestSeverity string                 `json:"highest_severity" example:"high"`
+	Sources         []string               `json:"sources" example:"AbuseIPDB,Manual"`
+	FirstSeen       *time.Time             `json:"first_seen" example:"2024-01-01T00:00:00Z"`
+	LastSeen        *time.Time             `json:"last_seen" example:"2024-01-02T00:00:00Z"`
+	Details         []ThreatIntelligenceVO `json:"details"`
+}
+
 // ThreatIntelligenceBulkCreateVO 批量建立回應
 type ThreatIntelligenceBulkCreateVO struct {
 	Success      []ThreatIntelligenceVO `json:"success"`
@@ -86,6 +143,8 @@ type ThreatIntelligenceBulkCreateVO struct {
 	TotalCount   int                    `json:"total_count" example:"100"`
 	SuccessCount int                    `json:"success_count" example:"95"`
 	FailedCount  int                    `json:"failed_count" example:"5"`
+	CreatedCount int                    `json:"created_count" example:"80"` // 新建立的記錄數
+	UpdatedCount int                    `json:"updated_count" example:"15"` // 合併到既有記錄的數量
 }
 
 // ThreatIntelligenceBulkUpdateVO 批量更新回應
@@ -95,6 +154,8 @@ type ThreatIntelligenceBulkUpdateVO struct {
 	TotalCount   int                    `json:"total_count" example:"50"`
 	SuccessCount int                    `json:"success_count" example:"48"`
 	FailedCount  int                    `json:"failed_count" example:"2"`
+	Atomic       bool                   `json:"atomic" example:"false"`
+	RolledBack   bool                   `json:"rolled_back" example:"false"` // 原子模式下因失敗而全部回滾
 }
 
 // ThreatIntelligenceBulkDeleteVO 批量刪除回應
@@ -104,6 +165,8 @@ type ThreatIntelligenceBulkDeleteVO struct {
 	TotalCount   int                  `json:"total_count" example:"50"`
 	SuccessCount int                  `json:"success_count" example:"48"`
 	FailedCount  int                  `json:"failed_count" example:"2"`
+	Atomic       bool                 `json:"atomic" example:"false"`
+	RolledBack   bool                 `json:"rolled_back" example:"false"` // 原子模式下因失敗而全部回滾
 }
 
 // BulkOperationError 批量操作錯誤
@@ -144,6 +207,7 @@ type ThreatIntelligenceMetricsVO struct {
 	TopMaliciousDomains []string               `json:"top_malicious_domains"`
 	RecentActivity      []ThreatTimelineVO     `json:"recent_activity"`
 	CollectionStatus    map[string]interface{} `json:"collection_status"`
+	GeneratedAt         time.Time              `json:"generated_at" example:"2024-01-01T00:00:00Z"`
 }
 
 // ThreatIntelligenceHealthVO 健康狀態回應
@@ -0,0 +1,56 @@
+package vo
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookVO Webhook 訂閱回應，不含簽章密鑰
+type WebhookVO struct {
+	ID                  uuid.UUID  `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name                string     `json:"name" example:"SOC 告警"`
+	URL                 string     `json:"url" example:"https://soc.example.com/hooks/threats"`
+	ThreatTypes         []string   `json:"threat_types" example:"malware,botnet"`
+	Severities          []string   `json:"severities" example:"high,critical"`
+	MinConfidenceScore  int        `json:"min_confidence_score" example:"70"`
+	Sources             []string   `json:"sources" example:"AbuseIPDB"`
+	IsActive            bool       `json:"is_active" example:"true"`
+	ConsecutiveFailures int        `json:"consecutive_failures" example:"0"`
+	DisabledReason      *string    `json:"disabled_reason" example:"連續投遞失敗 20 次後自動停用"`
+	DisabledAt          *time.Time `json:"disabled_at" example:"2024-01-01T10:00:00Z"`
+	LastSuccessAt       *time.Time `json:"last_success_at" example:"2024-01-01T10:00:00Z"`
+	LastFailureAt       *time.Time `json:"last_failure_at" example:"2024-01-01T09:00:00Z"`
+	CreatedAt           time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt           time.Time  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// WebhookSecretVO 建立 Webhook 或輪替密鑰的回應，簽章密鑰只會在此時回傳
+type WebhookSecretVO struct {
+	WebhookVO
+	Secret string `json:"secret" example:"whsec_3f9a..."`
+}
+
+// WebhookDeliveryVO Webhook 投遞紀錄回應
+type WebhookDeliveryVO struct {
+	ID             uuid.UUID       `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	WebhookID      uuid.UUID       `json:"webhook_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	EventID        string          `json:"event_id" example:"1024"`
+	EventType      string          `json:"event_type" example:"created" enums:"created,updated,deleted,alert"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" example:"succeeded" enums:"pending,succeeded,failed"`
+	Attempts       int             `json:"attempts" example:"1"`
+	ResponseStatus *int            `json:"response_status" example:"200"`
+	LastError      *string         `json:"last_error" example:"webhook responded with status 503"`
+	DurationMs     *int            `json:"duration_ms" example:"120"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at" example:"2024-01-01T10:05:00Z"`
+	CreatedAt      time.Time       `json:"created_at" example:"2024-01-01T10:00:00Z"`
+	DeliveredAt    *time.Time      `json:"delivered_at" example:"2024-01-01T10:00:01Z"`
+}
+
+// WebhookDeliveryListVO Webhook 投遞紀錄列表回應
+type WebhookDeliveryListVO struct {
+	Data       []WebhookDeliveryVO `json:"data"`
+	Pagination PaginationVO        `json:"pagination"`
+}
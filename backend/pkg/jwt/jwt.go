@@ -9,12 +9,22 @@ import (
 	"github.com/google/uuid"
 )
 
+// tokenTimePrecision 令牌時間欄位的精度
+// 撤銷截止時間需與 iat 比較到次秒，才能區分同一秒內撤銷前後簽發的令牌
+const tokenTimePrecision = time.Microsecond
+
+func init() {
+	// golang-jwt 預設將時間截斷為秒；RFC 7519 的 NumericDate 允許小數
+	jwt.TimePrecision = tokenTimePrecision
+}
+
 // JWTClaims JWT聲明結構
 type JWTClaims struct {
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
 	Email    string    `json:"email"`
 	Role     string    `json:"role"`
+	MFA      bool      `json:"mfa,omitempty"` // 本次登入是否已通過多因素驗證
 	jwt.RegisteredClaims
 }
 
@@ -24,6 +34,7 @@ type JWTManager struct {
 	issuer       string
 	expiration   time.Duration
 	refreshToken time.Duration
+	revocations  RevocationStore
 }
 
 // NewJWTManager 建立JWT管理器
@@ -38,12 +49,18 @@ func NewJWTManager(secretKey string, issuer string, expiration int) *JWTManager
 
 // GenerateToken 生成存取令牌
 func (m *JWTManager) GenerateToken(userID uuid.UUID, username, email, role string) (string, error) {
+	return m.GenerateTokenWithMFA(userID, username, email, role, false)
+}
+
+// GenerateTokenWithMFA 生成存取令牌並標記本次登入是否已通過多因素驗證
+func (m *JWTManager) GenerateTokenWithMFA(userID uuid.UUID, username, email, role string, mfa bool) (string, error) {
 	now := time.Now()
 	claims := &JWTClaims{
 		UserID:   userID,
 		Username: username,
 		Email:    email,
 		Role:     role,
+		MFA:      mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    m.issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -57,10 +74,19 @@ func (m *JWTManager) GenerateToken(userID uuid.UUID, username, email, role strin
 	return token.SignedString([]byte(m.secretKey))
 }
 
+// RefreshTokenClaims 刷新令牌聲明，ID 為 jti、Subject 為使用者 ID
+type RefreshTokenClaims = jwt.RegisteredClaims
+
 // GenerateRefreshToken 生成刷新令牌
 func (m *JWTManager) GenerateRefreshToken(userID uuid.UUID) (string, error) {
+	token, _, err := m.IssueRefreshToken(userID)
+	return token, err
+}
+
+// IssueRefreshToken 生成刷新令牌並回傳其聲明，供呼叫端以 jti 與到期時間持久化
+func (m *JWTManager) IssueRefreshToken(userID uuid.UUID) (string, *RefreshTokenClaims, error) {
 	now := time.Now()
-	claims := &jwt.RegisteredClaims{
+	claims := &RefreshTokenClaims{
 		Subject:   userID.String(),
 		Issuer:    m.issuer,
 		IssuedAt:  jwt.NewNumericDate(now),
@@ -70,7 +96,11 @@ func (m *JWTManager) GenerateRefreshToken(userID uuid.UUID) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.secretKey))
+	signed, err := token.SignedString([]byte(m.secretKey))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
 // VerifyToken 驗證令牌
@@ -97,6 +127,21 @@ func (m *JWTManager) VerifyToken(tokenString string) (*JWTClaims, error) {
 
 // VerifyRefreshToken 驗證刷新令牌
 func (m *JWTManager) VerifyRefreshToken(tokenString string) (uuid.UUID, error) {
+	claims, err := m.parseRefreshToken(tokenString)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid user ID in token: %w", err)
+	}
+
+	return userID, nil
+}
+
+// parseRefreshToken 解析並驗證刷新令牌的簽章與有效期
+func (m *JWTManager) parseRefreshToken(tokenString string) (*jwt.RegisteredClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -105,20 +150,15 @@ func (m *JWTManager) VerifyRefreshToken(tokenString string) (uuid.UUID, error) {
 	})
 
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
 
 	claims, ok := token.Claims.(*jwt.RegisteredClaims)
 	if !ok || !token.Valid {
-		return uuid.Nil, errors.New("invalid refresh token claims")
+		return nil, errors.New("invalid refresh token claims")
 	}
 
-	userID, err := uuid.Parse(claims.Subject)
-	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid user ID in token: %w", err)
-	}
-
-	return userID, nil
+	return claims, nil
 }
 
 // ExtractTokenFromHeader 從HTTP標頭提取令牌
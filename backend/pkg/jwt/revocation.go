@@ -0,0 +1,291 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// 撤銷相關錯誤
+var (
+	ErrTokenRevoked          = errors.New("token has been revoked")
+	ErrRevocationCheckFailed = errors.New("token revocation check failed")
+)
+
+// RevocationStore 令牌撤銷儲存介面
+// 單一令牌以 jti 記錄；「登出所有裝置」則記錄使用者的截止時間，在此之前簽發的令牌一律失效
+// 所有紀錄都帶有 TTL，令牌自然過期後即可移除
+type RevocationStore interface {
+	Revoke(ctx context.Context, jti string, ttl time.Duration) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeBefore(ctx context.Context, userID uuid.UUID, before time.Time, ttl time.Duration) error
+	RevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error)
+}
+
+// memorySweepInterval 記憶體儲存清除過期紀錄的間隔
+const memorySweepInterval = time.Minute
+
+// MemoryRevocationStore 記憶體撤銷儲存，僅適用單一執行個體
+type MemoryRevocationStore struct {
+	mu        sync.Mutex
+	tokens    map[string]time.Time // jti -> 紀錄到期時間
+	users     map[uuid.UUID]userCutoff
+	lastSweep time.Time
+}
+
+// userCutoff 使用者的令牌截止時間
+type userCutoff struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+// NewMemoryRevocationStore 建立記憶體撤銷儲存
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens:    make(map[string]time.Time),
+		users:     make(map[uuid.UUID]userCutoff),
+		lastSweep: time.Now(),
+	}
+}
+
+// Revoke 撤銷單一令牌
+func (s *MemoryRevocationStore) Revoke(ctx context.Context, jti string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	s.tokens[jti] = now.Add(ttl)
+	return nil
+}
+
+// IsRevoked 檢查令牌是否已撤銷
+func (s *MemoryRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expiresAt, ok := s.tokens[jti]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+// RevokeBefore 撤銷使用者在 before 之前簽發的所有令牌；截止時間只會往後移
+func (s *MemoryRevocationStore) RevokeBefore(ctx context.Context, userID uuid.UUID, before time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	if current, ok := s.users[userID]; ok && current.before.After(before) {
+		before = current.before
+	}
+	s.users[userID] = userCutoff{before: before, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// RevokedBefore 取得使用者的令牌截止時間，沒有時回傳零值
+func (s *MemoryRevocationStore) RevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff, ok := s.users[userID]
+	if !ok || !time.Now().Before(cutoff.expiresAt) {
+		return time.Time{}, nil
+	}
+	return cutoff.before, nil
+}
+
+// sweep 定期移除過期紀錄，呼叫端須持有鎖
+func (s *MemoryRevocationStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for jti, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, cutoff := range s.users {
+		if !now.Before(cutoff.expiresAt) {
+			delete(s.users, userID)
+		}
+	}
+}
+
+// RedisCommander 執行 Redis 指令的最小介面，pkg/redis 的 Client 即符合
+type RedisCommander interface {
+	Do(ctx context.Context, args ...string) (interface{}, error)
+}
+
+// Redis 鍵前綴
+const (
+	redisRevokedTokenPrefix = "auth:revoked:jti:"
+	redisRevokedUserPrefix  = "auth:revoked:user:"
+)
+
+// RedisRevocationStore Redis 撤銷儲存，多個執行個體共用；以 PX 設定 TTL 由伺服器自動清除
+type RedisRevocationStore struct {
+	client RedisCommander
+}
+
+// NewRedisRevocationStore 建立 Redis 撤銷儲存
+func NewRedisRevocationStore(client RedisCommander) *RedisRevocationStore {
+	return &RedisRevocationStore{client: client}
+}
+
+// Revoke 撤銷單一令牌
+func (s *RedisRevocationStore) Revoke(ctx context.Context, jti string, ttl time.Duration) error {
+	_, err := s.client.Do(ctx, "SET", redisRevokedTokenPrefix+jti, "1", "PX", redisTTL(ttl))
+	return err
+}
+
+// IsRevoked 檢查令牌是否已撤銷
+func (s *RedisRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	reply, err := s.client.Do(ctx, "EXISTS", redisRevokedTokenPrefix+jti)
+	if err != nil {
+		return false, err
+	}
+	count, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected EXISTS reply %T", reply)
+	}
+	return count > 0, nil
+}
+
+// RevokeBefore 撤銷使用者在 before 之前簽發的所有令牌，以帶六位小數（微秒）的 Unix 秒記錄
+func (s *RedisRevocationStore) RevokeBefore(ctx context.Context, userID uuid.UUID, before time.Time, ttl time.Duration) error {
+	value := fmt.Sprintf("%d.%06d", before.Unix(), before.Nanosecond()/int(time.Microsecond))
+	_, err := s.client.Do(ctx, "SET", redisRevokedUserPrefix+userID.String(), value, "PX", redisTTL(ttl))
+	return err
+}
+
+// RevokedBefore 取得使用者的令牌截止時間，沒有時回傳零值
+func (s *RedisRevocationStore) RevokedBefore(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	reply, err := s.client.Do(ctx, "GET", redisRevokedUserPrefix+userID.String())
+	if err != nil || reply == nil {
+		return time.Time{}, err
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected GET reply %T", reply)
+	}
+	return parseRevocationCutoff(value)
+}
+
+// parseRevocationCutoff 解析 Redis 中的截止時間；舊版以整數秒記錄，不帶小數
+func parseRevocationCutoff(value string) (time.Time, error) {
+	secondsPart, microsPart, _ := strings.Cut(value, ".")
+	seconds, err := strconv.ParseInt(secondsPart, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid revocation cutoff %q: %w", value, err)
+	}
+	var micros int64
+	if microsPart != "" {
+		if len(microsPart) != 6 {
+			return time.Time{}, fmt.Errorf("invalid revocation cutoff %q", value)
+		}
+		if micros, err = strconv.ParseInt(microsPart, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("invalid revocation cutoff %q: %w", value, err)
+		}
+	}
+	return time.Unix(seconds, micros*int64(time.Microsecond)), nil
+}
+
+// redisTTL 將 TTL 轉為毫秒字串，至少 1 毫秒
+func redisTTL(ttl time.Duration) string {
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// SetRevocationStore 設定撤銷儲存；未設定時不檢查撤銷
+func (m *JWTManager) SetRevocationStore(store RevocationStore) {
+	m.revocations = store
+}
+
+// VerifyTokenContext 驗證存取令牌並檢查是否已撤銷
+// 令牌被撤銷時回傳 ErrTokenRevoked；撤銷儲存無法查詢時回傳 ErrRevocationCheckFailed，呼叫端應拒絕請求
+func (m *JWTManager) VerifyTokenContext(ctx context.Context, tokenString string) (*JWTClaims, error) {
+	claims, err := m.VerifyToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkRevoked(ctx, claims.UserID, claims.ID, claims.IssuedAt); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyRefreshTokenContext 驗證刷新令牌並檢查使用者是否已登出所有裝置，回傳令牌聲明
+func (m *JWTManager) VerifyRefreshTokenContext(ctx context.Context, tokenString string) (*RefreshTokenClaims, error) {
+	claims, err := m.parseRefreshToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID in token: %w", err)
+	}
+	if err := m.checkRevoked(ctx, userID, claims.ID, claims.IssuedAt); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// RevokeToken 撤銷單一存取令牌，紀錄保留到令牌原本的到期時間
+func (m *JWTManager) RevokeToken(ctx context.Context, claims *JWTClaims) error {
+	if m.revocations == nil || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return m.revocations.Revoke(ctx, claims.ID, ttl)
+}
+
+// RevokeAllTokens 撤銷使用者目前為止簽發的所有存取與刷新令牌
+// 紀錄保留到最長的刷新令牌到期為止；截止時間與 iat 皆精確到微秒，撤銷後立即簽發的令牌不受影響
+func (m *JWTManager) RevokeAllTokens(ctx context.Context, userID uuid.UUID) error {
+	if m.revocations == nil {
+		return nil
+	}
+	ttl := m.refreshToken
+	if m.expiration > ttl {
+		ttl = m.expiration
+	}
+	return m.revocations.RevokeBefore(ctx, userID, time.Now(), ttl)
+}
+
+// checkRevoked 檢查 jti 是否被撤銷，或令牌是否在使用者的截止時間之前（含同一微秒）簽發
+// 舊版令牌的 iat 只有整數秒，會被視為在該秒開頭簽發，與截止時間同一秒時一併失效
+// 未帶 iat 的令牌無法判斷簽發時間，在使用者設有截止時間時一律視為已撤銷
+func (m *JWTManager) checkRevoked(ctx context.Context, userID uuid.UUID, jti string, issuedAt *jwt.NumericDate) error {
+	if m.revocations == nil {
+		return nil
+	}
+
+	if jti != "" {
+		revoked, err := m.revocations.IsRevoked(ctx, jti)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrRevocationCheckFailed, err)
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	cutoff, err := m.revocations.RevokedBefore(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRevocationCheckFailed, err)
+	}
+	if !cutoff.IsZero() && (issuedAt == nil || !issuedAt.After(cutoff.Truncate(tokenTimePrecision))) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
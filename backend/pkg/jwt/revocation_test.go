@@ -0,0 +1,215 @@
+package jwt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgredis "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/redis"
+)
+
+// fakeRedis 僅支援 PING、SET PX、GET、EXISTS 的 RESP 伺服器
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	values   map[string]string
+	expiry   map[string]time.Time
+}
+
+func startFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeRedis{listener: listener, values: map[string]string{}, expiry: map[string]time.Time{}}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		fmt.Fprint(conn, f.exec(args))
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := ""
+	if len(args) > 1 {
+		key = args[1]
+		if deadline, ok := f.expiry[key]; ok && !time.Now().Before(deadline) {
+			delete(f.values, key)
+			delete(f.expiry, key)
+		}
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SET":
+		f.values[key] = args[2]
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			ms, _ := strconv.Atoi(args[4])
+			f.expiry[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	case "GET":
+		value, ok := f.values[key]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "EXISTS":
+		if _, ok := f.values[key]; ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestJWTManager_Revocation(t *testing.T) {
+	redisServer := startFakeRedis(t)
+	redisClient := pkgredis.NewClient(pkgredis.Options{Addr: redisServer.listener.Addr().String()})
+	defer redisClient.Close()
+
+	stores := map[string]RevocationStore{
+		"memory": NewMemoryRevocationStore(),
+		"redis":  NewRedisRevocationStore(redisClient),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			manager := NewJWTManager("test-secret", "test", 1)
+			manager.SetRevocationStore(store)
+
+			userID := uuid.New()
+			token, err := manager.GenerateToken(userID, "alice", "alice@example.com", "basic")
+			require.NoError(t, err)
+			other, err := manager.GenerateToken(userID, "alice", "alice@example.com", "basic")
+			require.NoError(t, err)
+
+			claims, err := manager.VerifyTokenContext(ctx, token)
+			require.NoError(t, err)
+
+			// 撤銷單一令牌不影響同一使用者的其他令牌
+			require.NoError(t, manager.RevokeToken(ctx, claims))
+			_, err = manager.VerifyTokenContext(ctx, token)
+			assert.ErrorIs(t, err, ErrTokenRevoked)
+			_, err = manager.VerifyTokenContext(ctx, other)
+			assert.NoError(t, err)
+
+			// 登出所有裝置後，先前簽發的存取與刷新令牌都失效
+			refresh, err := manager.GenerateRefreshToken(userID)
+			require.NoError(t, err)
+			require.NoError(t, manager.RevokeAllTokens(ctx, userID))
+			_, err = manager.VerifyTokenContext(ctx, other)
+			assert.ErrorIs(t, err, ErrTokenRevoked)
+			_, err = manager.VerifyRefreshTokenContext(ctx, refresh)
+			assert.ErrorIs(t, err, ErrTokenRevoked)
+
+			// 截止時間之後簽發的令牌不受影響，即使與截止時間在同一秒
+			fresh, err := manager.GenerateToken(userID, "alice", "alice@example.com", "basic")
+			require.NoError(t, err)
+			_, err = manager.VerifyTokenContext(ctx, fresh)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestJWTManager_RevocationCheckFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	manager := NewJWTManager("test-secret", "test", 1)
+	manager.SetRevocationStore(NewRedisRevocationStore(pkgredis.NewClient(pkgredis.Options{Addr: addr, DialTimeout: time.Second})))
+
+	token, err := manager.GenerateToken(uuid.New(), "alice", "alice@example.com", "basic")
+	require.NoError(t, err)
+
+	_, err = manager.VerifyTokenContext(context.Background(), token)
+	assert.ErrorIs(t, err, ErrRevocationCheckFailed, "unreachable store must not let tokens through")
+}
+
+func TestParseRevocationCutoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		expected  time.Time
+		expectErr bool
+	}{
+		{name: "microseconds", value: "1712345678.000123", expected: time.Unix(1712345678, 123000)},
+		{name: "legacy seconds", value: "1712345678", expected: time.Unix(1712345678, 0)},
+		{name: "short fraction", value: "1712345678.5", expectErr: true},
+		{name: "not a number", value: "abc", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cutoff, err := parseRevocationCutoff(tt.value)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(cutoff), "got %v", cutoff)
+		})
+	}
+}
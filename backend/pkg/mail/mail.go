@@ -0,0 +1,205 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pkglogger "github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/logger"
+)
+
+// ErrInvalidHeader 標頭含有換行字元，可能是標頭注入
+var ErrInvalidHeader = errors.New("mail: header contains line break")
+
+// Message 純文字郵件
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Sender 郵件發送介面
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// build 組成 RFC 5322 郵件內容，主旨以 MIME 編碼、內文以 quoted-printable 編碼
+func (m *Message) build(from string) ([]byte, error) {
+	if len(m.To) == 0 {
+		return nil, errors.New("mail: no recipients")
+	}
+	for _, value := range append([]string{from, m.Subject}, m.To...) {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	writer := quotedprintable.NewWriter(&buf)
+	if _, err := writer.Write([]byte(m.Body)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SMTPConfig SMTP 連線設定
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration // 連線與傳送逾時，預設 30 秒
+}
+
+// SMTPSender 透過 SMTP 發送郵件；465 連接埠使用隱式 TLS，其餘連接埠在伺服器支援時升級 STARTTLS
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPSender 建立 SMTP 發送器
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{cfg: cfg}
+}
+
+// Send 發送郵件
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.build(s.cfg.From)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	dialer := net.Dialer{Timeout: s.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("mail: failed to connect to %s: %w", addr, err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.cfg.Timeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host}
+	if s.cfg.Port == "465" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mail: failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("mail: STARTTLS failed: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("mail: authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("mail: MAIL FROM rejected: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("mail: RCPT TO %s rejected: %w", to, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mail: DATA rejected: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("mail: failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("mail: message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// FileSender 將郵件寫成 .eml 檔案，供本機開發與測試檢視
+type FileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender 建立檔案發送器
+func NewFileSender(dir, from string) *FileSender {
+	return &FileSender{dir: dir, from: from}
+}
+
+// Send 將郵件寫入目錄，檔名以時間排序
+func (s *FileSender) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.build(s.from)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("mail: failed to create directory: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("mail: failed to write message: %w", err)
+	}
+	return nil
+}
+
+// LogSender 將郵件內容寫入日誌，僅供本機開發；內文可能含有重設連結等敏感資訊，正式環境不可使用
+type LogSender struct{}
+
+// NewLogSender 建立日誌發送器
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send 將郵件寫入日誌
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	pkglogger.Info("Mail message", pkglogger.Fields{
+		"to":      strings.Join(msg.To, ", "),
+		"subject": msg.Subject,
+		"body":    msg.Body,
+	})
+	return nil
+}
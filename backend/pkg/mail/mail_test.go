@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender_Send(t *testing.T) {
+	dir := t.TempDir()
+	sender := NewFileSender(dir, "noreply@example.com")
+
+	err := sender.Send(context.Background(), &Message{
+		To:      []string{"alice@example.com"},
+		Subject: "重設密碼",
+		Body:    "https://example.com/reset-password?token=abc",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "To: alice@example.com\r\n")
+	assert.Contains(t, content, "Subject: =?utf-8?q?")
+	assert.Contains(t, content, "token=3Dabc", "body must be quoted-printable encoded")
+}
+
+func TestMessage_RejectsHeaderInjection(t *testing.T) {
+	sender := NewFileSender(t.TempDir(), "noreply@example.com")
+
+	err := sender.Send(context.Background(), &Message{
+		To:      []string{"alice@example.com\r\nBcc: victim@example.com"},
+		Subject: "hello",
+	})
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+
+	err = sender.Send(context.Background(), &Message{
+		To:      []string{"alice@example.com"},
+		Subject: "hello\nBcc: victim@example.com",
+	})
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
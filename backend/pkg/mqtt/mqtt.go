@@ -73,6 +73,9 @@ func NewMQTTClient(broker, username, password string) MQTTClientInterface {
 	opts.SetConnectTimeout(10 * time.Second)
 	opts.SetAutoReconnect(true)
 	opts.SetMaxReconnectInterval(10 * time.Second)
+	// 初次連線失敗時持續在背景重試，broker 恢復後即可送出暫存的通知
+	opts.SetConnectRetry(true)
+	opts.SetConnectRetryInterval(10 * time.Second)
 	opts.SetCleanSession(true)
 
 	// 設定連線處理器
@@ -110,8 +113,12 @@ func (m *MQTTClient) Connect() error {
 		"client_id": m.clientID,
 	})
 
+	// 啟用連線重試時 token 要到連線成功才會完成，逾時後改由背景繼續重試
 	token := m.client.Connect()
-	if token.Wait() && token.Error() != nil {
+	if !token.WaitTimeout(m.options.ConnectTimeout) {
+		return fmt.Errorf("timed out connecting to MQTT broker, retrying in background")
+	}
+	if token.Error() != nil {
 		return fmt.Errorf("failed to connect to MQTT broker: %w", token.Error())
 	}
 
@@ -125,8 +132,10 @@ func (m *MQTTClient) Connect() error {
 
 // Disconnect 斷開MQTT連接
 func (m *MQTTClient) Disconnect() {
-	if m.client.IsConnected() {
-		m.client.Disconnect(1000) // 1秒超時
+	connected := m.client.IsConnected()
+	// 尚未連上時也需呼叫，以停止背景連線重試
+	m.client.Disconnect(1000) // 1秒超時
+	if connected {
 		pkglogger.Info("MQTT client disconnected", pkglogger.Fields{
 			"client_id": m.clientID,
 		})
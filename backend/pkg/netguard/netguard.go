@@ -0,0 +1,110 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress 目的位址屬於本機、內部網路或雲端中繼資料服務
+var ErrBlockedAddress = errors.New("netguard: destination address is not allowed")
+
+// blockedNetworks net.IP 判斷方法未涵蓋的保留網段
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // 本網路
+	"100.64.0.0/10",  // 電信級 NAT，部分雲端的中繼資料服務位於此網段
+	"192.0.0.0/24",   // IETF 協定保留
+	"198.18.0.0/15",  // 效能測試
+	"240.0.0.0/4",    // 保留與廣播
+	"64:ff9b::/96",   // NAT64，可對應到任意 IPv4 位址
+	"64:ff9b:1::/48", // 本地 NAT64
+	"2002::/16",      // 6to4，可嵌入內部 IPv4 位址
+)
+
+// IsBlockedIP 檢查位址是否為本機、私有、鏈路本地（含 169.254.169.254 中繼資料服務）、未指定、多播或其他保留位址
+func IsBlockedIP(ip net.IP) bool {
+	if ip == nil {
+		return true
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Control 作為 net.Dialer.Control，於 DNS 解析後、實際連線前檢查位址，避免 DNS rebinding 繞過主機名稱檢查
+func Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if IsBlockedIP(net.ParseIP(host)) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// CheckHost 檢查主機名稱或 IP；名稱解析出任何受限位址即拒絕，解析失敗則交由連線時的檢查處理
+func CheckHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if IsBlockedIP(ip) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if IsBlockedIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrBlockedAddress, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// NewHTTPClient 建立只能連到公開位址的 HTTP 客戶端
+// 不使用環境變數設定的代理（代理會代為解析目的位址而繞過檢查），也不跟隨重新導向，3xx 回應直接回傳給呼叫端
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   Control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// mustParseCIDRs 解析網段清單
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
@@ -0,0 +1,44 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBlockedIP(t *testing.T) {
+	blocked := []string{
+		"127.0.0.1", "::1", "0.0.0.0", "::", "10.1.2.3", "172.16.0.1", "192.168.1.1",
+		"169.254.169.254", "100.100.100.200", "fd00:ec2::254", "fe80::1", "::ffff:127.0.0.1", "224.0.0.1",
+	}
+	for _, addr := range blocked {
+		assert.True(t, IsBlockedIP(net.ParseIP(addr)), addr)
+	}
+
+	for _, addr := range []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"} {
+		assert.False(t, IsBlockedIP(net.ParseIP(addr)), addr)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := context.Background()
+	assert.ErrorIs(t, CheckHost(ctx, "169.254.169.254"), ErrBlockedAddress)
+	assert.ErrorIs(t, CheckHost(ctx, "localhost"), ErrBlockedAddress)
+	assert.NoError(t, CheckHost(ctx, "8.8.8.8"))
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	_, err := NewHTTPClient(time.Second).Get(server.URL)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBlockedAddress), err.Error())
+}
@@ -0,0 +1,132 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lipeichen/Ultimate-Security-Intelligence-Platform/backend/pkg/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:3000/auth/callback"
+
+// authorize 走完授權端點，回傳授權碼
+func authorize(t *testing.T, provider *Provider, state, nonce, verifier string) string {
+	t.Helper()
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, CodeChallengeS256(verifier))
+	require.NoError(t, err)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, state, location.Query().Get("state"))
+	return location.Query().Get("code")
+}
+
+func TestProvider_AuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewServer("usip", "secret")
+	defer idp.Close()
+	idp.SetUser(oidctest.User{
+		Subject:       "alice",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Claims: map[string]interface{}{
+			"realm_access": map[string]interface{}{"roles": []string{"analyst", "soc-admin"}},
+			"amr":          []string{"pwd", "mfa"},
+		},
+	})
+
+	provider := NewProvider(Config{
+		IssuerURL:    idp.URL,
+		ClientID:     "usip",
+		ClientSecret: "secret",
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"email", "profile"},
+	})
+	ctx := context.Background()
+
+	verifier, err := GenerateCodeVerifier()
+	require.NoError(t, err)
+	code := authorize(t, provider, "state-1", "nonce-1", verifier)
+
+	token, err := provider.Exchange(ctx, code, verifier)
+	require.NoError(t, err)
+
+	idToken, err := provider.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", idToken.Subject)
+	assert.Equal(t, "alice@example.com", idToken.Email)
+	assert.True(t, idToken.EmailVerified)
+	assert.True(t, idToken.HasMFA())
+	assert.Equal(t, []string{"analyst", "soc-admin"}, StringClaims(idToken.Claims, "realm_access.roles"))
+
+	_, err = provider.VerifyIDToken(ctx, token.IDToken, "other-nonce")
+	assert.ErrorIs(t, err, ErrInvalidIDToken, "nonce must match the authorization request")
+
+	_, err = provider.Exchange(ctx, code, verifier)
+	assert.ErrorIs(t, err, ErrTokenExchange, "authorization codes are single use")
+}
+
+func TestProvider_RejectsInvalidTokens(t *testing.T) {
+	idp := oidctest.NewServer("usip", "")
+	defer idp.Close()
+
+	provider := NewProvider(Config{IssuerURL: idp.URL, ClientID: "usip", RedirectURL: redirectURL})
+	ctx := context.Background()
+
+	verifier, err := GenerateCodeVerifier()
+	require.NoError(t, err)
+	code := authorize(t, provider, "state", "nonce", verifier)
+	_, err = provider.Exchange(ctx, code, "wrong-verifier")
+	assert.ErrorIs(t, err, ErrTokenExchange, "PKCE verifier must match the challenge")
+
+	valid := jwt.MapClaims{
+		"iss":   idp.URL,
+		"sub":   "alice",
+		"aud":   "usip",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"nonce": "nonce",
+	}
+	tests := map[string]func(jwt.MapClaims){
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "another-client" },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+		"missing sub":    func(c jwt.MapClaims) { delete(c, "sub") },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims := jwt.MapClaims{}
+			for k, v := range valid {
+				claims[k] = v
+			}
+			mutate(claims)
+			raw, err := idp.SignIDToken(claims)
+			require.NoError(t, err)
+
+			_, err = provider.VerifyIDToken(ctx, raw, "nonce")
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+
+	raw, err := idp.SignIDToken(valid)
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(ctx, raw, "nonce")
+	assert.NoError(t, err)
+}
+
+func TestProvider_Unavailable(t *testing.T) {
+	provider := NewProvider(Config{IssuerURL: "http://127.0.0.1:1", ClientID: "usip"})
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+}
@@ -0,0 +1,284 @@
+// Package oidctest 提供本地模擬的 OpenID Connect 身分提供者，供測試與本機開發使用
+// 授權端點不顯示登入畫面，直接以目前設定的使用者核發授權碼並導回用戶端
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// keyID 簽章金鑰 ID
+const keyID = "oidctest"
+
+// User 模擬登入的使用者
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Claims            map[string]interface{} // 額外宣告，例如角色或群組
+}
+
+// authRequest 已核發授權碼對應的授權請求
+type authRequest struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	user          User
+}
+
+// Provider 模擬的身分提供者，實作 http.Handler
+type Provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // 為空時不驗證用戶端密鑰
+
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	user  User
+	codes map[string]authRequest
+}
+
+// NewProvider 建立模擬身分提供者，issuer 須為實際對外提供服務的網址
+func NewProvider(issuer, clientID, clientSecret string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		user: User{
+			Subject:           "user-1",
+			Email:             "user@example.com",
+			EmailVerified:     true,
+			Name:              "Test User",
+			PreferredUsername: "testuser",
+		},
+		codes: make(map[string]authRequest),
+	}, nil
+}
+
+// Server 以 httptest 啟動的模擬身分提供者
+type Server struct {
+	*httptest.Server
+	*Provider
+}
+
+// NewServer 啟動模擬身分提供者，issuer 為伺服器網址；使用完畢須呼叫 Close
+func NewServer(clientID, clientSecret string) *Server {
+	srv := httptest.NewUnstartedServer(nil)
+	provider, err := NewProvider("http://"+srv.Listener.Addr().String(), clientID, clientSecret)
+	if err != nil {
+		panic("oidctest: failed to generate signing key: " + err.Error())
+	}
+	srv.Config.Handler = provider
+	srv.Start()
+	return &Server{Server: srv, Provider: provider}
+}
+
+// SetUser 設定之後授權請求登入的使用者
+func (p *Provider) SetUser(user User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.user = user
+}
+
+// ServeHTTP 處理探索、授權、權杖與 JWKS 端點
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		p.handleDiscovery(w)
+	case "/authorize":
+		p.handleAuthorize(w, r)
+	case "/token":
+		p.handleToken(w, r)
+	case "/jwks":
+		p.handleJWKS(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleDiscovery 探索文件
+func (p *Provider) handleDiscovery(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// handleAuthorize 直接以目前使用者核發授權碼並導回 redirect_uri
+func (p *Provider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if query.Get("client_id") != p.ClientID || redirectURI == "" {
+		http.Error(w, "invalid client_id or redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "authorization code flow with S256 PKCE is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authRequest{
+		clientID:      query.Get("client_id"),
+		redirectURI:   redirectURI,
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		user:          p.user,
+	}
+	p.mu.Unlock()
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := target.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// handleToken 驗證用戶端、授權碼與 PKCE 後核發 ID 令牌；授權碼只能使用一次
+func (p *Provider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+	if !p.authenticateClient(r) {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	p.mu.Lock()
+	request, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if !ok || request.redirectURI != r.PostForm.Get("redirect_uri") || challenge != request.codeChallenge {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	idToken, err := p.signIDToken(request)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// handleJWKS 公開簽章金鑰
+func (p *Provider) handleJWKS(w http.ResponseWriter) {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   encode(p.key.N.Bytes()),
+			"e":   encode(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// SignIDToken 以模擬身分提供者的金鑰簽署任意宣告，供測試無效或竄改的令牌
+func (p *Provider) SignIDToken(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(p.key)
+}
+
+// signIDToken 依授權請求簽署 ID 令牌
+func (p *Provider) signIDToken(request authRequest) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                p.Issuer,
+		"sub":                request.user.Subject,
+		"aud":                request.clientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"email":              request.user.Email,
+		"email_verified":     request.user.EmailVerified,
+		"name":               request.user.Name,
+		"preferred_username": request.user.PreferredUsername,
+	}
+	if request.nonce != "" {
+		claims["nonce"] = request.nonce
+	}
+	for name, value := range request.user.Claims {
+		claims[name] = value
+	}
+	return p.SignIDToken(claims)
+}
+
+// authenticateClient 支援 client_secret_basic 與 client_secret_post
+func (p *Provider) authenticateClient(r *http.Request) bool {
+	clientID, secret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != p.ClientID {
+		return false
+	}
+	return p.ClientSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(p.ClientSecret)) == 1
+}
+
+// randomString 產生隨機字串
+func randomString() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// writeOAuthError 回傳 OAuth 錯誤
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+// writeJSON 回傳 JSON
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
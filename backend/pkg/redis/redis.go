@@ -0,0 +1,264 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrClosed 客戶端已關閉
+var ErrClosed = errors.New("redis: client is closed")
+
+// Error Redis 伺服器回傳的錯誤回覆，連線仍可繼續使用
+type Error string
+
+// Error 實作 error 介面
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// Options Redis 連線設定，適用於任何相容 RESP 協定的伺服器（Redis、Valkey、KeyDB 等）
+type Options struct {
+	Addr        string
+	Password    string
+	DB          int
+	PoolSize    int           // 閒置連線上限，預設 10
+	DialTimeout time.Duration // 建立連線逾時，預設 5 秒
+	IOTimeout   time.Duration // context 未設定期限時的單一指令逾時，預設 3 秒
+}
+
+// Client 精簡的 RESP 客戶端，內建連線池，可安全併發使用
+type Client struct {
+	opts Options
+	idle chan *conn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// conn 單一連線
+type conn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+}
+
+// NewClient 建立 Redis 客戶端；連線在第一次執行指令時才建立
+func NewClient(opts Options) *Client {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	if opts.IOTimeout <= 0 {
+		opts.IOTimeout = 3 * time.Second
+	}
+	return &Client{
+		opts: opts,
+		idle: make(chan *conn, opts.PoolSize),
+	}
+}
+
+// Ping 檢查伺服器是否可連線
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Do 執行指令並回傳回覆：簡單字串與大量字串為 string、整數為 int64、陣列為 []interface{}，不存在的值為 nil
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, c.opts.IOTimeout, args)
+	var serverErr Error
+	if err != nil && !errors.As(err, &serverErr) {
+		// 讀寫失敗後連線狀態不明，直接丟棄
+		cn.netConn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close 關閉客戶端及所有閒置連線
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.idle)
+	for cn := range c.idle {
+		cn.netConn.Close()
+	}
+	return nil
+}
+
+// get 取得閒置連線，沒有時建立新連線
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	select {
+	case cn, ok := <-c.idle:
+		if ok {
+			return cn, nil
+		}
+		return nil, ErrClosed
+	default:
+	}
+	return c.dial(ctx)
+}
+
+// put 歸還連線；連線池已滿或客戶端已關閉時關閉連線
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		cn.netConn.Close()
+		return
+	}
+	select {
+	case c.idle <- cn:
+	default:
+		cn.netConn.Close()
+	}
+}
+
+// dial 建立連線並完成認證與選擇資料庫
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := net.Dialer{Timeout: c.opts.DialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect to %s: %w", c.opts.Addr, err)
+	}
+	cn := &conn{netConn: netConn, reader: bufio.NewReader(netConn)}
+
+	if c.opts.Password != "" {
+		if _, err := cn.do(ctx, c.opts.IOTimeout, []string{"AUTH", c.opts.Password}); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("redis: authentication failed: %w", err)
+		}
+	}
+	if c.opts.DB != 0 {
+		if _, err := cn.do(ctx, c.opts.IOTimeout, []string{"SELECT", strconv.Itoa(c.opts.DB)}); err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("redis: failed to select database %d: %w", c.opts.DB, err)
+		}
+	}
+	return cn, nil
+}
+
+// do 送出指令並讀取回覆
+func (cn *conn) do(ctx context.Context, timeout time.Duration, args []string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := cn.netConn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := cn.netConn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(cn.reader)
+}
+
+// encodeCommand 將指令編碼為 RESP 大量字串陣列
+func encodeCommand(args []string) []byte {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+// readReply 讀取一個 RESP 回覆
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line[1:])
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line[1:])
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			item, err := readReply(r)
+			var serverErr Error
+			if err != nil && !errors.As(err, &serverErr) {
+				return nil, err
+			}
+			if err != nil {
+				item = serverErr
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", line[0])
+	}
+}
+
+// readLine 讀取以 CRLF 結尾的一行
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("redis: malformed reply line %q", line)
+	}
+	return line[:len(line)-2], nil
+}